@@ -0,0 +1,623 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"gin/api/middleware"
+	"gin/internal/models"
+	"gin/internal/services/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// AdminHandler serves the /admin API for moderators and admins. Routes must run after
+// middleware.RequireRole; moderators can do everything except manage roles.
+type AdminHandler struct {
+	users         database.UserRepository
+	admin         database.AdminRepository
+	moderationLog database.ModerationRepository
+}
+
+// NewAdminHandler creates a new AdminHandler.
+func NewAdminHandler(users database.UserRepository, admin database.AdminRepository, moderationLog database.ModerationRepository) *AdminHandler {
+	return &AdminHandler{users: users, admin: admin, moderationLog: moderationLog}
+}
+
+// adminUsersQuery binds and validates the query parameters of GET /admin/users.
+type adminUsersQuery struct {
+	Q         string `form:"q" binding:"omitempty,max=200"`
+	Role      string `form:"role" binding:"omitempty,oneof=user moderator admin"`
+	Suspended *bool  `form:"suspended"`
+	Page      int    `form:"page" binding:"omitempty,min=1"`
+	PageSize  int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// ListUsers godoc
+// @Summary List and search users
+// @Description Lists every user, newest first, including suspended users and those pending deletion. q matches part of the username or GitHub URL, or a user or Clerk ID exactly.
+// @Tags Admin
+// @Produce json
+// @Security ClerkAuth
+// @Param q query string false "Search text"
+// @Param role query string false "Only users with this role" Enums(user, moderator, admin)
+// @Param suspended query bool false "Only suspended (true) or active (false) users"
+// @Param page query int false "Page number, from 1"
+// @Param page_size query int false "Users per page (max 100)"
+// @Success 200 {object} gin.H "users, page, page_size and has_more"
+// @Failure 400 {object} gin.H "Invalid query"
+// @Failure 403 {object} gin.H "Not a moderator"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /admin/users [get]
+func (h *AdminHandler) ListUsers(c *gin.Context) {
+	var query adminUsersQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + err.Error()})
+		return
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 50
+	}
+
+	filter := models.AdminUserFilter{Query: query.Q, Role: models.UserRole(query.Role), Suspended: query.Suspended}
+	// Fetch one extra row to know whether another page exists
+	offset := (query.Page - 1) * query.PageSize
+	users, err := h.admin.ListUsers(c.Request.Context(), filter, query.PageSize+1, offset)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Error listing users for admin", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to list users"})
+		return
+	}
+	hasMore := len(users) > query.PageSize
+	if hasMore {
+		users = users[:query.PageSize]
+	}
+
+	accounts := make([]*models.UserAccount, len(users))
+	for i := range users {
+		accounts[i] = models.NewUserAccount(&users[i])
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"users":     accounts,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"has_more":  hasMore,
+	})
+}
+
+// GetUser godoc
+// @Summary Get any user
+// @Tags Admin
+// @Produce json
+// @Security ClerkAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} models.UserAccount
+// @Failure 403 {object} gin.H "Not a moderator"
+// @Failure 404 {object} gin.H "User not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /admin/users/{id} [get]
+func (h *AdminHandler) GetUser(c *gin.Context) {
+	target, ok := h.targetUser(c)
+	if !ok {
+		return
+	}
+	c.JSON(http.StatusOK, models.NewUserAccount(target))
+}
+
+// suspendRequest is the body of POST /admin/users/:id/suspend.
+type suspendRequest struct {
+	Reason string `json:"reason" binding:"required,max=500"` // Shown to the suspended user
+}
+
+// SuspendUser godoc
+// @Summary Suspend a user
+// @Description Locks the user out of the API and hides them from decks and search until the suspension is lifted. Only users with a lower role than the caller can be suspended. Suspending a suspended user updates the reason.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ClerkAuth
+// @Param id path string true "User ID"
+// @Param request body suspendRequest true "Reason for the suspension"
+// @Success 200 {object} models.UserAccount "The suspended user"
+// @Failure 400 {object} gin.H "Invalid request or own account"
+// @Failure 403 {object} gin.H "Not a moderator, or the user's role is not below the caller's"
+// @Failure 404 {object} gin.H "User not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /admin/users/{id}/suspend [post]
+func (h *AdminHandler) SuspendUser(c *gin.Context) {
+	var req suspendRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A reason is required"})
+		return
+	}
+
+	actor, target, ok := h.actOnUser(c)
+	if !ok {
+		return
+	}
+	if err := h.admin.SuspendUser(c.Request.Context(), target.ID, reason); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), "Error suspending user", "user_id", target.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suspend user"})
+		return
+	}
+	middleware.ForgetUser(c, target.ClerkUserID)
+	logger.InfoContext(c.Request.Context(), "User suspended", "actor_id", actor.ID, "actor_role", actor.Role, "user_id", target.ID, "reason", reason)
+
+	h.respondWithUser(c, target.ID)
+}
+
+// UnsuspendUser godoc
+// @Summary Lift a user's suspension
+// @Tags Admin
+// @Produce json
+// @Security ClerkAuth
+// @Param id path string true "User ID"
+// @Success 200 {object} models.UserAccount "The reinstated user"
+// @Failure 400 {object} gin.H "Own account"
+// @Failure 403 {object} gin.H "Not a moderator, or the user's role is not below the caller's"
+// @Failure 404 {object} gin.H "User not found"
+// @Failure 409 {object} gin.H "User is not suspended"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /admin/users/{id}/unsuspend [post]
+func (h *AdminHandler) UnsuspendUser(c *gin.Context) {
+	actor, target, ok := h.actOnUser(c)
+	if !ok {
+		return
+	}
+	if err := h.admin.UnsuspendUser(c.Request.Context(), target.ID); err != nil {
+		if errors.Is(err, database.ErrNotSuspended) {
+			c.JSON(http.StatusConflict, gin.H{"error": "User is not suspended"})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), "Error lifting suspension", "user_id", target.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to lift suspension"})
+		return
+	}
+	middleware.ForgetUser(c, target.ClerkUserID)
+	logger.InfoContext(c.Request.Context(), "Suspension lifted", "actor_id", actor.ID, "actor_role", actor.Role, "user_id", target.ID)
+
+	h.respondWithUser(c, target.ID)
+}
+
+// setRoleRequest is the body of PUT /admin/users/:id/role.
+type setRoleRequest struct {
+	Role string `json:"role" binding:"required,oneof=user moderator admin"`
+}
+
+// SetUserRole godoc
+// @Summary Change a user's role
+// @Description Admins only. Admins cannot change their own role, so the last admin cannot lock everyone out. A role set in the user's Clerk public metadata takes precedence and is applied again on the next sync from Clerk.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ClerkAuth
+// @Param id path string true "User ID"
+// @Param request body setRoleRequest true "New role"
+// @Success 200 {object} models.UserAccount "The updated user"
+// @Failure 400 {object} gin.H "Invalid request or own account"
+// @Failure 403 {object} gin.H "Not an admin"
+// @Failure 404 {object} gin.H "User not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /admin/users/{id}/role [put]
+func (h *AdminHandler) SetUserRole(c *gin.Context) {
+	var req setRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+
+	actor, ok := currentUserProfile(c)
+	if !ok {
+		return
+	}
+	target, ok := h.targetUser(c)
+	if !ok {
+		return
+	}
+	if target.ID == actor.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot change your own role"})
+		return
+	}
+
+	role := models.UserRole(req.Role)
+	if err := h.admin.SetUserRole(c.Request.Context(), target.ID, role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), "Error setting role", "user_id", target.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to change role"})
+		return
+	}
+	middleware.ForgetUser(c, target.ClerkUserID)
+	logger.InfoContext(c.Request.Context(), "Role changed", "actor_id", actor.ID, "user_id", target.ID, "from", target.Role, "to", role)
+
+	h.respondWithUser(c, target.ID)
+}
+
+// reportsQuery binds and validates the query parameters of GET /admin/reports.
+type reportsQuery struct {
+	Status   string `form:"status" binding:"omitempty,oneof=open resolved dismissed all"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// GetReports godoc
+// @Summary List reports
+// @Description Lists reports filed against users, oldest first, so the queue is worked in order.
+// @Tags Admin
+// @Produce json
+// @Security ClerkAuth
+// @Param status query string false "Report status (default open)" Enums(open, resolved, dismissed, all)
+// @Param page query int false "Page number, from 1"
+// @Param page_size query int false "Reports per page (max 100)"
+// @Success 200 {object} gin.H "reports, page, page_size and has_more"
+// @Failure 400 {object} gin.H "Invalid query"
+// @Failure 403 {object} gin.H "Not a moderator"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /admin/reports [get]
+func (h *AdminHandler) GetReports(c *gin.Context) {
+	var query reportsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + err.Error()})
+		return
+	}
+	status := models.ReportStatus(query.Status)
+	switch query.Status {
+	case "":
+		status = models.ReportOpen
+	case "all":
+		status = ""
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 50
+	}
+
+	// Fetch one extra row to know whether another page exists
+	offset := (query.Page - 1) * query.PageSize
+	reports, err := h.admin.GetReports(c.Request.Context(), status, query.PageSize+1, offset)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Error fetching reports", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reports"})
+		return
+	}
+	hasMore := len(reports) > query.PageSize
+	if hasMore {
+		reports = reports[:query.PageSize]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"reports":   reports,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"has_more":  hasMore,
+	})
+}
+
+// reportDetail is a report with everything a moderator needs to review it.
+type reportDetail struct {
+	Report       models.Report            `json:"report"`
+	Messages     []models.ReportedMessage `json:"messages"`           // Copies of the reported messages
+	ReportedUser *models.UserAccount      `json:"reportedUser"`       // Nil only if the lookup raced with a purge
+	Reporter     *models.UserAccount      `json:"reporter,omitempty"` // Nil once the reporter deleted their account
+}
+
+// GetReport godoc
+// @Summary Review a report
+// @Description Returns the report with copies of the messages attached to it, the reported user and the reporter.
+// @Tags Admin
+// @Produce json
+// @Security ClerkAuth
+// @Param id path string true "Report ID"
+// @Success 200 {object} reportDetail
+// @Failure 403 {object} gin.H "Not a moderator"
+// @Failure 404 {object} gin.H "Report not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /admin/reports/{id} [get]
+func (h *AdminHandler) GetReport(c *gin.Context) {
+	ctx := c.Request.Context()
+	reportID := c.Param("id")
+	report, err := h.admin.GetReport(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch report"})
+		}
+		return
+	}
+	messages, err := h.admin.GetReportMessages(ctx, reportID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch reported messages"})
+		return
+	}
+
+	detail := reportDetail{Report: *report, Messages: messages}
+	reportedUser, err := h.users.GetUserProfileByID(ctx, report.ReportedUserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reported user"})
+		return
+	}
+	detail.ReportedUser = models.NewUserAccount(reportedUser)
+	if report.ReporterUserID != nil {
+		reporter, err := h.users.GetUserProfileByID(ctx, *report.ReporterUserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reporter"})
+			return
+		}
+		detail.Reporter = models.NewUserAccount(reporter)
+	}
+	c.JSON(http.StatusOK, detail)
+}
+
+// resolveReportRequest is the body of POST /admin/reports/:id/resolve.
+type resolveReportRequest struct {
+	Status  string   `json:"status" binding:"required,oneof=resolved dismissed"`
+	Actions []string `json:"actions" binding:"omitempty,max=2,dive,oneof=suspend_user remove_messages"` // Only when resolved
+	Note    *string  `json:"note" binding:"omitempty,max=1000"`                                         // Also the suspension reason, if given
+}
+
+// ResolveReport godoc
+// @Summary Close a report
+// @Description Marks an open report as resolved (action was taken) or dismissed (nothing to act on). A resolution can take actions first: suspend_user suspends the reported user (subject to the same role rules as POST /admin/users/{id}/suspend) and remove_messages deletes the reported messages from their conversation. The actions are recorded on the report.
+// @Tags Admin
+// @Accept json
+// @Produce json
+// @Security ClerkAuth
+// @Param id path string true "Report ID"
+// @Param request body resolveReportRequest true "Outcome, actions and an optional note"
+// @Success 200 {object} models.Report "The closed report"
+// @Failure 400 {object} gin.H "Invalid request"
+// @Failure 403 {object} gin.H "Not a moderator, or not allowed to suspend the reported user"
+// @Failure 404 {object} gin.H "Report not found"
+// @Failure 409 {object} gin.H "Report was already closed"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /admin/reports/{id}/resolve [post]
+func (h *AdminHandler) ResolveReport(c *gin.Context) {
+	var req resolveReportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	resolution := models.ReportResolution{Status: models.ReportStatus(req.Status), Note: req.Note}
+	for _, action := range req.Actions {
+		resolution.Actions = append(resolution.Actions, models.ReportAction(action))
+	}
+	if resolution.Status == models.ReportDismissed && len(resolution.Actions) > 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "A dismissed report cannot take actions"})
+		return
+	}
+	actor, ok := currentUserProfile(c)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	reportID := c.Param("id")
+	report, err := h.admin.GetReport(ctx, reportID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch report"})
+		}
+		return
+	}
+	if report.Status != models.ReportOpen {
+		c.JSON(http.StatusConflict, gin.H{"error": "Report was already closed"})
+		return
+	}
+
+	// Take the actions before closing the report, so a failed action leaves it in the queue
+	for _, action := range resolution.Actions {
+		switch action {
+		case models.ReportActionSuspendUser:
+			target, err := h.users.GetUserProfileByID(ctx, report.ReportedUserID)
+			if err != nil {
+				logger.ErrorContext(ctx, "Error fetching reported user", "user_id", report.ReportedUserID, "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve reported user"})
+				return
+			}
+			if !canModerate(c, actor, target) {
+				return
+			}
+			reason := "Reported for " + string(report.Reason)
+			if req.Note != nil && strings.TrimSpace(*req.Note) != "" {
+				reason = strings.TrimSpace(*req.Note)
+			}
+			if err := h.admin.SuspendUser(ctx, target.ID, reason); err != nil {
+				logger.ErrorContext(ctx, "Error suspending user for report", "user_id", target.ID, "report_id", report.ID, "error", err)
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to suspend user"})
+				return
+			}
+			middleware.ForgetUser(c, target.ClerkUserID)
+			logger.InfoContext(ctx, "User suspended for report", "actor_id", actor.ID, "actor_role", actor.Role, "user_id", target.ID, "report_id", report.ID)
+
+		case models.ReportActionRemoveMessages:
+			removed, err := h.admin.RemoveReportedMessages(ctx, report.ID)
+			if err != nil {
+				c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to remove reported messages"})
+				return
+			}
+			logger.InfoContext(ctx, "Reported messages removed", "actor_id", actor.ID, "report_id", report.ID, "removed", removed)
+		}
+	}
+
+	report, err = h.admin.ResolveReport(ctx, reportID, actor.ID, resolution)
+	if err != nil {
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Report not found"})
+		case errors.Is(err, database.ErrReportNotOpen):
+			c.JSON(http.StatusConflict, gin.H{"error": "Report was already closed"})
+		default:
+			logger.ErrorContext(ctx, "Error resolving report", "report_id", reportID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to resolve report"})
+		}
+		return
+	}
+	logger.InfoContext(ctx, "Report resolved", "actor_id", actor.ID, "report_id", report.ID, "status", report.Status)
+
+	c.JSON(http.StatusOK, report)
+}
+
+// moderationQuery binds and validates the query parameters of GET /admin/moderation.
+type moderationQuery struct {
+	Decision    string `form:"decision" binding:"omitempty,oneof=flag reject"`
+	ContentType string `form:"content_type" binding:"omitempty,oneof=message bio"`
+	UserID      string `form:"user_id"`
+	Page        int    `form:"page" binding:"omitempty,min=1"`
+	PageSize    int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// GetModerationEvents godoc
+// @Summary List moderated content
+// @Description Lists messages and bios the moderation pipeline flagged (saved, but worth a look) or rejected (never saved), newest first, with the content and the rule that triggered.
+// @Tags Admin
+// @Produce json
+// @Security ClerkAuth
+// @Param decision query string false "Only flagged or only rejected content" Enums(flag, reject)
+// @Param content_type query string false "Only messages or only bios" Enums(message, bio)
+// @Param user_id query string false "Only content by this user"
+// @Param page query int false "Page number, from 1"
+// @Param page_size query int false "Entries per page (max 100)"
+// @Success 200 {object} gin.H "events, page, page_size and has_more"
+// @Failure 400 {object} gin.H "Invalid query"
+// @Failure 403 {object} gin.H "Not a moderator"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /admin/moderation [get]
+func (h *AdminHandler) GetModerationEvents(c *gin.Context) {
+	var query moderationQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + err.Error()})
+		return
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 50
+	}
+
+	filter := models.ModerationEventFilter{
+		Decision:    models.ModerationDecision(query.Decision),
+		ContentType: models.ModerationContentType(query.ContentType),
+		UserID:      query.UserID,
+	}
+	// Fetch one extra row to know whether another page exists
+	offset := (query.Page - 1) * query.PageSize
+	events, err := h.moderationLog.GetModerationEvents(c.Request.Context(), filter, query.PageSize+1, offset)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch moderation log"})
+		return
+	}
+	hasMore := len(events) > query.PageSize
+	if hasMore {
+		events = events[:query.PageSize]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"events":    events,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"has_more":  hasMore,
+	})
+}
+
+// GetStats godoc
+// @Summary System statistics
+// @Description Counts users by role and state, recent activity, swipes, matches, messages and open reports.
+// @Tags Admin
+// @Produce json
+// @Security ClerkAuth
+// @Success 200 {object} models.SystemStats
+// @Failure 403 {object} gin.H "Not a moderator"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /admin/stats [get]
+func (h *AdminHandler) GetStats(c *gin.Context) {
+	stats, err := h.admin.GetSystemStats(c.Request.Context(), time.Now())
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Error computing system stats", "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to compute stats"})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// targetUser loads the user named by the :id parameter, writing a 404 or 500 and
+// returning false if that fails.
+func (h *AdminHandler) targetUser(c *gin.Context) (*models.User, bool) {
+	userID := c.Param("id")
+	user, err := h.users.GetUserProfileByID(c.Request.Context(), userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			logger.ErrorContext(c.Request.Context(), "Error fetching user for admin", "user_id", userID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		}
+		return nil, false
+	}
+	return user, true
+}
+
+// actOnUser returns the caller and the :id user for moderation actions, which are only
+// allowed on other users whose role is below the caller's: moderators act on users,
+// admins on users and moderators.
+func (h *AdminHandler) actOnUser(c *gin.Context) (actor, target *models.User, ok bool) {
+	actor, ok = currentUserProfile(c)
+	if !ok {
+		return nil, nil, false
+	}
+	target, ok = h.targetUser(c)
+	if !ok {
+		return nil, nil, false
+	}
+	if !canModerate(c, actor, target) {
+		return nil, nil, false
+	}
+	return actor, target, true
+}
+
+// canModerate reports whether actor may take moderation actions against target, writing
+// a 400 or 403 if not.
+func canModerate(c *gin.Context, actor, target *models.User) bool {
+	if target.ID == actor.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot moderate your own account"})
+		return false
+	}
+	if target.Role.AtLeast(actor.Role) {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You can only moderate users with a lower role than yours"})
+		return false
+	}
+	return true
+}
+
+// respondWithUser writes the current state of userID after a change.
+func (h *AdminHandler) respondWithUser(c *gin.Context, userID string) {
+	user, err := h.users.GetUserProfileByID(c.Request.Context(), userID)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Error reloading user after admin change", "user_id", userID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Change saved, but failed to reload the user"})
+		return
+	}
+	c.JSON(http.StatusOK, models.NewUserAccount(user))
+}
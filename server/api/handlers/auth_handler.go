@@ -1,58 +1,165 @@
 package handlers
 
 import (
-	"log"
+	"context"
+	"database/sql"
+	"errors"
 	"net/http"
+	"time"
 
 	"gin/api/middleware"
+	"gin/internal/models"
+	"gin/internal/services"
 	"gin/internal/services/database"
+	"gin/internal/services/githubsync"
 
+	"github.com/clerkinc/clerk-sdk-go/clerk"
 	"github.com/gin-gonic/gin"
-	"github.com/jackc/pgx/v5"
 )
 
+// ClerkUserFetcher reads users from Clerk. *services.ClerkService implements it.
+type ClerkUserFetcher interface {
+	GetClerkUserMetadata(ctx context.Context, userID string) (*clerk.User, error)
+}
+
 type AuthHandler struct {
-	DBService *database.DBService
-	// Add Clerk client or other services if needed directly
+	users         database.UserRepository
+	accounts      database.AccountRepository
+	clerkUsers    ClerkUserFetcher   // Source of the identity used to provision new profiles; nil without Clerk
+	githubSync    *githubsync.Syncer // Fetches the GitHub stats of new profiles; optional
+	deletionGrace time.Duration      // How long a deletion request can be cancelled before the account is purged
 }
 
-func NewAuthHandler(db *database.DBService) *AuthHandler {
-	return &AuthHandler{DBService: db}
+func NewAuthHandler(users database.UserRepository, accounts database.AccountRepository, clerkUsers ClerkUserFetcher, githubSync *githubsync.Syncer, deletionGrace time.Duration) *AuthHandler {
+	return &AuthHandler{users: users, accounts: accounts, clerkUsers: clerkUsers, githubSync: githubSync, deletionGrace: deletionGrace}
 }
 
 // GetCurrentUserProfile godoc
 // @Summary Get current authenticated user's profile
-// @Description Retrieves the profile data for the user authenticated via Clerk.
+// @Description Retrieves the profile data for the user authenticated via Clerk. On the first request of a new user the profile is created from their Clerk account, pre-filled with the username, avatar and linked GitHub account, and returned with 201.
 // @Tags Auth
 // @Produce json
 // @Security ClerkAuth
-// @Success 200 {object} models.User "Successfully retrieved user profile"
+// @Success 200 {object} models.UserAccount "Successfully retrieved user profile"
+// @Success 201 {object} models.UserAccount "Profile created from the Clerk account"
 // @Failure 401 {object} gin.H "Unauthorized"
-// @Failure 404 {object} gin.H "User profile not found in DB"
 // @Failure 500 {object} gin.H "Internal Server Error"
+// @Failure 502 {object} gin.H "Clerk account could not be read to create the profile"
 // @Router /auth/user [get]
 func (h *AuthHandler) GetCurrentUserProfile(c *gin.Context) {
+	// LoadUser has already looked the profile up
+	if userProfile, ok := middleware.CurrentUser(c); ok {
+		c.JSON(http.StatusOK, models.NewUserAccount(userProfile))
+		return
+	}
+
 	clerkUserID, exists := middleware.GetClerkUserID(c)
 	if !exists {
 		// This shouldn't happen if middleware is applied correctly, but good practice to check
-		log.Println("Error: ClerkUserID not found in context in GetCurrentUserProfile")
+		logger.ErrorContext(c.Request.Context(), "ClerkUserID not found in context in GetCurrentUserProfile")
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Could not identify authenticated user"})
 		return
 	}
+	logger.InfoContext(c.Request.Context(), "Profile not found, provisioning it from Clerk", "clerk_user_id", clerkUserID)
+	h.provisionUserProfile(c, clerkUserID)
+}
 
-	userProfile, err := h.DBService.GetUserProfileByClerkID(c.Request.Context(), clerkUserID)
+// provisionUserProfile creates the profile of a user signing in for the first time from
+// their Clerk account, so onboarding starts from a populated profile.
+func (h *AuthHandler) provisionUserProfile(c *gin.Context, clerkUserID string) {
+	// Without Clerk (local or dev auth) there is nothing to pre-fill from
+	profile := models.User{ClerkUserID: clerkUserID}
+	if h.clerkUsers != nil {
+		clerkUser, err := h.clerkUsers.GetClerkUserMetadata(c.Request.Context(), clerkUserID)
+		if err != nil {
+			c.JSON(http.StatusBadGateway, gin.H{"error": "Could not read your account from Clerk to create your profile. Please try again."})
+			return
+		}
+		profile = services.ProfileFromClerkUser(clerkUser)
+	}
+
+	// The upsert is keyed on the Clerk ID, so concurrent first requests (or a
+	// user.created webhook) converge on the same profile
+	userProfile, err := h.users.CreateOrUpdateUserProfile(c.Request.Context(), profile)
 	if err != nil {
-		if err == pgx.ErrNoRows {
-			log.Printf("Profile not found for Clerk User ID: %s\n", clerkUserID)
-			c.JSON(http.StatusNotFound, gin.H{"error": "User profile not found. Please create one."})
+		logger.ErrorContext(c.Request.Context(), "Error provisioning profile", "clerk_user_id", clerkUserID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to create user profile"})
+		return
+	}
+
+	logger.InfoContext(c.Request.Context(), "Provisioned profile", "user_id", userProfile.ID, "clerk_user_id", clerkUserID)
+	if h.githubSync != nil && githubsync.NeedsSync(nil, userProfile) {
+		h.githubSync.SyncUserAsync(c.Request.Context(), *userProfile)
+	}
+	c.JSON(http.StatusCreated, models.NewUserAccount(userProfile))
+}
+
+// DeleteCurrentUser godoc
+// @Summary Request deletion of the current user's account
+// @Description Schedules the account for deletion after a grace period, during which it can be cancelled. The profile is hidden from new swipe decks and searches right away. When the grace period ends the Clerk user and all profile data, swipes and conversation memberships are deleted; messages in conversations other people still have are kept without a sender. Repeating the request keeps the original schedule.
+// @Tags Auth
+// @Produce json
+// @Security ClerkAuth
+// @Success 202 {object} gin.H "Deletion scheduled; includes deletion_scheduled_for"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Profile required"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /auth/user [delete]
+func (h *AuthHandler) DeleteCurrentUser(c *gin.Context) {
+	user, ok := currentUserProfile(c)
+	if !ok {
+		return
+	}
+
+	scheduledFor, err := h.accounts.ScheduleAccountDeletion(c.Request.Context(), user.ID, time.Now().Add(h.deletionGrace))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User profile not found"})
+		} else {
+			logger.ErrorContext(c.Request.Context(), "Error scheduling account deletion", "user_id", user.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to schedule account deletion"})
+		}
+		return
+	}
+
+	middleware.ForgetCurrentUser(c) // The cached profile has no deletion date yet
+	logger.InfoContext(c.Request.Context(), "Account deletion scheduled", "user_id", user.ID, "scheduled_for", scheduledFor.Format(time.RFC3339))
+	c.JSON(http.StatusAccepted, gin.H{
+		"message":                "Account scheduled for deletion. Cancel before the scheduled time to keep it.",
+		"deletion_scheduled_for": scheduledFor,
+	})
+}
+
+// CancelAccountDeletion godoc
+// @Summary Cancel a pending account deletion
+// @Tags Auth
+// @Produce json
+// @Security ClerkAuth
+// @Success 200 {object} gin.H "Deletion cancelled"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Profile required"
+// @Failure 404 {object} gin.H "No deletion pending"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /auth/user/deletion/cancel [post]
+func (h *AuthHandler) CancelAccountDeletion(c *gin.Context) {
+	user, ok := currentUserProfile(c)
+	if !ok {
+		return
+	}
+
+	if err := h.accounts.CancelAccountDeletion(c.Request.Context(), user.ID); err != nil {
+		if errors.Is(err, database.ErrNoDeletionScheduled) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "No account deletion is pending"})
 		} else {
-			log.Printf("Error fetching profile for Clerk User ID %s: %v\n", clerkUserID, err)
-			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user profile"})
+			logger.ErrorContext(c.Request.Context(), "Error cancelling account deletion", "user_id", user.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to cancel account deletion"})
 		}
 		return
 	}
 
-	c.JSON(http.StatusOK, userProfile)
+	middleware.ForgetCurrentUser(c)
+	logger.InfoContext(c.Request.Context(), "Account deletion cancelled", "user_id", user.ID)
+	c.JSON(http.StatusOK, gin.H{"message": "Account deletion cancelled"})
 }
 
 // TODO: Implement /auth/github/login (Redirect to Clerk's GitHub handler)
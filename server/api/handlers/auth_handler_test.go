@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin/api/middleware"
+	"gin/internal/models"
+	"gin/internal/services/database/memory"
+
+	"github.com/clerkinc/clerk-sdk-go/clerk"
+	"github.com/gin-gonic/gin"
+)
+
+// fakeClerkUsers serves Clerk users from a map and counts lookups.
+type fakeClerkUsers struct {
+	users   map[string]*clerk.User
+	lookups int
+}
+
+func (f *fakeClerkUsers) GetClerkUserMetadata(ctx context.Context, userID string) (*clerk.User, error) {
+	f.lookups++
+	user, ok := f.users[userID]
+	if !ok {
+		return nil, errors.New("clerk: user not found")
+	}
+	return user, nil
+}
+
+// getCurrentUser calls GET /auth/user on h as the Clerk user clerkUserID, loading
+// their profile from store as the router does.
+func getCurrentUser(h *AuthHandler, store *memory.Store, clerkUserID string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.GET("/auth/user", func(c *gin.Context) {
+		c.Set(string(middleware.UserIDKey), clerkUserID)
+	}, middleware.LoadUser(middleware.NewUserCache(store, time.Hour)), h.GetCurrentUserProfile)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/auth/user", nil))
+	return w
+}
+
+func TestGetCurrentUserProfileProvisions(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	var clerkUser clerk.User
+	err := json.Unmarshal([]byte(`{
+		"id": "user_ada",
+		"profile_image_url": "https://img.clerk.com/ada.png",
+		"external_accounts": [{"object": "external_account", "provider": "oauth_github", "username": "ada-l"}]
+	}`), &clerkUser)
+	if err != nil {
+		t.Fatal(err)
+	}
+	clerkUsers := &fakeClerkUsers{users: map[string]*clerk.User{"user_ada": &clerkUser}}
+	store := memory.NewStore()
+	h := NewAuthHandler(store, store, clerkUsers, nil, time.Hour)
+
+	// The first request creates the profile from the Clerk account
+	w := getCurrentUser(h, store, "user_ada")
+	if w.Code != http.StatusCreated {
+		t.Fatalf("first request: got %d %s, want 201", w.Code, w.Body.String())
+	}
+	var created models.User
+	if err := json.Unmarshal(w.Body.Bytes(), &created); err != nil {
+		t.Fatal(err)
+	}
+	if created.Username == nil || *created.Username != "ada-l" {
+		t.Errorf("username = %v, want the GitHub login", created.Username)
+	}
+	if created.GitHubURL == nil || *created.GitHubURL != "https://github.com/ada-l" {
+		t.Errorf("GitHub URL = %v, want https://github.com/ada-l", created.GitHubURL)
+	}
+	if created.PictureURL == nil || *created.PictureURL != "https://img.clerk.com/ada.png" {
+		t.Errorf("picture URL = %v, want the Clerk avatar", created.PictureURL)
+	}
+
+	// Later requests read the stored profile without asking Clerk
+	w = getCurrentUser(h, store, "user_ada")
+	if w.Code != http.StatusOK {
+		t.Fatalf("second request: got %d %s, want 200", w.Code, w.Body.String())
+	}
+	if clerkUsers.lookups != 1 {
+		t.Errorf("Clerk was asked %d times, want once", clerkUsers.lookups)
+	}
+}
+
+func TestGetCurrentUserProfileClerkUnavailable(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.NewStore()
+	h := NewAuthHandler(store, store, &fakeClerkUsers{}, nil, time.Hour)
+
+	w := getCurrentUser(h, store, "user_unknown")
+	if w.Code != http.StatusBadGateway {
+		t.Errorf("got %d %s, want 502", w.Code, w.Body.String())
+	}
+	if _, err := store.GetUserProfileByClerkID(context.Background(), "user_unknown"); err == nil {
+		t.Error("a profile was created without the Clerk account")
+	}
+}
+
+func TestAccountStateOnlyInAccountViews(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	alice := newTestUser(t, store, "alice")
+	bob := newTestUser(t, store, "bob")
+	if err := store.SetUserRole(ctx, bob.ID, models.RoleModerator); err != nil {
+		t.Fatal(err)
+	}
+	bob, err := store.GetUserProfileByID(ctx, bob.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The user themselves sees their role
+	auth := NewAuthHandler(store, store, nil, nil, time.Hour)
+	w := serve(t, auth.GetCurrentUserProfile, "/auth/user", bob, http.MethodGet, "/auth/user", nil)
+	expectStatus(t, w, http.StatusOK)
+	var account models.UserAccount
+	decode(t, w, &account)
+	if account.ID != bob.ID || account.Role != models.RoleModerator {
+		t.Errorf("/auth/user = %+v, want bob as a moderator", account)
+	}
+
+	// Other users do not
+	dashboard := NewDashboardHandler(store, store, store, nil, 10*time.Second, 3)
+	w = serve(t, dashboard.GetSwipeCards, "/dashboard/cards", alice, http.MethodGet, "/dashboard/cards", nil)
+	expectStatus(t, w, http.StatusOK)
+	var body struct {
+		Cards []map[string]json.RawMessage `json:"cards"`
+	}
+	decode(t, w, &body)
+	if len(body.Cards) != 1 {
+		t.Fatalf("cards = %s, want bob's", w.Body.String())
+	}
+	for _, field := range []string{"role", "suspendedAt", "suspensionReason", "deletionScheduledFor"} {
+		if _, leaked := body.Cards[0][field]; leaked {
+			t.Errorf("card exposes %s: %s", field, w.Body.String())
+		}
+	}
+}
@@ -1,68 +1,207 @@
 package handlers
 
 import (
+	"errors"
+	"fmt"
 	"net/http"
+	"strings"
+	"unicode/utf8"
 
-	"gin/api/middleware" // Needed for GetClerkUserID
+	"gin/internal/metrics"
+	"gin/internal/models"
 	"gin/internal/services/database"
+	"gin/internal/services/moderation"
 
 	"github.com/gin-gonic/gin"
-	// Add other necessary imports like "gin/internal/models"
 )
 
 // ChatHandler handles API requests related to chat functionality.
 type ChatHandler struct {
-	dbService *database.DBService
+	users         database.UserRepository
+	conversations database.ConversationRepository
+	blocks        database.BlockRepository
+	moderator     *moderation.Moderator
 	// Add other services if needed, e.g., notification service
 }
 
 // NewChatHandler creates a new ChatHandler.
-func NewChatHandler(db *database.DBService) *ChatHandler {
+func NewChatHandler(users database.UserRepository, conversations database.ConversationRepository, blocks database.BlockRepository, moderator *moderation.Moderator) *ChatHandler {
 	return &ChatHandler{
-		dbService: db,
+		users:         users,
+		conversations: conversations,
+		blocks:        blocks,
+		moderator:     moderator,
 	}
 }
 
-// GetConversations fetches conversations for the logged-in user.
-// GET /chat/conversations/:userId (Note: route param might be redundant if using middleware)
+// GetConversations fetches conversations for the logged-in user, most recently active first.
+// Conversations with a user the caller blocked or was blocked by are left out.
+// GET /chat/conversations/:userId (Note: the route param is ignored; the caller's own conversations are returned)
 func (h *ChatHandler) GetConversations(c *gin.Context) {
-	userID, exists := middleware.GetClerkUserID(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+	user, ok := currentUserProfile(c)
+	if !ok {
 		return
 	}
 
-	// TODO: Implement logic to fetch conversations for userID from h.dbService
-	c.JSON(http.StatusNotImplemented, gin.H{"message": "TODO: Get conversations for user " + userID})
+	conversations, err := h.conversations.GetConversations(c.Request.Context(), user.ID)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Error fetching conversations", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch conversations"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"conversations": conversations})
+}
+
+// messagesQuery binds and validates the query parameters of GET /chat/messages/:conversationId.
+type messagesQuery struct {
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100"`
 }
 
-// GetMessages fetches messages for a specific conversation.
-// GET /chat/messages/:conversationId
+// GetMessages fetches messages for a specific conversation, newest first, so page 1 is the latest.
+// Conversations the caller is not part of, or whose other participant is blocked either way, are 404s.
+// GET /chat/messages/:conversationId?page=1&page_size=50
 func (h *ChatHandler) GetMessages(c *gin.Context) {
 	conversationID := c.Param("conversationId")
-	userID, exists := middleware.GetClerkUserID(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+	user, ok := currentUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var query messagesQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + err.Error()})
 		return
 	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 50
+	}
 
-	// TODO: Verify user is part of conversationID
-	// TODO: Implement logic to fetch messages for conversationID from h.dbService
-	c.JSON(http.StatusNotImplemented, gin.H{"message": "TODO: Get messages for conversation " + conversationID, "user": userID})
+	ctx := c.Request.Context()
+	isParticipant, err := h.conversations.IsConversationParticipant(ctx, conversationID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
+		return
+	}
+	blocked := false
+	if isParticipant {
+		if blocked, err = h.blocks.IsBlockedInConversation(ctx, conversationID, user.ID); err != nil {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
+			return
+		}
+	}
+	if !isParticipant || blocked {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+
+	// Fetch one extra row to know whether another page exists
+	offset := (query.Page - 1) * query.PageSize
+	messages, err := h.conversations.GetMessages(ctx, conversationID, query.PageSize+1, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error fetching messages", "conversation_id", conversationID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch messages"})
+		return
+	}
+	hasMore := len(messages) > query.PageSize
+	if hasMore {
+		messages = messages[:query.PageSize]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"messages":  messages,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"has_more":  hasMore,
+	})
+}
+
+// sendMessageRequest is the body of POST /chat/message.
+type sendMessageRequest struct {
+	ConversationID string `json:"conversation_id" binding:"required"`
+	Content        string `json:"content" binding:"required"`
 }
 
 // SendMessage handles sending a new message.
+// Messages cannot be sent to a user who blocked, or was blocked by, the sender. Every
+// message goes through content moderation first: rejected messages are not sent (422)
+// and flagged ones are sent but logged for moderators.
 // POST /chat/message
 func (h *ChatHandler) SendMessage(c *gin.Context) {
-	userID, exists := middleware.GetClerkUserID(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+	user, ok := currentUserProfile(c)
+	if !ok {
 		return
 	}
 
-	// TODO: Bind request body (e.g., { "conversation_id": "...", "content": "..." })
-	// TODO: Verify user is part of the conversation
-	// TODO: Implement logic to save message via h.dbService
+	var req sendMessageRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Message content is required"})
+		return
+	}
+	if utf8.RuneCountInString(content) > models.MaxMessageLength {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("Messages must be at most %d characters", models.MaxMessageLength)})
+		return
+	}
+
+	ctx := c.Request.Context()
+	// Only participants who can still message each other get their message moderated;
+	// anyone else gets the same errors as CreateMessage below
+	isParticipant, err := h.conversations.IsConversationParticipant(ctx, req.ConversationID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+		return
+	}
+	if !isParticipant {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		return
+	}
+	blocked, err := h.blocks.IsBlockedInConversation(ctx, req.ConversationID, user.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+		return
+	}
+	if blocked {
+		c.JSON(http.StatusForbidden, gin.H{"error": "You cannot message this user"})
+		return
+	}
+	moderated := moderation.Content{
+		Type:           models.ModerationContentMessage,
+		AuthorID:       user.ID,
+		ConversationID: req.ConversationID,
+		Text:           content,
+	}
+	verdict := h.moderator.Check(ctx, moderated)
+	if verdict.Decision == models.ModerationReject {
+		h.moderator.Record(ctx, moderated, verdict, nil)
+		c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Message rejected by moderation", "reason": verdict.Reason})
+		return
+	}
+
+	message, err := h.conversations.CreateMessage(ctx, req.ConversationID, user.ID, content)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrNotConversationParticipant):
+			c.JSON(http.StatusNotFound, gin.H{"error": "Conversation not found"})
+		case errors.Is(err, database.ErrBlocked):
+			c.JSON(http.StatusForbidden, gin.H{"error": "You cannot message this user"})
+		default:
+			logger.ErrorContext(ctx, "Error sending message", "user_id", user.ID, "conversation_id", req.ConversationID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to send message"})
+		}
+		return
+	}
+
+	h.moderator.Record(ctx, moderated, verdict, &message.ID) // Only logged if flagged
+	metrics.Messages.Inc()
+
 	// TODO: Potentially push message via WebSockets
-	c.JSON(http.StatusNotImplemented, gin.H{"message": "TODO: Send message from user " + userID})
-}
\ No newline at end of file
+	c.JSON(http.StatusCreated, message)
+}
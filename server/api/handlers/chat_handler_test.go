@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"strings"
+	"testing"
+
+	"gin/internal/models"
+	"gin/internal/services/database/memory"
+	"gin/internal/services/moderation"
+)
+
+// chatFixture is a conversation between two matched users and a third user outside it.
+type chatFixture struct {
+	store          *memory.Store
+	handler        *ChatHandler
+	alice, bob     *models.User
+	outsider       *models.User
+	conversationID string
+}
+
+func newChatFixture(t *testing.T) *chatFixture {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	moderator := moderation.NewModerator(store, moderation.NewRuleEngine(moderation.RuleOptions{BannedWords: []string{"forbidden"}}))
+	f := &chatFixture{
+		store:    store,
+		handler:  NewChatHandler(store, store, store, moderator),
+		alice:    newTestUser(t, store, "alice"),
+		bob:      newTestUser(t, store, "bob"),
+		outsider: newTestUser(t, store, "outsider"),
+	}
+	if _, err := store.CreateSwipe(ctx, models.Swipe{SwiperID: f.alice.ID, SwipedID: f.bob.ID, Direction: models.SwipeLike}, 0); err != nil {
+		t.Fatal(err)
+	}
+	match, err := store.CreateSwipe(ctx, models.Swipe{SwiperID: f.bob.ID, SwipedID: f.alice.ID, Direction: models.SwipeLike}, 0)
+	if err != nil || !match.Matched {
+		t.Fatalf("match = %+v (err %v)", match, err)
+	}
+	f.conversationID = *match.ConversationID
+	return f
+}
+
+// send posts content to conversationID as user.
+func (f *chatFixture) send(t *testing.T, user *models.User, conversationID, content string) int {
+	t.Helper()
+	w := serve(t, f.handler.SendMessage, "/chat/message", user, http.MethodPost, "/chat/message",
+		sendMessageRequest{ConversationID: conversationID, Content: content})
+	return w.Code
+}
+
+// messages fetches the messages of conversationID as user.
+func (f *chatFixture) messages(t *testing.T, user *models.User, conversationID string) int {
+	t.Helper()
+	w := serve(t, f.handler.GetMessages, "/chat/messages/:conversationId", user, http.MethodGet, "/chat/messages/"+conversationID, nil)
+	return w.Code
+}
+
+func TestSendMessage(t *testing.T) {
+	f := newChatFixture(t)
+
+	tests := []struct {
+		name           string
+		user           *models.User
+		conversationID string
+		content        string
+		want           int
+	}{
+		{"participant", f.alice, f.conversationID, "Hi Bob!", http.StatusCreated},
+		{"other participant", f.bob, f.conversationID, "Hi Alice!", http.StatusCreated},
+		{"outsider", f.outsider, f.conversationID, "Let me in", http.StatusNotFound},
+		{"unknown conversation", f.alice, "missing", "Hello?", http.StatusNotFound},
+		{"empty", f.alice, f.conversationID, "   ", http.StatusBadRequest},
+		{"rejected by moderation", f.alice, f.conversationID, "something forbidden", http.StatusUnprocessableEntity},
+		{"no profile", nil, f.conversationID, "Hi", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := f.send(t, tt.user, tt.conversationID, tt.content); got != tt.want {
+				t.Errorf("status = %d, want %d", got, tt.want)
+			}
+		})
+	}
+
+	messages, err := f.store.GetMessages(context.Background(), f.conversationID, 10, 0)
+	if err != nil || len(messages) != 2 {
+		t.Errorf("stored messages = %+v (err %v), want the two accepted ones", messages, err)
+	}
+}
+
+func TestSendMessageBlocked(t *testing.T) {
+	f := newChatFixture(t)
+	if err := f.store.BlockUser(context.Background(), f.bob.ID, f.alice.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	// Both sides are refused, whoever placed the block, before their message is moderated
+	for _, user := range []*models.User{f.alice, f.bob} {
+		if got := f.send(t, user, f.conversationID, "Still there? forbidden"); got != http.StatusForbidden {
+			t.Errorf("message from %s: status %d, want 403", *user.Username, got)
+		}
+	}
+	events, err := f.store.GetModerationEvents(context.Background(), models.ModerationEventFilter{}, 10, 0)
+	if err != nil || len(events) != 0 {
+		t.Errorf("moderation events = %+v (err %v), want none", events, err)
+	}
+}
+
+func TestGetMessages(t *testing.T) {
+	f := newChatFixture(t)
+	if f.send(t, f.alice, f.conversationID, "Hi Bob!") != http.StatusCreated {
+		t.Fatal("sending the first message failed")
+	}
+
+	if got := f.messages(t, f.bob, f.conversationID); got != http.StatusOK {
+		t.Errorf("participant: status %d, want 200", got)
+	}
+	if got := f.messages(t, f.outsider, f.conversationID); got != http.StatusNotFound {
+		t.Errorf("outsider: status %d, want 404", got)
+	}
+	if got := f.messages(t, f.alice, "missing"); got != http.StatusNotFound {
+		t.Errorf("unknown conversation: status %d, want 404", got)
+	}
+
+	// A blocked conversation disappears for both participants
+	if err := f.store.BlockUser(context.Background(), f.alice.ID, f.bob.ID); err != nil {
+		t.Fatal(err)
+	}
+	for _, user := range []*models.User{f.alice, f.bob} {
+		if got := f.messages(t, user, f.conversationID); got != http.StatusNotFound {
+			t.Errorf("blocked conversation as %s: status %d, want 404", *user.Username, got)
+		}
+	}
+}
+
+func TestGetMessagesNewestFirst(t *testing.T) {
+	f := newChatFixture(t)
+	for _, content := range []string{"first", "second", "third"} {
+		if f.send(t, f.alice, f.conversationID, content) != http.StatusCreated {
+			t.Fatalf("sending %q failed", content)
+		}
+	}
+
+	page := func(number string) (contents []string, hasMore bool) {
+		t.Helper()
+		target := "/chat/messages/" + f.conversationID + "?page_size=2&page=" + number
+		w := serve(t, f.handler.GetMessages, "/chat/messages/:conversationId", f.bob, http.MethodGet, target, nil)
+		expectStatus(t, w, http.StatusOK)
+		var body struct {
+			Messages []models.Message `json:"messages"`
+			HasMore  bool             `json:"has_more"`
+		}
+		decode(t, w, &body)
+		for _, msg := range body.Messages {
+			contents = append(contents, msg.Content)
+		}
+		return contents, body.HasMore
+	}
+	if got, hasMore := page("1"); strings.Join(got, ",") != "third,second" || !hasMore {
+		t.Errorf("page 1 = %v (has_more %v), want third,second with more", got, hasMore)
+	}
+	if got, hasMore := page("2"); strings.Join(got, ",") != "first" || hasMore {
+		t.Errorf("page 2 = %v (has_more %v), want first and no more", got, hasMore)
+	}
+}
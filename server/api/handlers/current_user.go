@@ -0,0 +1,25 @@
+package handlers
+
+import (
+	"net/http"
+
+	"gin/api/middleware"
+	"gin/internal/logging"
+	"gin/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+var logger = logging.For("handlers")
+
+// currentUserProfile returns the authenticated user's profile, loaded by
+// middleware.LoadUser. It writes the same 403 as middleware.RequireProfile and returns
+// false if the user has no profile; routes that need one normally reject those earlier.
+func currentUserProfile(c *gin.Context) (*models.User, bool) {
+	user, ok := middleware.CurrentUser(c)
+	if !ok {
+		c.JSON(http.StatusForbidden, gin.H{"error": "profile required"})
+		return nil, false
+	}
+	return user, true
+}
@@ -1,80 +1,390 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
 	"net/http"
+	"strings"
+	"time"
+	"unicode/utf8"
 
-	"gin/api/middleware" // Needed for GetClerkUserID
+	"gin/internal/metrics"
+	"gin/internal/models"
 	"gin/internal/services/database"
+	"gin/internal/services/recommender"
 
 	"github.com/gin-gonic/gin"
-	// Add other necessary imports like "gin/internal/models"
+)
+
+const (
+	defaultCardLimit = 20
+	// candidatePoolSize is how many unswiped users are loaded and ranked per deck request.
+	candidatePoolSize = 500
 )
 
 // DashboardHandler handles API requests related to the user dashboard (swiping, favorites).
 type DashboardHandler struct {
-	dbService *database.DBService
-	// Add other services if needed (e.g., matching service)
+	users         database.UserRepository
+	swipes        database.SwipeRepository
+	notifications database.NotificationRepository
+	recommender   *recommender.Recommender // Optional; without it the deck is unranked
+	undoWindow    time.Duration            // How long after swiping a swipe can still be undone
+	superLikes    int                      // Super likes each user may send per UTC day
 }
 
 // NewDashboardHandler creates a new DashboardHandler.
-func NewDashboardHandler(db *database.DBService) *DashboardHandler {
+func NewDashboardHandler(users database.UserRepository, swipes database.SwipeRepository, notifications database.NotificationRepository, rec *recommender.Recommender, undoWindow time.Duration, superLikeDailyLimit int) *DashboardHandler {
 	return &DashboardHandler{
-		dbService: db,
+		users:         users,
+		swipes:        swipes,
+		notifications: notifications,
+		recommender:   rec,
+		undoWindow:    undoWindow,
+		superLikes:    superLikeDailyLimit,
 	}
 }
 
-// GetSwipeCards fetches potential matches for the logged-in user.
-// GET /dashboard/cards
+// currentUser returns the authenticated user's profile, loaded by middleware.LoadUser.
+// It writes the error response and returns false if there is none.
+func (h *DashboardHandler) currentUser(c *gin.Context) (*models.User, bool) {
+	return currentUserProfile(c)
+}
+
+// cardQuery binds and validates the query parameters of GET /dashboard/cards.
+type cardQuery struct {
+	Limit            int      `form:"limit" binding:"omitempty,min=1,max=100"`
+	Language         string   `form:"language" binding:"omitempty,max=64"`
+	Interests        string   `form:"interests" binding:"omitempty,max=512"`     // Comma-separated tags; any may match
+	TimezoneMin      *float64 `form:"tz_min" binding:"omitempty,min=-12,max=14"` // UTC offset in hours, e.g. -5 or 5.5
+	TimezoneMax      *float64 `form:"tz_max" binding:"omitempty,min=-12,max=14"`
+	MinFollowers     int      `form:"min_followers" binding:"omitempty,min=0"`
+	MinRepos         int      `form:"min_repos" binding:"omitempty,min=0"`
+	ActiveWithinDays int      `form:"active_within_days" binding:"omitempty,min=1,max=365"`
+}
+
+// filters converts the bound query into CardFilters, checking constraints that span fields.
+func (q cardQuery) filters() (models.CardFilters, error) {
+	if q.TimezoneMin != nil && q.TimezoneMax != nil && *q.TimezoneMin > *q.TimezoneMax {
+		return models.CardFilters{}, errors.New("tz_min must not be greater than tz_max")
+	}
+
+	filters := models.CardFilters{
+		PrimaryLanguage:  strings.TrimSpace(q.Language),
+		MinFollowers:     q.MinFollowers,
+		MinPublicRepos:   q.MinRepos,
+		ActiveWithinDays: q.ActiveWithinDays,
+	}
+	if q.Interests != "" {
+		filters.Interests = models.NormalizeInterestTags(strings.Split(q.Interests, ","))
+	}
+	if q.TimezoneMin != nil {
+		minutes := int(math.Round(*q.TimezoneMin * 60))
+		filters.TimezoneMinMinutes = &minutes
+	}
+	if q.TimezoneMax != nil {
+		minutes := int(math.Round(*q.TimezoneMax * 60))
+		filters.TimezoneMaxMinutes = &minutes
+	}
+	return filters, nil
+}
+
+// GetSwipeCards fetches potential matches for the logged-in user, best recommendations first.
+// Optional filters: language, interests (comma-separated), tz_min/tz_max (UTC offset hours),
+// min_followers, min_repos and active_within_days.
+// GET /dashboard/cards?limit=20&language=go&interests=rust,wasm&tz_min=-5&tz_max=2
 func (h *DashboardHandler) GetSwipeCards(c *gin.Context) {
-	userID, exists := middleware.GetClerkUserID(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var query cardQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filters: " + err.Error()})
+		return
+	}
+	filters, err := query.filters()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid filters: " + err.Error()})
+		return
+	}
+	limit := query.Limit
+	if limit == 0 {
+		limit = defaultCardLimit
+	}
+
+	candidates, err := h.swipes.GetSwipeCandidates(c.Request.Context(), user.ID, filters, candidatePoolSize)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Error fetching swipe candidates", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch swipe cards"})
 		return
 	}
 
-	// TODO: Implement logic to fetch potential matches for userID from h.dbService
-	// This might involve filtering out users already swiped, etc.
-	c.JSON(http.StatusNotImplemented, gin.H{"message": "TODO: Get swipe cards for user " + userID})
+	var cards []models.SwipeCard
+	if h.recommender != nil {
+		cards = h.recommender.RankCandidates(user.ID, candidates)
+	} else {
+		cards = make([]models.SwipeCard, 0, len(candidates))
+		for _, candidate := range candidates {
+			cards = append(cards, models.SwipeCard{User: candidate})
+		}
+	}
+	if len(cards) > limit {
+		cards = cards[:limit]
+	}
+
+	c.JSON(http.StatusOK, gin.H{"cards": cards})
 }
 
-// LogSwipe records a swipe action.
+// LogSwipe records a swipe action and reports whether it produced a match.
+// A superlike may carry an intro_note and counts against the caller's daily quota.
 // POST /dashboard/swipe
 func (h *DashboardHandler) LogSwipe(c *gin.Context) {
-	userID, exists := middleware.GetClerkUserID(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var req models.LogSwipeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
+		return
+	}
+	if !req.Direction.Valid() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "direction must be 'like', 'dislike' or 'superlike'"})
+		return
+	}
+	if req.IntroNote != nil {
+		if req.Direction != models.SwipeSuperLike {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "intro_note is only allowed with a superlike"})
+			return
+		}
+		note := strings.TrimSpace(*req.IntroNote)
+		if utf8.RuneCountInString(note) > models.MaxIntroNoteLength {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("intro_note must be at most %d characters", models.MaxIntroNoteLength)})
+			return
+		}
+		if note == "" {
+			req.IntroNote = nil
+		} else {
+			req.IntroNote = &note
+		}
+	}
+	if req.SwipedUserID == user.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot swipe on yourself"})
 		return
 	}
 
-	// TODO: Bind request body (e.g., { "swiped_user_id": "...", "direction": "like|dislike" })
-	// TODO: Implement logic to save swipe via h.dbService
-	// TODO: Check for a match if direction is 'like'
-	c.JSON(http.StatusNotImplemented, gin.H{"message": "TODO: Log swipe from user " + userID})
+	if _, err := h.users.GetUserProfileByID(c.Request.Context(), req.SwipedUserID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Swiped user not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record swipe"})
+		}
+		return
+	}
+
+	swipe := models.Swipe{
+		SwiperID:  user.ID,
+		SwipedID:  req.SwipedUserID,
+		Direction: req.Direction,
+		IntroNote: req.IntroNote,
+	}
+	result, err := h.swipes.CreateSwipe(c.Request.Context(), swipe, h.superLikes)
+	if err != nil {
+		if errors.Is(err, database.ErrAlreadySwiped) {
+			c.JSON(http.StatusConflict, gin.H{"error": "You have already swiped on this user"})
+			return
+		}
+		if errors.Is(err, database.ErrBlocked) {
+			// Don't reveal the block; to the swiper the user simply isn't there
+			c.JSON(http.StatusNotFound, gin.H{"error": "Swiped user not found"})
+			return
+		}
+		if errors.Is(err, database.ErrSuperLikeQuotaExceeded) {
+			c.JSON(http.StatusTooManyRequests, gin.H{"error": "You have used all of today's super likes"})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), "Error recording swipe", "user_id", user.ID, "swiped_user_id", req.SwipedUserID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to record swipe"})
+		return
+	}
+	metrics.Swipes.WithLabelValues(string(req.Direction)).Inc()
+	if result.Matched {
+		metrics.Matches.Inc()
+	}
+
+	c.JSON(http.StatusCreated, result)
+}
+
+// UndoSwipe reverts the caller's most recent swipe if it was made within the undo window.
+// If the swipe had produced a match whose conversation has no messages yet, the match is dissolved.
+// POST /dashboard/swipe/undo
+func (h *DashboardHandler) UndoSwipe(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	result, err := h.swipes.UndoLastSwipe(c.Request.Context(), user.ID, h.undoWindow)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrNoSwipeToUndo):
+			c.JSON(http.StatusNotFound, gin.H{"error": "There is no swipe to undo"})
+		case errors.Is(err, database.ErrUndoWindowExpired):
+			c.JSON(http.StatusConflict, gin.H{"error": "Your last swipe can no longer be undone"})
+		default:
+			logger.ErrorContext(c.Request.Context(), "Error undoing last swipe", "user_id", user.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to undo swipe"})
+		}
+		return
+	}
+	metrics.SwipeUndos.Inc()
+
+	c.JSON(http.StatusOK, result)
+}
+
+// swipeHistoryQuery binds and validates the query parameters of GET /dashboard/swipes.
+type swipeHistoryQuery struct {
+	Direction string `form:"direction" binding:"omitempty,oneof=like dislike superlike"`
+	Page      int    `form:"page" binding:"omitempty,min=1"`
+	PageSize  int    `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// GetSwipeHistory lists the caller's swipes, newest first, optionally filtered by direction.
+// GET /dashboard/swipes?direction=like&page=1&page_size=20
+func (h *DashboardHandler) GetSwipeHistory(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var query swipeHistoryQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + err.Error()})
+		return
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 20
+	}
+
+	// Fetch one extra row to know whether another page exists
+	offset := (query.Page - 1) * query.PageSize
+	entries, err := h.swipes.GetSwipeHistory(c.Request.Context(), user.ID, models.SwipeDirection(query.Direction), query.PageSize+1, offset)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Error fetching swipe history", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch swipe history"})
+		return
+	}
+	hasMore := len(entries) > query.PageSize
+	if hasMore {
+		entries = entries[:query.PageSize]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"swipes":    entries,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"has_more":  hasMore,
+	})
+}
+
+// notificationsQuery binds and validates the query parameters of GET /dashboard/notifications.
+type notificationsQuery struct {
+	UnreadOnly bool `form:"unread_only"`
+	Page       int  `form:"page" binding:"omitempty,min=1"`
+	PageSize   int  `form:"page_size" binding:"omitempty,min=1,max=100"`
+}
+
+// GetNotifications lists the caller's notifications, newest first.
+// GET /dashboard/notifications?unread_only=true&page=1&page_size=20
+func (h *DashboardHandler) GetNotifications(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	var query notificationsQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + err.Error()})
+		return
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 20
+	}
+
+	// Fetch one extra row to know whether another page exists
+	offset := (query.Page - 1) * query.PageSize
+	notifications, err := h.notifications.GetNotifications(c.Request.Context(), user.ID, query.UnreadOnly, query.PageSize+1, offset)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Error fetching notifications", "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch notifications"})
+		return
+	}
+	hasMore := len(notifications) > query.PageSize
+	if hasMore {
+		notifications = notifications[:query.PageSize]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"notifications": notifications,
+		"page":          query.Page,
+		"page_size":     query.PageSize,
+		"has_more":      hasMore,
+	})
+}
+
+// MarkNotificationRead marks one of the caller's notifications as read.
+// POST /dashboard/notifications/:id/read
+func (h *DashboardHandler) MarkNotificationRead(c *gin.Context) {
+	user, ok := h.currentUser(c)
+	if !ok {
+		return
+	}
+
+	notificationID := c.Param("id")
+	if err := h.notifications.MarkNotificationRead(c.Request.Context(), user.ID, notificationID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Notification not found"})
+			return
+		}
+		logger.ErrorContext(c.Request.Context(), "Error marking notification read", "notification_id", notificationID, "user_id", user.ID, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to update notification"})
+		return
+	}
+
+	c.Status(http.StatusNoContent)
 }
 
 // ToggleFavorite adds or removes a user from the logged-in user's favorites.
 // POST /dashboard/favorite
 func (h *DashboardHandler) ToggleFavorite(c *gin.Context) {
-	userID, exists := middleware.GetClerkUserID(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+	user, ok := h.currentUser(c)
+	if !ok {
 		return
 	}
 
 	// TODO: Bind request body (e.g., { "favorite_user_id": "...", "action": "add|remove" })
-	// TODO: Implement logic to add/remove favorite via h.dbService
-	c.JSON(http.StatusNotImplemented, gin.H{"message": "TODO: Toggle favorite for user " + userID})
+	// TODO: Implement logic to add/remove favorite via h.swipes
+	c.JSON(http.StatusNotImplemented, gin.H{"message": "TODO: Toggle favorite for user " + user.ID})
 }
 
 // GetFavorites fetches the list of users favorited by the logged-in user.
 // GET /dashboard/favorites
 func (h *DashboardHandler) GetFavorites(c *gin.Context) {
-	userID, exists := middleware.GetClerkUserID(c)
-	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{"error": "User ID not found in context"})
+	user, ok := h.currentUser(c)
+	if !ok {
 		return
 	}
 
-	// TODO: Implement logic to fetch favorite users for userID from h.dbService
-	c.JSON(http.StatusNotImplemented, gin.H{"message": "TODO: Get favorites for user " + userID})
-}
\ No newline at end of file
+	// TODO: Implement logic to fetch favorite users for user.ID from h.users
+	c.JSON(http.StatusNotImplemented, gin.H{"message": "TODO: Get favorites for user " + user.ID})
+}
@@ -0,0 +1,94 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+
+	"gin/internal/models"
+	"gin/internal/services/database/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestCardQueryFilters(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	minusFive, fiveThirty := -300, 330
+
+	tests := []struct {
+		name    string
+		query   string
+		want    models.CardFilters
+		wantErr bool
+	}{
+		{"empty", "", models.CardFilters{}, false},
+		{"all filters", "language=+Go+&interests=Rust,+wasm,,rust&min_followers=10&min_repos=3&active_within_days=30",
+			models.CardFilters{PrimaryLanguage: "Go", Interests: []string{"rust", "wasm"}, MinFollowers: 10, MinPublicRepos: 3, ActiveWithinDays: 30}, false},
+		{"fractional timezones", "tz_min=-5&tz_max=5.5", models.CardFilters{TimezoneMinMinutes: &minusFive, TimezoneMaxMinutes: &fiveThirty}, false},
+		{"timezone range reversed", "tz_min=3&tz_max=-3", models.CardFilters{}, true},
+		{"timezone out of range", "tz_min=-13", models.CardFilters{}, true},
+		{"limit too large", "limit=101", models.CardFilters{}, true},
+		{"negative followers", "min_followers=-1", models.CardFilters{}, true},
+		{"not a number", "min_repos=many", models.CardFilters{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, _ := gin.CreateTestContext(httptest.NewRecorder())
+			c.Request = httptest.NewRequest("GET", "/dashboard/cards?"+tt.query, nil)
+
+			var query cardQuery
+			err := c.ShouldBindQuery(&query)
+			var got models.CardFilters
+			if err == nil {
+				got, err = query.filters()
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if !tt.wantErr && !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("filters = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUndoThenLikeAgainKeepsOneConversation(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	h := NewDashboardHandler(store, store, store, nil, time.Minute, 3)
+	alice := newTestUser(t, store, "alice")
+	bob := newTestUser(t, store, "bob")
+
+	like := func(swiper, swiped *models.User) models.SwipeResult {
+		t.Helper()
+		w := serve(t, h.LogSwipe, "/dashboard/swipe", swiper, http.MethodPost, "/dashboard/swipe",
+			models.LogSwipeRequest{SwipedUserID: swiped.ID, Direction: models.SwipeLike})
+		expectStatus(t, w, http.StatusCreated)
+		var result models.SwipeResult
+		decode(t, w, &result)
+		return result
+	}
+	like(alice, bob)
+	match := like(bob, alice)
+	if !match.Matched || match.ConversationID == nil {
+		t.Fatalf("match = %+v, want a conversation", match)
+	}
+	if _, err := store.CreateMessage(ctx, *match.ConversationID, alice.ID, "Hi Bob!"); err != nil {
+		t.Fatal(err)
+	}
+
+	// The conversation has a message, so the undo keeps it and liking again reuses it
+	w := serve(t, h.UndoSwipe, "/dashboard/swipe/undo", bob, http.MethodPost, "/dashboard/swipe/undo", nil)
+	expectStatus(t, w, http.StatusOK)
+	rematch := like(bob, alice)
+	if !rematch.Matched || rematch.ConversationID == nil || *rematch.ConversationID != *match.ConversationID {
+		t.Errorf("rematch = %+v, want the kept conversation %s", rematch, *match.ConversationID)
+	}
+	conversations, err := store.GetConversations(ctx, alice.ID)
+	if err != nil || len(conversations) != 1 {
+		t.Errorf("alice has %d conversations (err %v), want 1", len(conversations), err)
+	}
+}
@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"os"
+	"time"
+
+	"gin/internal/models"
+	"gin/internal/services/database"
+	"gin/internal/services/export"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ExportHandler serves personal data exports.
+type ExportHandler struct {
+	exports  database.DataExportRepository
+	exporter *export.Exporter
+}
+
+// NewExportHandler creates a new ExportHandler.
+func NewExportHandler(exports database.DataExportRepository, exporter *export.Exporter) *ExportHandler {
+	return &ExportHandler{exports: exports, exporter: exporter}
+}
+
+type exportQuery struct {
+	Async bool `form:"async"` // Always generate the archive in the background
+}
+
+// dataExportResponse is a DataExport plus where to poll it and, once ready, download it.
+type dataExportResponse struct {
+	models.DataExport
+	StatusURL   string  `json:"statusUrl"`
+	DownloadURL *string `json:"downloadUrl,omitempty"`
+}
+
+func newDataExportResponse(e models.DataExport) dataExportResponse {
+	resp := dataExportResponse{DataExport: e, StatusURL: "/auth/user/exports/" + e.ID}
+	if e.Status == models.DataExportReady && e.DownloadToken != nil {
+		url := "/exports/" + *e.DownloadToken
+		resp.DownloadURL = &url
+	}
+	return resp
+}
+
+// ExportUserData godoc
+// @Summary Export the current user's personal data
+// @Description Returns a ZIP archive of everything DevMatch stores about the caller: profile, GitHub data, swipes made, conversations, messages sent and notifications. Large histories (or ?async=true) are exported in the background: the response is 202 with a job to poll, whose download link expires.
+// @Tags Auth
+// @Produce application/zip
+// @Produce json
+// @Security ClerkAuth
+// @Param async query bool false "Always export in the background"
+// @Success 200 {file} binary "ZIP archive"
+// @Success 202 {object} dataExportResponse "Export is being generated"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Profile required"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /auth/user/export [get]
+func (h *ExportHandler) ExportUserData(c *gin.Context) {
+	user, ok := currentUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var query exportQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + err.Error()})
+		return
+	}
+
+	async := query.Async
+	if !async {
+		var err error
+		async, err = h.exporter.ShouldRunAsync(c.Request.Context(), user.ID)
+		if err != nil {
+			logger.ErrorContext(c.Request.Context(), "Error sizing data export", "user_id", user.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to export user data"})
+			return
+		}
+	}
+
+	if async {
+		job, err := h.exporter.StartExport(c.Request.Context(), user.ID)
+		if err != nil {
+			logger.ErrorContext(c.Request.Context(), "Error starting data export", "user_id", user.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to start data export"})
+			return
+		}
+		c.Header("Location", "/auth/user/exports/"+job.ID)
+		c.JSON(http.StatusAccepted, newDataExportResponse(*job))
+		return
+	}
+
+	// Small export: stream it. Once the first byte is written the status can no longer
+	// change, so a failure part way through can only be logged (the ZIP will be truncated).
+	c.Header("Content-Type", "application/zip")
+	c.Header("Content-Disposition", `attachment; filename="`+export.ArchiveName(user.ID, time.Now())+`"`)
+	c.Header("Cache-Control", "no-store")
+	c.Status(http.StatusOK)
+	if err := h.exporter.WriteArchive(c.Request.Context(), c.Writer, user.ID); err != nil {
+		logger.ErrorContext(c.Request.Context(), "Error streaming data export", "user_id", user.ID, "error", err)
+	}
+}
+
+// GetDataExport godoc
+// @Summary Get the status of a background data export
+// @Tags Auth
+// @Produce json
+// @Security ClerkAuth
+// @Param id path string true "Export ID"
+// @Success 200 {object} dataExportResponse "Export status, with a download link once ready"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Profile required"
+// @Failure 404 {object} gin.H "No such export (or it expired)"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /auth/user/exports/{id} [get]
+func (h *ExportHandler) GetDataExport(c *gin.Context) {
+	user, ok := currentUserProfile(c)
+	if !ok {
+		return
+	}
+
+	job, err := h.exports.GetDataExport(c.Request.Context(), user.ID, c.Param("id"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Export not found"})
+		} else {
+			logger.ErrorContext(c.Request.Context(), "Error fetching data export", "export_id", c.Param("id"), "user_id", user.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch export"})
+		}
+		return
+	}
+	c.JSON(http.StatusOK, newDataExportResponse(*job))
+}
+
+// DownloadDataExport godoc
+// @Summary Download a finished data export
+// @Description The token in the link is the credential, so the link works without an Authorization header until the export expires.
+// @Tags Auth
+// @Produce application/zip
+// @Param token path string true "Download token from downloadUrl"
+// @Success 200 {file} binary "ZIP archive"
+// @Failure 404 {object} gin.H "Unknown or expired link"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /exports/{token} [get]
+func (h *ExportHandler) DownloadDataExport(c *gin.Context) {
+	job, err := h.exports.GetDataExportByToken(c.Request.Context(), c.Param("token"))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "Download link is invalid or has expired"})
+		} else {
+			logger.ErrorContext(c.Request.Context(), "Error fetching data export by token", "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch export"})
+		}
+		return
+	}
+	// Expired exports may not have been cleaned up yet
+	if time.Now().After(job.ExpiresAt) || job.FilePath == nil {
+		c.JSON(http.StatusNotFound, gin.H{"error": "Download link is invalid or has expired"})
+		return
+	}
+	if _, err := os.Stat(*job.FilePath); err != nil {
+		logger.ErrorContext(c.Request.Context(), "Archive of data export is missing", "export_id", job.ID, "error", err)
+		c.JSON(http.StatusNotFound, gin.H{"error": "Download link is invalid or has expired"})
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(*job.FilePath, export.ArchiveName(job.UserID, job.CreatedAt))
+}
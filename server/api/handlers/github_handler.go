@@ -2,7 +2,6 @@ package handlers
 
 import (
 	"context"
-	"log"
 	"net/http"
 
 	"gin/internal/services"
@@ -40,7 +39,7 @@ func (h *GitHubHandler) GetGitHubData(c *gin.Context) {
 
 	userData, err := h.githubService.GetUserData(context.Background(), username)
 	if err != nil {
-		log.Printf("Error calling GitHub service for %s: %v", username, err)
+		logger.ErrorContext(c.Request.Context(), "Error calling GitHub service", "username", username, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch GitHub data"})
 		return
 	}
@@ -66,7 +65,7 @@ func (h *GitHubHandler) SummarizeGitHubData(c *gin.Context) {
 
 	summary, err := h.geminiService.AnalyzeText(context.Background(), textToSummarize)
 	if err != nil {
-		log.Printf("Error calling Gemini service for summarization: %v", err)
+		logger.ErrorContext(c.Request.Context(), "Error calling Gemini service for summarization", "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to generate summary"})
 		return
 	}
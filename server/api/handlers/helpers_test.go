@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"testing"
+
+	"gin/api/middleware"
+	"gin/internal/models"
+	"gin/internal/services/database/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+func init() {
+	gin.SetMode(gin.TestMode)
+}
+
+// newTestUser stores a profile named username in store.
+func newTestUser(t *testing.T, store *memory.Store, username string) *models.User {
+	t.Helper()
+	user, err := store.CreateOrUpdateUserProfile(context.Background(), models.User{
+		ClerkUserID: "clerk_" + username,
+		Username:    &username,
+	})
+	if err != nil {
+		t.Fatalf("creating user %s: %v", username, err)
+	}
+	return user
+}
+
+// serve sends a request to handler, mounted at route, as user, the way the router does
+// after middleware.LoadUser. A nil user sends it without a profile. body, if not nil,
+// is sent as JSON.
+func serve(t *testing.T, handler gin.HandlerFunc, route string, user *models.User, method, target string, body any) *httptest.ResponseRecorder {
+	t.Helper()
+	router := gin.New()
+	router.Handle(method, route, func(c *gin.Context) {
+		if user != nil {
+			c.Set(string(middleware.CurrentUserKey), user)
+		}
+		c.Next()
+	}, handler)
+
+	var encoded []byte
+	if body != nil {
+		var err error
+		if encoded, err = json.Marshal(body); err != nil {
+			t.Fatalf("encoding body: %v", err)
+		}
+	}
+	req := httptest.NewRequest(method, target, bytes.NewReader(encoded))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// decode unmarshals the JSON body of w into v.
+func decode(t *testing.T, w *httptest.ResponseRecorder, v any) {
+	t.Helper()
+	if err := json.Unmarshal(w.Body.Bytes(), v); err != nil {
+		t.Fatalf("decoding response %q: %v", w.Body.String(), err)
+	}
+}
+
+// expectStatus fails the test if w does not have status want.
+func expectStatus(t *testing.T, w *httptest.ResponseRecorder, want int) {
+	t.Helper()
+	if w.Code != want {
+		t.Fatalf("status = %d, want %d; body %s", w.Code, want, w.Body.String())
+	}
+}
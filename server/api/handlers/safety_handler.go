@@ -0,0 +1,214 @@
+package handlers
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+
+	"gin/internal/models"
+	"gin/internal/services/database"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SafetyHandler lets users block and report each other. Reports land in the moderation
+// queue served by AdminHandler.
+type SafetyHandler struct {
+	users   database.UserRepository
+	blocks  database.BlockRepository
+	reports database.ReportRepository
+}
+
+// NewSafetyHandler creates a new SafetyHandler.
+func NewSafetyHandler(users database.UserRepository, blocks database.BlockRepository, reports database.ReportRepository) *SafetyHandler {
+	return &SafetyHandler{users: users, blocks: blocks, reports: reports}
+}
+
+// otherUser resolves the :id path parameter to a user other than the caller, writing a
+// 400 or 404 and returning false if there is none.
+func (h *SafetyHandler) otherUser(c *gin.Context, caller *models.User) (*models.User, bool) {
+	targetID := c.Param("id")
+	if targetID == caller.ID {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "You cannot do this to your own account"})
+		return nil, false
+	}
+	target, err := h.users.GetUserProfileByID(c.Request.Context(), targetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user"})
+		}
+		return nil, false
+	}
+	return target, true
+}
+
+// BlockUser godoc
+// @Summary Block a user
+// @Description Hides both users from each other's decks, search results and conversations, and stops them messaging each other. The blocked user is not told. Blocking someone twice is not an error.
+// @Tags Safety
+// @Security ClerkAuth
+// @Param id path string true "User ID"
+// @Success 204 "Blocked"
+// @Failure 400 {object} gin.H "Own account"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 404 {object} gin.H "User not found"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /users/{id}/block [post]
+func (h *SafetyHandler) BlockUser(c *gin.Context) {
+	caller, ok := currentUserProfile(c)
+	if !ok {
+		return
+	}
+	target, ok := h.otherUser(c, caller)
+	if !ok {
+		return
+	}
+	if err := h.blocks.BlockUser(c.Request.Context(), caller.ID, target.ID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to block user"})
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// UnblockUser godoc
+// @Summary Unblock a user
+// @Description Lifts a block the caller placed. Swipes, matches and messages from before the block reappear.
+// @Tags Safety
+// @Security ClerkAuth
+// @Param id path string true "User ID"
+// @Success 204 "Unblocked"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 404 {object} gin.H "User is not blocked"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /users/{id}/block [delete]
+func (h *SafetyHandler) UnblockUser(c *gin.Context) {
+	caller, ok := currentUserProfile(c)
+	if !ok {
+		return
+	}
+	if err := h.blocks.UnblockUser(c.Request.Context(), caller.ID, c.Param("id")); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			c.JSON(http.StatusNotFound, gin.H{"error": "User is not blocked"})
+		} else {
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to unblock user"})
+		}
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// GetBlockedUsers godoc
+// @Summary List blocked users
+// @Description Lists the users the caller has blocked, most recently blocked first.
+// @Tags Safety
+// @Produce json
+// @Security ClerkAuth
+// @Success 200 {object} gin.H "blocks"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /users/blocks [get]
+func (h *SafetyHandler) GetBlockedUsers(c *gin.Context) {
+	caller, ok := currentUserProfile(c)
+	if !ok {
+		return
+	}
+	blocked, err := h.blocks.GetBlockedUsers(c.Request.Context(), caller.ID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to fetch blocked users"})
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"blocks": blocked})
+}
+
+// reportRequest is the body of POST /users/:id/report.
+type reportRequest struct {
+	Reason     string   `json:"reason" binding:"required,oneof=harassment spam inappropriate_content impersonation scam other"`
+	Details    *string  `json:"details" binding:"omitempty,max=1000"`                // Required when the reason is "other"
+	MessageIDs []string `json:"messageIds" binding:"omitempty,max=20,dive,required"` // Messages the reported user sent the caller
+	Block      bool     `json:"block"`                                               // Also block the reported user
+}
+
+// ReportUser godoc
+// @Summary Report a user
+// @Description Files a report for moderators to review. Messages the reported user sent the caller can be attached; moderators see copies of them even if they are later deleted. A user can have one open report about each other user. Set block to also block them.
+// @Tags Safety
+// @Accept json
+// @Produce json
+// @Security ClerkAuth
+// @Param id path string true "User ID"
+// @Param request body reportRequest true "Reason, details and attached messages"
+// @Success 201 {object} gin.H "report and blocked"
+// @Failure 400 {object} gin.H "Invalid request, own account or a message that cannot be attached"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 404 {object} gin.H "User not found"
+// @Failure 409 {object} gin.H "An open report about this user already exists"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /users/{id}/report [post]
+func (h *SafetyHandler) ReportUser(c *gin.Context) {
+	caller, ok := currentUserProfile(c)
+	if !ok {
+		return
+	}
+	var req reportRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request: " + err.Error()})
+		return
+	}
+	if req.Details != nil {
+		details := strings.TrimSpace(*req.Details)
+		if details == "" {
+			req.Details = nil
+		} else {
+			req.Details = &details
+		}
+	}
+	reason := models.ReportReason(req.Reason)
+	if reason == models.ReportOther && req.Details == nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Details are required when the reason is other"})
+		return
+	}
+
+	target, ok := h.otherUser(c, caller)
+	if !ok {
+		return
+	}
+
+	ctx := c.Request.Context()
+	report := models.Report{
+		ReporterUserID: &caller.ID,
+		ReportedUserID: target.ID,
+		Reason:         reason,
+		Details:        req.Details,
+	}
+	created, err := h.reports.CreateReport(ctx, report, req.MessageIDs)
+	if err != nil {
+		switch {
+		case errors.Is(err, database.ErrInvalidReportedMessage):
+			c.JSON(http.StatusBadRequest, gin.H{"error": "Only messages this user sent you can be attached: " + err.Error()})
+		case errors.Is(err, database.ErrDuplicateReport):
+			c.JSON(http.StatusConflict, gin.H{"error": "You already have an open report about this user"})
+		default:
+			logger.ErrorContext(c.Request.Context(), "Error filing report", "reporter_id", caller.ID, "user_id", target.ID, "error", err)
+			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to file report"})
+		}
+		return
+	}
+	logger.InfoContext(c.Request.Context(), "User reported", "reporter_id", caller.ID, "user_id", target.ID, "reason", created.Reason, "report_id", created.ID, "messages", len(req.MessageIDs))
+
+	if req.Block {
+		if err := h.blocks.BlockUser(ctx, caller.ID, target.ID); err != nil {
+			// The report is filed; tell the client so it can offer to block separately
+			logger.ErrorContext(c.Request.Context(), "Error blocking user after report", "user_id", target.ID, "report_id", created.ID, "error", err)
+			c.JSON(http.StatusCreated, gin.H{"report": created, "blocked": false})
+			return
+		}
+	}
+	c.JSON(http.StatusCreated, gin.H{"report": created, "blocked": req.Block})
+}
@@ -0,0 +1,119 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gin/api/middleware"
+	"gin/internal/models"
+	"gin/internal/services/database/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newSafetyRouter serves the safety routes from store, authenticating each request as
+// the Clerk user in the X-Test-User header.
+func newSafetyRouter(store *memory.Store) *gin.Engine {
+	h := NewSafetyHandler(store, store, store)
+	router := gin.New()
+	users := router.Group("/users", func(c *gin.Context) {
+		c.Set(string(middleware.UserIDKey), c.GetHeader("X-Test-User"))
+	}, middleware.LoadUser(middleware.NewUserCache(store, time.Hour)))
+	users.GET("/blocks", h.GetBlockedUsers)
+	users.POST("/:id/block", h.BlockUser)
+	users.DELETE("/:id/block", h.UnblockUser)
+	users.POST("/:id/report", h.ReportUser)
+	return router
+}
+
+func serveAs(router *gin.Engine, user *models.User, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("X-Test-User", user.ClerkUserID)
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func newSafetyUser(t *testing.T, store *memory.Store, name string) *models.User {
+	t.Helper()
+	user, err := store.CreateOrUpdateUserProfile(context.Background(), models.User{ClerkUserID: "user_" + name, Username: &name})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestBlockEndpoints(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.NewStore()
+	router := newSafetyRouter(store)
+	alice := newSafetyUser(t, store, "alice")
+	bob := newSafetyUser(t, store, "bob")
+
+	tests := []struct {
+		name, method, path string
+		want               int
+	}{
+		{"block", http.MethodPost, "/users/" + bob.ID + "/block", http.StatusNoContent},
+		{"block again", http.MethodPost, "/users/" + bob.ID + "/block", http.StatusNoContent},
+		{"block self", http.MethodPost, "/users/" + alice.ID + "/block", http.StatusBadRequest},
+		{"block unknown user", http.MethodPost, "/users/nobody/block", http.StatusNotFound},
+		{"unblock", http.MethodDelete, "/users/" + bob.ID + "/block", http.StatusNoContent},
+		{"unblock again", http.MethodDelete, "/users/" + bob.ID + "/block", http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		if w := serveAs(router, alice, tt.method, tt.path, ""); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+
+	serveAs(router, alice, http.MethodPost, "/users/"+bob.ID+"/block", "")
+	w := serveAs(router, alice, http.MethodGet, "/users/blocks", "")
+	var body struct {
+		Blocks []models.BlockedUser `json:"blocks"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || len(body.Blocks) != 1 || body.Blocks[0].User.ID != bob.ID {
+		t.Errorf("GET /users/blocks = %d %s, want bob", w.Code, w.Body)
+	}
+}
+
+func TestReportEndpoint(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.NewStore()
+	router := newSafetyRouter(store)
+	alice := newSafetyUser(t, store, "alice")
+	bob := newSafetyUser(t, store, "bob")
+	path := "/users/" + bob.ID + "/report"
+
+	tests := []struct {
+		name, body string
+		want       int
+	}{
+		{"unknown reason", `{"reason": "rude"}`, http.StatusBadRequest},
+		{"other without details", `{"reason": "other", "details": "  "}`, http.StatusBadRequest},
+		{"unknown message", `{"reason": "spam", "messageIds": ["missing"]}`, http.StatusBadRequest},
+		{"report and block", `{"reason": "spam", "block": true}`, http.StatusCreated},
+		{"duplicate", `{"reason": "scam"}`, http.StatusConflict},
+	}
+	for _, tt := range tests {
+		if w := serveAs(router, alice, http.MethodPost, path, tt.body); w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d: %s", tt.name, w.Code, tt.want, w.Body)
+		}
+	}
+	if w := serveAs(router, alice, http.MethodPost, "/users/"+alice.ID+"/report", `{"reason": "spam"}`); w.Code != http.StatusBadRequest {
+		t.Errorf("reporting self: status = %d, want 400", w.Code)
+	}
+
+	if blocked, _ := store.IsBlockedBetween(context.Background(), alice.ID, bob.ID); !blocked {
+		t.Error("bob is not blocked after a report with block set")
+	}
+	reports, err := store.GetReports(context.Background(), models.ReportOpen, 10, 0)
+	if err != nil || len(reports) != 1 || reports[0].ReportedUserID != bob.ID || reports[0].Reason != models.ReportSpam {
+		t.Errorf("open reports = %+v, %v; want the spam report about bob", reports, err)
+	}
+}
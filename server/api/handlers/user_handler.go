@@ -1,23 +1,27 @@
 package handlers
 
 import (
-	"log"
 	"net/http"
+	"strings"
 
 	"gin/api/middleware"             // Corrected import path
 	"gin/internal/models"            // Corrected import path
 	"gin/internal/services/database" // Corrected import path
+	"gin/internal/services/githubsync"
+	"gin/internal/services/moderation"
 
 	"github.com/gin-gonic/gin"
 )
 
 type UserHandler struct {
-	DBService *database.DBService
-	// Add GeminiService, GitHubService later if needed
+	users      database.UserRepository
+	moderator  *moderation.Moderator // Screens bios
+	githubSync *githubsync.Syncer    // Fetches GitHub stats when the GitHub URL changes; optional
+	// Add GeminiService later if needed
 }
 
-func NewUserHandler(db *database.DBService) *UserHandler {
-	return &UserHandler{DBService: db}
+func NewUserHandler(users database.UserRepository, moderator *moderation.Moderator, githubSync *githubsync.Syncer) *UserHandler {
+	return &UserHandler{users: users, moderator: moderator, githubSync: githubSync}
 }
 
 // GetUserProfileByID godoc
@@ -48,9 +52,9 @@ func (h *UserHandler) GetUserProfileByID(c *gin.Context) {
 	// BUT YOU SHOULD CREATE A SEPARATE FUNCTION GetUserProfileByDBID in db.go
 	// For now, we will just return a placeholder error.
 
-	// userProfile, err := h.DBService.GetUserProfileByDBID(c.Request.Context(), userID) // Correct approach
+	// userProfile, err := h.users.GetUserProfileByDBID(c.Request.Context(), userID) // Correct approach
 	// Placeholder:
-	log.Printf("Attempting to get user profile by DB ID: %s (DB query function not implemented yet for this endpoint)", userID)
+	logger.DebugContext(c.Request.Context(), "Getting user profile by DB ID", "user_id", userID)
 	c.JSON(http.StatusNotImplemented, gin.H{"error": "Fetching user by database ID not fully implemented yet"})
 
 	/* // Example of how it *would* look with GetUserProfileByDBID:
@@ -58,7 +62,7 @@ func (h *UserHandler) GetUserProfileByID(c *gin.Context) {
 		if err == pgx.ErrNoRows {
 			c.JSON(http.StatusNotFound, gin.H{"error": "User not found"})
 		} else {
-			log.Printf("Error fetching profile for DB ID %s: %v\n", userID, err)
+			logger.ErrorContext(c.Request.Context(), "Error fetching profile", "user_id", userID, "error", err)
 			c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user profile"})
 		}
 		return
@@ -77,23 +81,24 @@ func (h *UserHandler) GetUserProfileByID(c *gin.Context) {
 // @Produce json
 // @Security ClerkAuth
 // @Param profile body models.CreateUserProfileRequest true "Profile data to create or update"
-// @Success 200 {object} models.User "Successfully updated profile"
-// @Success 201 {object} models.User "Successfully created profile"
+// @Success 200 {object} models.UserAccount "Successfully updated profile"
+// @Success 201 {object} models.UserAccount "Successfully created profile"
 // @Failure 400 {object} gin.H "Invalid request body"
 // @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 422 {object} gin.H "Bio rejected by moderation"
 // @Failure 500 {object} gin.H "Internal Server Error"
 // @Router /users/profile [post] // Using a dedicated endpoint instead of /users/create
 func (h *UserHandler) CreateOrUpdateCurrentUserProfile(c *gin.Context) {
 	clerkUserID, exists := middleware.GetClerkUserID(c)
 	if !exists {
-		log.Println("Error: ClerkUserID not found in context in CreateOrUpdateCurrentUserProfile")
+		logger.ErrorContext(c.Request.Context(), "ClerkUserID not found in context in CreateOrUpdateCurrentUserProfile")
 		c.JSON(http.StatusUnauthorized, gin.H{"error": "Unauthorized: Cannot identify user"})
 		return
 	}
 
 	var req models.CreateUserProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		log.Printf("Error binding JSON for profile creation/update: %v\n", err)
+		logger.InfoContext(c.Request.Context(), "Invalid profile creation/update request", "error", err)
 		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid request body: " + err.Error()})
 		return
 	}
@@ -105,25 +110,115 @@ func (h *UserHandler) CreateOrUpdateCurrentUserProfile(c *gin.Context) {
 		PictureURL:  req.PictureURL,
 		Bio:         req.Bio,
 		GitHubURL:   req.GitHubURL,
+
+		Interests:             req.Interests,
+		TimezoneOffsetMinutes: req.TimezoneOffsetMinutes,
 	}
 
-	// Check if user already exists to return 200 (update) or 201 (create)
-	_, err := h.DBService.GetUserProfileByClerkID(c.Request.Context(), clerkUserID)
-	isUpdate := err == nil // If no error (found), it's an update
+	// LoadUser found the profile if it exists, so 200 (update) or 201 (create)
+	existing, isUpdate := middleware.CurrentUser(c)
+
+	// A new or changed bio is moderated; rejected bios are not saved, flagged ones are logged
+	var bio *moderation.Content
+	verdict := moderation.Allowed
+	if req.Bio != nil && strings.TrimSpace(*req.Bio) != "" && (!isUpdate || existing.Bio == nil || *existing.Bio != *req.Bio) {
+		bio = &moderation.Content{Type: models.ModerationContentBio, Text: *req.Bio}
+		if isUpdate {
+			bio.AuthorID = existing.ID
+		}
+		verdict = h.moderator.Check(c.Request.Context(), *bio)
+		if verdict.Decision == models.ModerationReject {
+			// New profiles have no ID to log the rejection under yet
+			if isUpdate {
+				h.moderator.Record(c.Request.Context(), *bio, verdict, nil)
+			}
+			c.JSON(http.StatusUnprocessableEntity, gin.H{"error": "Bio rejected by moderation", "reason": verdict.Reason})
+			return
+		}
+	}
 
-	createdOrUpdatedUser, err := h.DBService.CreateOrUpdateUserProfile(c.Request.Context(), user)
+	createdOrUpdatedUser, err := h.users.CreateOrUpdateUserProfile(c.Request.Context(), user)
 	if err != nil {
-		log.Printf("Error saving profile for Clerk User ID %s: %v\n", clerkUserID, err)
+		logger.ErrorContext(c.Request.Context(), "Error saving profile", "clerk_user_id", clerkUserID, "error", err)
 		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to save user profile"})
 		return
 	}
+	middleware.ForgetCurrentUser(c)
+	if h.githubSync != nil && githubsync.NeedsSync(existing, createdOrUpdatedUser) {
+		h.githubSync.SyncUserAsync(c.Request.Context(), *createdOrUpdatedUser)
+	}
+	if bio != nil {
+		bio.AuthorID = createdOrUpdatedUser.ID
+		h.moderator.Record(c.Request.Context(), *bio, verdict, nil) // Only logged if flagged
+	}
 
 	if isUpdate {
-		c.JSON(http.StatusOK, createdOrUpdatedUser) // 200 OK for update
+		c.JSON(http.StatusOK, models.NewUserAccount(createdOrUpdatedUser)) // 200 OK for update
 	} else {
-		c.JSON(http.StatusCreated, createdOrUpdatedUser) // 201 Created for new profile
+		c.JSON(http.StatusCreated, models.NewUserAccount(createdOrUpdatedUser)) // 201 Created for new profile
 	}
 }
 
+// searchQuery binds and validates the query parameters of GET /users/search.
+type searchQuery struct {
+	Q        string `form:"q" binding:"required,max=200"`
+	Page     int    `form:"page" binding:"omitempty,min=1"`
+	PageSize int    `form:"page_size" binding:"omitempty,min=1,max=50"`
+}
+
+// SearchUsers godoc
+// @Summary Search developers
+// @Description Full-text search over usernames, bios, interests and GitHub repository names. Every word is matched as a prefix. Users who blocked or were blocked by the caller are excluded.
+// @Tags Users
+// @Produce json
+// @Security ClerkAuth
+// @Param q query string true "Search terms"
+// @Param page query int false "Page number, starting at 1"
+// @Param page_size query int false "Results per page (max 50, default 20)"
+// @Success 200 {object} gin.H "results, page, page_size and has_more"
+// @Failure 400 {object} gin.H "Invalid query"
+// @Failure 401 {object} gin.H "Unauthorized"
+// @Failure 403 {object} gin.H "Profile required"
+// @Failure 500 {object} gin.H "Internal Server Error"
+// @Router /users/search [get]
+func (h *UserHandler) SearchUsers(c *gin.Context) {
+	caller, ok := currentUserProfile(c)
+	if !ok {
+		return
+	}
+
+	var query searchQuery
+	if err := c.ShouldBindQuery(&query); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Invalid query: " + err.Error()})
+		return
+	}
+	if query.Page == 0 {
+		query.Page = 1
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 20
+	}
+
+	// Fetch one extra row to know whether another page exists
+	offset := (query.Page - 1) * query.PageSize
+	results, err := h.users.SearchUsers(c.Request.Context(), caller.ID, query.Q, query.PageSize+1, offset)
+	if err != nil {
+		logger.ErrorContext(c.Request.Context(), "Error searching users", "query", query.Q, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to search users"})
+		return
+	}
+	hasMore := len(results) > query.PageSize
+	if hasMore {
+		results = results[:query.PageSize]
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"results":   results,
+		"page":      query.Page,
+		"page_size": query.PageSize,
+		"has_more":  hasMore,
+	})
+}
+
 // TODO: Implement PUT /users/:id (Edit existing profile info - requires careful auth checks)
 // TODO: Implement GET /users/random (Get list of random users for swiping)
@@ -0,0 +1,185 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"gin/api/middleware"
+	"gin/internal/services"
+	"gin/internal/services/accounts"
+	"gin/internal/services/database"
+	"gin/internal/services/githubsync"
+
+	"github.com/clerkinc/clerk-sdk-go/clerk"
+	"github.com/gin-gonic/gin"
+)
+
+// maxWebhookBodyBytes bounds the webhook payloads we read; Clerk user events are a few KB.
+const maxWebhookBodyBytes = 1 << 20
+
+// clerkWebhookSource identifies Clerk deliveries in webhook_events.
+const clerkWebhookSource = "clerk"
+
+// WebhookHandler receives webhooks from Clerk to keep profiles in step with Clerk users.
+type WebhookHandler struct {
+	verifier   *services.SvixVerifier // Nil when no signing secret is configured
+	users      database.UserRepository
+	events     database.WebhookEventRepository
+	purger     *accounts.Purger
+	githubSync *githubsync.Syncer    // Fetches GitHub stats when a profile's GitHub account changes; optional
+	userCache  *middleware.UserCache // Changed and deleted profiles are dropped from it; optional
+}
+
+// NewWebhookHandler creates a new WebhookHandler. verifier may be nil, in which case
+// every webhook is refused. The public webhook route has no LoadUser, so profiles the
+// webhook changes are invalidated in userCache directly.
+func NewWebhookHandler(verifier *services.SvixVerifier, users database.UserRepository, events database.WebhookEventRepository, purger *accounts.Purger, githubSync *githubsync.Syncer, userCache *middleware.UserCache) *WebhookHandler {
+	return &WebhookHandler{verifier: verifier, users: users, events: events, purger: purger, githubSync: githubSync, userCache: userCache}
+}
+
+// forgetUser drops the cached profile of clerkUserID, so a changed role or a deletion
+// applies to the user's next request.
+func (h *WebhookHandler) forgetUser(clerkUserID string) {
+	if h.userCache != nil {
+		h.userCache.Invalidate(clerkUserID)
+	}
+}
+
+// clerkWebhookEvent is the envelope of every Clerk webhook.
+type clerkWebhookEvent struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data"`
+}
+
+// HandleClerkWebhook godoc
+// @Summary Receive Clerk user lifecycle webhooks
+// @Description Verifies the Svix signature, then creates or updates the profile on user.created and user.updated and purges the account on user.deleted. Each delivery is applied once; redeliveries are acknowledged without effect. Other event types are acknowledged and ignored.
+// @Tags Webhooks
+// @Accept json
+// @Produce json
+// @Success 200 {object} gin.H "Event processed, ignored or already processed"
+// @Failure 400 {object} gin.H "Malformed payload"
+// @Failure 401 {object} gin.H "Invalid signature"
+// @Failure 500 {object} gin.H "Processing failed; Clerk will retry"
+// @Failure 503 {object} gin.H "Webhooks are not configured"
+// @Router /webhooks/clerk [post]
+func (h *WebhookHandler) HandleClerkWebhook(c *gin.Context) {
+	if h.verifier == nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": "Clerk webhooks are not configured"})
+		return
+	}
+
+	body, err := io.ReadAll(io.LimitReader(c.Request.Body, maxWebhookBodyBytes+1))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Failed to read request body"})
+		return
+	}
+	if len(body) > maxWebhookBodyBytes {
+		c.JSON(http.StatusRequestEntityTooLarge, gin.H{"error": "Payload too large"})
+		return
+	}
+
+	// The signature covers the exact bytes received, so verify before parsing anything
+	eventID, err := h.verifier.Verify(c.Request.Header, body)
+	if err != nil {
+		logger.WarnContext(c.Request.Context(), "Rejected Clerk webhook", "error", err)
+		c.JSON(http.StatusUnauthorized, gin.H{"error": "Invalid webhook signature"})
+		return
+	}
+
+	var event clerkWebhookEvent
+	if err := json.Unmarshal(body, &event); err != nil || event.Type == "" {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "Malformed webhook payload"})
+		return
+	}
+
+	ctx := c.Request.Context()
+	processed, err := h.events.IsWebhookEventProcessed(ctx, clerkWebhookSource, eventID)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to check webhook event"})
+		return
+	}
+	if processed {
+		c.JSON(http.StatusOK, gin.H{"status": "already_processed"})
+		return
+	}
+
+	status := "processed"
+	switch event.Type {
+	case "user.created", "user.updated":
+		err = h.upsertClerkUser(c, event.Data)
+	case "user.deleted":
+		err = h.deleteClerkUser(c, event.Data)
+	default:
+		status = "ignored"
+	}
+	if err != nil {
+		// A non-2xx response makes Svix retry the delivery later
+		logger.ErrorContext(ctx, "Error processing Clerk webhook", "event_id", eventID, "event_type", event.Type, "error", err)
+		c.JSON(http.StatusInternalServerError, gin.H{"error": "Failed to process webhook"})
+		return
+	}
+
+	// Recorded only after success, so a failed delivery is applied when it is retried.
+	// If recording fails the event may be applied again, which every handler tolerates.
+	if err := h.events.RecordWebhookEvent(ctx, clerkWebhookSource, eventID, event.Type); err != nil {
+		logger.WarnContext(ctx, "Processed Clerk webhook but could not record it", "event_id", eventID, "error", err)
+	}
+	c.JSON(http.StatusOK, gin.H{"status": status})
+}
+
+// upsertClerkUser creates or updates the profile of the Clerk user in data.
+func (h *WebhookHandler) upsertClerkUser(c *gin.Context, data json.RawMessage) error {
+	var clerkUser clerk.User
+	if err := json.Unmarshal(data, &clerkUser); err != nil {
+		return err
+	}
+	if clerkUser.ID == "" {
+		return errors.New("user event without a user ID")
+	}
+
+	// The profile as it was, to tell whether the linked GitHub account changed
+	before, err := h.users.GetUserProfileByClerkID(c.Request.Context(), clerkUser.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+
+	profile, err := h.users.CreateOrUpdateUserProfile(c.Request.Context(), services.ProfileFromClerkUser(&clerkUser))
+	if err != nil {
+		return err
+	}
+	h.forgetUser(clerkUser.ID)
+	logger.InfoContext(c.Request.Context(), "Synced profile from Clerk", "user_id", profile.ID, "clerk_user_id", clerkUser.ID)
+	if h.githubSync != nil && githubsync.NeedsSync(before, profile) {
+		h.githubSync.SyncUserAsync(c.Request.Context(), *profile)
+	}
+	return nil
+}
+
+// deleteClerkUser purges the profile of the Clerk user in data, if there is one.
+func (h *WebhookHandler) deleteClerkUser(c *gin.Context, data json.RawMessage) error {
+	var deleted struct {
+		ID string `json:"id"`
+	}
+	if err := json.Unmarshal(data, &deleted); err != nil {
+		return err
+	}
+	if deleted.ID == "" {
+		return errors.New("user.deleted event without a user ID")
+	}
+
+	defer h.forgetUser(deleted.ID)
+
+	user, err := h.users.GetUserProfileByClerkID(c.Request.Context(), deleted.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil // Never had a profile, or already purged
+	}
+	if err != nil {
+		return err
+	}
+	// The user is already gone from Clerk, so only our side is left to delete
+	return h.purger.PurgeAccount(c.Request.Context(), *user, false)
+}
@@ -0,0 +1,158 @@
+package handlers
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"gin/api/middleware"
+	"gin/internal/models"
+	"gin/internal/services"
+	"gin/internal/services/accounts"
+	"gin/internal/services/database/memory"
+
+	"github.com/gin-gonic/gin"
+)
+
+// testWebhookKey signs the deliveries sent by postClerkWebhook.
+var testWebhookKey = []byte("test-webhook-signing-key")
+
+// postClerkWebhook sends body to h as a Clerk delivery with ID msgID, signed the way
+// Svix signs it.
+func postClerkWebhook(t *testing.T, h *WebhookHandler, msgID, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	mac := hmac.New(sha256.New, testWebhookKey)
+	mac.Write([]byte(msgID + "." + timestamp + "." + body))
+
+	router := gin.New()
+	router.POST("/webhooks/clerk", h.HandleClerkWebhook)
+	req := httptest.NewRequest(http.MethodPost, "/webhooks/clerk", strings.NewReader(body))
+	req.Header.Set("svix-id", msgID)
+	req.Header.Set("svix-timestamp", timestamp)
+	req.Header.Set("svix-signature", "v1,"+base64.StdEncoding.EncodeToString(mac.Sum(nil)))
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// newTestWebhookHandler returns a handler verifying deliveries signed with testWebhookKey.
+func newTestWebhookHandler(t *testing.T, store *memory.Store) *WebhookHandler {
+	t.Helper()
+	verifier, err := services.NewSvixVerifier("whsec_" + base64.StdEncoding.EncodeToString(testWebhookKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return NewWebhookHandler(verifier, store, store, accounts.NewPurger(store, store, nil, nil, time.Hour), nil, nil)
+}
+
+func TestClerkWebhookLifecycle(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+	store := memory.NewStore()
+	h := newTestWebhookHandler(t, store)
+
+	steps := []struct {
+		name, msgID, body string
+		wantStatus        int
+		wantBody          string
+	}{
+		{"created", "msg_1", `{"type":"user.created","data":{"id":"user_ada","username":"ada"}}`, http.StatusOK, `"processed"`},
+		{"redelivered", "msg_1", `{"type":"user.created","data":{"id":"user_ada","username":"ada"}}`, http.StatusOK, `"already_processed"`},
+		{"updated", "msg_2", `{"type":"user.updated","data":{"id":"user_ada","username":"lovelace"}}`, http.StatusOK, `"processed"`},
+		{"other event", "msg_3", `{"type":"session.created","data":{}}`, http.StatusOK, `"ignored"`},
+		{"without user ID", "msg_4", `{"type":"user.updated","data":{}}`, http.StatusInternalServerError, "Failed to process webhook"},
+		{"malformed", "msg_5", `not json`, http.StatusBadRequest, "Malformed webhook payload"},
+	}
+	for _, step := range steps {
+		w := postClerkWebhook(t, h, step.msgID, step.body)
+		if w.Code != step.wantStatus || !strings.Contains(w.Body.String(), step.wantBody) {
+			t.Fatalf("%s: got %d %s, want %d with %s", step.name, w.Code, w.Body.String(), step.wantStatus, step.wantBody)
+		}
+	}
+
+	user, err := store.GetUserProfileByClerkID(ctx, "user_ada")
+	if err != nil {
+		t.Fatalf("profile of user_ada: %v", err)
+	}
+	if user.Username == nil || *user.Username != "lovelace" {
+		t.Errorf("username = %v, want lovelace", user.Username)
+	}
+
+	// Deleting in Clerk purges the profile; deleting an unknown user is a no-op
+	for _, msgID := range []string{"msg_6", "msg_7"} {
+		w := postClerkWebhook(t, h, msgID, `{"type":"user.deleted","data":{"id":"user_ada","deleted":true}}`)
+		if w.Code != http.StatusOK {
+			t.Fatalf("user.deleted %s: got %d %s, want 200", msgID, w.Code, w.Body.String())
+		}
+	}
+	if _, err := store.GetUserProfileByID(ctx, user.ID); err == nil {
+		t.Error("profile still exists after user.deleted")
+	}
+}
+
+func TestClerkWebhookRejected(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store := memory.NewStore()
+
+	w := postClerkWebhook(t, NewWebhookHandler(nil, store, store, nil, nil, nil), "msg_1", `{"type":"user.created"}`)
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("without a signing secret: got %d, want 503", w.Code)
+	}
+
+	otherKey := base64.StdEncoding.EncodeToString([]byte("someone-elses-key"))
+	verifier, err := services.NewSvixVerifier("whsec_" + otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body := `{"type":"user.created","data":{"id":"user_mallory"}}`
+	w = postClerkWebhook(t, NewWebhookHandler(verifier, store, store, nil, nil, nil), "msg_1", body)
+	if w.Code != http.StatusUnauthorized {
+		t.Errorf("with a bad signature: got %d, want 401", w.Code)
+	}
+	if _, err := store.GetUserProfileByClerkID(context.Background(), "user_mallory"); err == nil {
+		t.Error("a webhook with a bad signature created a profile")
+	}
+}
+
+func TestClerkWebhookInvalidatesCachedProfile(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	cache := middleware.NewUserCache(store, time.Hour)
+	verifier, err := services.NewSvixVerifier("whsec_" + base64.StdEncoding.EncodeToString(testWebhookKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	purger := accounts.NewPurger(store, store, nil, cache, time.Hour)
+	h := NewWebhookHandler(verifier, store, store, purger, nil, cache)
+
+	username := "ada"
+	if _, err := store.CreateOrUpdateUserProfile(ctx, models.User{ClerkUserID: "user_ada", Username: &username, Role: models.RoleAdmin}); err != nil {
+		t.Fatal(err)
+	}
+	if cached, err := cache.Get(ctx, "user_ada"); err != nil || cached.Role != models.RoleAdmin {
+		t.Fatalf("cached profile = %+v (err %v), want an admin", cached, err)
+	}
+
+	// Demoting the user in Clerk applies on their next request, not when the cache expires
+	w := postClerkWebhook(t, h, "msg_1", `{"type":"user.updated","data":{"id":"user_ada","username":"ada","public_metadata":{"role":"user"}}}`)
+	expectStatus(t, w, http.StatusOK)
+	if cached, err := cache.Get(ctx, "user_ada"); err != nil || cached.Role != models.RoleUser {
+		t.Errorf("cached profile after user.updated = %+v (err %v), want the user role", cached, err)
+	}
+
+	w = postClerkWebhook(t, h, "msg_2", `{"type":"user.deleted","data":{"id":"user_ada","deleted":true}}`)
+	expectStatus(t, w, http.StatusOK)
+	if _, err := cache.Get(ctx, "user_ada"); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("cache lookup after user.deleted error = %v, want sql.ErrNoRows", err)
+	}
+}
@@ -1,10 +1,11 @@
 package middleware
 
 import (
-	"log"
+	"errors"
 	"net/http"
 
-	"github.com/clerkinc/clerk-sdk-go/clerk"
+	"gin/internal/services/auth"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -20,42 +21,31 @@ type ContextKey string
 // UserIDKey is the key used to store the Clerk User ID in the Gin context.
 const UserIDKey ContextKey = "clerkUserID"
 
-// ClerkMiddleware creates Gin middleware for Clerk authentication.
-func ClerkMiddleware(clerkClient clerk.Client) gin.HandlerFunc {
-	return func(c *gin.Context) {
-		// Extract the session token from the Authorization header
-		sessionToken := c.GetHeader("Authorization")
-		if sessionToken == "" {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header missing"})
-			return
+// AuthMiddleware creates Gin middleware authenticating requests with the configured
+// provider (Clerk, local tokens or dev mode). Dev mode trusts a plain header, so in
+// Gin release mode it refuses every request, whatever the config let through.
+func AuthMiddleware(authenticator auth.Authenticator) gin.HandlerFunc {
+	if _, dev := authenticator.(*auth.DevAuthenticator); dev && gin.Mode() == gin.ReleaseMode {
+		logger.Error("Dev authentication is not allowed in release mode; refusing all authenticated requests")
+		return func(c *gin.Context) {
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Authentication is misconfigured"})
 		}
+	}
 
-		// The token is usually prefixed with "Bearer ", remove it
-		if len(sessionToken) > 7 && sessionToken[:7] == "Bearer " {
-			sessionToken = sessionToken[7:]
-		} else {
-			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid Authorization header format"})
+	return func(c *gin.Context) {
+		clerkUserID, err := authenticator.Authenticate(c.Request)
+		if errors.Is(err, auth.ErrNoCredentials) {
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Authorization header missing"})
 			return
 		}
-
-		// Verify the session token
-		sessionClaims, err := clerkClient.VerifyToken(sessionToken)
 		if err != nil {
-			log.Printf("Error verifying Clerk session token: %v", err)
-			// Differentiate between invalid token and other errors if needed
+			logger.WarnContext(c.Request.Context(), "Rejected request with invalid credentials", "error", err)
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid session token"})
 			return
 		}
 
-		// Check if session is active (optional but recommended)
-		// if sessionClaims.Expiry.Before(time.Now()) { // This check might be handled by VerifyToken already
-		// 	c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Session expired"})
-		// 	return
-		// }
-
 		// Set the user ID in the context for downstream handlers
-		c.Set(string(UserIDKey), sessionClaims.Subject) // Subject usually holds the User ID
-		log.Printf("Clerk Auth successful for user: %s", sessionClaims.Subject)
+		c.Set(string(UserIDKey), clerkUserID)
 
 		// Continue to the next handler
 		c.Next()
@@ -67,13 +57,13 @@ func ClerkMiddleware(clerkClient clerk.Client) gin.HandlerFunc {
 func GetClerkUserID(c *gin.Context) (string, bool) {
 	userID, exists := c.Get(string(UserIDKey))
 	if !exists {
-		log.Println("Warning: Clerk User ID not found in context")
+		logger.WarnContext(c.Request.Context(), "Clerk User ID not found in context")
 		return "", false
 	}
 
 	userIDStr, ok := userID.(string)
 	if !ok {
-		log.Println("Warning: Clerk User ID in context is not a string")
+		logger.WarnContext(c.Request.Context(), "Clerk User ID in context is not a string")
 		return "", false
 	}
 
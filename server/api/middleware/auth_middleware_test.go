@@ -0,0 +1,106 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin/internal/services/auth"
+
+	"github.com/clerkinc/clerk-sdk-go/clerk"
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+// serveAuthenticated sends req through AuthMiddleware(authenticator) and returns the
+// response and the Clerk user ID the handler saw.
+func serveAuthenticated(authenticator auth.Authenticator, req *http.Request) (*httptest.ResponseRecorder, string) {
+	var clerkUserID string
+	router := gin.New()
+	router.GET("/", AuthMiddleware(authenticator), func(c *gin.Context) {
+		clerkUserID, _ = GetClerkUserID(c)
+		c.Status(http.StatusOK)
+	})
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w, clerkUserID
+}
+
+func TestAuthMiddlewareDevMode(t *testing.T) {
+	defer gin.SetMode(gin.Mode())
+
+	tests := []struct {
+		name       string
+		mode       string
+		header     string
+		wantStatus int
+		wantUser   string
+	}{
+		{"login", gin.DebugMode, "torvalds", http.StatusOK, "seed_user_torvalds"},
+		{"seeded ID", gin.TestMode, "seed_user_torvalds", http.StatusOK, "seed_user_torvalds"},
+		{"not a login", gin.DebugMode, "user_2abc/../x", http.StatusUnauthorized, ""},
+		{"no header", gin.DebugMode, "", http.StatusUnauthorized, ""},
+		{"release mode", gin.ReleaseMode, "torvalds", http.StatusInternalServerError, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(tt.mode)
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(auth.DevUserHeader, tt.header)
+			}
+			w, user := serveAuthenticated(auth.NewDevAuthenticator(), req)
+			if w.Code != tt.wantStatus || user != tt.wantUser {
+				t.Errorf("status %d as %q, want %d as %q", w.Code, user, tt.wantStatus, tt.wantUser)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareClerk(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	key, err := auth.NewTestKeySet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := auth.NewKeySet(key)
+	authenticator := auth.NewClerkAuthenticator(auth.NewVerifier(keys, auth.VerifierOptions{ClockSkew: 5 * time.Second}))
+
+	token := func(expiresIn time.Duration) string {
+		signed, err := key.Sign(clerk.SessionClaims{Claims: jwt.Claims{
+			Subject: "user_123",
+			Expiry:  jwt.NewNumericDate(time.Now().Add(expiresIn)),
+		}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return signed
+	}
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantStatus    int
+		wantUser      string
+	}{
+		// The key set starts empty: the first token's kid triggers the fetch
+		{"valid token", "Bearer " + token(time.Minute), http.StatusOK, "user_123"},
+		{"expired token", "Bearer " + token(-time.Minute), http.StatusUnauthorized, ""},
+		{"garbage", "Bearer not-a-token", http.StatusUnauthorized, ""},
+		{"not a bearer token", "Basic dXNlcjpwYXNz", http.StatusUnauthorized, ""},
+		{"missing", "", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			w, user := serveAuthenticated(authenticator, req)
+			if w.Code != tt.wantStatus || user != tt.wantUser {
+				t.Errorf("status %d as %q, want %d as %q", w.Code, user, tt.wantStatus, tt.wantUser)
+			}
+		})
+	}
+}
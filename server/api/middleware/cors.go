@@ -0,0 +1,148 @@
+package middleware
+
+import (
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSOptions is the cross-origin policy enforced by CORS.
+type CORSOptions struct {
+	// AllowedOrigins are the origins browsers may call the API from, e.g.
+	// "https://devmatch.app". "https://*.devmatch.app" allows every subdomain (but not
+	// the bare domain) over https, and "*" allows any origin.
+	AllowedOrigins   []string
+	AllowedMethods   []string
+	AllowedHeaders   []string // Request headers allowed in CORS requests
+	ExposedHeaders   []string // Response headers the frontend may read
+	MaxAge           time.Duration
+	AllowCredentials bool // Allow cookies and Authorization headers; never combined with "*"
+}
+
+// originPattern is a parsed entry of CORSOptions.AllowedOrigins.
+type originPattern struct {
+	scheme string
+	host   string // Includes the port, if any; for wildcards, the part after "*."
+	any    bool   // "*"
+	suffix bool   // "scheme://*.host"
+}
+
+// parseOriginPattern parses an allowed origin, returning false if it is malformed.
+func parseOriginPattern(origin string) (originPattern, bool) {
+	origin = strings.ToLower(strings.TrimSuffix(strings.TrimSpace(origin), "/"))
+	if origin == "*" {
+		return originPattern{any: true}, true
+	}
+	scheme, host, ok := strings.Cut(origin, "://")
+	if !ok || scheme == "" || host == "" {
+		return originPattern{}, false
+	}
+	if rest, wildcard := strings.CutPrefix(host, "*."); wildcard {
+		return originPattern{scheme: scheme, host: rest, suffix: true}, rest != ""
+	}
+	return originPattern{scheme: scheme, host: host}, true
+}
+
+// matches reports whether the request origin (scheme and host, lowercased) is allowed by p.
+func (p originPattern) matches(scheme, host string) bool {
+	switch {
+	case p.any:
+		return true
+	case scheme != p.scheme:
+		return false
+	case p.suffix:
+		return strings.HasSuffix(host, "."+p.host)
+	default:
+		return host == p.host
+	}
+}
+
+// CORS returns middleware applying opts to cross-origin requests. Requests from allowed
+// origins get the Access-Control-* response headers; others get none, so browsers block
+// them. Preflight requests are answered here: 204 for allowed origins and methods, 403
+// otherwise.
+func CORS(opts CORSOptions) gin.HandlerFunc {
+	patterns := make([]originPattern, 0, len(opts.AllowedOrigins))
+	anyOrigin := false
+	for _, origin := range opts.AllowedOrigins {
+		if p, ok := parseOriginPattern(origin); ok {
+			patterns = append(patterns, p)
+			anyOrigin = anyOrigin || p.any
+		}
+	}
+	allowedMethods := make(map[string]struct{}, len(opts.AllowedMethods))
+	for _, method := range opts.AllowedMethods {
+		allowedMethods[strings.ToUpper(method)] = struct{}{}
+	}
+	methods := strings.Join(opts.AllowedMethods, ", ")
+	headers := strings.Join(opts.AllowedHeaders, ", ")
+	exposed := strings.Join(opts.ExposedHeaders, ", ")
+	maxAge := strconv.Itoa(int(opts.MaxAge.Seconds()))
+
+	isAllowed := func(origin string) bool {
+		u, err := url.Parse(strings.ToLower(origin))
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return false
+		}
+		for _, p := range patterns {
+			if p.matches(u.Scheme, u.Host) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next() // Not a cross-origin request
+			return
+		}
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+
+		h := c.Writer.Header()
+		h.Add("Vary", "Origin") // The response depends on the origin, so caches must not share it
+		_, methodAllowed := allowedMethods[strings.ToUpper(c.GetHeader("Access-Control-Request-Method"))]
+		if !isAllowed(origin) || (preflight && !methodAllowed) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+				return
+			}
+			c.Next()
+			return
+		}
+
+		// Echo the origin unless any origin is allowed without credentials
+		if anyOrigin && !opts.AllowCredentials {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if opts.AllowCredentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposed != "" {
+				h.Set("Access-Control-Expose-Headers", exposed)
+			}
+			c.Next()
+			return
+		}
+
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", methods)
+		if headers != "" {
+			h.Set("Access-Control-Allow-Headers", headers)
+		}
+		if opts.MaxAge > 0 {
+			h.Set("Access-Control-Max-Age", maxAge)
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
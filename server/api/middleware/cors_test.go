@@ -0,0 +1,189 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// serveCORS sends a request from origin through CORS(opts), as a preflight for
+// requestMethod if it is set. The handler behind the middleware answers 200.
+func serveCORS(opts CORSOptions, method, origin, requestMethod string) *httptest.ResponseRecorder {
+	router := gin.New()
+	router.Use(CORS(opts))
+	router.Handle(method, "/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	req := httptest.NewRequest(method, "/", nil)
+	if origin != "" {
+		req.Header.Set("Origin", origin)
+	}
+	if requestMethod != "" {
+		req.Header.Set("Access-Control-Request-Method", requestMethod)
+	}
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	return w
+}
+
+func TestParseOriginPattern(t *testing.T) {
+	tests := []struct {
+		in   string
+		want originPattern
+		ok   bool
+	}{
+		{"*", originPattern{any: true}, true},
+		{"https://devmatch.app", originPattern{scheme: "https", host: "devmatch.app"}, true},
+		{" HTTPS://DevMatch.app/ ", originPattern{scheme: "https", host: "devmatch.app"}, true},
+		{"http://localhost:3000", originPattern{scheme: "http", host: "localhost:3000"}, true},
+		{"https://*.devmatch.app", originPattern{scheme: "https", host: "devmatch.app", suffix: true}, true},
+		{"https://*.", originPattern{}, false},
+		{"devmatch.app", originPattern{}, false},
+		{"https://", originPattern{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := parseOriginPattern(tt.in)
+		if ok != tt.ok || (ok && got != tt.want) {
+			t.Errorf("parseOriginPattern(%q) = %+v, %v; want %+v, %v", tt.in, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestCORSOrigins(t *testing.T) {
+	opts := CORSOptions{
+		AllowedOrigins:   []string{"https://devmatch.app", "https://*.preview.devmatch.app", "http://localhost:3000"},
+		AllowedMethods:   []string{"GET", "POST"},
+		ExposedHeaders:   []string{"X-Request-ID"},
+		AllowCredentials: true,
+	}
+
+	tests := []struct {
+		name    string
+		origin  string
+		allowed bool
+	}{
+		{"exact", "https://devmatch.app", true},
+		{"exact in another case", "https://DevMatch.app", true},
+		{"other scheme", "http://devmatch.app", false},
+		{"other port", "https://devmatch.app:8443", false},
+		{"subdomain of an exact origin", "https://www.devmatch.app", false},
+		{"wildcard subdomain", "https://pr-42.preview.devmatch.app", true},
+		{"nested wildcard subdomain", "https://a.b.preview.devmatch.app", true},
+		{"wildcard bare domain", "https://preview.devmatch.app", false},
+		{"wildcard over http", "http://pr-42.preview.devmatch.app", false},
+		{"suffix without a dot", "https://evilpreview.devmatch.app", false},
+		{"lookalike domain", "https://preview.devmatch.app.evil.com", false},
+		{"localhost with port", "http://localhost:3000", true},
+		{"localhost on another port", "http://localhost:3001", false},
+		{"null origin", "null", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveCORS(opts, http.MethodGet, tt.origin, "")
+			if w.Code != http.StatusOK {
+				t.Fatalf("status %d, want the handler's 200", w.Code)
+			}
+			h := w.Header()
+			if tt.allowed {
+				if h.Get("Access-Control-Allow-Origin") != tt.origin || h.Get("Access-Control-Allow-Credentials") != "true" || h.Get("Access-Control-Expose-Headers") != "X-Request-ID" {
+					t.Errorf("headers %v, want the origin echoed with credentials and exposed headers", h)
+				}
+			} else if h.Get("Access-Control-Allow-Origin") != "" || h.Get("Access-Control-Allow-Credentials") != "" {
+				t.Errorf("headers %v, want no Access-Control-* headers", h)
+			}
+			if h.Get("Vary") != "Origin" {
+				t.Errorf("Vary = %q, want Origin", h.Get("Vary"))
+			}
+		})
+	}
+}
+
+func TestCORSAnyOrigin(t *testing.T) {
+	tests := []struct {
+		name        string
+		credentials bool
+		want        string
+	}{
+		{"without credentials", false, "*"},
+		{"with credentials", true, "https://example.com"}, // "*" is not valid with credentials
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := CORSOptions{AllowedOrigins: []string{"*"}, AllowedMethods: []string{"GET"}, AllowCredentials: tt.credentials}
+			w := serveCORS(opts, http.MethodGet, "https://example.com", "")
+			if got := w.Header().Get("Access-Control-Allow-Origin"); got != tt.want {
+				t.Errorf("Access-Control-Allow-Origin = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCORSPreflight(t *testing.T) {
+	opts := CORSOptions{
+		AllowedOrigins: []string{"https://devmatch.app"},
+		AllowedMethods: []string{"GET", "POST", "DELETE"},
+		AllowedHeaders: []string{"Authorization", "Content-Type"},
+		MaxAge:         10 * time.Minute,
+	}
+
+	tests := []struct {
+		name          string
+		origin        string
+		requestMethod string
+		want          int
+	}{
+		{"allowed", "https://devmatch.app", "POST", http.StatusNoContent},
+		{"allowed in lowercase", "https://devmatch.app", "delete", http.StatusNoContent},
+		{"method not allowed", "https://devmatch.app", "PATCH", http.StatusForbidden},
+		{"origin not allowed", "https://evil.example", "POST", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := serveCORS(opts, http.MethodOptions, tt.origin, tt.requestMethod)
+			if w.Code != tt.want {
+				t.Fatalf("status %d, want %d", w.Code, tt.want)
+			}
+			h := w.Header()
+			if tt.want != http.StatusNoContent {
+				if h.Get("Access-Control-Allow-Origin") != "" || h.Get("Access-Control-Allow-Methods") != "" {
+					t.Errorf("refused preflight has headers %v", h)
+				}
+				return
+			}
+			want := map[string]string{
+				"Access-Control-Allow-Origin":  tt.origin,
+				"Access-Control-Allow-Methods": "GET, POST, DELETE",
+				"Access-Control-Allow-Headers": "Authorization, Content-Type",
+				"Access-Control-Max-Age":       "600",
+			}
+			for header, value := range want {
+				if got := h.Get(header); got != value {
+					t.Errorf("%s = %q, want %q", header, got, value)
+				}
+			}
+			if vary := h.Values("Vary"); len(vary) != 3 {
+				t.Errorf("Vary = %q, want Origin and the two request headers", vary)
+			}
+		})
+	}
+
+	// An OPTIONS request that is not a preflight reaches the route
+	router := gin.New()
+	router.Use(CORS(opts))
+	router.OPTIONS("/", func(c *gin.Context) { c.Status(http.StatusOK) })
+	req := httptest.NewRequest(http.MethodOptions, "/", nil)
+	req.Header.Set("Origin", "https://devmatch.app")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	if w.Code != http.StatusOK {
+		t.Errorf("plain OPTIONS: status %d, want the handler's 200", w.Code)
+	}
+}
+
+func TestCORSSameOrigin(t *testing.T) {
+	w := serveCORS(CORSOptions{AllowedOrigins: []string{"https://devmatch.app"}}, http.MethodGet, "", "")
+	if w.Code != http.StatusOK || w.Header().Get("Vary") != "" {
+		t.Errorf("request without Origin: status %d, headers %v; want 200 untouched", w.Code, w.Header())
+	}
+}
@@ -0,0 +1,186 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"gin/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxCachedUsers bounds the cache; past it, expired entries are swept on insert.
+const maxCachedUsers = 10000
+
+// CurrentUserKey is the key the authenticated user's profile is stored under in the Gin context.
+const CurrentUserKey ContextKey = "currentUser"
+
+// userCacheKey is the key LoadUser stores its cache under, so handlers can invalidate.
+const userCacheKey ContextKey = "userCache"
+
+// UserLoader loads profiles by Clerk user ID. database.UserRepository implements it.
+type UserLoader interface {
+	GetUserProfileByClerkID(ctx context.Context, clerkUserID string) (*models.User, error)
+}
+
+// UserCache caches profiles by Clerk user ID for a short time, so authenticated
+// requests do not each cost a profile query. Missing profiles are not cached, so a
+// profile created right after a miss is seen on the next request. It is safe for
+// concurrent use.
+type UserCache struct {
+	users UserLoader
+	ttl   time.Duration
+
+	mu      sync.Mutex
+	entries map[string]cachedUser
+}
+
+type cachedUser struct {
+	user      models.User
+	expiresAt time.Time
+}
+
+// NewUserCache creates a cache over users keeping profiles for ttl. A ttl of 0 disables caching.
+func NewUserCache(users UserLoader, ttl time.Duration) *UserCache {
+	return &UserCache{users: users, ttl: ttl, entries: map[string]cachedUser{}}
+}
+
+// Get returns the profile of clerkUserID, from the cache while it is fresh.
+// Returns sql.ErrNoRows if the user has no profile.
+func (uc *UserCache) Get(ctx context.Context, clerkUserID string) (*models.User, error) {
+	now := time.Now()
+	uc.mu.Lock()
+	entry, ok := uc.entries[clerkUserID]
+	uc.mu.Unlock()
+	if ok && now.Before(entry.expiresAt) {
+		user := entry.user // Copy, so callers cannot change the cached profile
+		return &user, nil
+	}
+
+	user, err := uc.users.GetUserProfileByClerkID(ctx, clerkUserID)
+	if err != nil {
+		return nil, err
+	}
+	if uc.ttl > 0 {
+		uc.mu.Lock()
+		if len(uc.entries) >= maxCachedUsers {
+			for id, e := range uc.entries {
+				if !now.Before(e.expiresAt) {
+					delete(uc.entries, id)
+				}
+			}
+		}
+		uc.entries[clerkUserID] = cachedUser{user: *user, expiresAt: now.Add(uc.ttl)}
+		uc.mu.Unlock()
+	}
+	return user, nil
+}
+
+// Invalidate drops the cached profile of clerkUserID.
+func (uc *UserCache) Invalidate(clerkUserID string) {
+	uc.mu.Lock()
+	delete(uc.entries, clerkUserID)
+	uc.mu.Unlock()
+}
+
+// LoadUser creates Gin middleware that resolves the authenticated Clerk user (set by
+// AuthMiddleware, which must run first) to their profile and stores it for
+// CurrentUser. Users without a profile pass through; routes that need one add
+// RequireProfile. Suspended users are rejected with 403 on every route.
+func LoadUser(cache *UserCache) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(string(userCacheKey), cache)
+
+		clerkUserID, exists := GetClerkUserID(c)
+		if !exists {
+			logger.ErrorContext(c.Request.Context(), "LoadUser ran without an authenticated user; is AuthMiddleware missing?")
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Could not identify authenticated user"})
+			return
+		}
+
+		user, err := cache.Get(c.Request.Context(), clerkUserID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			logger.ErrorContext(c.Request.Context(), "Error loading profile", "clerk_user_id", clerkUserID, "error", err)
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{"error": "Failed to retrieve user profile"})
+			return
+		}
+		if user != nil && user.SuspendedAt != nil {
+			body := gin.H{"error": "account suspended", "suspendedAt": user.SuspendedAt}
+			if user.SuspensionReason != nil {
+				body["reason"] = *user.SuspensionReason
+			}
+			c.AbortWithStatusJSON(http.StatusForbidden, body)
+			return
+		}
+		if user != nil {
+			c.Set(string(CurrentUserKey), user)
+		}
+		c.Next()
+	}
+}
+
+// RequireProfile creates Gin middleware rejecting, with 403, authenticated users who
+// have not created a profile yet. It must run after LoadUser.
+func RequireProfile() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := CurrentUser(c); !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{
+				"error": "profile required",
+				"hint":  "Create your profile with POST /users/profile (or GET /auth/user) first.",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// RequireRole creates Gin middleware rejecting, with 403, users whose role is below
+// minimum. It must run after LoadUser; users without a profile are rejected too.
+func RequireRole(minimum models.UserRole) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		user, ok := CurrentUser(c)
+		if !ok {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "profile required"})
+			return
+		}
+		if !user.Role.AtLeast(minimum) {
+			logger.InfoContext(c.Request.Context(), "Denied request for insufficient role", "method", c.Request.Method, "route", c.FullPath(), "user_id", user.ID, "role", user.Role, "required_role", minimum)
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient role", "requiredRole": minimum})
+			return
+		}
+		c.Next()
+	}
+}
+
+// CurrentUser returns the profile LoadUser stored for the authenticated user, and
+// false if they have none.
+func CurrentUser(c *gin.Context) (*models.User, bool) {
+	value, exists := c.Get(string(CurrentUserKey))
+	if !exists {
+		return nil, false
+	}
+	user, ok := value.(*models.User)
+	return user, ok && user != nil
+}
+
+// ForgetCurrentUser drops the authenticated user's cached profile. Handlers that change
+// the caller's own profile call it so the next request sees the change.
+func ForgetCurrentUser(c *gin.Context) {
+	if clerkUserID, exists := GetClerkUserID(c); exists {
+		ForgetUser(c, clerkUserID)
+	}
+}
+
+// ForgetUser drops the cached profile of clerkUserID. Handlers that change another
+// user's profile, such as suspending them, call it so the change applies at once.
+func ForgetUser(c *gin.Context, clerkUserID string) {
+	if value, ok := c.Get(string(userCacheKey)); ok {
+		if cache, ok := value.(*UserCache); ok {
+			cache.Invalidate(clerkUserID)
+		}
+	}
+}
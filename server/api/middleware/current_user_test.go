@@ -0,0 +1,178 @@
+package middleware
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// countingLoader serves profiles from a map, failing for the Clerk ID "broken", and
+// counts lookups.
+type countingLoader struct {
+	users   map[string]models.User
+	lookups int
+}
+
+func (l *countingLoader) GetUserProfileByClerkID(ctx context.Context, clerkUserID string) (*models.User, error) {
+	l.lookups++
+	if clerkUserID == "broken" {
+		return nil, errors.New("database is down")
+	}
+	user, ok := l.users[clerkUserID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	return &user, nil
+}
+
+func newCountingLoader() *countingLoader {
+	suspendedAt, reason := time.Now(), "spam"
+	return &countingLoader{users: map[string]models.User{
+		"user_ada":     {ID: "1", ClerkUserID: "user_ada", Role: models.RoleUser},
+		"user_mod":     {ID: "3", ClerkUserID: "user_mod", Role: models.RoleModerator},
+		"user_spammer": {ID: "4", ClerkUserID: "user_spammer", Role: models.RoleAdmin, SuspendedAt: &suspendedAt, SuspensionReason: &reason},
+	}}
+}
+
+func TestUserCache(t *testing.T) {
+	ctx := context.Background()
+	loader := newCountingLoader()
+	cache := NewUserCache(loader, time.Hour)
+
+	for i := 0; i < 3; i++ {
+		if user, err := cache.Get(ctx, "user_ada"); err != nil || user.ID != "1" {
+			t.Fatalf("Get = %+v, %v; want profile 1", user, err)
+		}
+	}
+	if loader.lookups != 1 {
+		t.Errorf("%d lookups for three Gets, want 1", loader.lookups)
+	}
+
+	// Changing the returned profile does not change the cached one
+	user, _ := cache.Get(ctx, "user_ada")
+	user.ID = "changed"
+	if again, _ := cache.Get(ctx, "user_ada"); again.ID != "1" {
+		t.Errorf("cached profile ID = %s after the caller changed its copy, want 1", again.ID)
+	}
+
+	cache.Invalidate("user_ada")
+	if _, err := cache.Get(ctx, "user_ada"); err != nil || loader.lookups != 2 {
+		t.Errorf("Get after Invalidate: %d lookups, err %v; want a second lookup", loader.lookups, err)
+	}
+
+	// Missing profiles are not cached, so one created after a miss is found
+	if _, err := cache.Get(ctx, "user_new"); !errors.Is(err, sql.ErrNoRows) {
+		t.Fatalf("Get(user_new) error = %v, want sql.ErrNoRows", err)
+	}
+	loader.users["user_new"] = models.User{ID: "2", ClerkUserID: "user_new"}
+	if user, err := cache.Get(ctx, "user_new"); err != nil || user.ID != "2" {
+		t.Errorf("Get(user_new) after creating it = %+v, %v; want profile 2", user, err)
+	}
+
+	uncached := NewUserCache(loader, 0)
+	before := loader.lookups
+	uncached.Get(ctx, "user_ada")
+	uncached.Get(ctx, "user_ada")
+	if loader.lookups-before != 2 {
+		t.Errorf("%d lookups with a ttl of 0, want 2", loader.lookups-before)
+	}
+}
+
+func TestLoadUserAndRequireProfile(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cache := NewUserCache(newCountingLoader(), time.Hour)
+
+	tests := []struct {
+		name        string
+		clerkUserID string // Empty for an unauthenticated request
+		wantStatus  int
+	}{
+		{"profile", "user_ada", http.StatusOK},
+		{"no profile", "user_new", http.StatusForbidden},
+		{"suspended", "user_spammer", http.StatusForbidden},
+		{"lookup fails", "broken", http.StatusInternalServerError},
+		{"not authenticated", "", http.StatusInternalServerError},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var seen *models.User
+			router := gin.New()
+			router.GET("/", func(c *gin.Context) {
+				if tt.clerkUserID != "" {
+					c.Set(string(UserIDKey), tt.clerkUserID)
+				}
+			}, LoadUser(cache), RequireProfile(), func(c *gin.Context) {
+				seen, _ = CurrentUser(c)
+				c.Status(http.StatusOK)
+			})
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantStatus == http.StatusOK && (seen == nil || seen.ClerkUserID != tt.clerkUserID) {
+				t.Errorf("CurrentUser = %+v, want the profile of %s", seen, tt.clerkUserID)
+			}
+		})
+	}
+}
+
+func TestForgetCurrentUser(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	loader := newCountingLoader()
+	cache := NewUserCache(loader, time.Hour)
+
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		c.Set(string(UserIDKey), "user_ada")
+	}, LoadUser(cache), func(c *gin.Context) {
+		ForgetCurrentUser(c)
+		c.Status(http.StatusOK)
+	})
+	for i := 0; i < 2; i++ {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/", nil))
+	}
+	if loader.lookups != 2 {
+		t.Errorf("%d lookups for two requests that forget the profile, want 2", loader.lookups)
+	}
+}
+
+func TestRequireRole(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cache := NewUserCache(newCountingLoader(), time.Hour)
+
+	tests := []struct {
+		clerkUserID string
+		minimum     models.UserRole
+		wantStatus  int
+	}{
+		{"user_ada", models.RoleUser, http.StatusOK},
+		{"user_ada", models.RoleModerator, http.StatusForbidden},
+		{"user_mod", models.RoleModerator, http.StatusOK},
+		{"user_mod", models.RoleAdmin, http.StatusForbidden},
+		{"user_spammer", models.RoleModerator, http.StatusForbidden}, // Suspension outranks the admin role
+		{"user_new", models.RoleUser, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		router := gin.New()
+		router.GET("/", func(c *gin.Context) {
+			c.Set(string(UserIDKey), tt.clerkUserID)
+		}, LoadUser(cache), RequireRole(tt.minimum), func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != tt.wantStatus {
+			t.Errorf("%s with RequireRole(%s): status %d, want %d", tt.clerkUserID, tt.minimum, w.Code, tt.wantStatus)
+		}
+	}
+}
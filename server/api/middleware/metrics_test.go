@@ -0,0 +1,75 @@
+package middleware
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin/internal/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+// scrape returns the metrics page in the Prometheus text format.
+func scrape(t *testing.T) string {
+	t.Helper()
+	w := httptest.NewRecorder()
+	metrics.Handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	body, _ := io.ReadAll(w.Body)
+	return string(body)
+}
+
+func TestMetrics(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(Metrics())
+	router.GET("/metrics-test/:id", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for _, path := range []string{"/metrics-test/1", "/metrics-test/2", "/metrics-test-missing"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, path, nil))
+	}
+
+	page := scrape(t)
+	for _, want := range []string{
+		`devmatch_http_requests_total{method="GET",route="/metrics-test/:id",status="204"} 2`,
+		`devmatch_http_requests_total{method="GET",route="unmatched",status="404"} 1`,
+		`devmatch_http_request_duration_seconds_count{method="GET",route="/metrics-test/:id"} 2`,
+		`devmatch_http_requests_in_flight 0`,
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("metrics page lacks %s", want)
+		}
+	}
+	if strings.Contains(page, `route="/metrics-test/1"`) {
+		t.Error("metrics are labelled with the requested path rather than the route")
+	}
+}
+
+func TestRequireBearerToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.GET("/metrics", RequireBearerToken("s3cret"), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	tests := []struct {
+		header string
+		want   int
+	}{
+		{"Bearer s3cret", http.StatusOK},
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"s3cret", http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		if w.Code != tt.want {
+			t.Errorf("Authorization %q: status = %d, want %d", tt.header, w.Code, tt.want)
+		}
+	}
+}
@@ -0,0 +1,125 @@
+package middleware
+
+import (
+	"math"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Response headers describing the caller's rate limit.
+const (
+	RateLimitLimitHeader     = "X-RateLimit-Limit"     // Requests allowed in a burst
+	RateLimitRemainingHeader = "X-RateLimit-Remaining" // Requests left right now
+	RateLimitResetHeader     = "X-RateLimit-Reset"     // Seconds until the full burst is available again
+)
+
+// RateLimiter is a token-bucket rate limiter with one bucket per caller. Each bucket
+// holds up to requests tokens and refills at requests per period, so a caller can burst
+// the whole allowance at once and then continue at the steady rate. It is safe for
+// concurrent use.
+type RateLimiter struct {
+	name     string  // Route group, for logs
+	capacity float64 // Tokens in a full bucket
+	rate     float64 // Tokens added per second
+	now      func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens  float64
+	updated time.Time
+}
+
+// NewRateLimiter creates a limiter for the route group name allowing requests per
+// period to each caller. requests <= 0 disables it.
+func NewRateLimiter(name string, requests int, period time.Duration) *RateLimiter {
+	rl := &RateLimiter{name: name, now: time.Now, buckets: map[string]*tokenBucket{}}
+	if requests > 0 && period > 0 {
+		rl.capacity = float64(requests)
+		rl.rate = float64(requests) / period.Seconds()
+	}
+	return rl
+}
+
+// take spends a token from key's bucket if it has one. It returns whether the request
+// is allowed, the tokens left, how long until the next token and how long until the
+// bucket is full again.
+func (rl *RateLimiter) take(key string) (allowed bool, remaining int, retryAfter, resetAfter time.Duration) {
+	now := rl.now()
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	// Buckets that have refilled completely carry no state; drop them once per refill period
+	fullAfter := time.Duration(rl.capacity / rl.rate * float64(time.Second))
+	if now.Sub(rl.lastSweep) > fullAfter {
+		for k, b := range rl.buckets {
+			if now.Sub(b.updated) >= fullAfter {
+				delete(rl.buckets, k)
+			}
+		}
+		rl.lastSweep = now
+	}
+
+	b, ok := rl.buckets[key]
+	if !ok {
+		b = &tokenBucket{tokens: rl.capacity, updated: now}
+		rl.buckets[key] = b
+	} else {
+		b.tokens = math.Min(rl.capacity, b.tokens+now.Sub(b.updated).Seconds()*rl.rate)
+		b.updated = now
+	}
+
+	if b.tokens >= 1 {
+		b.tokens--
+		allowed = true
+	} else {
+		retryAfter = time.Duration((1 - b.tokens) / rl.rate * float64(time.Second))
+	}
+	resetAfter = time.Duration((rl.capacity - b.tokens) / rl.rate * float64(time.Second))
+	return allowed, int(b.tokens), retryAfter, resetAfter
+}
+
+// RateLimit returns middleware enforcing limiter. Callers are identified by their Clerk
+// user ID when the route runs after AuthMiddleware, and by client IP otherwise (see
+// gin.Engine.SetTrustedProxies for how the IP is determined). Every response carries the
+// X-RateLimit-* headers; requests over the limit get a 429 with Retry-After.
+func RateLimit(limiter *RateLimiter) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if limiter.capacity == 0 {
+			c.Next()
+			return
+		}
+
+		key := "ip:" + c.ClientIP()
+		if userID, ok := c.Get(string(UserIDKey)); ok {
+			if id, ok := userID.(string); ok && id != "" {
+				key = "user:" + id
+			}
+		}
+
+		allowed, remaining, retryAfter, resetAfter := limiter.take(key)
+		c.Header(RateLimitLimitHeader, strconv.Itoa(int(limiter.capacity)))
+		c.Header(RateLimitRemainingHeader, strconv.Itoa(remaining))
+		c.Header(RateLimitResetHeader, strconv.Itoa(ceilSeconds(resetAfter)))
+		if !allowed {
+			seconds := ceilSeconds(retryAfter)
+			logger.InfoContext(c.Request.Context(), "Rate limit exceeded", "limiter", limiter.name, "key", key, "method", c.Request.Method, "route", c.FullPath())
+			c.Header("Retry-After", strconv.Itoa(seconds))
+			c.AbortWithStatusJSON(http.StatusTooManyRequests, gin.H{"error": "rate limit exceeded", "retryAfter": seconds})
+			return
+		}
+		c.Next()
+	}
+}
+
+// ceilSeconds rounds d up to whole seconds.
+func ceilSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// newTestRateLimiter creates a limiter whose clock is *now, and a router applying it to
+// GET /, identifying callers by the X-Test-User header when it is set.
+func newTestRateLimiter(requests int, period time.Duration, now *time.Time) (*RateLimiter, *gin.Engine) {
+	limiter := NewRateLimiter("test", requests, period)
+	limiter.now = func() time.Time { return *now }
+	router := gin.New()
+	router.GET("/", func(c *gin.Context) {
+		if user := c.GetHeader("X-Test-User"); user != "" {
+			c.Set(string(UserIDKey), user)
+		}
+	}, RateLimit(limiter), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	return limiter, router
+}
+
+func TestRateLimit(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	_, router := newTestRateLimiter(5, 10*time.Second, &now) // A token every 2s
+
+	// Each step advances the clock by after, then sends a request as user
+	tests := []struct {
+		name       string
+		after      time.Duration
+		user       string
+		wantStatus int
+		remaining  string
+		reset      string
+		retryAfter string // Only sent with 429s
+	}{
+		{"first request", 0, "alice", http.StatusOK, "4", "2", ""},
+		{"burst", 0, "alice", http.StatusOK, "3", "4", ""},
+		{"burst", 0, "alice", http.StatusOK, "2", "6", ""},
+		{"burst", 0, "alice", http.StatusOK, "1", "8", ""},
+		{"last token", 0, "alice", http.StatusOK, "0", "10", ""},
+		{"empty bucket", 0, "alice", http.StatusTooManyRequests, "0", "10", "2"},
+		{"other users have their own bucket", 0, "bob", http.StatusOK, "4", "2", ""},
+		{"half a token", time.Second, "alice", http.StatusTooManyRequests, "0", "9", "1"},
+		{"refilled token", time.Second, "alice", http.StatusOK, "0", "10", ""},
+		{"retry after rounds up", 300 * time.Millisecond, "alice", http.StatusTooManyRequests, "0", "10", "2"},
+		{"refilled completely", time.Minute, "alice", http.StatusOK, "4", "2", ""},
+		{"anonymous callers are limited by IP", 0, "", http.StatusOK, "4", "2", ""},
+	}
+	for i, tt := range tests {
+		now = now.Add(tt.after)
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.user != "" {
+			req.Header.Set("X-Test-User", tt.user)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		h := w.Header()
+		if w.Code != tt.wantStatus || h.Get(RateLimitLimitHeader) != "5" || h.Get(RateLimitRemainingHeader) != tt.remaining ||
+			h.Get(RateLimitResetHeader) != tt.reset || h.Get("Retry-After") != tt.retryAfter {
+			t.Errorf("request %d (%s): status %d, limit %q, remaining %q, reset %q, Retry-After %q; want %d, \"5\", %q, %q, %q",
+				i+1, tt.name, w.Code, h.Get(RateLimitLimitHeader), h.Get(RateLimitRemainingHeader), h.Get(RateLimitResetHeader), h.Get("Retry-After"),
+				tt.wantStatus, tt.remaining, tt.reset, tt.retryAfter)
+		}
+	}
+}
+
+func TestRateLimitDisabled(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	_, router := newTestRateLimiter(0, time.Minute, &now)
+	for i := 0; i < 10; i++ {
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+		if w.Code != http.StatusOK || w.Header().Get(RateLimitLimitHeader) != "" {
+			t.Fatalf("request %d: status %d with limit %q, want 200 without rate limit headers", i+1, w.Code, w.Header().Get(RateLimitLimitHeader))
+		}
+	}
+}
+
+func TestRateLimiterForgetsFullBuckets(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	limiter, _ := newTestRateLimiter(2, 10*time.Second, &now)
+	limiter.take("user:alice")
+	limiter.take("user:bob")
+
+	now = now.Add(11 * time.Second)
+	limiter.take("user:bob")
+	if _, kept := limiter.buckets["user:alice"]; kept {
+		t.Error("refilled bucket of alice was kept")
+	}
+	if len(limiter.buckets) != 1 {
+		t.Errorf("%d buckets, want only bob's", len(limiter.buckets))
+	}
+}
@@ -0,0 +1,60 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"gin/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+// RequestIDHeader carries the request ID in both directions: a caller (or a load
+// balancer) may set it, and every response echoes the ID used.
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDKey is the key the request ID is stored under in the Gin context.
+const RequestIDKey ContextKey = "requestID"
+
+// maxRequestIDLength bounds IDs taken from callers.
+const maxRequestIDLength = 128
+
+// RequestID returns middleware giving every request an ID: the caller's X-Request-ID
+// if it is well-formed, a random one otherwise. The ID is returned in X-Request-ID and
+// stored in the request context, so every line logged with that context includes it.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := c.GetHeader(RequestIDHeader)
+		if !validRequestID(id) {
+			id = newRequestID()
+		}
+		c.Set(string(RequestIDKey), id)
+		c.Header(RequestIDHeader, id)
+		c.Request = c.Request.WithContext(logging.WithRequestID(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+// validRequestID reports whether id is short and only has characters safe to log, so
+// callers cannot forge log lines or bloat them.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for _, r := range id {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		case r == '-', r == '_', r == '.', r == ':':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+// newRequestID returns 16 random bytes, hex-encoded.
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b) // Never fails, see crypto/rand.Read
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,52 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin/internal/logging"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(RequestID())
+	router.GET("/", func(c *gin.Context) {
+		c.String(http.StatusOK, logging.RequestID(c.Request.Context()))
+	})
+
+	tests := []struct {
+		name, header string
+		keep         bool
+	}{
+		{"caller's ID", "abc-123_x.y:z", true},
+		{"missing", "", false},
+		{"unsafe characters", "abc\ninjected", false},
+		{"too long", strings.Repeat("a", maxRequestIDLength+1), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				req.Header.Set(RequestIDHeader, tt.header)
+			}
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, req)
+
+			id := w.Header().Get(RequestIDHeader)
+			if w.Body.String() != id {
+				t.Errorf("context ID %q differs from the header %q", w.Body, id)
+			}
+			if tt.keep && id != tt.header {
+				t.Errorf("ID = %q, want the caller's %q", id, tt.header)
+			}
+			if !tt.keep && (len(id) != 32 || id == tt.header) {
+				t.Errorf("ID = %q, want a fresh random one", id)
+			}
+		})
+	}
+}
@@ -1,55 +1,120 @@
 package routes
 
 import (
-	"database/sql"
-	"log"
+	"fmt"
 	"net/http"
-
-	"gin/api/handlers"               // Corrected import path
-	"gin/api/middleware"             // Corrected import path
-	"gin/internal/services"          // Added services import
+	"strings"
+
+	"gin/api/handlers"   // Corrected import path
+	"gin/api/middleware" // Corrected import path
+	"gin/internal/config"
+	"gin/internal/logging"
+	"gin/internal/metrics"
+	"gin/internal/models"
+	"gin/internal/services" // Added services import
+	"gin/internal/services/accounts"
+	"gin/internal/services/auth"
 	"gin/internal/services/database" // Corrected import path
+	"gin/internal/services/export"
+	"gin/internal/services/githubsync"
+	"gin/internal/services/moderation"
+	"gin/internal/services/recommender"
 
-	"github.com/clerkinc/clerk-sdk-go/clerk"
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRouter(dbPool *sql.DB, clerkClient clerk.Client, githubService *services.GitHubService, geminiService *services.GeminiService, clerkService *services.ClerkService) *gin.Engine {
-	// Set Gin mode (debug, release, test)
-	gin.SetMode("debug")
+var logger = logging.For("routes")
+
+func SetupRouter(cfg *config.Config, dbPool *database.DB, authenticator auth.Authenticator, githubService *services.GitHubService, geminiService *services.GeminiService, clerkService *services.ClerkService, rec *recommender.Recommender, exporter *export.Exporter, purger *accounts.Purger, webhookVerifier *services.SvixVerifier, moderator *moderation.Moderator, githubSync *githubsync.Syncer, userCache *middleware.UserCache) *gin.Engine {
+	// Set Gin mode (debug, release, test) from GIN_MODE
+	gin.SetMode(cfg.GinMode)
+	// Gin's debug output (registered routes, warnings) goes through our logger at debug level
+	gin.DebugPrintFunc = func(format string, values ...any) {
+		logger.Debug(strings.TrimSpace(fmt.Sprintf(strings.TrimPrefix(format, "[WARNING] "), values...)))
+	}
+	gin.DebugPrintRouteFunc = func(httpMethod, absolutePath, handlerName string, nuHandlers int) {
+		logger.Debug("Route registered", "method", httpMethod, "route", absolutePath, "handler", handlerName, "handlers", nuHandlers)
+	}
+
+	router := gin.New()
+	// Request IDs first, so every later line about the request carries one; then request
+	// logging, metrics and panic recovery in place of Gin's text logger and recovery
+	router.Use(middleware.RequestID(), middleware.RequestLogger(), middleware.Metrics(), middleware.Recovery())
 
-	router := gin.Default() // Includes logger and recovery middleware
+	// Only believe X-Forwarded-For from our own load balancers, so c.ClientIP() (used for
+	// per-IP rate limits) is the real client and cannot be spoofed. Without any, the
+	// connection's remote address is used.
+	if err := router.SetTrustedProxies(cfg.TrustedProxies); err != nil {
+		logging.Fatal(logger, "Invalid TRUSTED_PROXIES", "error", err)
+	}
 
 	// --- Middleware ---
-	// CORS (Allow requests from your frontend) - Configure properly for production
-	router.Use(func(c *gin.Context) {
-		c.Writer.Header().Set("Access-Control-Allow-Origin", "*") // Allow any origin (adjust for prod)
-		c.Writer.Header().Set("Access-Control-Allow-Credentials", "true")
-		c.Writer.Header().Set("Access-Control-Allow-Headers", "Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, accept, origin, Cache-Control, X-Requested-With")
-		c.Writer.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS, GET, PUT, DELETE")
-
-		if c.Request.Method == "OPTIONS" {
-			c.AbortWithStatus(204)
-			return
-		}
-		c.Next()
-	})
+	// CORS (Allow requests from the frontend origins in CORS_ALLOWED_ORIGINS); answers preflight requests itself
+	corsHeaders := cfg.CORSAllowedHeaders
+	if cfg.AuthProvider == config.AuthProviderDev {
+		corsHeaders = append(corsHeaders[:len(corsHeaders):len(corsHeaders)], auth.DevUserHeader) // So a local frontend can pick the dev user
+	}
+	router.Use(middleware.CORS(middleware.CORSOptions{
+		AllowedOrigins:   cfg.CORSAllowedOrigins,
+		AllowedMethods:   cfg.CORSAllowedMethods,
+		AllowedHeaders:   corsHeaders,
+		ExposedHeaders:   cfg.CORSExposedHeaders,
+		MaxAge:           cfg.CORSMaxAge,
+		AllowCredentials: cfg.CORSAllowCredentials,
+	}))
+
+	// Reject oversized request bodies before any handler reads them
+	router.Use(middleware.MaxBodySize(cfg.MaxRequestBodyBytes))
+
+	// Rate limiting per client IP across every route; route groups below add per-user limits
+	router.Use(middleware.RateLimit(middleware.NewRateLimiter("global", cfg.RateLimitGlobal.Requests, cfg.RateLimitGlobal.Period)))
 
 	// Create services and handlers
 	dbService := database.NewDBService(dbPool)
-	authHandler := handlers.NewAuthHandler(dbService)
-	userHandler := handlers.NewUserHandler(dbService)
-	chatHandler := handlers.NewChatHandler(dbService)
-	dashboardHandler := handlers.NewDashboardHandler(dbService)
+	var clerkUsers handlers.ClerkUserFetcher // Left nil without Clerk, so new profiles start bare
+	if clerkService != nil {
+		clerkUsers = clerkService
+	}
+	authHandler := handlers.NewAuthHandler(dbService, dbService, clerkUsers, githubSync, cfg.AccountDeletionGracePeriod)
+	userHandler := handlers.NewUserHandler(dbService, moderator, githubSync)
+	chatHandler := handlers.NewChatHandler(dbService, dbService, dbService, moderator)
+	dashboardHandler := handlers.NewDashboardHandler(dbService, dbService, dbService, rec, cfg.SwipeUndoWindow, cfg.SuperLikeDailyLimit)
 	githubHandler := handlers.NewGitHubHandler(githubService, geminiService)
-
-	// Clerk Authentication Middleware Instance
-	authMiddleware := middleware.ClerkMiddleware(clerkClient)
+	exportHandler := handlers.NewExportHandler(dbService, exporter)
+	webhookHandler := handlers.NewWebhookHandler(webhookVerifier, dbService, dbService, purger, githubSync, userCache)
+	adminHandler := handlers.NewAdminHandler(dbService, dbService, dbService)
+	safetyHandler := handlers.NewSafetyHandler(dbService, dbService, dbService)
+
+	// Authentication Middleware Instance (Clerk, local tokens or dev mode, per AUTH_PROVIDER)
+	authMiddleware := middleware.AuthMiddleware(authenticator)
+	// Resolves the authenticated user to their profile (cached briefly) for every route after authMiddleware
+	loadUser := middleware.LoadUser(userCache)
+	// For routes acting as the caller's profile: users who have not created one get a 403
+	requireProfile := middleware.RequireProfile()
+	// Per-user (per-IP before authentication) limits of the busiest and most expensive route groups
+	dashboardLimit := middleware.RateLimit(middleware.NewRateLimiter("dashboard", cfg.RateLimitDashboard.Requests, cfg.RateLimitDashboard.Period))
+	chatLimit := middleware.RateLimit(middleware.NewRateLimiter("chat", cfg.RateLimitChat.Requests, cfg.RateLimitChat.Period))
+	githubLimit := middleware.RateLimit(middleware.NewRateLimiter("github", cfg.RateLimitGitHub.Requests, cfg.RateLimitGitHub.Period))
 
 	// --- Routes ---
 	// Public Routes (e.g., health check, maybe docs)
 	router.GET("/health", func(c *gin.Context) { c.JSON(200, gin.H{"status": "UP"}) })
 
+	// Prometheus metrics, behind METRICS_TOKEN if one is set
+	if cfg.MetricsEnabled {
+		metricsHandlers := []gin.HandlerFunc{gin.WrapH(metrics.Handler())}
+		if cfg.MetricsToken != "" {
+			metricsHandlers = append([]gin.HandlerFunc{middleware.RequireBearerToken(cfg.MetricsToken)}, metricsHandlers...)
+		}
+		router.GET("/metrics", metricsHandlers...)
+	}
+
+	// Data export downloads - the unguessable, expiring token in the link is the credential
+	router.GET("/exports/:token", exportHandler.DownloadDataExport)
+
+	// Webhooks - authenticated by their signature rather than a session
+	router.POST("/webhooks/clerk", webhookHandler.HandleClerkWebhook)
+
 	// Optional: Swagger Docs endpoint (if using Swaggo)
 	// router.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler))
 
@@ -68,7 +133,11 @@ func SetupRouter(dbPool *sql.DB, clerkClient clerk.Client, githubService *servic
 		})
 
 		// Requires Authentication via Clerk session
-		authGroup.GET("/user", authMiddleware, authHandler.GetCurrentUserProfile)
+		authGroup.GET("/user", authMiddleware, loadUser, authHandler.GetCurrentUserProfile)                                  // Creates the profile on first sign-in
+		authGroup.DELETE("/user", authMiddleware, loadUser, requireProfile, authHandler.DeleteCurrentUser)                   // Schedule account deletion after a grace period
+		authGroup.POST("/user/deletion/cancel", authMiddleware, loadUser, requireProfile, authHandler.CancelAccountDeletion) // Keep the account during the grace period
+		authGroup.GET("/user/export", authMiddleware, loadUser, requireProfile, exportHandler.ExportUserData)                // Personal data archive, or a background job for large histories
+		authGroup.GET("/user/exports/:id", authMiddleware, loadUser, requireProfile, exportHandler.GetDataExport)            // Status and download link of a background export
 		authGroup.POST("/logout", authMiddleware, func(c *gin.Context) {
 			// Backend can't easily invalidate Clerk session cookie (HttpOnly).
 			// Needs coordination with frontend Clerk SDK (signOut()).
@@ -76,16 +145,20 @@ func SetupRouter(dbPool *sql.DB, clerkClient clerk.Client, githubService *servic
 		})
 
 		// Dashboard Routes (Swiping, Favorites)
-		dashboardGroup := authGroup.Group("/dashboard")
+		dashboardGroup := authGroup.Group("/dashboard", authMiddleware, dashboardLimit, loadUser, requireProfile)
 		{
-			dashboardGroup.GET("/cards", dashboardHandler.GetSwipeCards)      // Get potential matches
-			dashboardGroup.POST("/swipe", dashboardHandler.LogSwipe)          // Log a swipe action
-			dashboardGroup.POST("/favorite", dashboardHandler.ToggleFavorite) // Add/remove favorite
-			dashboardGroup.GET("/favorites", dashboardHandler.GetFavorites)   // Get favorite users
+			dashboardGroup.GET("/cards", dashboardHandler.GetSwipeCards)                          // Get potential matches, ranked by the recommender
+			dashboardGroup.POST("/swipe", dashboardHandler.LogSwipe)                              // Log a swipe action
+			dashboardGroup.POST("/swipe/undo", dashboardHandler.UndoSwipe)                        // Revert the most recent swipe
+			dashboardGroup.GET("/swipes", dashboardHandler.GetSwipeHistory)                       // List own swipes, filterable by direction
+			dashboardGroup.GET("/notifications", dashboardHandler.GetNotifications)               // List own notifications (e.g. super likes received)
+			dashboardGroup.POST("/notifications/:id/read", dashboardHandler.MarkNotificationRead) // Mark a notification as read
+			dashboardGroup.POST("/favorite", dashboardHandler.ToggleFavorite)                     // Add/remove favorite
+			dashboardGroup.GET("/favorites", dashboardHandler.GetFavorites)                       // Get favorite users
 		}
 
 		// Chat Routes
-		chatGroup := authGroup.Group("/chat")
+		chatGroup := authGroup.Group("/chat", authMiddleware, chatLimit, loadUser, requireProfile)
 		{
 			chatGroup.GET("/conversations/:userId", chatHandler.GetConversations) // Get user's conversations (param might be redundant)
 			chatGroup.GET("/messages/:conversationId", chatHandler.GetMessages)   // Get messages for a conversation
@@ -93,7 +166,7 @@ func SetupRouter(dbPool *sql.DB, clerkClient clerk.Client, githubService *servic
 		}
 
 		// GitHub/Developer Tool Routes
-		githubGroup := authGroup.Group("/github")
+		githubGroup := authGroup.Group("/github", githubLimit) // Unauthenticated, so limited per IP
 		{
 			githubGroup.GET("/:username/data", githubHandler.GetGitHubData) // Fetch raw GitHub data
 			githubGroup.POST("/summary", githubHandler.SummarizeGitHubData) // Summarize provided data (e.g., from GitHub)
@@ -106,13 +179,37 @@ func SetupRouter(dbPool *sql.DB, clerkClient clerk.Client, githubService *servic
 		// Get public profile - potentially doesn't require auth depending on your rules
 		userGroup.GET("/:id", userHandler.GetUserProfileByID) // :id is DB ID
 
+		// Full-text developer search - Requires Authentication so block lists can be applied
+		userGroup.GET("/search", authMiddleware, loadUser, requireProfile, userHandler.SearchUsers)
+
+		// Blocking and reporting other users - Requires Authentication
+		userGroup.GET("/blocks", authMiddleware, loadUser, requireProfile, safetyHandler.GetBlockedUsers)   // Users the caller blocked
+		userGroup.POST("/:id/block", authMiddleware, loadUser, requireProfile, safetyHandler.BlockUser)     // Hide each other everywhere
+		userGroup.DELETE("/:id/block", authMiddleware, loadUser, requireProfile, safetyHandler.UnblockUser) // Lift a block
+		userGroup.POST("/:id/report", authMiddleware, loadUser, requireProfile, safetyHandler.ReportUser)   // File a report for moderators
+
 		// Create or Update OWN profile - Requires Authentication
-		userGroup.POST("/profile", authMiddleware, userHandler.CreateOrUpdateCurrentUserProfile)
+		userGroup.POST("/profile", authMiddleware, loadUser, userHandler.CreateOrUpdateCurrentUserProfile)
 
 		// TODO: userGroup.PUT("/:id", authMiddleware, userHandler.EditUserProfile) // Requires auth + check if user edits own profile
 		// TODO: userGroup.GET("/random", authMiddleware, userHandler.GetRandomUsers) // Requires auth?
 	}
 
-	log.Println("Router setup complete.")
+	// Admin Routes - Moderators and admins only; managing roles is for admins
+	adminGroup := router.Group("/admin", authMiddleware, loadUser, requireProfile, middleware.RequireRole(models.RoleModerator))
+	{
+		adminGroup.GET("/users", adminHandler.ListUsers)                                                      // List and search all users
+		adminGroup.GET("/users/:id", adminHandler.GetUser)                                                    // Any user, including suspended ones
+		adminGroup.POST("/users/:id/suspend", adminHandler.SuspendUser)                                       // Lock a user out
+		adminGroup.POST("/users/:id/unsuspend", adminHandler.UnsuspendUser)                                   // Lift a suspension
+		adminGroup.PUT("/users/:id/role", middleware.RequireRole(models.RoleAdmin), adminHandler.SetUserRole) // Promote or demote a user
+		adminGroup.GET("/reports", adminHandler.GetReports)                                                   // Moderation queue
+		adminGroup.GET("/reports/:id", adminHandler.GetReport)                                                // Report with the reported messages
+		adminGroup.POST("/reports/:id/resolve", adminHandler.ResolveReport)                                   // Close a report, optionally suspending or removing messages
+		adminGroup.GET("/moderation", adminHandler.GetModerationEvents)                                       // Flagged and rejected messages and bios
+		adminGroup.GET("/stats", adminHandler.GetStats)                                                       // System overview
+	}
+
+	logger.Info("Router setup complete.")
 	return router
 }
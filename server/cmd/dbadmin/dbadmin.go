@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gin/internal/models"
+	"gin/internal/services/database"
+)
+
+// dbadmin backs up, verifies and restores the SQLite database.
+//
+//	go run -tags sqlite_fts5 ./cmd/dbadmin [-db devmatch.db] [-dir backups] backup
+//	go run -tags sqlite_fts5 ./cmd/dbadmin [-db devmatch.db] verify [snapshot]
+//	go run -tags sqlite_fts5 ./cmd/dbadmin [-dir backups] list
+//	go run -tags sqlite_fts5 ./cmd/dbadmin [-dir backups] prune [-keep 7]
+//	go run -tags sqlite_fts5 ./cmd/dbadmin [-db devmatch.db] [-dir backups] restore <snapshot>
+//	go run -tags sqlite_fts5 ./cmd/dbadmin [-db devmatch.db] set-role <user or Clerk ID> <user|moderator|admin>
+//
+// backup is safe while the server runs. restore is not: stop the server first. Before
+// restoring, the current database is itself backed up to -dir. set-role bootstraps the
+// first admin; after that, admins manage roles through the /admin API.
+func main() {
+	dbPath := flag.String("db", "devmatch.db", "SQLite database path")
+	backupDir := flag.String("dir", "backups", "Directory holding the snapshots")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: dbadmin [-db path] [-dir backups] backup | verify [snapshot] | list | prune [-keep n] | restore <snapshot> | set-role <id> <role>\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+
+	switch command := flag.Arg(0); command {
+	case "backup":
+		db := openDB(*dbPath)
+		defer db.Close()
+		backup, err := db.CreateBackup(ctx, *backupDir)
+		if err != nil {
+			log.Fatalf("❌ Backup failed: %v", err)
+		}
+		log.Printf("✅ Backed up %s to %s (%d bytes, integrity ok).", *dbPath, backup.Path, backup.Size)
+
+	case "verify":
+		path := *dbPath
+		if flag.NArg() > 1 {
+			path = flag.Arg(1)
+		}
+		if err := database.CheckIntegrity(ctx, path); err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		log.Printf("✅ %s passed the integrity check.", path)
+
+	case "list":
+		backups, err := database.ListBackups(*backupDir)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Printf("%-20s %12s  %s\n", "created (UTC)", "bytes", "path")
+		for _, backup := range backups {
+			fmt.Printf("%-20s %12d  %s\n", backup.CreatedAt.Format("2006-01-02 15:04:05"), backup.Size, backup.Path)
+		}
+
+	case "prune":
+		pruneFlags := flag.NewFlagSet("prune", flag.ExitOnError)
+		keep := pruneFlags.Int("keep", 7, "Number of newest snapshots to keep")
+		pruneFlags.Parse(flag.Args()[1:])
+		pruned, err := database.PruneBackups(*backupDir, *keep)
+		if err != nil {
+			log.Fatalf("❌ Prune failed after deleting %d snapshot(s): %v", len(pruned), err)
+		}
+		for _, backup := range pruned {
+			log.Printf("Deleted %s", backup.Path)
+		}
+		log.Printf("✅ Pruned %d snapshot(s), kept up to %d.", len(pruned), *keep)
+
+	case "restore":
+		if flag.NArg() < 2 {
+			log.Fatal("❌ restore needs the snapshot to restore from")
+		}
+		snapshot := flag.Arg(1)
+		// Check the snapshot before touching anything, so a bad one costs nothing
+		if err := database.CheckIntegrity(ctx, snapshot); err != nil {
+			log.Fatalf("❌ Refusing to restore: %v", err)
+		}
+
+		db := openDB(*dbPath)
+		defer db.Close()
+		safety, err := db.CreateBackup(ctx, *backupDir)
+		if err != nil {
+			log.Fatalf("❌ Failed to back up the current database before restoring: %v", err)
+		}
+		log.Printf("Current database saved to %s.", safety.Path)
+
+		if err := db.RestoreFrom(ctx, snapshot); err != nil {
+			log.Fatalf("❌ Restore failed: %v (the previous database is in %s)", err, safety.Path)
+		}
+		log.Printf("✅ Restored %s from %s.", *dbPath, snapshot)
+
+	case "set-role":
+		if flag.NArg() < 3 {
+			log.Fatal("❌ set-role needs a user ID (or Clerk user ID) and a role")
+		}
+		role := models.UserRole(flag.Arg(2))
+		if !role.Valid() {
+			log.Fatalf("❌ Unknown role %q; use user, moderator or admin", role)
+		}
+		db := openDB(*dbPath)
+		defer db.Close()
+		dbService := database.NewDBService(db)
+		user, err := dbService.GetUserProfileByID(ctx, flag.Arg(1))
+		if errors.Is(err, sql.ErrNoRows) {
+			user, err = dbService.GetUserProfileByClerkID(ctx, flag.Arg(1))
+		}
+		if err != nil {
+			log.Fatalf("❌ User %s not found: %v", flag.Arg(1), err)
+		}
+		if err := dbService.SetUserRole(ctx, user.ID, role); err != nil {
+			log.Fatalf("❌ Failed to set role: %v", err)
+		}
+		log.Printf("✅ User %s (%s) is now %s (was %s).", user.ID, user.ClerkUserID, role, user.Role)
+
+	default:
+		log.Printf("❌ Unknown command %q", command)
+		flag.Usage()
+		os.Exit(2)
+	}
+}
+
+// openDB opens the database at path without migrating it: a snapshot or an old database
+// is handled exactly as it is on disk.
+func openDB(path string) *database.DB {
+	if _, err := os.Stat(path); err != nil {
+		log.Fatalf("❌ Database %s not found: %v", path, err)
+	}
+	db, err := database.OpenDB(path)
+	if err != nil {
+		log.Fatalf("❌ Failed to open database: %v", err)
+	}
+	return db
+}
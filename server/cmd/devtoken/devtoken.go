@@ -0,0 +1,92 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"time"
+
+	"gin/internal/services/auth"
+
+	"github.com/clerkinc/clerk-sdk-go/clerk"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+// devtoken mints session tokens for running the API without Clerk.
+//
+//	go run ./cmd/devtoken -sub user_123 [-azp http://localhost:3000] [-ttl 1h]
+//	LOCAL_AUTH_SECRET=... go run ./cmd/devtoken -local -sub seed_user_torvalds
+//
+// By default it mints Clerk-style RS256 tokens: the first run generates a signing key in
+// -key, and every run writes its public JWKS to -jwks; start the server with
+// CLERK_JWKS_FILE pointing at that file. With -local it mints HS256 tokens for
+// AUTH_PROVIDER=local, signed with LOCAL_AUTH_SECRET. Either way, send the printed token
+// as "Authorization: Bearer <token>".
+func main() {
+	keyPath := flag.String("key", "dev-signing-key.json", "Private signing key, created if missing")
+	jwksPath := flag.String("jwks", "dev-jwks.json", "Where to write the public JWKS for CLERK_JWKS_FILE")
+	subject := flag.String("sub", "", "Clerk user ID the token is for (required)")
+	party := flag.String("azp", "", "Authorized party (origin) claim")
+	issuer := flag.String("iss", "", "Issuer claim; must match CLERK_ISSUER if that is set")
+	ttl := flag.Duration("ttl", time.Hour, "How long the token is valid")
+	local := flag.Bool("local", false, "Mint a token for AUTH_PROVIDER=local, signed with LOCAL_AUTH_SECRET")
+	flag.Parse()
+	if *subject == "" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *local {
+		secret := os.Getenv("LOCAL_AUTH_SECRET")
+		if len(secret) < auth.MinLocalSecretLength {
+			log.Fatalf("❌ LOCAL_AUTH_SECRET must be set to at least %d bytes", auth.MinLocalSecretLength)
+		}
+		token, err := auth.SignLocalToken(secret, *subject, *ttl)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		fmt.Println(token)
+		return
+	}
+
+	keys, err := auth.LoadTestKeySet(*keyPath)
+	if errors.Is(err, os.ErrNotExist) {
+		if keys, err = auth.NewTestKeySet(); err == nil {
+			err = keys.Save(*keyPath)
+		}
+		if err == nil {
+			log.Printf("Generated a new signing key in %s.", *keyPath)
+		}
+	}
+	if err != nil {
+		log.Fatalf("❌ Loading signing key failed: %v", err)
+	}
+
+	jwks, err := json.MarshalIndent(keys.JWKS(), "", "  ")
+	if err == nil {
+		err = os.WriteFile(*jwksPath, jwks, 0o644)
+	}
+	if err != nil {
+		log.Fatalf("❌ Writing JWKS failed: %v", err)
+	}
+
+	now := time.Now()
+	token, err := keys.Sign(clerk.SessionClaims{
+		Claims: jwt.Claims{
+			Subject:   *subject,
+			Issuer:    *issuer,
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+			Expiry:    jwt.NewNumericDate(now.Add(*ttl)),
+		},
+		SessionID:       "sess_dev",
+		AuthorizedParty: *party,
+	})
+	if err != nil {
+		log.Fatalf("❌ %v", err)
+	}
+	fmt.Println(token)
+}
@@ -0,0 +1,230 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"gin/internal/models"
+	"gin/internal/services/database"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// loadtest hammers the database with concurrent swipes, messages and deck reads, the
+// mix that used to produce "database is locked" errors, and reports lock errors and
+// latency percentiles per operation.
+//
+//	go run -tags sqlite_fts5 ./cmd/loadtest [-db loadtest.db] [-users 200] [-workers 32] [-duration 10s]
+//
+// It creates loadtest_* users in the target database, so point it at a scratch file
+// (or a copy of devmatch.db), never at the real one.
+func main() {
+	dbPath := flag.String("db", "loadtest.db", "SQLite database path or postgres:// URL (scratch database!)")
+	numUsers := flag.Int("users", 200, "Number of synthetic users to create")
+	workers := flag.Int("workers", 32, "Number of concurrent workers")
+	duration := flag.Duration("duration", 10*time.Second, "How long to run")
+	flag.Parse()
+	if *numUsers < 2 || *workers < 1 {
+		log.Fatal("❌ -users must be at least 2 and -workers at least 1")
+	}
+
+	ctx := context.Background()
+	db, err := database.ConnectDB(*dbPath)
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	dbService := database.NewDBService(db)
+
+	runID := time.Now().Format("20060102150405")
+	userIDs := make([]string, 0, *numUsers)
+	for i := 0; i < *numUsers; i++ {
+		username := fmt.Sprintf("loadtest_%s_%d", runID, i)
+		user, err := dbService.CreateOrUpdateUserProfile(ctx, models.User{
+			ClerkUserID: "loadtest_user_" + username,
+			Username:    &username,
+			Interests:   []string{"go", "sqlite"},
+		})
+		if err != nil {
+			log.Fatalf("❌ Failed to create user %d: %v", i, err)
+		}
+		userIDs = append(userIDs, user.ID)
+	}
+	log.Printf("Created %d users; running %d workers for %s...", len(userIDs), *workers, *duration)
+
+	stats := newLoadStats()
+	var (
+		conversationsMu sync.Mutex
+		conversations   []conversationRef // Matches made so far, to send messages in
+	)
+
+	deadline := time.Now().Add(*duration)
+	var wg sync.WaitGroup
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func(seed int64) {
+			defer wg.Done()
+			r := rand.New(rand.NewSource(seed))
+			for time.Now().Before(deadline) {
+				switch op := r.Intn(10); {
+				case op < 5: // Swipe, mostly likes so matches happen
+					swiper, swiped := pickPair(r, userIDs)
+					direction := models.SwipeLike
+					if r.Intn(4) == 0 {
+						direction = models.SwipeDislike
+					}
+					start := time.Now()
+					result, err := dbService.CreateSwipe(ctx, models.Swipe{SwiperID: swiper, SwipedID: swiped, Direction: direction}, 0)
+					if errors.Is(err, database.ErrAlreadySwiped) {
+						err = nil // Expected once the pair space fills up; not a failure
+					}
+					stats.record("swipe", time.Since(start), err)
+					if err == nil && result != nil && result.Matched {
+						conversationsMu.Lock()
+						conversations = append(conversations, conversationRef{id: *result.ConversationID, userIDs: [2]string{swiper, swiped}})
+						conversationsMu.Unlock()
+					}
+
+				case op < 8: // Message in a random existing match
+					conversationsMu.Lock()
+					if len(conversations) == 0 {
+						conversationsMu.Unlock()
+						continue
+					}
+					conv := conversations[r.Intn(len(conversations))]
+					conversationsMu.Unlock()
+					start := time.Now()
+					_, err := dbService.CreateMessage(ctx, conv.id, conv.userIDs[r.Intn(2)], "load test message")
+					stats.record("message", time.Since(start), err)
+
+				default: // Read the swipe deck
+					start := time.Now()
+					_, err := dbService.GetSwipeCandidates(ctx, userIDs[r.Intn(len(userIDs))], models.CardFilters{}, 20)
+					stats.record("deck", time.Since(start), err)
+				}
+			}
+		}(time.Now().UnixNano() + int64(w))
+	}
+	wg.Wait()
+
+	stats.report(*duration)
+	if stats.totalLockErrors() > 0 {
+		log.Fatalf("❌ %d lock errors", stats.totalLockErrors())
+	}
+	log.Println("✅ No lock errors.")
+}
+
+type conversationRef struct {
+	id      string
+	userIDs [2]string
+}
+
+// pickPair returns two distinct random users.
+func pickPair(r *rand.Rand, userIDs []string) (string, string) {
+	a := r.Intn(len(userIDs))
+	b := r.Intn(len(userIDs) - 1)
+	if b >= a {
+		b++
+	}
+	return userIDs[a], userIDs[b]
+}
+
+// isLockError reports whether err is SQLite refusing the operation because of another
+// connection's lock.
+func isLockError(err error) bool {
+	var sqliteErr sqlite3.Error
+	return errors.As(err, &sqliteErr) && (sqliteErr.Code == sqlite3.ErrBusy || sqliteErr.Code == sqlite3.ErrLocked)
+}
+
+// opStats collects the outcome of one kind of operation.
+type opStats struct {
+	latencies  []time.Duration
+	errors     int
+	lockErrors int
+	lastError  error
+}
+
+type loadStats struct {
+	mu  sync.Mutex
+	ops map[string]*opStats
+}
+
+func newLoadStats() *loadStats {
+	return &loadStats{ops: make(map[string]*opStats)}
+}
+
+func (s *loadStats) record(op string, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	st, ok := s.ops[op]
+	if !ok {
+		st = &opStats{}
+		s.ops[op] = st
+	}
+	st.latencies = append(st.latencies, latency)
+	if err != nil {
+		st.errors++
+		st.lastError = err
+		if isLockError(err) {
+			st.lockErrors++
+		}
+	}
+}
+
+func (s *loadStats) totalLockErrors() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	total := 0
+	for _, st := range s.ops {
+		total += st.lockErrors
+	}
+	return total
+}
+
+func (s *loadStats) report(duration time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	names := make([]string, 0, len(s.ops))
+	for name := range s.ops {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Printf("%-8s %8s %8s %7s %6s %9s %9s %9s %9s\n", "op", "count", "ops/s", "errors", "locked", "p50", "p90", "p99", "max")
+	for _, name := range names {
+		st := s.ops[name]
+		sort.Slice(st.latencies, func(i, j int) bool { return st.latencies[i] < st.latencies[j] })
+		fmt.Printf("%-8s %8d %8.0f %7d %6d %9s %9s %9s %9s\n",
+			name, len(st.latencies), float64(len(st.latencies))/duration.Seconds(), st.errors, st.lockErrors,
+			percentile(st.latencies, 0.50), percentile(st.latencies, 0.90), percentile(st.latencies, 0.99),
+			percentile(st.latencies, 1))
+	}
+	for _, name := range names {
+		if err := s.ops[name].lastError; err != nil {
+			fmt.Printf("last %s error: %v\n", name, err)
+		}
+	}
+}
+
+// percentile returns the p-th percentile of sorted, rounded for display.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	i := int(p*float64(len(sorted))+0.5) - 1
+	if i < 0 {
+		i = 0
+	}
+	if i >= len(sorted) {
+		i = len(sorted) - 1
+	}
+	return sorted[i].Round(10 * time.Microsecond)
+}
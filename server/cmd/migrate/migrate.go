@@ -0,0 +1,92 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"gin/internal/services/database"
+)
+
+// migrate manages the database schema.
+//
+//	go run -tags sqlite_fts5 ./cmd/migrate [-db devmatch.db] up
+//	go run -tags sqlite_fts5 ./cmd/migrate [-db devmatch.db] down [-steps 1]
+//	go run -tags sqlite_fts5 ./cmd/migrate [-db devmatch.db] status
+func main() {
+	dbPath := flag.String("db", "devmatch.db", "SQLite database path or postgres:// URL")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: migrate [-db path-or-url] up | down [-steps n] | status\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	ctx := context.Background()
+
+	db, err := database.OpenDB(*dbPath)
+	if err != nil {
+		log.Fatalf("❌ Failed to open database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		log.Fatalf("❌ Failed to load migrations: %v", err)
+	}
+
+	switch command := flag.Arg(0); command {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			log.Fatalf("❌ Migration failed after applying %d: %v", applied, err)
+		}
+		log.Printf("✅ Applied %d migration(s).", applied)
+
+	case "down":
+		downFlags := flag.NewFlagSet("down", flag.ExitOnError)
+		steps := downFlags.Int("steps", 1, "Number of migrations to revert")
+		downFlags.Parse(flag.Args()[1:])
+		if *steps < 1 {
+			log.Fatal("❌ -steps must be at least 1")
+		}
+		reverted, err := migrator.Down(ctx, *steps)
+		if err != nil {
+			log.Fatalf("❌ Revert failed after reverting %d: %v", reverted, err)
+		}
+		log.Printf("✅ Reverted %d migration(s).", reverted)
+
+	case "status":
+		states, err := migrator.Status(ctx)
+		if errors.Is(err, database.ErrNotInitialised) {
+			fmt.Println("Database not initialised: no migrations have been applied. Run `migrate up` to create the schema.")
+			return
+		}
+		if err != nil {
+			log.Fatalf("❌ Failed to read migration status: %v", err)
+		}
+		fmt.Printf("%-8s %-28s %-10s %s\n", "version", "name", "status", "applied at")
+		for _, state := range states {
+			status, appliedAt := "pending", "-"
+			if state.Applied {
+				status = "applied"
+				appliedAt = state.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			if state.ChecksumMismatch {
+				status = "modified"
+			}
+			fmt.Printf("%04d     %-28s %-10s %s\n", state.Version, state.Name, status, appliedAt)
+		}
+
+	default:
+		log.Printf("❌ Unknown command %q", command)
+		flag.Usage()
+		os.Exit(2)
+	}
+}
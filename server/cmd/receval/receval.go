@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+
+	"gin/internal/services/database"
+	"gin/internal/services/recommender"
+)
+
+// receval measures recommendation quality offline: it hides a share of each user's
+// likes, trains on the rest and reports precision@k on the hidden likes for the
+// blended model alongside its collaborative-only and content-only components.
+func main() {
+	dbPath := flag.String("db", "devmatch.db", "SQLite database path or postgres:// URL")
+	k := flag.Int("k", 10, "Number of recommendations scored per user")
+	holdout := flag.Float64("holdout", 0.2, "Fraction of each user's likes held out for evaluation")
+	minLikes := flag.Int("min-likes", 3, "Skip users with fewer likes than this")
+	cfWeight := flag.Float64("cf-weight", 0.7, "Weight of collaborative filtering in the blended score")
+	seed := flag.Int64("seed", 42, "Random seed for the holdout split")
+	flag.Parse()
+
+	if *k < 1 {
+		log.Fatal("❌ -k must be at least 1")
+	}
+	if *holdout <= 0 || *holdout >= 1 {
+		log.Fatal("❌ -holdout must be between 0 and 1 (exclusive)")
+	}
+
+	ctx := context.Background()
+
+	db, err := database.ConnectDB(*dbPath)
+	if err != nil {
+		log.Fatalf("❌ Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+	dbService := database.NewDBService(db)
+
+	likes, err := dbService.GetLikes(ctx)
+	if err != nil {
+		log.Fatalf("❌ Failed to load likes: %v", err)
+	}
+	profiles, err := dbService.GetAllUserProfiles(ctx)
+	if err != nil {
+		log.Fatalf("❌ Failed to load profiles: %v", err)
+	}
+	interactions := recommender.InteractionsFromSwipes(likes)
+	log.Printf("Loaded %d likes across %d profiles.", len(interactions), len(profiles))
+
+	variants := []struct {
+		name   string
+		weight float64
+	}{
+		{"blended", *cfWeight},
+		{"collaborative only", 1},
+		{"content only", 0},
+	}
+
+	fmt.Printf("%-20s %8s %12s %12s\n", "model", "users", fmt.Sprintf("precision@%d", *k), fmt.Sprintf("recall@%d", *k))
+	for _, v := range variants {
+		result := recommender.Evaluate(interactions, profiles, recommender.EvalOptions{
+			K:               *k,
+			HoldoutFraction: *holdout,
+			MinLikes:        *minLikes,
+			CFWeight:        v.weight,
+			Seed:            *seed,
+		})
+		if result.UsersEvaluated == 0 {
+			log.Fatalf("❌ No users have at least %d likes; nothing to evaluate.", *minLikes)
+		}
+		fmt.Printf("%-20s %8d %12.4f %12.4f\n", v.name, result.UsersEvaluated, result.PrecisionAtK, result.RecallAtK)
+	}
+}
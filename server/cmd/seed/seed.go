@@ -109,6 +109,15 @@ func main() {
 			continue
 		}
 
+		// Store follower/repo counts and primary language for deck filters.
+		// A failure here is not fatal; the profile itself is already seeded.
+		stats, err := githubService.GetUserStats(ctx, githubUser)
+		if err != nil {
+			log.Printf("⚠️ Failed to fetch GitHub stats for %s: %v", username, err)
+		} else if err := dbService.UpdateGitHubStats(ctx, createdUser.ID, *stats); err != nil {
+			log.Printf("⚠️ Failed to store GitHub stats for %s: %v", username, err)
+		}
+
 		log.Printf("✅ Successfully seeded user: %s (DB ID: %s)", *createdUser.Username, createdUser.ID)
 		seededCount++
 	}
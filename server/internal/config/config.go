@@ -1,40 +1,305 @@
 package config
 
 import (
-	"log"
+	"fmt"
+	"log/slog"
+	"net"
 	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin/internal/logging"
 
 	"github.com/joho/godotenv"
 )
 
+var logger = logging.For("config")
+
+// Authentication providers selectable with AUTH_PROVIDER.
+const (
+	AuthProviderClerk = "clerk"
+	AuthProviderLocal = "local"
+	AuthProviderDev   = "dev"
+)
+
+// RateLimit allows Requests per Period to each caller; Requests 0 means unlimited.
+type RateLimit struct {
+	Requests int
+	Period   time.Duration
+}
+
 type Config struct {
 	SQLitePath     string // Path to the SQLite database file
-	ClerkSecretKey string
+	DatabaseURL    string // postgres:// URL or SQLite path; defaults to SQLitePath
+	ClerkSecretKey string // Required when AuthProvider is "clerk"
 	GeminiAPIKey   string // Add if needed now
-	GinMode        string
+	GitHubToken    string // Personal access token for the GitHub API; optional, but unauthenticated calls are limited to 60 an hour
+	GinMode        string // "debug" (default), "release" or "test"
 	Port           string
+
+	SwipeUndoWindow     time.Duration // How long after swiping a user may undo their last swipe
+	SuperLikeDailyLimit int           // Super likes each user may send per UTC day
+
+	// Recommender settings
+	RecommenderRefreshInterval time.Duration // How often item-item similarities are recomputed from swipes
+	RecommenderCFWeight        float64       // Weight of collaborative filtering vs content similarity, in [0, 1]
+
+	// GitHub stats (followers, repositories, primary language) used by deck filters and search
+	GitHubStatsRefreshInterval time.Duration // How old a profile's GitHub stats may get before the background job refetches them; 0 disables the job
+
+	// Backup settings (SQLite only)
+	BackupDir      string        // Directory for periodic snapshots of the database
+	BackupInterval time.Duration // How often to snapshot the database; 0 disables periodic backups
+	BackupKeep     int           // Number of newest snapshots kept when pruning
+
+	// Personal data export settings
+	ExportDir            string        // Directory for archives generated in the background
+	ExportTTL            time.Duration // How long a background export can be downloaded
+	ExportAsyncThreshold int           // Users with more swipes+messages than this are exported in the background
+
+	AccountDeletionGracePeriod time.Duration // How long an account deletion can be cancelled before the account is purged
+
+	ClerkWebhookSecret string // Signing secret of the Clerk webhook endpoint (whsec_...); webhooks are refused without it
+
+	// Authentication settings
+	AuthProvider    string // "clerk" (default), "local" (HMAC-signed tokens) or "dev" (X-Dev-User header, debug mode only)
+	LocalAuthSecret string // Signing secret of local tokens; required when AuthProvider is "local"
+
+	// Clerk session token verification settings
+	ClerkIssuer              string        // Expected "iss" of session tokens (the Frontend API URL); empty skips the check
+	ClerkAuthorizedParties   []string      // Origins allowed in the "azp" claim; empty allows any
+	ClerkClockSkew           time.Duration // Leeway for token timestamps between our clock and Clerk's
+	ClerkJWKSRefreshInterval time.Duration // How often the signing keys are re-fetched in the background
+	ClerkJWKSFile            string        // Read signing keys from this file instead of Clerk (offline development)
+
+	UserCacheTTL time.Duration // How long an authenticated user's profile is cached between requests; 0 disables the cache
+
+	// Content moderation settings (chat messages and profile bios)
+	ModerationBannedWords    []string      // Words and phrases that get content rejected
+	ModerationFlaggedPhrases []string      // Extra words and phrases that get content flagged for review
+	ModerationMaxLinks       int           // Content with more links than this is flagged; 0 disables the rule
+	ModerationRepeatWindow   time.Duration // How far back identical messages count as repeats
+	ModerationRepeatLimit    int           // Messages repeated this many times within the window are rejected; 0 disables the rule
+	ModerationLLM            bool          // Also classify content with Gemini (needs GEMINI_API_KEY)
+	ModerationLLMTimeout     time.Duration // Upper bound on a Gemini classification
+
+	// Rate limits per user (or per IP for unauthenticated routes), as "<requests>/<period>" or "off"
+	RateLimitGlobal    RateLimit // Every route, per IP
+	RateLimitDashboard RateLimit // Swiping, favorites and notifications
+	RateLimitChat      RateLimit // Conversations and messages
+	RateLimitGitHub    RateLimit // GitHub proxy and summaries, which spend our GitHub and Gemini quotas
+
+	// CORS settings
+	CORSAllowedOrigins   []string      // Frontend origins; "https://*.example.com" allows subdomains, "*" any origin
+	CORSAllowedMethods   []string      // Methods allowed in cross-origin requests
+	CORSAllowedHeaders   []string      // Request headers allowed in cross-origin requests
+	CORSExposedHeaders   []string      // Response headers the frontend may read
+	CORSMaxAge           time.Duration // How long browsers may cache a preflight response
+	CORSAllowCredentials bool          // Allow cookies and Authorization headers; not allowed with "*"
+
+	// HTTP server settings
+	ServerReadTimeout       time.Duration // Upper bound on reading a whole request, body included
+	ServerReadHeaderTimeout time.Duration // Upper bound on reading request headers, against slow clients holding connections open
+	ServerWriteTimeout      time.Duration // Upper bound from the end of the request headers to the end of the response
+	ServerIdleTimeout       time.Duration // How long keep-alive connections wait for the next request
+	MaxHeaderBytes          int           // Largest request headers accepted, request line included
+	MaxRequestBodyBytes     int64         // Largest request body accepted; larger ones get a 413
+	TrustedProxies          []string      // IPs or CIDRs of load balancers whose X-Forwarded-For is believed; empty trusts none
+
+	// Logging settings
+	LogLevel  slog.Level            // Level of packages without their own
+	LogLevels map[string]slog.Level // Per-package levels, e.g. {"database": debug}
+
+	// Prometheus metrics settings
+	MetricsEnabled bool   // Serve metrics at /metrics
+	MetricsToken   string // Bearer token required to read /metrics; empty leaves it open
 }
 
 func LoadConfig() *Config {
 	// Attempt to load .env file. If it doesn't exist, environment variables are used.
 	err := godotenv.Load()
 	if err != nil {
-		log.Println("No .env file found, loading from environment variables")
+		logger.Info("No .env file found, loading from environment variables")
 	}
 
 	cfg := &Config{
 		SQLitePath:     getEnv("SQLITE_PATH", "./devmatch.db"), // Default path
 		ClerkSecretKey: getEnv("CLERK_SECRET_KEY", ""),
 		GeminiAPIKey:   getEnv("GEMINI_API_KEY", ""), // Optional for now
+		GitHubToken:    getEnv("GITHUB_TOKEN", ""),
 		GinMode:        getEnv("GIN_MODE", "debug"),
 		Port:           getEnv("PORT", "8080"), // Default port
+
+		SwipeUndoWindow:     getEnvDuration("SWIPE_UNDO_WINDOW", 5*time.Minute),
+		SuperLikeDailyLimit: getEnvInt("SUPERLIKE_DAILY_LIMIT", 3),
+
+		RecommenderRefreshInterval: getEnvDuration("RECOMMENDER_REFRESH_INTERVAL", 15*time.Minute),
+		RecommenderCFWeight:        getEnvFloat("RECOMMENDER_CF_WEIGHT", 0.7),
+
+		GitHubStatsRefreshInterval: getEnvDuration("GITHUB_STATS_REFRESH_INTERVAL", 24*time.Hour),
+
+		BackupDir:      getEnv("BACKUP_DIR", "./backups"),
+		BackupInterval: getEnvDuration("BACKUP_INTERVAL", 0),
+		BackupKeep:     getEnvInt("BACKUP_KEEP", 7),
+
+		ExportDir:            getEnv("EXPORT_DIR", "./exports"),
+		ExportTTL:            getEnvDuration("EXPORT_TTL", 24*time.Hour),
+		ExportAsyncThreshold: getEnvInt("EXPORT_ASYNC_THRESHOLD", 1000),
+
+		AccountDeletionGracePeriod: getEnvDuration("ACCOUNT_DELETION_GRACE_PERIOD", 7*24*time.Hour),
+
+		ClerkWebhookSecret: getEnv("CLERK_WEBHOOK_SECRET", ""), // Optional: only needed to receive Clerk webhooks
+
+		AuthProvider:    getEnv("AUTH_PROVIDER", AuthProviderClerk),
+		LocalAuthSecret: getEnv("LOCAL_AUTH_SECRET", ""),
+
+		ClerkIssuer:              getEnv("CLERK_ISSUER", ""),
+		ClerkAuthorizedParties:   getEnvList("CLERK_AUTHORIZED_PARTIES", nil),
+		ClerkClockSkew:           getEnvDuration("CLERK_CLOCK_SKEW", 5*time.Second),
+		ClerkJWKSRefreshInterval: getEnvDuration("CLERK_JWKS_REFRESH_INTERVAL", time.Hour),
+		ClerkJWKSFile:            getEnv("CLERK_JWKS_FILE", ""),
+
+		UserCacheTTL: getEnvDuration("USER_CACHE_TTL", 30*time.Second),
+
+		ModerationBannedWords:    getEnvList("MODERATION_BANNED_WORDS", nil),
+		ModerationFlaggedPhrases: getEnvList("MODERATION_FLAGGED_PHRASES", nil),
+		ModerationMaxLinks:       getEnvInt("MODERATION_MAX_LINKS", 3),
+		ModerationRepeatWindow:   getEnvDuration("MODERATION_REPEAT_WINDOW", time.Hour),
+		ModerationRepeatLimit:    getEnvInt("MODERATION_REPEAT_LIMIT", 5),
+		ModerationLLM:            getEnvBool("MODERATION_LLM", false),
+		ModerationLLMTimeout:     getEnvDuration("MODERATION_LLM_TIMEOUT", 3*time.Second),
+
+		RateLimitGlobal:    getEnvRateLimit("RATE_LIMIT_GLOBAL", RateLimit{Requests: 600, Period: time.Minute}),
+		RateLimitDashboard: getEnvRateLimit("RATE_LIMIT_DASHBOARD", RateLimit{Requests: 120, Period: time.Minute}),
+		RateLimitChat:      getEnvRateLimit("RATE_LIMIT_CHAT", RateLimit{Requests: 120, Period: time.Minute}),
+		RateLimitGitHub:    getEnvRateLimit("RATE_LIMIT_GITHUB", RateLimit{Requests: 30, Period: time.Hour}),
+
+		CORSAllowedOrigins:   getEnvList("CORS_ALLOWED_ORIGINS", []string{"http://localhost:3000"}),
+		CORSAllowedMethods:   getEnvList("CORS_ALLOWED_METHODS", []string{"GET", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"}),
+		CORSAllowedHeaders:   getEnvList("CORS_ALLOWED_HEADERS", []string{"Authorization", "Content-Type", "Accept", "Origin", "Cache-Control", "X-Requested-With", "X-CSRF-Token", "X-Request-ID"}),
+		CORSExposedHeaders:   getEnvList("CORS_EXPOSED_HEADERS", []string{"Retry-After", "X-RateLimit-Limit", "X-RateLimit-Remaining", "X-RateLimit-Reset", "X-Request-ID"}),
+		CORSMaxAge:           getEnvDuration("CORS_MAX_AGE", 12*time.Hour),
+		CORSAllowCredentials: getEnvBool("CORS_ALLOW_CREDENTIALS", true),
+
+		ServerReadTimeout:       getEnvDuration("SERVER_READ_TIMEOUT", 15*time.Second),
+		ServerReadHeaderTimeout: getEnvDuration("SERVER_READ_HEADER_TIMEOUT", 5*time.Second),
+		ServerWriteTimeout:      getEnvDuration("SERVER_WRITE_TIMEOUT", 60*time.Second), // Leaves room for Gemini summaries and export downloads
+		ServerIdleTimeout:       getEnvDuration("SERVER_IDLE_TIMEOUT", 120*time.Second),
+		MaxHeaderBytes:          getEnvInt("MAX_HEADER_BYTES", 64<<10),
+		MaxRequestBodyBytes:     int64(getEnvInt("MAX_REQUEST_BODY_BYTES", 1<<20)),
+		TrustedProxies:          getEnvList("TRUSTED_PROXIES", nil),
+
+		MetricsEnabled: getEnvBool("METRICS_ENABLED", true),
+		MetricsToken:   getEnv("METRICS_TOKEN", ""),
 	}
 
-	if cfg.SQLitePath == "" {
-		log.Fatal("FATAL: SQLITE_PATH environment variable is required")
+	// LOG_LEVEL is the level of every package, and LOG_LEVELS overrides it per package as
+	// comma-separated package=level entries, e.g. "database=debug,auth=warn"
+	logLevel, err := logging.ParseLevel(getEnv("LOG_LEVEL", "info"))
+	if err != nil {
+		logging.Fatal(logger, "LOG_LEVEL must be debug, info, warn or error", "error", err)
 	}
-	if cfg.ClerkSecretKey == "" {
-		log.Fatal("FATAL: CLERK_SECRET_KEY environment variable is required")
+	cfg.LogLevel = logLevel
+	if cfg.LogLevels, err = logging.ParsePackageLevels(getEnvList("LOG_LEVELS", nil)); err != nil {
+		logging.Fatal(logger, "LOG_LEVELS must be comma-separated package=level entries", "error", err)
+	}
+
+	switch cfg.GinMode {
+	case "debug", "release", "test":
+	default:
+		logging.Fatal(logger, "GIN_MODE must be \"debug\", \"release\" or \"test\"")
+	}
+
+	// DATABASE_URL selects the backend; without it the SQLite file at SQLITE_PATH is used
+	cfg.DatabaseURL = getEnv("DATABASE_URL", cfg.SQLitePath)
+	if cfg.DatabaseURL == "" {
+		logging.Fatal(logger, "DATABASE_URL or SQLITE_PATH environment variable is required")
+	}
+	switch cfg.AuthProvider {
+	case AuthProviderClerk:
+		if cfg.ClerkSecretKey == "" {
+			logging.Fatal(logger, "CLERK_SECRET_KEY environment variable is required (or set AUTH_PROVIDER=local or dev to run without Clerk)")
+		}
+	case AuthProviderLocal:
+		if cfg.LocalAuthSecret == "" {
+			logging.Fatal(logger, "LOCAL_AUTH_SECRET environment variable is required when AUTH_PROVIDER=local")
+		}
+	case AuthProviderDev:
+		// Dev mode lets anyone act as any seeded user; never allow it in production
+		if cfg.GinMode == "release" {
+			logging.Fatal(logger, "AUTH_PROVIDER=dev is not allowed when GIN_MODE=release")
+		}
+	default:
+		logging.Fatal(logger, "AUTH_PROVIDER must be one of the supported providers", "auth_provider", cfg.AuthProvider, "supported", []string{AuthProviderClerk, AuthProviderLocal, AuthProviderDev})
+	}
+	if cfg.SuperLikeDailyLimit < 0 {
+		logging.Fatal(logger, "SUPERLIKE_DAILY_LIMIT must not be negative")
+	}
+	if cfg.RecommenderRefreshInterval <= 0 {
+		logging.Fatal(logger, "RECOMMENDER_REFRESH_INTERVAL must be positive")
+	}
+	if cfg.RecommenderCFWeight < 0 || cfg.RecommenderCFWeight > 1 {
+		logging.Fatal(logger, "RECOMMENDER_CF_WEIGHT must be between 0 and 1")
+	}
+	if cfg.GitHubStatsRefreshInterval < 0 {
+		logging.Fatal(logger, "GITHUB_STATS_REFRESH_INTERVAL must not be negative")
+	}
+	if cfg.BackupInterval < 0 {
+		logging.Fatal(logger, "BACKUP_INTERVAL must not be negative")
+	}
+	if cfg.BackupInterval > 0 && cfg.BackupKeep < 1 {
+		logging.Fatal(logger, "BACKUP_KEEP must be at least 1 when periodic backups are enabled")
+	}
+	if cfg.ExportTTL <= 0 {
+		logging.Fatal(logger, "EXPORT_TTL must be positive")
+	}
+	if cfg.ExportAsyncThreshold < 0 {
+		logging.Fatal(logger, "EXPORT_ASYNC_THRESHOLD must not be negative")
+	}
+	if cfg.AccountDeletionGracePeriod < 0 {
+		logging.Fatal(logger, "ACCOUNT_DELETION_GRACE_PERIOD must not be negative")
+	}
+	if cfg.ClerkClockSkew < 0 {
+		logging.Fatal(logger, "CLERK_CLOCK_SKEW must not be negative")
+	}
+	if cfg.ClerkJWKSRefreshInterval <= 0 {
+		logging.Fatal(logger, "CLERK_JWKS_REFRESH_INTERVAL must be positive")
+	}
+	if cfg.UserCacheTTL < 0 {
+		logging.Fatal(logger, "USER_CACHE_TTL must not be negative")
+	}
+	if cfg.ModerationMaxLinks < 0 {
+		logging.Fatal(logger, "MODERATION_MAX_LINKS must not be negative")
+	}
+	if cfg.ModerationRepeatLimit < 0 || cfg.ModerationRepeatWindow < 0 {
+		logging.Fatal(logger, "MODERATION_REPEAT_LIMIT and MODERATION_REPEAT_WINDOW must not be negative")
+	}
+	if cfg.ModerationLLMTimeout <= 0 {
+		logging.Fatal(logger, "MODERATION_LLM_TIMEOUT must be positive")
+	}
+	for _, origin := range cfg.CORSAllowedOrigins {
+		// Browsers refuse credentials with a wildcard origin, and echoing any origin instead would let every site act as the user
+		if origin == "*" && cfg.CORSAllowCredentials {
+			logging.Fatal(logger, "CORS_ALLOWED_ORIGINS cannot contain * while CORS_ALLOW_CREDENTIALS is true")
+		}
+	}
+	if cfg.CORSMaxAge < 0 {
+		logging.Fatal(logger, "CORS_MAX_AGE must not be negative")
+	}
+	if cfg.ServerReadTimeout <= 0 || cfg.ServerReadHeaderTimeout <= 0 || cfg.ServerWriteTimeout <= 0 || cfg.ServerIdleTimeout <= 0 {
+		logging.Fatal(logger, "SERVER_READ_TIMEOUT, SERVER_READ_HEADER_TIMEOUT, SERVER_WRITE_TIMEOUT and SERVER_IDLE_TIMEOUT must be positive")
+	}
+	if cfg.MaxHeaderBytes <= 0 {
+		logging.Fatal(logger, "MAX_HEADER_BYTES must be positive")
+	}
+	if cfg.MaxRequestBodyBytes <= 0 {
+		logging.Fatal(logger, "MAX_REQUEST_BODY_BYTES must be positive")
+	}
+	for _, proxy := range cfg.TrustedProxies {
+		if !validProxy(proxy) {
+			logging.Fatal(logger, "TRUSTED_PROXIES entry is not an IP address or CIDR", "entry", proxy)
+		}
 	}
 
 	return cfg
@@ -44,6 +309,88 @@ func getEnv(key, fallback string) string {
 	if value, exists := os.LookupEnv(key); exists {
 		return value
 	}
-	log.Printf("Warning: Environment variable %s not set, using fallback '%s'\n", key, fallback)
+	logger.Debug("Environment variable not set, using fallback", "key", key, "fallback", fallback)
 	return fallback
 }
+
+func getEnvDuration(key string, fallback time.Duration) time.Duration {
+	value := getEnv(key, fallback.String())
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		logger.Warn("Environment variable has invalid duration, using fallback", "key", key, "value", value, "fallback", fallback.String())
+		return fallback
+	}
+	return d
+}
+
+// getEnvList reads a comma-separated list, dropping empty entries.
+func getEnvList(key string, fallback []string) []string {
+	value := getEnv(key, strings.Join(fallback, ","))
+	var list []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func getEnvFloat(key string, fallback float64) float64 {
+	value := getEnv(key, strconv.FormatFloat(fallback, 'f', -1, 64))
+	f, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		logger.Warn("Environment variable has invalid number, using fallback", "key", key, "value", value, "fallback", fallback)
+		return fallback
+	}
+	return f
+}
+
+func getEnvInt(key string, fallback int) int {
+	value := getEnv(key, strconv.Itoa(fallback))
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		logger.Warn("Environment variable has invalid integer, using fallback", "key", key, "value", value, "fallback", fallback)
+		return fallback
+	}
+	return n
+}
+
+func getEnvBool(key string, fallback bool) bool {
+	value := getEnv(key, strconv.FormatBool(fallback))
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		logger.Warn("Environment variable has invalid boolean, using fallback", "key", key, "value", value, "fallback", fallback)
+		return fallback
+	}
+	return b
+}
+
+// getEnvRateLimit reads a rate limit written as "<requests>/<period>", e.g. "60/1m".
+// "off" or "0" disables the limit.
+func getEnvRateLimit(key string, fallback RateLimit) RateLimit {
+	value := getEnv(key, fmt.Sprintf("%d/%s", fallback.Requests, fallback.Period))
+	if value == "off" || value == "0" {
+		return RateLimit{}
+	}
+	requests, period, ok := strings.Cut(value, "/")
+	n, err := strconv.Atoi(strings.TrimSpace(requests))
+	if !ok || err != nil || n < 0 {
+		logger.Warn("Environment variable has invalid rate limit, using fallback", "key", key, "value", value, "fallback", fmt.Sprintf("%d/%s", fallback.Requests, fallback.Period))
+		return fallback
+	}
+	d, err := time.ParseDuration(strings.TrimSpace(period))
+	if err != nil || d <= 0 {
+		logger.Warn("Environment variable has invalid rate limit, using fallback", "key", key, "value", value, "fallback", fmt.Sprintf("%d/%s", fallback.Requests, fallback.Period))
+		return fallback
+	}
+	return RateLimit{Requests: n, Period: d}
+}
+
+// validProxy reports whether proxy is an IP address or CIDR, as gin.Engine.SetTrustedProxies accepts.
+func validProxy(proxy string) bool {
+	if strings.Contains(proxy, "/") {
+		_, _, err := net.ParseCIDR(proxy)
+		return err == nil
+	}
+	return net.ParseIP(proxy) != nil
+}
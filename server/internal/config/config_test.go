@@ -0,0 +1,78 @@
+package config
+
+import (
+	"os"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// loadConfigInSubprocess runs LoadConfig in a child test process with env added, since
+// it exits on invalid settings, and returns the child's output and whether it succeeded.
+func loadConfigInSubprocess(t *testing.T, env ...string) (string, bool) {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^TestLoadConfigSubprocess$")
+	cmd.Env = append(os.Environ(), append([]string{"CONFIG_TEST_SUBPROCESS=1"}, env...)...)
+	out, err := cmd.CombinedOutput()
+	if _, exited := err.(*exec.ExitError); err != nil && !exited {
+		t.Fatalf("running subprocess: %v", err)
+	}
+	return string(out), err == nil
+}
+
+// TestLoadConfigSubprocess is the child process of loadConfigInSubprocess.
+func TestLoadConfigSubprocess(t *testing.T) {
+	if os.Getenv("CONFIG_TEST_SUBPROCESS") != "1" {
+		t.Skip("only runs as a subprocess of the other config tests")
+	}
+	LoadConfig()
+}
+
+func TestLoadConfigDevAuth(t *testing.T) {
+	tests := []struct {
+		ginMode string
+		ok      bool
+	}{
+		{"debug", true},
+		{"test", true},
+		{"release", false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.ginMode, func(t *testing.T) {
+			out, ok := loadConfigInSubprocess(t, "AUTH_PROVIDER=dev", "GIN_MODE="+tt.ginMode, "SQLITE_PATH=test.db")
+			if ok != tt.ok {
+				t.Fatalf("LoadConfig succeeded = %v, want %v; output:\n%s", ok, tt.ok, out)
+			}
+			if !tt.ok && !strings.Contains(out, "AUTH_PROVIDER=dev is not allowed when GIN_MODE=release") {
+				t.Errorf("output does not explain the refusal:\n%s", out)
+			}
+		})
+	}
+}
+
+func TestLoadConfigServerSettings(t *testing.T) {
+	tests := []struct {
+		name string
+		env  []string
+		want string // Part of the refusal; empty when LoadConfig succeeds
+	}{
+		{"defaults", nil, ""},
+		{"trusted proxies", []string{"TRUSTED_PROXIES=10.0.0.0/8, 192.168.1.1, ::1"}, ""},
+		{"unknown gin mode", []string{"GIN_MODE=production"}, "GIN_MODE must be"},
+		{"bad proxy", []string{"TRUSTED_PROXIES=10.0.0.0/33"}, "TRUSTED_PROXIES entry is not an IP address or CIDR"},
+		{"zero timeout", []string{"SERVER_WRITE_TIMEOUT=0s"}, "must be positive"},
+		{"zero body limit", []string{"MAX_REQUEST_BODY_BYTES=0"}, "MAX_REQUEST_BODY_BYTES must be positive"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			env := append([]string{"AUTH_PROVIDER=dev", "GIN_MODE=test", "SQLITE_PATH=test.db"}, tt.env...)
+			out, ok := loadConfigInSubprocess(t, env...)
+			if ok != (tt.want == "") {
+				t.Fatalf("LoadConfig succeeded = %v; output:\n%s", ok, out)
+			}
+			if tt.want != "" && !strings.Contains(out, tt.want) {
+				t.Errorf("output does not contain %q:\n%s", tt.want, out)
+			}
+		})
+	}
+}
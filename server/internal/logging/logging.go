@@ -0,0 +1,149 @@
+// Package logging provides structured JSON logging on top of log/slog. Every package
+// logs through its own logger (see For), whose level can be set separately, request IDs
+// carried in contexts are added to every line, and credentials and user content are
+// redacted before anything is written.
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"sync/atomic"
+)
+
+// LevelFatal is the level of Fatal, above slog.LevelError.
+const LevelFatal = slog.Level(12)
+
+// PackageKey is the attribute naming the package a line was logged from.
+const PackageKey = "package"
+
+// RequestIDKey is the attribute holding the ID of the request a line was logged for.
+const RequestIDKey = "request_id"
+
+// levels is the level configuration shared by every logger.
+type levels struct {
+	fallback slog.Level            // Packages without their own level
+	packages map[string]slog.Level // By package name, as passed to For
+}
+
+var (
+	current atomic.Pointer[levels]
+
+	// output is shared by every logger. Its own level is the lowest possible, as each
+	// logger filters by its package's level first.
+	output slog.Handler = slog.NewJSONHandler(os.Stderr, &slog.HandlerOptions{
+		Level:       slog.Level(-100),
+		ReplaceAttr: replaceAttr,
+	})
+)
+
+func init() {
+	current.Store(&levels{fallback: slog.LevelInfo})
+}
+
+// Configure sets the level of every package without its own entry in packages. It also
+// routes the standard library's log package (used by third-party code) through the
+// "stdlog" logger.
+func Configure(fallback slog.Level, packages map[string]slog.Level) {
+	current.Store(&levels{fallback: fallback, packages: packages})
+	slog.SetDefault(For("stdlog"))
+}
+
+// For returns the logger of the named package. It can be created before Configure,
+// for example in a package-level variable; level changes apply to it immediately.
+func For(pkg string) *slog.Logger {
+	return slog.New(&handler{pkg: pkg, next: output.WithAttrs([]slog.Attr{slog.String(PackageKey, pkg)})})
+}
+
+// Fatal logs msg at LevelFatal and exits, like log.Fatal.
+func Fatal(logger *slog.Logger, msg string, args ...any) {
+	logger.Log(context.Background(), LevelFatal, msg, args...)
+	os.Exit(1)
+}
+
+// ParseLevel parses "debug", "info", "warn" or "error" (any case).
+func ParseLevel(s string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(strings.TrimSpace(s))); err != nil {
+		return 0, fmt.Errorf("invalid log level %q", s)
+	}
+	return level, nil
+}
+
+// ParsePackageLevels parses per-package levels written as "package=level" entries,
+// e.g. ["database=debug", "auth=warn"].
+func ParsePackageLevels(entries []string) (map[string]slog.Level, error) {
+	packages := make(map[string]slog.Level, len(entries))
+	for _, entry := range entries {
+		pkg, value, ok := strings.Cut(entry, "=")
+		if !ok || strings.TrimSpace(pkg) == "" {
+			return nil, fmt.Errorf("invalid package level %q, want package=level", entry)
+		}
+		level, err := ParseLevel(value)
+		if err != nil {
+			return nil, err
+		}
+		packages[strings.TrimSpace(pkg)] = level
+	}
+	return packages, nil
+}
+
+type requestIDContextKey struct{}
+
+// WithRequestID returns a copy of ctx carrying the request ID id, which is then added
+// to every line logged with ctx.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestID returns the request ID carried by ctx, or "" if there is none.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// handler filters records by its package's level and adds the request ID from the
+// context before passing them to the shared output.
+type handler struct {
+	pkg  string
+	next slog.Handler
+}
+
+func (h *handler) Enabled(_ context.Context, level slog.Level) bool {
+	l := current.Load()
+	threshold, ok := l.packages[h.pkg]
+	if !ok {
+		threshold = l.fallback
+	}
+	return level >= threshold
+}
+
+func (h *handler) Handle(ctx context.Context, r slog.Record) error {
+	if ctx != nil {
+		if id := RequestID(ctx); id != "" {
+			r.AddAttrs(slog.String(RequestIDKey, id))
+		}
+	}
+	return h.next.Handle(ctx, r)
+}
+
+func (h *handler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &handler{pkg: h.pkg, next: h.next.WithAttrs(attrs)}
+}
+
+func (h *handler) WithGroup(name string) slog.Handler {
+	return &handler{pkg: h.pkg, next: h.next.WithGroup(name)}
+}
+
+// replaceAttr names LevelFatal and redacts every attribute before it is written.
+func replaceAttr(groups []string, a slog.Attr) slog.Attr {
+	if a.Key == slog.LevelKey && len(groups) == 0 {
+		if level, ok := a.Value.Any().(slog.Level); ok && level >= LevelFatal {
+			return slog.String(slog.LevelKey, "FATAL")
+		}
+		return a
+	}
+	return redact(a)
+}
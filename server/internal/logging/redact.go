@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"log/slog"
+	"regexp"
+	"strings"
+)
+
+// redacted replaces whatever must not be logged.
+const redacted = "[REDACTED]"
+
+// sensitiveKeys are attribute keys whose values are never logged: credentials, and
+// content users wrote, which moderators see through the API rather than in logs.
+var sensitiveKeys = map[string]bool{
+	"token":         true,
+	"authorization": true,
+	"cookie":        true,
+	"password":      true,
+	"secret":        true,
+	"api_key":       true,
+	"content":       true,
+	"text":          true,
+	"body":          true,
+	"bio":           true,
+}
+
+// sensitiveKeySuffixes extend sensitiveKeys to keys like "session_token" or "webhook_secret".
+var sensitiveKeySuffixes = []string{"_token", "_secret", "_password"}
+
+// secretPatterns match credentials that end up inside other values, such as error
+// messages quoting a header or a URL with an API key in its query.
+var secretPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/=-]+`),
+	regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), // JWTs, e.g. Clerk session tokens
+	regexp.MustCompile(`\bwhsec_[A-Za-z0-9+/=]+`),                             // Svix webhook secrets
+	regexp.MustCompile(`\bsk_(?:live|test)_[A-Za-z0-9]+`),                     // Clerk secret keys
+	regexp.MustCompile(`\b(?:gh[pousr]_[A-Za-z0-9]{20,}|github_pat_[A-Za-z0-9_]{20,})`),
+	regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{20,}`), // Google API keys (Gemini)
+}
+
+// secretQueryParam matches credentials passed in URL query strings, keeping the name.
+var secretQueryParam = regexp.MustCompile(`(?i)([?&](?:key|token|access_token|client_secret)=)[^&\s"]+`)
+
+// redact hides a's value if its key is sensitive, and any credentials inside it otherwise.
+func redact(a slog.Attr) slog.Attr {
+	if isSensitiveKey(a.Key) {
+		return slog.String(a.Key, redacted)
+	}
+	switch a.Value.Kind() {
+	case slog.KindString:
+		if s := a.Value.String(); s != "" {
+			return slog.String(a.Key, RedactString(s))
+		}
+	case slog.KindAny:
+		if err, ok := a.Value.Any().(error); ok {
+			return slog.String(a.Key, RedactString(err.Error()))
+		}
+	}
+	return a
+}
+
+func isSensitiveKey(key string) bool {
+	key = strings.ToLower(key)
+	if sensitiveKeys[key] {
+		return true
+	}
+	for _, suffix := range sensitiveKeySuffixes {
+		if strings.HasSuffix(key, suffix) {
+			return true
+		}
+	}
+	return false
+}
+
+// RedactString replaces credentials found in s.
+func RedactString(s string) string {
+	for _, p := range secretPatterns {
+		s = p.ReplaceAllString(s, redacted)
+	}
+	return secretQueryParam.ReplaceAllString(s, "${1}"+redacted)
+}
@@ -0,0 +1,152 @@
+// Package metrics defines the Prometheus metrics of the server and the handler
+// exposing them. Metrics are package-level, like loggers, so any package can record
+// them without threading a registry through every constructor.
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+// namespace prefixes every metric name.
+const namespace = "devmatch"
+
+// Outcome label values.
+const (
+	OutcomeSuccess = "success"
+	OutcomeError   = "error"
+)
+
+// Registry holds every metric below plus the Go runtime and process collectors. A
+// registry of our own keeps metrics registered by libraries out of /metrics.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+// HTTP metrics, labelled by route as registered ("/users/:id"), so IDs in paths do not
+// create a series each.
+var (
+	HTTPRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "http", Name: "requests_total",
+		Help: "HTTP requests handled, by method, route and status code.",
+	}, []string{"method", "route", "status"})
+
+	HTTPRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace, Subsystem: "http", Name: "request_duration_seconds",
+		Help:    "Time to handle HTTP requests, by method and route.",
+		Buckets: []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30},
+	}, []string{"method", "route"})
+
+	HTTPRequestsInFlight = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "http", Name: "requests_in_flight",
+		Help: "HTTP requests being handled right now.",
+	})
+
+	HTTPConnections = factory.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "http", Name: "connections",
+		Help: "Open client connections, by state (new, active or idle).",
+	}, []string{"state"})
+)
+
+// DBQueryDuration times database queries by the DBService method that ran them.
+var DBQueryDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+	Namespace: namespace, Subsystem: "db", Name: "query_duration_seconds",
+	Help:    "Time to execute database queries, by DBService method.",
+	Buckets: []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 5},
+}, []string{"operation"})
+
+// GitHub API metrics. The rate limit gauges follow the headers of the latest response.
+var (
+	GitHubAPICalls = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "github", Name: "api_calls_total",
+		Help: "GitHub API calls, by endpoint and outcome.",
+	}, []string{"endpoint", "outcome"})
+
+	GitHubRateLimitRemaining = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "github", Name: "rate_limit_remaining",
+		Help: "GitHub API requests left in the current rate limit window.",
+	})
+
+	GitHubRateLimit = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "github", Name: "rate_limit",
+		Help: "GitHub API requests allowed per rate limit window.",
+	})
+
+	GitHubRateLimitReset = factory.NewGauge(prometheus.GaugeOpts{
+		Namespace: namespace, Subsystem: "github", Name: "rate_limit_reset_timestamp_seconds",
+		Help: "Unix time at which the GitHub rate limit window resets.",
+	})
+)
+
+// Gemini metrics.
+var (
+	GeminiCalls = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "gemini", Name: "calls_total",
+		Help: "Gemini API calls, by operation and outcome.",
+	}, []string{"operation", "outcome"})
+
+	GeminiTokens = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Subsystem: "gemini", Name: "tokens_total",
+		Help: "Gemini tokens used, by operation and kind (prompt or completion).",
+	}, []string{"operation", "kind"})
+)
+
+// Business metrics.
+var (
+	Swipes = factory.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace, Name: "swipes_total",
+		Help: "Swipes recorded, by direction.",
+	}, []string{"direction"})
+
+	SwipeUndos = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Name: "swipe_undos_total",
+		Help: "Swipes undone.",
+	})
+
+	Matches = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Name: "matches_total",
+		Help: "Matches created by reciprocal likes.",
+	})
+
+	Messages = factory.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace, Name: "messages_total",
+		Help: "Chat messages sent.",
+	})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+}
+
+// Handler serves the metrics in the Prometheus text format.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+// connStates remembers the last state of every open connection for TrackConnState.
+var connStates sync.Map // net.Conn -> http.ConnState
+
+// TrackConnState is an http.Server ConnState hook keeping HTTPConnections current.
+// Closed connections, and hijacked ones (which the server no longer manages), are
+// dropped from the count.
+func TrackConnState(conn net.Conn, state http.ConnState) {
+	if previous, ok := connStates.Load(conn); ok {
+		HTTPConnections.WithLabelValues(previous.(http.ConnState).String()).Dec()
+	}
+	switch state {
+	case http.StateNew, http.StateActive, http.StateIdle:
+		connStates.Store(conn, state)
+		HTTPConnections.WithLabelValues(state.String()).Inc()
+	default:
+		connStates.Delete(conn)
+	}
+}
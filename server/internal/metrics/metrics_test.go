@@ -0,0 +1,63 @@
+package metrics
+
+import (
+	"net"
+	"net/http"
+	"testing"
+)
+
+// connectionsIn returns the HTTPConnections gauge for state.
+func connectionsIn(t *testing.T, state http.ConnState) float64 {
+	t.Helper()
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != namespace+"_http_connections" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "state" && label.GetValue() == state.String() {
+					return m.GetGauge().GetValue()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestTrackConnState(t *testing.T) {
+	a, b := net.Pipe()
+	defer a.Close()
+	defer b.Close()
+
+	steps := []struct {
+		conn  net.Conn
+		state http.ConnState
+	}{
+		{a, http.StateNew},
+		{b, http.StateNew},
+		{a, http.StateActive},
+		{a, http.StateIdle},
+		{b, http.StateHijacked},
+	}
+	for _, step := range steps {
+		TrackConnState(step.conn, step.state)
+	}
+	if n := connectionsIn(t, http.StateNew); n != 0 {
+		t.Errorf("new connections = %v, want 0", n)
+	}
+	if n := connectionsIn(t, http.StateActive); n != 0 {
+		t.Errorf("active connections = %v, want 0", n)
+	}
+	if n := connectionsIn(t, http.StateIdle); n != 1 {
+		t.Errorf("idle connections = %v, want 1", n)
+	}
+
+	TrackConnState(a, http.StateClosed)
+	if n := connectionsIn(t, http.StateIdle); n != 0 {
+		t.Errorf("idle connections after closing = %v, want 0", n)
+	}
+}
@@ -0,0 +1,129 @@
+package models
+
+import "time"
+
+// UserRole is what a user is allowed to do beyond using the app. Roles are ordered:
+// each one can do everything the roles below it can.
+type UserRole string
+
+const (
+	RoleUser      UserRole = "user"      // Everyone
+	RoleModerator UserRole = "moderator" // Reviews reports and suspends users
+	RoleAdmin     UserRole = "admin"     // Also manages roles
+)
+
+// roleRanks orders the roles for AtLeast.
+var roleRanks = map[UserRole]int{
+	RoleUser:      0,
+	RoleModerator: 1,
+	RoleAdmin:     2,
+}
+
+// Valid reports whether r is a known role.
+func (r UserRole) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// AtLeast reports whether r grants everything minimum does. Unknown roles grant nothing
+// beyond RoleUser.
+func (r UserRole) AtLeast(minimum UserRole) bool {
+	return roleRanks[r] >= roleRanks[minimum]
+}
+
+// ReportStatus is the state of a report in the moderation queue.
+type ReportStatus string
+
+const (
+	ReportOpen      ReportStatus = "open"      // Waiting for a moderator
+	ReportResolved  ReportStatus = "resolved"  // A moderator acted on it
+	ReportDismissed ReportStatus = "dismissed" // A moderator found nothing to act on
+)
+
+// ReportReason is the category a reporter picks for a report.
+type ReportReason string
+
+const (
+	ReportHarassment    ReportReason = "harassment"
+	ReportSpam          ReportReason = "spam"
+	ReportInappropriate ReportReason = "inappropriate_content"
+	ReportImpersonation ReportReason = "impersonation"
+	ReportScam          ReportReason = "scam"
+	ReportOther         ReportReason = "other" // Details are required
+)
+
+// Valid reports whether r is a known reason.
+func (r ReportReason) Valid() bool {
+	switch r {
+	case ReportHarassment, ReportSpam, ReportInappropriate, ReportImpersonation, ReportScam, ReportOther:
+		return true
+	}
+	return false
+}
+
+// ReportAction is something a moderator did about a report when resolving it.
+type ReportAction string
+
+const (
+	ReportActionSuspendUser    ReportAction = "suspend_user"    // Suspend the reported user
+	ReportActionRemoveMessages ReportAction = "remove_messages" // Delete the reported messages from their conversation
+)
+
+// Valid reports whether a is a known action.
+func (a ReportAction) Valid() bool {
+	return a == ReportActionSuspendUser || a == ReportActionRemoveMessages
+}
+
+// MaxReportedMessages bounds how many messages one report can reference.
+const MaxReportedMessages = 20
+
+// Report is a complaint one user filed about another.
+type Report struct {
+	ID                string         `json:"id" db:"id"`
+	ReporterUserID    *string        `json:"reporterUserId,omitempty" db:"reporter_user_id"` // Nil once the reporter deleted their account
+	ReportedUserID    string         `json:"reportedUserId" db:"reported_user_id"`
+	Reason            ReportReason   `json:"reason" db:"reason"`
+	Details           *string        `json:"details,omitempty" db:"details"`
+	Status            ReportStatus   `json:"status" db:"status"`
+	CreatedAt         time.Time      `json:"createdAt" db:"created_at"`
+	ResolvedAt        *time.Time     `json:"resolvedAt,omitempty" db:"resolved_at"`
+	ResolvedByUserID  *string        `json:"resolvedByUserId,omitempty" db:"resolved_by_user_id"`
+	ResolutionNote    *string        `json:"resolutionNote,omitempty" db:"resolution_note"`
+	ResolutionActions []ReportAction `json:"resolutionActions,omitempty" db:"resolution_actions"` // Stored comma-separated
+}
+
+// ReportedMessage is a copy of a message attached to a report as evidence.
+type ReportedMessage struct {
+	MessageID    *string   `json:"messageId,omitempty" db:"message_id"` // Nil once the message was removed
+	SenderUserID *string   `json:"senderUserId,omitempty" db:"sender_user_id"`
+	Content      string    `json:"content" db:"content"`
+	SentAt       time.Time `json:"sentAt" db:"sent_at"`
+}
+
+// ReportResolution is how a moderator closes a report.
+type ReportResolution struct {
+	Status  ReportStatus   // ReportResolved or ReportDismissed
+	Actions []ReportAction // Taken before resolving; none for a dismissal
+	Note    *string
+}
+
+// AdminUserFilter narrows the user list of the admin API. Zero values match everyone.
+type AdminUserFilter struct {
+	Query     string   // Case-insensitive substring of the username or GitHub URL, or an exact user or Clerk ID
+	Role      UserRole // Only users with this role
+	Suspended *bool    // Only suspended (true) or active (false) users
+}
+
+// SystemStats is the overview shown on the admin dashboard.
+type SystemStats struct {
+	Users                int              `json:"users"`
+	UsersByRole          map[UserRole]int `json:"usersByRole"`
+	SuspendedUsers       int              `json:"suspendedUsers"`
+	PendingDeletions     int              `json:"pendingDeletions"`
+	ActiveUsersLast7Days int              `json:"activeUsersLast7Days"`
+	NewUsersLast7Days    int              `json:"newUsersLast7Days"`
+	Swipes               int              `json:"swipes"`
+	Matches              int              `json:"matches"` // Each match opens a conversation, so this counts conversations
+	Messages             int              `json:"messages"`
+	OpenReports          int              `json:"openReports"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "testing"
+
+func TestUserRoleAtLeast(t *testing.T) {
+	tests := []struct {
+		role, minimum UserRole
+		want          bool
+	}{
+		{RoleUser, RoleUser, true},
+		{RoleUser, RoleModerator, false},
+		{RoleModerator, RoleModerator, true},
+		{RoleModerator, RoleAdmin, false},
+		{RoleAdmin, RoleModerator, true},
+		{"owner", RoleUser, true}, // Unknown roles grant what every user has
+		{"owner", RoleModerator, false},
+		{"", RoleModerator, false},
+	}
+	for _, tt := range tests {
+		if got := tt.role.AtLeast(tt.minimum); got != tt.want {
+			t.Errorf("%q.AtLeast(%q) = %v, want %v", tt.role, tt.minimum, got, tt.want)
+		}
+	}
+	if UserRole("owner").Valid() || !RoleModerator.Valid() {
+		t.Error("Valid does not match the known roles")
+	}
+}
@@ -2,6 +2,9 @@ package models
 
 import "time"
 
+// MaxMessageLength is the maximum length of a chat message, in characters.
+const MaxMessageLength = 2000
+
 // Conversation represents a chat session between users.
 type Conversation struct {
 	ID        string    `json:"id" db:"id"`                 // Unique identifier for the conversation
@@ -15,7 +18,7 @@ type Conversation struct {
 type Message struct {
 	ID             string    `json:"id" db:"id"`                           // Unique identifier for the message
 	ConversationID string    `json:"conversation_id" db:"conversation_id"` // ID of the conversation this message belongs to
-	SenderID       string    `json:"sender_id" db:"sender_id"`             // ID of the user who sent the message
+	SenderID       *string   `json:"sender_id" db:"sender_id"`             // ID of the user who sent the message; nil once they deleted their account
 	Content        string    `json:"content" db:"content"`                 // The text content of the message
 	SentAt         time.Time `json:"sent_at" db:"sent_at"`                 // Timestamp when the message was sent
 	// Add other fields like ReadStatus, MessageType (text, image), etc. if needed
@@ -0,0 +1,45 @@
+package models
+
+import "time"
+
+// DataExportStatus is the state of an asynchronous personal data export.
+type DataExportStatus string
+
+const (
+	DataExportPending DataExportStatus = "pending" // Archive is being generated
+	DataExportReady   DataExportStatus = "ready"   // Archive can be downloaded until ExpiresAt
+	DataExportFailed  DataExportStatus = "failed"  // Generation failed; see Error
+)
+
+// DataExport is a personal data export job and, once ready, its downloadable archive.
+type DataExport struct {
+	ID            string           `json:"id" db:"id"`
+	UserID        string           `json:"userId" db:"user_id"`
+	Status        DataExportStatus `json:"status" db:"status"`
+	DownloadToken *string          `json:"-" db:"download_token"` // Secret part of the download link, set when ready
+	FilePath      *string          `json:"-" db:"file_path"`      // Archive location on the server
+	SizeBytes     *int64           `json:"sizeBytes,omitempty" db:"size_bytes"`
+	Error         *string          `json:"error,omitempty" db:"error"`
+	CreatedAt     time.Time        `json:"createdAt" db:"created_at"`
+	CompletedAt   *time.Time       `json:"completedAt,omitempty" db:"completed_at"`
+	ExpiresAt     time.Time        `json:"expiresAt" db:"expires_at"` // The job and its archive are deleted after this
+}
+
+// ExportedGitHubData is the GitHub information DevMatch keeps about a user.
+type ExportedGitHubData struct {
+	GitHubURL       *string  `json:"githubUrl,omitempty"`
+	PrimaryLanguage *string  `json:"primaryLanguage,omitempty"`
+	Followers       int      `json:"followers"`
+	PublicRepos     int      `json:"publicRepos"`
+	RepoNames       []string `json:"repoNames"`
+}
+
+// UserDataExport is everything DevMatch stores about one user, as written to an export archive.
+type UserDataExport struct {
+	Profile       UserAccount        `json:"profile"`
+	GitHub        ExportedGitHubData `json:"github"`
+	Swipes        []Swipe            `json:"swipes"`        // Swipes the user made
+	Conversations []Conversation     `json:"conversations"` // Conversations the user takes part in
+	MessagesSent  []Message          `json:"messagesSent"`  // Only the user's own messages
+	Notifications []Notification     `json:"notifications"`
+}
@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// ModerationDecision is what the moderation pipeline decided about a piece of content.
+// Decisions are ordered: a stricter decision from any checker wins.
+type ModerationDecision string
+
+const (
+	ModerationAllow  ModerationDecision = "allow"  // Nothing wrong found
+	ModerationFlag   ModerationDecision = "flag"   // Saved, but logged for moderators to review
+	ModerationReject ModerationDecision = "reject" // Not saved; the author is told why
+)
+
+// moderationSeverity orders the decisions for Stricter.
+var moderationSeverity = map[ModerationDecision]int{
+	ModerationAllow:  0,
+	ModerationFlag:   1,
+	ModerationReject: 2,
+}
+
+// Valid reports whether d is a known decision.
+func (d ModerationDecision) Valid() bool {
+	_, ok := moderationSeverity[d]
+	return ok
+}
+
+// Stricter reports whether d is a stricter decision than other.
+func (d ModerationDecision) Stricter(other ModerationDecision) bool {
+	return moderationSeverity[d] > moderationSeverity[other]
+}
+
+// ModerationContentType is the kind of user-written content being moderated.
+type ModerationContentType string
+
+const (
+	ModerationContentMessage ModerationContentType = "message" // A chat message
+	ModerationContentBio     ModerationContentType = "bio"     // A profile bio
+)
+
+// ModerationEvent is an audit log entry for content that was flagged or rejected.
+type ModerationEvent struct {
+	ID             string                `json:"id" db:"id"`
+	UserID         string                `json:"userId" db:"user_id"` // The author
+	ContentType    ModerationContentType `json:"contentType" db:"content_type"`
+	ConversationID *string               `json:"conversationId,omitempty" db:"conversation_id"` // Set for messages
+	MessageID      *string               `json:"messageId,omitempty" db:"message_id"`           // Set for flagged messages, which were sent
+	Decision       ModerationDecision    `json:"decision" db:"decision"`
+	Stage          string                `json:"stage" db:"stage"` // The checker that decided, e.g. "rules" or "llm"
+	Rule           string                `json:"rule" db:"rule"`   // What triggered, e.g. "banned_word"
+	Reason         string                `json:"reason" db:"reason"`
+	Content        string                `json:"content" db:"content"`
+	CreatedAt      time.Time             `json:"createdAt" db:"created_at"`
+}
+
+// ModerationEventFilter narrows the moderation log. Zero values match everything.
+type ModerationEventFilter struct {
+	Decision    ModerationDecision    // Only flagged or only rejected content
+	ContentType ModerationContentType // Only messages or only bios
+	UserID      string                // Only content by this author
+}
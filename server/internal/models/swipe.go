@@ -6,16 +6,96 @@ import "time"
 type SwipeDirection string
 
 const (
-	SwipeLike    SwipeDirection = "like"    // Represents a positive swipe (e.g., right)
-	SwipeDislike SwipeDirection = "dislike" // Represents a negative swipe (e.g., left)
+	SwipeLike      SwipeDirection = "like"      // Represents a positive swipe (e.g., right)
+	SwipeDislike   SwipeDirection = "dislike"   // Represents a negative swipe (e.g., left)
+	SwipeSuperLike SwipeDirection = "superlike" // An intro request: a like with an optional note that notifies the recipient
 )
 
+// MaxIntroNoteLength is the maximum length of a super like intro note, in characters.
+const MaxIntroNoteLength = 280
+
+// Valid reports whether d is a known swipe direction.
+func (d SwipeDirection) Valid() bool {
+	return d == SwipeLike || d == SwipeDislike || d == SwipeSuperLike
+}
+
+// IsLike reports whether d expresses interest, i.e. a like or a super like.
+func (d SwipeDirection) IsLike() bool {
+	return d == SwipeLike || d == SwipeSuperLike
+}
+
 // Swipe represents a swipe action performed by one user on another.
 type Swipe struct {
-	ID        string         `json:"id" db:"id"`                 // Unique identifier for the swipe action
-	SwiperID  string         `json:"swiper_id" db:"swiper_id"`   // ID of the user who performed the swipe
-	SwipedID  string         `json:"swiped_id" db:"swiped_id"`   // ID of the user who was swiped on
-	Direction SwipeDirection `json:"direction" db:"direction"` // The direction of the swipe (like/dislike)
-	CreatedAt time.Time      `json:"created_at" db:"created_at"` // Timestamp when the swipe occurred
+	ID        string         `json:"id" db:"id"`                           // Unique identifier for the swipe action
+	SwiperID  string         `json:"swiper_id" db:"swiper_id"`             // ID of the user who performed the swipe
+	SwipedID  string         `json:"swiped_id" db:"swiped_id"`             // ID of the user who was swiped on
+	Direction SwipeDirection `json:"direction" db:"direction"`             // The direction of the swipe (like/dislike/superlike)
+	IntroNote *string        `json:"intro_note,omitempty" db:"intro_note"` // Optional note attached to a super like
+	CreatedAt time.Time      `json:"created_at" db:"created_at"`           // Timestamp when the swipe occurred
 	// Could add MatchID string if a match is created immediately upon swiping
-}
\ No newline at end of file
+}
+
+// LogSwipeRequest defines the expected payload for recording a swipe.
+type LogSwipeRequest struct {
+	SwipedUserID string         `json:"swiped_user_id" binding:"required"` // DB ID of the user being swiped on
+	Direction    SwipeDirection `json:"direction" binding:"required"`      // like, dislike or superlike
+	IntroNote    *string        `json:"intro_note"`                        // Only allowed with superlike
+}
+
+// SwipeResult is returned after a swipe is recorded.
+type SwipeResult struct {
+	Swipe          Swipe   `json:"swipe"`
+	Matched        bool    `json:"matched"`                   // True if this swipe completed a mutual like
+	ConversationID *string `json:"conversation_id,omitempty"` // Conversation opened for the match, if any
+	// SuperLikesRemaining is how many super likes the swiper has left today; set for super likes only
+	SuperLikesRemaining *int `json:"superlikes_remaining,omitempty"`
+}
+
+// SwipeCard is a candidate profile shown in the swipe deck, along with its recommendation score.
+type SwipeCard struct {
+	User
+	Score float64 `json:"score"` // Blended recommendation score in [0, 1]; higher is shown first
+}
+
+// CardFilters narrows the swipe deck. Zero values mean "no filter".
+type CardFilters struct {
+	PrimaryLanguage    string   // Case-insensitive match on the primary GitHub language
+	Interests          []string // Candidate must have at least one of these tags
+	TimezoneMinMinutes *int     // Inclusive lower bound on the UTC offset
+	TimezoneMaxMinutes *int     // Inclusive upper bound on the UTC offset
+	MinFollowers       int
+	MinPublicRepos     int
+	ActiveWithinDays   int // Candidate must have been active within this many days
+}
+
+// SwipeHistoryEntry is a swipe made by the current user together with the profile they swiped on.
+type SwipeHistoryEntry struct {
+	Swipe      Swipe `json:"swipe"`
+	SwipedUser User  `json:"swiped_user"`
+}
+
+// UndoSwipeResult describes what was reverted by undoing a swipe.
+type UndoSwipeResult struct {
+	Swipe          Swipe   `json:"swipe"`                     // The swipe that was removed
+	MatchDissolved bool    `json:"match_dissolved"`           // True if the match's empty conversation was deleted
+	ConversationID *string `json:"conversation_id,omitempty"` // Conversation of the match, if one existed
+}
+
+// NotificationType identifies what a notification is about.
+type NotificationType string
+
+const (
+	NotificationSuperLike NotificationType = "superlike" // Someone super liked the recipient
+)
+
+// Notification is an in-app notification delivered to a user.
+type Notification struct {
+	ID          string           `json:"id" db:"id"`
+	UserID      string           `json:"user_id" db:"user_id"` // Recipient
+	Type        NotificationType `json:"type" db:"type"`
+	ActorUserID *string          `json:"actor_user_id,omitempty" db:"actor_user_id"` // User who triggered it
+	SwipeID     *string          `json:"swipe_id,omitempty" db:"swipe_id"`
+	Message     *string          `json:"message,omitempty" db:"message"` // E.g. the intro note of a super like
+	CreatedAt   time.Time        `json:"created_at" db:"created_at"`
+	ReadAt      *time.Time       `json:"read_at,omitempty" db:"read_at"`
+}
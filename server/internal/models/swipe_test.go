@@ -0,0 +1,25 @@
+package models
+
+import "testing"
+
+func TestSwipeDirection(t *testing.T) {
+	tests := []struct {
+		direction SwipeDirection
+		valid     bool
+		like      bool
+	}{
+		{SwipeLike, true, true},
+		{SwipeSuperLike, true, true},
+		{SwipeDislike, true, false},
+		{"sideways", false, false},
+		{"", false, false},
+	}
+	for _, tt := range tests {
+		if got := tt.direction.Valid(); got != tt.valid {
+			t.Errorf("%q.Valid() = %v, want %v", tt.direction, got, tt.valid)
+		}
+		if got := tt.direction.IsLike(); got != tt.like {
+			t.Errorf("%q.IsLike() = %v, want %v", tt.direction, got, tt.like)
+		}
+	}
+}
@@ -1,19 +1,59 @@
 package models
 
 import (
+	"strings"
 	"time"
 )
 
 // User represents the user profile stored in the database.
 type User struct {
-	ID          string    `json:"id" db:"id"`                       // Assuming UUID or string ID from DB
-	ClerkUserID string    `json:"-" db:"clerk_user_id"`             // Usually not exposed directly in API responses
-	Username    *string   `json:"username,omitempty" db:"username"` // Use pointers for optional fields
-	PictureURL  *string   `json:"pictureUrl,omitempty" db:"picture_url"`
-	Bio         *string   `json:"bio,omitempty" db:"bio"`
-	GitHubURL   *string   `json:"githubUrl,omitempty" db:"github_url"`
-	CreatedAt   time.Time `json:"createdAt" db:"created_at"`
-	UpdatedAt   time.Time `json:"updatedAt" db:"updated_at"`
+	ID          string  `json:"id" db:"id"`                       // Assuming UUID or string ID from DB
+	ClerkUserID string  `json:"-" db:"clerk_user_id"`             // Usually not exposed directly in API responses
+	Username    *string `json:"username,omitempty" db:"username"` // Use pointers for optional fields
+	PictureURL  *string `json:"pictureUrl,omitempty" db:"picture_url"`
+	Bio         *string `json:"bio,omitempty" db:"bio"`
+	GitHubURL   *string `json:"githubUrl,omitempty" db:"github_url"`
+
+	// Fields used to filter the swipe deck
+	PrimaryLanguage       *string    `json:"primaryLanguage,omitempty" db:"primary_language"`              // Most used language across the user's GitHub repos
+	Interests             []string   `json:"interests,omitempty" db:"-"`                                   // Lowercase tags, stored in user_interests
+	TimezoneOffsetMinutes *int       `json:"timezoneOffsetMinutes,omitempty" db:"timezone_offset_minutes"` // Offset from UTC, e.g. -300 for UTC-5
+	GitHubFollowers       int        `json:"githubFollowers" db:"github_followers"`
+	GitHubPublicRepos     int        `json:"githubPublicRepos" db:"github_public_repos"`
+	LastActiveAt          *time.Time `json:"lastActiveAt,omitempty" db:"last_active_at"`
+
+	// Account state, only shown to the user themselves and to admins through UserAccount
+	DeletionScheduledFor *time.Time `json:"-" db:"deletion_scheduled_for"` // Set while the user's account deletion is pending; they are purged at this time
+	Role                 UserRole   `json:"-" db:"role"`                   // See admin.go
+	SuspendedAt          *time.Time `json:"-" db:"suspended_at"`           // Suspended users are locked out of the API
+	SuspensionReason     *string    `json:"-" db:"suspension_reason"`
+
+	CreatedAt time.Time `json:"createdAt" db:"created_at"`
+	UpdatedAt time.Time `json:"updatedAt" db:"updated_at"`
+}
+
+// UserAccount is a user as shown to themselves and to admins: the public profile plus the
+// account state User keeps out of its JSON.
+type UserAccount struct {
+	User
+	DeletionScheduledFor *time.Time `json:"deletionScheduledFor,omitempty"`
+	Role                 UserRole   `json:"role"`
+	SuspendedAt          *time.Time `json:"suspendedAt,omitempty"`
+	SuspensionReason     *string    `json:"suspensionReason,omitempty"`
+}
+
+// NewUserAccount returns the account view of user, or nil if user is nil.
+func NewUserAccount(user *User) *UserAccount {
+	if user == nil {
+		return nil
+	}
+	return &UserAccount{
+		User:                 *user,
+		DeletionScheduledFor: user.DeletionScheduledFor,
+		Role:                 user.Role,
+		SuspendedAt:          user.SuspendedAt,
+		SuspensionReason:     user.SuspensionReason,
+	}
 }
 
 // CreateUserProfileRequest defines the expected payload for creating/updating a user profile.
@@ -23,5 +63,53 @@ type CreateUserProfileRequest struct {
 	PictureURL *string `json:"pictureUrl"`
 	Bio        *string `json:"bio"`
 	GitHubURL  *string `json:"githubUrl"`
+	// Interests replaces the user's interest tags when present; omit to leave them unchanged
+	Interests             []string `json:"interests" binding:"omitempty,max=20,dive,min=1,max=32"`
+	TimezoneOffsetMinutes *int     `json:"timezoneOffsetMinutes" binding:"omitempty,min=-720,max=840"`
 	// ClerkUserID will be added from the authenticated session, not the request body
 }
+
+// GitHubStats summarizes the public GitHub activity stored on a profile.
+type GitHubStats struct {
+	Followers       int
+	PublicRepos     int
+	PrimaryLanguage *string  // Nil if the user has no repositories with a detected language
+	RepoNames       []string // Names of the user's own repositories, used by search
+}
+
+// UserSearchResult is a single hit returned by the developer search endpoint.
+type UserSearchResult struct {
+	User    User    `json:"user"`
+	Snippet string  `json:"snippet"` // HTML-escaped excerpt with matches wrapped in <mark> tags
+	Rank    float64 `json:"rank"`    // Relevance score; higher is better
+}
+
+// BlockedUser is a user the caller has blocked.
+type BlockedUser struct {
+	User      User      `json:"user"`
+	BlockedAt time.Time `json:"blockedAt"`
+}
+
+// NormalizeInterestTags lowercases and trims tags, dropping empty values and duplicates.
+func NormalizeInterestTags(tags []string) []string {
+	seen := make(map[string]struct{}, len(tags))
+	normalized := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		if tag == "" {
+			continue
+		}
+		if _, dup := seen[tag]; dup {
+			continue
+		}
+		seen[tag] = struct{}{}
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// AccountPurgeResult summarizes what happened to a user's data when their account was purged.
+type AccountPurgeResult struct {
+	ConversationsDeleted int // Conversations nobody else was left in
+	MessagesAnonymized   int // Messages kept for the other participants without a sender
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeInterestTags(t *testing.T) {
+	tests := []struct {
+		name string
+		tags []string
+		want []string
+	}{
+		{"nil", nil, []string{}},
+		{"lowercased and trimmed", []string{" Rust ", "WebAssembly"}, []string{"rust", "webassembly"}},
+		{"duplicates keep first position", []string{"go", "Rust", "GO", "rust"}, []string{"go", "rust"}},
+		{"empty dropped", []string{"", "  ", "ml"}, []string{"ml"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeInterestTags(tt.tags); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeInterestTags(%q) = %q, want %q", tt.tags, got, tt.want)
+			}
+		})
+	}
+}
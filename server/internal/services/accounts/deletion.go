@@ -0,0 +1,143 @@
+// Package accounts carries out account deletions once their grace period has passed.
+package accounts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"gin/internal/logging"
+	"gin/internal/models"
+	"gin/internal/services/database"
+)
+
+var logger = logging.For("accounts")
+
+// purgeBatchSize bounds how many accounts one pass purges, so a backlog is worked off
+// over several passes instead of holding up the loop.
+const purgeBatchSize = 50
+
+// ClerkUserDeleter deletes users from Clerk. *services.ClerkService implements it.
+type ClerkUserDeleter interface {
+	DeleteUser(ctx context.Context, clerkUserID string) error
+}
+
+// ProfileCache caches profiles by Clerk user ID. *middleware.UserCache implements it.
+type ProfileCache interface {
+	Invalidate(clerkUserID string)
+}
+
+// Purger periodically purges the accounts whose deletion grace period has ended: the
+// Clerk user first, so the person can no longer sign in, then everything in the database
+// and any export archives on disk.
+type Purger struct {
+	accounts database.AccountRepository
+	exports  database.DataExportRepository
+	clerk    ClerkUserDeleter
+	profiles ProfileCache // Purged profiles are dropped from it so they stop authenticating at once
+	interval time.Duration
+}
+
+// NewPurger creates a Purger that checks for due accounts every interval. clerk may be
+// nil when running without Clerk, in which case only our data is deleted; profiles may
+// be nil when nothing caches profiles.
+func NewPurger(accounts database.AccountRepository, exports database.DataExportRepository, clerk ClerkUserDeleter, profiles ProfileCache, interval time.Duration) *Purger {
+	return &Purger{
+		accounts: accounts,
+		exports:  exports,
+		clerk:    clerk,
+		profiles: profiles,
+		interval: interval,
+	}
+}
+
+// Start purges due accounts now and then on every interval until ctx is done.
+// It blocks, so run it in its own goroutine.
+func (p *Purger) Start(ctx context.Context) {
+	p.PurgeDue(ctx)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Account purge loop stopped.")
+			return
+		case <-ticker.C:
+			p.PurgeDue(ctx)
+		}
+	}
+}
+
+// PurgeDue purges the accounts that are due and returns how many were purged. Accounts
+// that fail are logged and stay scheduled, so the next pass retries them.
+func (p *Purger) PurgeDue(ctx context.Context) int {
+	due, err := p.accounts.GetAccountsDueForDeletion(ctx, time.Now(), purgeBatchSize)
+	if err != nil {
+		logger.WarnContext(ctx, "Loading accounts due for deletion failed", "error", err)
+		return 0
+	}
+
+	purged := 0
+	for _, user := range due {
+		if err := p.PurgeAccount(ctx, user, true); err != nil {
+			logger.WarnContext(ctx, "Purging account failed", "user_id", user.ID, "error", err)
+			continue
+		}
+		purged++
+	}
+	return purged
+}
+
+// PurgeAccount deletes one account everywhere. deleteFromClerk is false when Clerk
+// told us the user is already gone there.
+func (p *Purger) PurgeAccount(ctx context.Context, user models.User, deleteFromClerk bool) error {
+	// Remember the export archives before their rows cascade away with the user
+	exports, err := p.exports.GetDataExports(ctx, user.ID)
+	if err != nil {
+		return fmt.Errorf("listing data exports: %w", err)
+	}
+
+	// Clerk goes first: if the database purge then fails it is simply retried, and Clerk
+	// treats the already deleted user as done. The other order could leave a user who
+	// can still sign in with no data left to retry from.
+	if deleteFromClerk && p.clerk != nil && user.ClerkUserID != "" {
+		if err := p.clerk.DeleteUser(ctx, user.ClerkUserID); err != nil {
+			return err
+		}
+	}
+
+	result, err := p.accounts.PurgeUser(ctx, user.ID)
+	if errors.Is(err, sql.ErrNoRows) {
+		p.forgetProfile(user)
+		return nil // Purged concurrently
+	}
+	if err != nil {
+		return err
+	}
+	p.forgetProfile(user)
+
+	for _, export := range exports {
+		if export.FilePath == nil {
+			continue
+		}
+		if err := os.Remove(*export.FilePath); err != nil && !os.IsNotExist(err) {
+			logger.WarnContext(ctx, "Deleting export archive of purged account failed", "export_id", export.ID, "user_id", user.ID, "error", err)
+		}
+	}
+
+	logger.InfoContext(ctx, "Purged account", "user_id", user.ID,
+		"conversations_deleted", result.ConversationsDeleted, "messages_anonymized", result.MessagesAnonymized)
+	return nil
+}
+
+// forgetProfile drops user's cached profile, so requests carrying a still valid session
+// token stop finding it.
+func (p *Purger) forgetProfile(user models.User) {
+	if p.profiles != nil && user.ClerkUserID != "" {
+		p.profiles.Invalidate(user.ClerkUserID)
+	}
+}
@@ -0,0 +1,105 @@
+package accounts
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gin/api/middleware"
+	"gin/internal/models"
+	"gin/internal/services/database/memory"
+)
+
+// fakeClerk records the Clerk users it deletes and fails for the IDs in failFor.
+type fakeClerk struct {
+	deleted []string
+	failFor map[string]bool
+}
+
+func (c *fakeClerk) DeleteUser(ctx context.Context, clerkUserID string) error {
+	if c.failFor[clerkUserID] {
+		return errors.New("clerk unavailable")
+	}
+	c.deleted = append(c.deleted, clerkUserID)
+	return nil
+}
+
+func TestPurgeDue(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	newUser := func(clerkID string) *models.User {
+		user, err := store.CreateOrUpdateUserProfile(ctx, models.User{ClerkUserID: clerkID})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return user
+	}
+	due, failing, later, kept := newUser("clerk_due"), newUser("clerk_failing"), newUser("clerk_later"), newUser("clerk_kept")
+
+	for _, user := range []*models.User{due, failing} {
+		if _, err := store.ScheduleAccountDeletion(ctx, user.ID, time.Now().Add(-time.Minute)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := store.ScheduleAccountDeletion(ctx, later.ID, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	// The due account's export archive is removed from disk with it
+	archive := filepath.Join(t.TempDir(), "export.zip")
+	if err := os.WriteFile(archive, []byte("zip"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	export, err := store.CreateDataExport(ctx, due.ID, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.CompleteDataExport(ctx, export.ID, archive, "token", 3, time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Everyone is signed in, so their profiles are cached
+	cache := middleware.NewUserCache(store, time.Hour)
+	for _, user := range []*models.User{due, failing, later, kept} {
+		if _, err := cache.Get(ctx, user.ClerkUserID); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	clerk := &fakeClerk{failFor: map[string]bool{"clerk_failing": true}}
+	purger := NewPurger(store, store, clerk, cache, time.Hour)
+	if purged := purger.PurgeDue(ctx); purged != 1 {
+		t.Errorf("PurgeDue = %d, want 1", purged)
+	}
+	if len(clerk.deleted) != 1 || clerk.deleted[0] != "clerk_due" {
+		t.Errorf("Clerk users deleted = %v, want [clerk_due]", clerk.deleted)
+	}
+	if _, err := store.GetUserProfileByID(ctx, due.ID); err == nil {
+		t.Error("due account still exists")
+	}
+	if _, err := os.Stat(archive); !os.IsNotExist(err) {
+		t.Errorf("export archive of the purged account still exists: %v", err)
+	}
+	// A still valid session token of the purged user must not find the cached profile
+	if _, err := cache.Get(ctx, due.ClerkUserID); !errors.Is(err, sql.ErrNoRows) {
+		t.Errorf("cache lookup of the purged account error = %v, want sql.ErrNoRows", err)
+	}
+
+	// An account whose Clerk deletion failed stays scheduled and is retried
+	for _, user := range []*models.User{failing, later, kept} {
+		if _, err := store.GetUserProfileByID(ctx, user.ID); err != nil {
+			t.Errorf("account %s was purged: %v", user.ClerkUserID, err)
+		}
+		if _, err := cache.Get(ctx, user.ClerkUserID); err != nil {
+			t.Errorf("cache lookup of account %s: %v", user.ClerkUserID, err)
+		}
+	}
+	delete(clerk.failFor, "clerk_failing")
+	if purged := purger.PurgeDue(ctx); purged != 1 {
+		t.Errorf("second PurgeDue = %d, want the retried account", purged)
+	}
+}
@@ -0,0 +1,56 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+)
+
+// ErrNoCredentials is returned by an Authenticator when the request carries no
+// credentials at all, as opposed to credentials that fail verification.
+var ErrNoCredentials = errors.New("no credentials")
+
+// Authenticator identifies the user behind a request. Each provider (Clerk, local
+// HMAC tokens, dev headers) implements it; the auth middleware uses whichever is
+// configured through AUTH_PROVIDER.
+type Authenticator interface {
+	// Authenticate returns the Clerk user ID of the caller. It returns ErrNoCredentials
+	// if the request carries none, and another error if they are invalid.
+	Authenticate(r *http.Request) (string, error)
+}
+
+// BearerToken returns the token of an "Authorization: Bearer <token>" header.
+func BearerToken(r *http.Request) (string, error) {
+	header := r.Header.Get("Authorization")
+	if header == "" {
+		return "", ErrNoCredentials
+	}
+	token, ok := strings.CutPrefix(header, "Bearer ")
+	if !ok || token == "" {
+		return "", errors.New("invalid Authorization header format")
+	}
+	return token, nil
+}
+
+// ClerkAuthenticator authenticates Clerk session tokens with a Verifier.
+type ClerkAuthenticator struct {
+	verifier *Verifier
+}
+
+// NewClerkAuthenticator creates an Authenticator for Clerk session tokens.
+func NewClerkAuthenticator(verifier *Verifier) *ClerkAuthenticator {
+	return &ClerkAuthenticator{verifier: verifier}
+}
+
+// Authenticate implements Authenticator.
+func (a *ClerkAuthenticator) Authenticate(r *http.Request) (string, error) {
+	token, err := BearerToken(r)
+	if err != nil {
+		return "", err
+	}
+	claims, err := a.verifier.VerifyToken(r.Context(), token)
+	if err != nil {
+		return "", err
+	}
+	return claims.Subject, nil // Subject holds the Clerk user ID
+}
@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+)
+
+// DevUserHeader names the user a request acts as in dev mode.
+const DevUserHeader = "X-Dev-User"
+
+// seedUserPrefix is the prefix cmd/seed gives the Clerk IDs of seeded users.
+const seedUserPrefix = "seed_user_"
+
+// githubLogin matches GitHub usernames, which the seeded Clerk IDs end with.
+var githubLogin = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})$`)
+
+// DevAuthenticator trusts the X-Dev-User header, with no credentials at all, so the API
+// can be clicked through as any seeded user. The header takes a GitHub login
+// ("torvalds") or a seeded Clerk ID ("seed_user_torvalds"); either way only seed_user_*
+// IDs can be reached, never a real Clerk user. It must never run in production, which
+// the config enforces by refusing AUTH_PROVIDER=dev in release mode.
+type DevAuthenticator struct{}
+
+// NewDevAuthenticator creates the dev mode Authenticator.
+func NewDevAuthenticator() *DevAuthenticator {
+	return &DevAuthenticator{}
+}
+
+// Authenticate implements Authenticator.
+func (a *DevAuthenticator) Authenticate(r *http.Request) (string, error) {
+	user := strings.TrimSpace(r.Header.Get(DevUserHeader))
+	if user == "" {
+		return "", ErrNoCredentials
+	}
+	login := strings.TrimPrefix(user, seedUserPrefix)
+	if !githubLogin.MatchString(login) {
+		return "", fmt.Errorf("%s must be a seeded GitHub login or seed_user_<login>", DevUserHeader)
+	}
+	return seedUserPrefix + login, nil
+}
@@ -0,0 +1,195 @@
+// Package auth identifies the user behind an API request. Clerk session tokens are
+// verified locally against Clerk's published signing keys (the JWKS), so authenticating
+// a request needs no call to Clerk; locally signed tokens and a dev mode header are
+// alternatives for running without Clerk.
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"gin/internal/logging"
+
+	"github.com/clerkinc/clerk-sdk-go/clerk"
+	"github.com/go-jose/go-jose/v3"
+)
+
+var logger = logging.For("auth")
+
+// minUnknownKeyRefresh is how often a token signed with a key we have not seen may
+// trigger a JWKS fetch. Clerk publishes a new key before signing with it, so one fetch
+// picks up a rotation; the limit stops forged kids from hammering Clerk.
+const minUnknownKeyRefresh = time.Minute
+
+// ErrUnknownKey is returned for tokens signed with a key that is not in the JWKS.
+var ErrUnknownKey = errors.New("token signed with an unknown key")
+
+// JWKSSource fetches the current set of signing keys.
+type JWKSSource interface {
+	FetchJWKS(ctx context.Context) (*jose.JSONWebKeySet, error)
+}
+
+// ClerkJWKSSource fetches the instance's keys from the Clerk Backend API.
+type ClerkJWKSSource struct {
+	client clerk.Client
+}
+
+// NewClerkJWKSSource creates a source reading the JWKS through client.
+func NewClerkJWKSSource(client clerk.Client) *ClerkJWKSSource {
+	return &ClerkJWKSSource{client: client}
+}
+
+// FetchJWKS implements JWKSSource.
+func (s *ClerkJWKSSource) FetchJWKS(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	// The SDK call takes no context; its HTTP client has its own timeout
+	jwks, err := s.client.JWKS().ListAll()
+	if err != nil {
+		return nil, fmt.Errorf("fetching JWKS from Clerk: %w", err)
+	}
+	return (*jose.JSONWebKeySet)(jwks), nil
+}
+
+// FileJWKSSource reads the keys from a JWKS JSON file, for running without Clerk
+// (see cmd/devtoken). The file is re-read on every refresh.
+type FileJWKSSource struct {
+	path string
+}
+
+// NewFileJWKSSource creates a source reading the JWKS file at path.
+func NewFileJWKSSource(path string) *FileJWKSSource {
+	return &FileJWKSSource{path: path}
+}
+
+// FetchJWKS implements JWKSSource.
+func (s *FileJWKSSource) FetchJWKS(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return nil, fmt.Errorf("reading JWKS file: %w", err)
+	}
+	var jwks jose.JSONWebKeySet
+	if err := json.Unmarshal(data, &jwks); err != nil {
+		return nil, fmt.Errorf("parsing JWKS file %s: %w", s.path, err)
+	}
+	return &jwks, nil
+}
+
+// KeySet caches the signing keys of a JWKSSource. Keys are refreshed in the background
+// (see Start) and whenever a token names a key that is not cached yet, which is how a
+// key rotation shows up. Keys dropped from the JWKS stop verifying after the next
+// refresh. It is safe for concurrent use.
+type KeySet struct {
+	source JWKSSource
+
+	mu   sync.RWMutex
+	keys map[string]jose.JSONWebKey // By key ID; public keys only
+
+	fetchMu  sync.Mutex // Serializes fetches so concurrent misses share one request
+	lastMiss time.Time  // Latest fetch triggered by an unknown key ID; guarded by fetchMu
+
+	now func() time.Time
+}
+
+// NewKeySet creates an empty KeySet over source. Call Refresh (or Start) to load it.
+func NewKeySet(source JWKSSource) *KeySet {
+	return &KeySet{source: source, keys: map[string]jose.JSONWebKey{}, now: time.Now}
+}
+
+// Refresh replaces the cached keys with the ones source publishes now. On failure the
+// cached keys are kept.
+func (k *KeySet) Refresh(ctx context.Context) error {
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+	return k.refreshLocked(ctx)
+}
+
+// refreshLocked fetches the keys; k.fetchMu must be held.
+func (k *KeySet) refreshLocked(ctx context.Context) error {
+	jwks, err := k.source.FetchJWKS(ctx)
+	if err != nil {
+		return err
+	}
+
+	keys := make(map[string]jose.JSONWebKey, len(jwks.Keys))
+	for _, key := range jwks.Keys {
+		if key.KeyID == "" || (key.Use != "" && key.Use != "sig") {
+			continue
+		}
+		// Never hold on to private material, even if a file source contains some.
+		// Symmetric keys have no public half and are never valid here.
+		public := key.Public()
+		if public.Key == nil {
+			continue
+		}
+		keys[key.KeyID] = public
+	}
+	if len(keys) == 0 {
+		return errors.New("JWKS contains no signing keys")
+	}
+
+	k.mu.Lock()
+	k.keys = keys
+	k.mu.Unlock()
+	return nil
+}
+
+// Key returns the key with the given ID. An ID that is not cached triggers a fetch,
+// at most once per minUnknownKeyRefresh; returns ErrUnknownKey if it is still missing.
+func (k *KeySet) Key(ctx context.Context, kid string) (*jose.JSONWebKey, error) {
+	if key, ok := k.cached(kid); ok {
+		return &key, nil
+	}
+
+	k.fetchMu.Lock()
+	defer k.fetchMu.Unlock()
+
+	// Another request may have fetched while we waited for the lock
+	if key, ok := k.cached(kid); ok {
+		return &key, nil
+	}
+	// Only fetches for unknown keys count towards the limit: the background refresh may
+	// have run just before Clerk published the key this token was signed with
+	now := k.now()
+	if now.Sub(k.lastMiss) < minUnknownKeyRefresh {
+		return nil, ErrUnknownKey
+	}
+	k.lastMiss = now
+
+	if err := k.refreshLocked(ctx); err != nil {
+		logger.WarnContext(ctx, "Refreshing JWKS for unknown key failed", "kid", kid, "error", err)
+		return nil, ErrUnknownKey
+	}
+	if key, ok := k.cached(kid); ok {
+		return &key, nil
+	}
+	return nil, ErrUnknownKey
+}
+
+func (k *KeySet) cached(kid string) (jose.JSONWebKey, bool) {
+	k.mu.RLock()
+	defer k.mu.RUnlock()
+	key, ok := k.keys[kid]
+	return key, ok
+}
+
+// Start refreshes the keys every interval until ctx is done. It blocks, so run it in
+// its own goroutine.
+func (k *KeySet) Start(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("JWKS refresh loop stopped.")
+			return
+		case <-ticker.C:
+			if err := k.Refresh(ctx); err != nil {
+				logger.WarnContext(ctx, "Refreshing JWKS failed, keeping cached keys", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,118 @@
+package auth
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/clerkinc/clerk-sdk-go/clerk"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+const testLocalSecret = "0123456789abcdef0123456789abcdef"
+
+// requestWithAuthorization returns a request carrying the given Authorization header.
+func requestWithAuthorization(authorization string) *http.Request {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	if authorization != "" {
+		r.Header.Set("Authorization", authorization)
+	}
+	return r
+}
+
+func TestNewLocalAuthenticatorShortSecret(t *testing.T) {
+	if _, err := NewLocalAuthenticator(strings.Repeat("x", MinLocalSecretLength-1), 0); err == nil {
+		t.Error("NewLocalAuthenticator accepted a short secret")
+	}
+}
+
+func TestLocalAuthenticator(t *testing.T) {
+	authenticator, err := NewLocalAuthenticator(testLocalSecret, 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	valid, err := SignLocalToken(testLocalSecret, "seed_user_torvalds", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherSecret, err := SignLocalToken(strings.Repeat("y", MinLocalSecretLength), "seed_user_torvalds", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := SignLocalToken(testLocalSecret, "seed_user_torvalds", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	claims := clerk.SessionClaims{Claims: jwt.Claims{
+		Subject: "seed_user_torvalds",
+		Issuer:  "someone-else",
+		Expiry:  jwt.NewNumericDate(now.Add(time.Hour)),
+	}}
+	wrongIssuer := signWith(t, jose.HS256, []byte(testLocalSecret), "", claims)
+	claims.Issuer, claims.Expiry = LocalIssuer, nil
+	noExpiry := signWith(t, jose.HS256, []byte(testLocalSecret), "", claims)
+	claims.Expiry = jwt.NewNumericDate(now.Add(time.Hour))
+	hs512 := signWith(t, jose.HS512, []byte(testLocalSecret), "", claims)
+
+	tests := []struct {
+		name          string
+		authorization string
+		wantUser      string
+		wantErr       error
+	}{
+		{"valid", "Bearer " + valid, "seed_user_torvalds", nil},
+		{"other secret", "Bearer " + otherSecret, "", ErrInvalidToken},
+		{"expired", "Bearer " + expired, "", ErrInvalidToken},
+		{"wrong issuer", "Bearer " + wrongIssuer, "", ErrInvalidToken},
+		{"no expiry", "Bearer " + noExpiry, "", ErrInvalidToken},
+		{"other algorithm", "Bearer " + hs512, "", ErrInvalidToken},
+		{"garbage", "Bearer not-a-token", "", ErrInvalidToken},
+		{"missing", "", "", ErrNoCredentials},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			user, err := authenticator.Authenticate(requestWithAuthorization(tt.authorization))
+			if user != tt.wantUser || !errors.Is(err, tt.wantErr) {
+				t.Errorf("Authenticate = %q, %v; want %q, %v", user, err, tt.wantUser, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestSignLocalTokenRequiresUser(t *testing.T) {
+	if _, err := SignLocalToken(testLocalSecret, "", time.Hour); err == nil {
+		t.Error("SignLocalToken without a user ID succeeded")
+	}
+}
+
+func TestDevAuthenticator(t *testing.T) {
+	tests := []struct {
+		header   string
+		wantUser string
+		wantErr  bool
+	}{
+		{"torvalds", "seed_user_torvalds", false},
+		{" seed_user_torvalds ", "seed_user_torvalds", false},
+		{"user_2abc/../x", "", true},
+		{"-leading-dash", "", true},
+		{strings.Repeat("a", 40), "", true},
+	}
+	authenticator := NewDevAuthenticator()
+	for _, tt := range tests {
+		r := httptest.NewRequest(http.MethodGet, "/", nil)
+		r.Header.Set(DevUserHeader, tt.header)
+		user, err := authenticator.Authenticate(r)
+		if user != tt.wantUser || (err != nil) != tt.wantErr {
+			t.Errorf("Authenticate(%s: %q) = %q, %v; want %q", DevUserHeader, tt.header, user, err, tt.wantUser)
+		}
+	}
+
+	if _, err := authenticator.Authenticate(httptest.NewRequest(http.MethodGet, "/", nil)); !errors.Is(err, ErrNoCredentials) {
+		t.Errorf("Authenticate without the header error = %v, want ErrNoCredentials", err)
+	}
+}
@@ -0,0 +1,89 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/clerkinc/clerk-sdk-go/clerk"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+// ErrInvalidToken is returned for session tokens that fail verification.
+var ErrInvalidToken = errors.New("invalid session token")
+
+// VerifierOptions configures the checks a Verifier makes beyond the signature.
+type VerifierOptions struct {
+	Issuer            string        // Expected "iss" (the Clerk Frontend API URL); empty skips the check
+	AuthorizedParties []string      // Origins allowed in "azp"; empty allows any
+	ClockSkew         time.Duration // Leeway for "exp", "nbf" and "iat" between our clock and Clerk's
+}
+
+// Verifier verifies Clerk session tokens against a KeySet, without calling Clerk.
+type Verifier struct {
+	keys              *KeySet
+	issuer            string
+	authorizedParties map[string]struct{}
+	leeway            time.Duration
+	now               func() time.Time
+}
+
+// NewVerifier creates a Verifier checking signatures against keys.
+func NewVerifier(keys *KeySet, opts VerifierOptions) *Verifier {
+	parties := make(map[string]struct{}, len(opts.AuthorizedParties))
+	for _, party := range opts.AuthorizedParties {
+		parties[party] = struct{}{}
+	}
+	return &Verifier{
+		keys:              keys,
+		issuer:            opts.Issuer,
+		authorizedParties: parties,
+		leeway:            opts.ClockSkew,
+		now:               time.Now,
+	}
+}
+
+// VerifyToken checks the signature and claims of a session token and returns its
+// claims. Every failure wraps ErrInvalidToken.
+func (v *Verifier) VerifyToken(ctx context.Context, token string) (*clerk.SessionClaims, error) {
+	parsed, err := jwt.ParseSigned(token)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	if len(parsed.Headers) != 1 || parsed.Headers[0].KeyID == "" {
+		return nil, fmt.Errorf("%w: missing kid header", ErrInvalidToken)
+	}
+	header := parsed.Headers[0]
+
+	key, err := v.keys.Key(ctx, header.KeyID)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	// The algorithm comes from the key, never from the token, so a token cannot pick a
+	// weaker one (or "none")
+	if key.Algorithm == "" || header.Algorithm != key.Algorithm {
+		return nil, fmt.Errorf("%w: unexpected signing algorithm %q", ErrInvalidToken, header.Algorithm)
+	}
+
+	var claims clerk.SessionClaims
+	if err := parsed.Claims(key.Key, &claims); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	if claims.Subject == "" || claims.Expiry == nil {
+		return nil, fmt.Errorf("%w: missing sub or exp claim", ErrInvalidToken)
+	}
+	if err := claims.Claims.ValidateWithLeeway(jwt.Expected{Issuer: v.issuer, Time: v.now()}, v.leeway); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	// Like Clerk's own SDK, azp is only checked when present: browsers always send an
+	// Origin so their tokens carry one, while native clients' tokens may not
+	if claims.AuthorizedParty != "" && len(v.authorizedParties) > 0 {
+		if _, ok := v.authorizedParties[claims.AuthorizedParty]; !ok {
+			return nil, fmt.Errorf("%w: unauthorized party %q", ErrInvalidToken, claims.AuthorizedParty)
+		}
+	}
+	return &claims, nil
+}
@@ -0,0 +1,255 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/clerkinc/clerk-sdk-go/clerk"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-jose/go-jose/v3/jwt"
+)
+
+const testIssuer = "https://clerk.example.com"
+
+// testNow is the fixed time the verifiers and key sets in these tests run at.
+var testNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// rotatingSource is a JWKSSource whose published keys can be changed, counting fetches.
+type rotatingSource struct {
+	mu      sync.Mutex
+	keys    []*TestKeySet
+	fetches int
+}
+
+func (s *rotatingSource) publish(keys ...*TestKeySet) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.keys = keys
+}
+
+func (s *rotatingSource) fetchCount() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.fetches
+}
+
+func (s *rotatingSource) FetchJWKS(ctx context.Context) (*jose.JSONWebKeySet, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.fetches++
+	jwks := &jose.JSONWebKeySet{}
+	for _, key := range s.keys {
+		jwks.Keys = append(jwks.Keys, key.JWKS().Keys...)
+	}
+	return jwks, nil
+}
+
+func newTestKey(t *testing.T) *TestKeySet {
+	t.Helper()
+	key, err := NewTestKeySet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key
+}
+
+// newTestVerifier creates a Verifier over source at testNow, with the keys loaded.
+func newTestVerifier(t *testing.T, source JWKSSource, opts VerifierOptions) (*Verifier, *KeySet) {
+	t.Helper()
+	keys := NewKeySet(source)
+	keys.now = func() time.Time { return testNow }
+	if err := keys.Refresh(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	verifier := NewVerifier(keys, opts)
+	verifier.now = func() time.Time { return testNow }
+	return verifier, keys
+}
+
+// validClaims returns claims that pass every check of a verifier built with testIssuer.
+func validClaims() clerk.SessionClaims {
+	return clerk.SessionClaims{
+		Claims: jwt.Claims{
+			Subject:   "user_123",
+			Issuer:    testIssuer,
+			IssuedAt:  jwt.NewNumericDate(testNow.Add(-time.Minute)),
+			NotBefore: jwt.NewNumericDate(testNow.Add(-time.Minute)),
+			Expiry:    jwt.NewNumericDate(testNow.Add(time.Minute)),
+		},
+		AuthorizedParty: "https://app.example.com",
+	}
+}
+
+func sign(t *testing.T, key *TestKeySet, claims clerk.SessionClaims) string {
+	t.Helper()
+	token, err := key.Sign(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// signWith signs claims with an arbitrary algorithm and key, putting kid in the header.
+func signWith(t *testing.T, alg jose.SignatureAlgorithm, signingKey any, kid string, claims clerk.SessionClaims) string {
+	t.Helper()
+	opts := (&jose.SignerOptions{}).WithType("JWT")
+	if kid != "" {
+		opts = opts.WithHeader("kid", kid)
+	}
+	signer, err := jose.NewSigner(jose.SigningKey{Algorithm: alg, Key: signingKey}, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := jwt.Signed(signer).Claims(claims).CompactSerialize()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+func TestVerifyToken(t *testing.T) {
+	key := newTestKey(t)
+	other := newTestKey(t)
+	verifier, _ := newTestVerifier(t, key, VerifierOptions{
+		Issuer:            testIssuer,
+		AuthorizedParties: []string{"https://app.example.com"},
+		ClockSkew:         30 * time.Second,
+	})
+
+	withClaims := func(change func(*clerk.SessionClaims)) string {
+		claims := validClaims()
+		change(&claims)
+		return sign(t, key, claims)
+	}
+	// A token whose payload was swapped for another one keeps a signature that no longer matches
+	tampered := func() string {
+		original := strings.Split(sign(t, key, validClaims()), ".")
+		forged := strings.Split(withClaims(func(c *clerk.SessionClaims) { c.Subject = "user_admin" }), ".")
+		return original[0] + "." + forged[1] + "." + original[2]
+	}
+	rsa := key.key.Key
+
+	tests := []struct {
+		name  string
+		token string
+		valid bool
+	}{
+		{"valid", sign(t, key, validClaims()), true},
+		{"tampered payload", tampered(), false},
+		{"signed by another key with our kid", signWith(t, jose.RS256, other.key.Key, key.key.KeyID, validClaims()), false},
+		{"RS512 with the RS256 key", signWith(t, jose.RS512, rsa, key.key.KeyID, validClaims()), false},
+		{"PS256 with the RS256 key", signWith(t, jose.PS256, rsa, key.key.KeyID, validClaims()), false},
+		{"HS256 keyed with a guessable secret", signWith(t, jose.HS256, []byte("0123456789abcdef0123456789abcdef"), key.key.KeyID, validClaims()), false},
+		{"missing kid", signWith(t, jose.RS256, rsa, "", validClaims()), false},
+		{"not a JWT", "not.a.token", false},
+		{"wrong issuer", withClaims(func(c *clerk.SessionClaims) { c.Issuer = "https://clerk.evil.example" }), false},
+		{"unauthorized party", withClaims(func(c *clerk.SessionClaims) { c.AuthorizedParty = "https://evil.example" }), false},
+		{"no party", withClaims(func(c *clerk.SessionClaims) { c.AuthorizedParty = "" }), true},
+		{"missing subject", withClaims(func(c *clerk.SessionClaims) { c.Subject = "" }), false},
+		{"missing expiry", withClaims(func(c *clerk.SessionClaims) { c.Expiry = nil }), false},
+		{"expired within skew", withClaims(func(c *clerk.SessionClaims) { c.Expiry = jwt.NewNumericDate(testNow.Add(-20 * time.Second)) }), true},
+		{"expired beyond skew", withClaims(func(c *clerk.SessionClaims) { c.Expiry = jwt.NewNumericDate(testNow.Add(-40 * time.Second)) }), false},
+		{"not yet valid within skew", withClaims(func(c *clerk.SessionClaims) { c.NotBefore = jwt.NewNumericDate(testNow.Add(20 * time.Second)) }), true},
+		{"not yet valid beyond skew", withClaims(func(c *clerk.SessionClaims) { c.NotBefore = jwt.NewNumericDate(testNow.Add(40 * time.Second)) }), false},
+		{"issued in the future beyond skew", withClaims(func(c *clerk.SessionClaims) { c.IssuedAt = jwt.NewNumericDate(testNow.Add(40 * time.Second)) }), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := verifier.VerifyToken(context.Background(), tt.token)
+			if tt.valid {
+				if err != nil {
+					t.Fatalf("VerifyToken: %v", err)
+				}
+				if claims.Subject != "user_123" {
+					t.Errorf("subject = %q, want user_123", claims.Subject)
+				}
+				return
+			}
+			if !errors.Is(err, ErrInvalidToken) {
+				t.Errorf("VerifyToken error = %v, want ErrInvalidToken", err)
+			}
+		})
+	}
+}
+
+func TestVerifyTokenKeyRotation(t *testing.T) {
+	ctx := context.Background()
+	oldKey, newKey, forgedKey := newTestKey(t), newTestKey(t), newTestKey(t)
+	source := &rotatingSource{}
+	source.publish(oldKey)
+	verifier, keys := newTestVerifier(t, source, VerifierOptions{Issuer: testIssuer})
+	now := testNow
+	keys.now = func() time.Time { return now }
+	verify := func(key *TestKeySet) error {
+		_, err := verifier.VerifyToken(ctx, sign(t, key, validClaims()))
+		return err
+	}
+
+	if err := verify(oldKey); err != nil {
+		t.Fatalf("token signed with the current key: %v", err)
+	}
+	if got := source.fetchCount(); got != 1 {
+		t.Fatalf("fetches after a known kid = %d, want only the initial one", got)
+	}
+
+	// Clerk publishes the new key before signing with it; the first token naming it
+	// refreshes the keys instead of waiting for the background refresh
+	source.publish(oldKey, newKey)
+	if err := verify(newKey); err != nil {
+		t.Fatalf("token signed with the rotated-in key: %v", err)
+	}
+	if got := source.fetchCount(); got != 2 {
+		t.Errorf("fetches after the rotation = %d, want 2", got)
+	}
+
+	// Unknown kids refresh at most once per minUnknownKeyRefresh, so forged ones cannot
+	// hammer Clerk
+	now = now.Add(10 * time.Second)
+	for i := 0; i < 5; i++ {
+		if err := verify(forgedKey); !errors.Is(err, ErrUnknownKey) {
+			t.Fatalf("token with an unknown kid error = %v, want ErrUnknownKey", err)
+		}
+	}
+	if got := source.fetchCount(); got != 2 {
+		t.Errorf("fetches after unknown kids within the throttle = %d, want still 2", got)
+	}
+
+	now = now.Add(minUnknownKeyRefresh)
+	if err := verify(forgedKey); !errors.Is(err, ErrUnknownKey) {
+		t.Fatalf("token with an unknown kid error = %v, want ErrUnknownKey", err)
+	}
+	if got := source.fetchCount(); got != 3 {
+		t.Errorf("fetches after the throttle = %d, want 3", got)
+	}
+
+	// Keys dropped from the JWKS stop verifying after the next refresh
+	source.publish(newKey)
+	if err := keys.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if err := verify(oldKey); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token signed with a retired key error = %v, want ErrInvalidToken", err)
+	}
+	if err := verify(newKey); err != nil {
+		t.Errorf("token signed with the current key: %v", err)
+	}
+}
+
+func TestKeySetKeepsKeysWhenRefreshFails(t *testing.T) {
+	key := newTestKey(t)
+	source := &rotatingSource{}
+	source.publish(key)
+	verifier, keys := newTestVerifier(t, source, VerifierOptions{})
+
+	source.publish() // An empty JWKS is treated as a failed fetch
+	if err := keys.Refresh(context.Background()); err == nil {
+		t.Fatal("Refresh of an empty JWKS succeeded")
+	}
+	if _, err := verifier.VerifyToken(context.Background(), sign(t, key, validClaims())); err != nil {
+		t.Errorf("token after a failed refresh: %v", err)
+	}
+}
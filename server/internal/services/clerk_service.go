@@ -2,13 +2,20 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"log"
+	"net/http"
+	"strings"
+
 	"gin/internal/config"
+	"gin/internal/logging"
+	"gin/internal/models"
 
 	"github.com/clerkinc/clerk-sdk-go/clerk"
 )
 
+var logger = logging.For("services")
+
 // ClerkService handles specific interactions with the Clerk API beyond basic auth middleware.
 type ClerkService struct {
 	client clerk.Client
@@ -18,7 +25,7 @@ type ClerkService struct {
 // NewClerkService creates a new instance of ClerkService.
 func NewClerkService(client clerk.Client, cfg *config.Config) *ClerkService {
 	if client == nil {
-		log.Println("Warning: Clerk client provided to NewClerkService is nil. Service may not function correctly.")
+		logger.Warn("Clerk client provided to NewClerkService is nil. Service may not function correctly.")
 		// Depending on use case, might return nil or an error, or allow a nil client
 	}
 	return &ClerkService{
@@ -37,13 +44,105 @@ func (s *ClerkService) GetClerkUserMetadata(ctx context.Context, userID string)
 
 	user, err := s.client.Users().Read(userID)
 	if err != nil {
-		log.Printf("Error fetching Clerk user data for %s: %v", userID, err)
+		logger.ErrorContext(ctx, "Error fetching Clerk user data", "clerk_user_id", userID, "error", err)
 		// Handle specific Clerk errors if necessary, e.g., user not found
 		return nil, fmt.Errorf("failed to fetch user data from Clerk: %w", err)
 	}
 
-	log.Printf("Successfully fetched Clerk user data for %s", userID)
+	logger.DebugContext(ctx, "Fetched Clerk user data", "clerk_user_id", userID)
 	return user, nil
 }
 
-// Add other Clerk API interaction functions as needed.
\ No newline at end of file
+// DeleteUser deletes the Clerk user with the given ID, ending their sessions and sign-in.
+// A user that no longer exists in Clerk counts as deleted, so retries are safe.
+func (s *ClerkService) DeleteUser(ctx context.Context, userID string) error {
+	if s.client == nil {
+		return fmt.Errorf("Clerk client is not initialized")
+	}
+
+	if _, err := s.client.Users().Delete(userID); err != nil {
+		var clerkErr *clerk.ErrorResponse
+		if errors.As(err, &clerkErr) && clerkErr.Response != nil && clerkErr.Response.StatusCode == http.StatusNotFound {
+			logger.InfoContext(ctx, "Clerk user was already deleted", "clerk_user_id", userID)
+			return nil
+		}
+		logger.ErrorContext(ctx, "Error deleting Clerk user", "clerk_user_id", userID, "error", err)
+		return fmt.Errorf("failed to delete user from Clerk: %w", err)
+	}
+
+	logger.InfoContext(ctx, "Deleted Clerk user", "clerk_user_id", userID)
+	return nil
+}
+
+// ProfileFromClerkUser maps the identity Clerk holds for a user onto a profile: the
+// Clerk username (falling back to the linked GitHub login), the avatar and the GitHub
+// profile URL, plus the role from the public metadata. Fields Clerk has no value for are
+// left empty so an upsert keeps ours.
+func ProfileFromClerkUser(user *clerk.User) models.User {
+	profile := models.User{ClerkUserID: user.ID}
+
+	githubLogin := githubLoginFromExternalAccounts(user.ExternalAccounts)
+	if user.Username != nil && *user.Username != "" {
+		profile.Username = user.Username
+	} else if githubLogin != "" {
+		profile.Username = &githubLogin
+	}
+
+	if user.ImageURL != nil && *user.ImageURL != "" {
+		profile.PictureURL = user.ImageURL
+	} else if user.ProfileImageURL != "" {
+		pictureURL := user.ProfileImageURL
+		profile.PictureURL = &pictureURL
+	}
+
+	if githubLogin != "" {
+		githubURL := "https://github.com/" + githubLogin
+		profile.GitHubURL = &githubURL
+	}
+	profile.Role, _ = RoleFromClerkUser(user)
+	return profile
+}
+
+// RoleFromClerkUser returns the role set under "role" in the Clerk user's public
+// metadata, which only the Clerk dashboard and backend API can write. It returns false
+// if there is none or it is not a known role, in which case the role stored in our
+// database applies.
+func RoleFromClerkUser(user *clerk.User) (models.UserRole, bool) {
+	metadata, ok := user.PublicMetadata.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	value, _ := metadata["role"].(string)
+	role := models.UserRole(strings.ToLower(strings.TrimSpace(value)))
+	if role == "" {
+		return "", false
+	}
+	if !role.Valid() {
+		logger.Warn("Ignoring unknown role in Clerk public metadata", "role", value, "clerk_user_id", user.ID)
+		return "", false
+	}
+	return role, true
+}
+
+// githubLoginFromExternalAccounts returns the username of the GitHub account linked in
+// Clerk, or "" if there is none. The SDK leaves external accounts as raw JSON objects;
+// GitHub ones have provider "oauth_github" (object "github_account" in older payloads).
+func githubLoginFromExternalAccounts(accounts []interface{}) string {
+	for _, account := range accounts {
+		fields, ok := account.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		provider, _ := fields["provider"].(string)
+		object, _ := fields["object"].(string)
+		if provider != "oauth_github" && object != "github_account" {
+			continue
+		}
+		if username, _ := fields["username"].(string); username != "" {
+			return username
+		}
+	}
+	return ""
+}
+
+// Add other Clerk API interaction functions as needed.
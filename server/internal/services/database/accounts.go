@@ -0,0 +1,133 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin/internal/models"
+)
+
+// --- Account Deletion Operations ---
+
+// ErrNoDeletionScheduled is returned when cancelling the deletion of an account that is
+// not scheduled for deletion.
+var ErrNoDeletionScheduled = errors.New("account is not scheduled for deletion")
+
+// ScheduleAccountDeletion schedules userID to be purged at purgeAt and returns the
+// effective time. Asking again keeps the original schedule, so repeated requests
+// cannot push the purge further out.
+// Returns sql.ErrNoRows if the user does not exist.
+func (s *DBService) ScheduleAccountDeletion(ctx context.Context, userID string, purgeAt time.Time) (time.Time, error) {
+	query := `
+		UPDATE users SET deletion_scheduled_for = COALESCE(deletion_scheduled_for, ?)
+		WHERE id = ?
+		RETURNING deletion_scheduled_for`
+
+	var scheduledFor time.Time
+	err := s.DB.Writer().QueryRowContext(ctx, query, purgeAt.UTC(), userID).Scan(&scheduledFor)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.ErrorContext(ctx, "Error scheduling account deletion", "user_id", userID, "error", err)
+		return time.Time{}, fmt.Errorf("scheduling account deletion failed: %w", err)
+	}
+	return scheduledFor, err
+}
+
+// CancelAccountDeletion cancels the pending deletion of userID.
+// Returns ErrNoDeletionScheduled if none is pending.
+func (s *DBService) CancelAccountDeletion(ctx context.Context, userID string) error {
+	query := `UPDATE users SET deletion_scheduled_for = NULL WHERE id = ? AND deletion_scheduled_for IS NOT NULL`
+	res, err := s.DB.ExecContext(ctx, query, userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error cancelling account deletion", "user_id", userID, "error", err)
+		return fmt.Errorf("cancelling account deletion failed: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNoDeletionScheduled
+	}
+	return nil
+}
+
+// GetAccountsDueForDeletion returns up to limit users whose grace period ended by now,
+// longest overdue first.
+func (s *DBService) GetAccountsDueForDeletion(ctx context.Context, now time.Time, limit int) ([]models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE deletion_scheduled_for IS NOT NULL AND deletion_scheduled_for <= ?
+		ORDER BY deletion_scheduled_for
+		LIMIT ?`
+
+	rows, err := s.DB.QueryContext(ctx, query, now.UTC(), limit)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying accounts due for deletion", "error", err)
+		return nil, fmt.Errorf("querying accounts due for deletion failed: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning user row failed: %w", err)
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating user rows failed: %w", err)
+	}
+	return users, nil
+}
+
+// PurgeUser permanently deletes userID. Swipes, participants, interests, notifications
+// and export jobs go with the user through ON DELETE CASCADE. Conversations nobody else
+// is left in are deleted with their messages; in the others, the user's messages stay
+// for the remaining participants with the sender cleared (ON DELETE SET NULL).
+// Returns sql.ErrNoRows if the user does not exist.
+func (s *DBService) PurgeUser(ctx context.Context, userID string) (*models.AccountPurgeResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	var result models.AccountPurgeResult
+	res, err := tx.ExecContext(ctx, `
+		DELETE FROM conversations
+		WHERE id IN (
+			SELECT cp.conversation_id FROM conversation_participants cp
+			WHERE cp.user_id = ?
+			  AND NOT EXISTS (
+				SELECT 1 FROM conversation_participants other
+				WHERE other.conversation_id = cp.conversation_id AND other.user_id != ?
+			  )
+		)`, userID, userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error deleting conversations of user", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("deleting conversations failed: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil {
+		result.ConversationsDeleted = int(n)
+	}
+
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM messages WHERE sender_user_id = ?`, userID).
+		Scan(&result.MessagesAnonymized); err != nil {
+		return nil, fmt.Errorf("counting messages failed: %w", err)
+	}
+
+	res, err = tx.ExecContext(ctx, `DELETE FROM users WHERE id = ?`, userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error deleting user", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("deleting user failed: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return &result, nil
+}
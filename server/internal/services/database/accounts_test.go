@@ -0,0 +1,126 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"gin/internal/models"
+)
+
+func TestScheduleAndCancelAccountDeletion(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "")
+		bob := createUser(t, s, "bob", "")
+		now := time.Now().UTC().Truncate(time.Second)
+
+		scheduled, err := s.ScheduleAccountDeletion(ctx, alice.ID, now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("ScheduleAccountDeletion: %v", err)
+		}
+		// Asking again does not push the purge out
+		again, err := s.ScheduleAccountDeletion(ctx, alice.ID, now.Add(48*time.Hour))
+		if err != nil || !again.Equal(scheduled) {
+			t.Errorf("second ScheduleAccountDeletion = %s, %v; want the original %s", again, err, scheduled)
+		}
+		if _, err := s.ScheduleAccountDeletion(ctx, "00000000-0000-0000-0000-000000000000", now); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("ScheduleAccountDeletion(unknown user) error = %v, want sql.ErrNoRows", err)
+		}
+
+		// Accounts on their way out are no longer dealt
+		candidates, err := s.GetSwipeCandidates(ctx, bob.ID, models.CardFilters{}, 10)
+		if err != nil {
+			t.Fatalf("GetSwipeCandidates: %v", err)
+		}
+		if len(candidates) != 0 {
+			t.Errorf("candidates = %d profiles, want none", len(candidates))
+		}
+
+		if due, err := s.GetAccountsDueForDeletion(ctx, now, 10); err != nil || len(due) != 0 {
+			t.Errorf("GetAccountsDueForDeletion before the grace period ends = %d users, %v; want none", len(due), err)
+		}
+		due, err := s.GetAccountsDueForDeletion(ctx, now.Add(2*time.Hour), 10)
+		if err != nil || len(due) != 1 || due[0].ID != alice.ID || due[0].DeletionScheduledFor == nil {
+			t.Errorf("GetAccountsDueForDeletion after the grace period = %+v, %v; want alice", due, err)
+		}
+
+		if err := s.CancelAccountDeletion(ctx, alice.ID); err != nil {
+			t.Fatalf("CancelAccountDeletion: %v", err)
+		}
+		if err := s.CancelAccountDeletion(ctx, alice.ID); !errors.Is(err, ErrNoDeletionScheduled) {
+			t.Errorf("second CancelAccountDeletion error = %v, want ErrNoDeletionScheduled", err)
+		}
+		if due, _ := s.GetAccountsDueForDeletion(ctx, now.Add(2*time.Hour), 10); len(due) != 0 {
+			t.Errorf("%d accounts due after cancelling, want none", len(due))
+		}
+	})
+}
+
+func TestPurgeUser(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "")
+		bob := createUser(t, s, "bob", "")
+
+		for _, swipe := range []models.Swipe{
+			{SwiperID: alice.ID, SwipedID: bob.ID, Direction: models.SwipeLike},
+			{SwiperID: bob.ID, SwipedID: alice.ID, Direction: models.SwipeLike},
+		} {
+			if _, err := s.CreateSwipe(ctx, swipe, 0); err != nil {
+				t.Fatalf("CreateSwipe: %v", err)
+			}
+		}
+		// A conversation alice has to herself is deleted with her
+		var soloID string
+		if err := s.DB.Writer().QueryRowContext(ctx, `INSERT INTO conversations DEFAULT VALUES RETURNING id`).Scan(&soloID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.DB.ExecContext(ctx, `INSERT INTO conversation_participants (conversation_id, user_id) VALUES (?, ?)`, soloID, alice.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		conversations, err := s.GetConversations(ctx, bob.ID)
+		if err != nil || len(conversations) != 1 {
+			t.Fatalf("GetConversations = %v, %v; want the match's conversation", conversations, err)
+		}
+		shared := conversations[0].ID
+		_, err = s.DB.ExecContext(ctx, `INSERT INTO messages (conversation_id, sender_user_id, content) VALUES (?, ?, ?), (?, ?, ?), (?, ?, ?)`,
+			shared, alice.ID, "Hi Bob", shared, bob.ID, "Hi Alice", soloID, alice.ID, "Note to self")
+		if err != nil {
+			t.Fatalf("inserting messages: %v", err)
+		}
+
+		result, err := s.PurgeUser(ctx, alice.ID)
+		if err != nil {
+			t.Fatalf("PurgeUser: %v", err)
+		}
+		if result.ConversationsDeleted != 1 || result.MessagesAnonymized != 1 {
+			t.Errorf("PurgeUser = %+v, want 1 conversation deleted and 1 message anonymized", result)
+		}
+		if _, err := s.GetUserProfileByID(ctx, alice.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetUserProfileByID after purge error = %v, want sql.ErrNoRows", err)
+		}
+
+		// Bob keeps the conversation and alice's message, without its sender
+		var anonymized int
+		err = s.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM messages WHERE conversation_id = ? AND sender_user_id IS NULL AND content = ?`,
+			shared, "Hi Bob").Scan(&anonymized)
+		if err != nil || anonymized != 1 {
+			t.Errorf("anonymized messages in the shared conversation = %d, %v; want 1", anonymized, err)
+		}
+		if n := countConversations(t, s, bob.ID); n != 1 {
+			t.Errorf("bob is in %d conversations, want the shared one", n)
+		}
+		var swipes int
+		if err := s.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM swipes`).Scan(&swipes); err != nil || swipes != 0 {
+			t.Errorf("%d swipes left, %v; want none in either direction", swipes, err)
+		}
+
+		if _, err := s.PurgeUser(ctx, alice.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("second PurgeUser error = %v, want sql.ErrNoRows", err)
+		}
+	})
+}
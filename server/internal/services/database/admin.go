@@ -0,0 +1,298 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin/internal/models"
+)
+
+// --- Administration Operations ---
+
+var (
+	// ErrNotSuspended is returned when lifting the suspension of a user who is not suspended.
+	ErrNotSuspended = errors.New("user is not suspended")
+	// ErrReportNotOpen is returned when resolving a report that was already resolved or dismissed.
+	ErrReportNotOpen = errors.New("report is not open")
+)
+
+// reportColumns lists the user_reports columns in the order expected by scanReport.
+const reportColumns = `id, reporter_user_id, reported_user_id, reason, details, status, created_at, resolved_at, resolved_by_user_id, resolution_note, resolution_actions`
+
+// scanReport scans a row selected with reportColumns into a models.Report.
+func scanReport(row rowScanner) (*models.Report, error) {
+	var report models.Report
+	var actions sql.NullString
+	err := row.Scan(
+		&report.ID,
+		&report.ReporterUserID,
+		&report.ReportedUserID,
+		&report.Reason,
+		&report.Details,
+		&report.Status,
+		&report.CreatedAt,
+		&report.ResolvedAt,
+		&report.ResolvedByUserID,
+		&report.ResolutionNote,
+		&actions,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if actions.String != "" {
+		for _, action := range strings.Split(actions.String, ",") {
+			report.ResolutionActions = append(report.ResolutionActions, models.ReportAction(action))
+		}
+	}
+	return &report, nil
+}
+
+// escapeLike escapes the LIKE wildcards in s for use with ESCAPE '\'.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(s)
+}
+
+// SetUserRole changes the role of userID.
+// Returns sql.ErrNoRows if the user does not exist.
+func (s *DBService) SetUserRole(ctx context.Context, userID string, role models.UserRole) error {
+	if !role.Valid() {
+		return fmt.Errorf("unknown role %q", role)
+	}
+	res, err := s.DB.ExecContext(ctx, `UPDATE users SET role = ? WHERE id = ?`, role, userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error setting role", "user_id", userID, "role", role, "error", err)
+		return fmt.Errorf("setting user role failed: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// ListUsers returns users matching filter, newest first. Unlike decks and search, it
+// includes suspended users and those pending deletion.
+func (s *DBService) ListUsers(ctx context.Context, filter models.AdminUserFilter, limit, offset int) ([]models.User, error) {
+	conditions := []string{}
+	args := []any{}
+
+	if q := strings.TrimSpace(filter.Query); q != "" {
+		pattern := "%" + escapeLike(strings.ToLower(q)) + "%"
+		conditions = append(conditions, `(LOWER(username) LIKE ? ESCAPE '\' OR LOWER(github_url) LIKE ? ESCAPE '\' OR id = ? OR clerk_user_id = ?)`)
+		args = append(args, pattern, pattern, q, q)
+	}
+	if filter.Role != "" {
+		conditions = append(conditions, "role = ?")
+		args = append(args, filter.Role)
+	}
+	if filter.Suspended != nil {
+		if *filter.Suspended {
+			conditions = append(conditions, "suspended_at IS NOT NULL")
+		} else {
+			conditions = append(conditions, "suspended_at IS NULL")
+		}
+	}
+
+	query := `SELECT ` + userColumns + ` FROM users`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY created_at DESC` + s.DB.Dialect.insertionTiebreak("") + ` LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error listing users", "error", err)
+		return nil, fmt.Errorf("listing users failed: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning user row failed: %w", err)
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating user rows failed: %w", err)
+	}
+	rows.Close()
+
+	if err := attachInterests(ctx, s.DB, users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// SuspendUser suspends userID for reason. Suspending a suspended user updates the reason
+// but keeps the original suspension time.
+// Returns sql.ErrNoRows if the user does not exist.
+func (s *DBService) SuspendUser(ctx context.Context, userID, reason string) error {
+	query := `
+		UPDATE users SET suspended_at = COALESCE(suspended_at, CURRENT_TIMESTAMP), suspension_reason = ?
+		WHERE id = ?`
+	res, err := s.DB.ExecContext(ctx, query, reason, userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error suspending user", "user_id", userID, "error", err)
+		return fmt.Errorf("suspending user failed: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// UnsuspendUser lifts the suspension of userID.
+// Returns ErrNotSuspended if the user is not suspended or does not exist.
+func (s *DBService) UnsuspendUser(ctx context.Context, userID string) error {
+	query := `UPDATE users SET suspended_at = NULL, suspension_reason = NULL WHERE id = ? AND suspended_at IS NOT NULL`
+	res, err := s.DB.ExecContext(ctx, query, userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error lifting suspension", "user_id", userID, "error", err)
+		return fmt.Errorf("lifting suspension failed: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return ErrNotSuspended
+	}
+	return nil
+}
+
+// GetReports returns reports with status (any status if empty), oldest first so the
+// moderation queue is worked in order.
+func (s *DBService) GetReports(ctx context.Context, status models.ReportStatus, limit, offset int) ([]models.Report, error) {
+	query := `SELECT ` + reportColumns + ` FROM user_reports`
+	args := []any{}
+	if status != "" {
+		query += ` WHERE status = ?`
+		args = append(args, status)
+	}
+	query += ` ORDER BY created_at, id LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying reports", "error", err)
+		return nil, fmt.Errorf("querying reports failed: %w", err)
+	}
+	defer rows.Close()
+
+	reports := []models.Report{}
+	for rows.Next() {
+		report, err := scanReport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning report row failed: %w", err)
+		}
+		reports = append(reports, *report)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating report rows failed: %w", err)
+	}
+	return reports, nil
+}
+
+// GetReport returns the report with reportID.
+// Returns sql.ErrNoRows if there is none.
+func (s *DBService) GetReport(ctx context.Context, reportID string) (*models.Report, error) {
+	report, err := scanReport(s.DB.QueryRowContext(ctx, `SELECT `+reportColumns+` FROM user_reports WHERE id = ?`, reportID))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.ErrorContext(ctx, "Error querying report", "report_id", reportID, "error", err)
+		return nil, fmt.Errorf("querying report failed: %w", err)
+	}
+	return report, err
+}
+
+// ResolveReport closes the open report reportID as resolution describes on behalf of
+// moderatorID, and returns it. It only records the actions; callers take them first.
+// Returns sql.ErrNoRows if there is no such report and ErrReportNotOpen if it was closed already.
+func (s *DBService) ResolveReport(ctx context.Context, reportID, moderatorID string, resolution models.ReportResolution) (*models.Report, error) {
+	if resolution.Status != models.ReportResolved && resolution.Status != models.ReportDismissed {
+		return nil, fmt.Errorf("cannot resolve a report as %q", resolution.Status)
+	}
+	var actions *string
+	if len(resolution.Actions) > 0 {
+		names := make([]string, len(resolution.Actions))
+		for i, action := range resolution.Actions {
+			names[i] = string(action)
+		}
+		joined := strings.Join(names, ",")
+		actions = &joined
+	}
+	query := `
+		UPDATE user_reports
+		SET status = ?, resolved_at = CURRENT_TIMESTAMP, resolved_by_user_id = ?, resolution_note = ?, resolution_actions = ?
+		WHERE id = ? AND status = 'open'
+		RETURNING ` + reportColumns
+
+	report, err := scanReport(s.DB.Writer().QueryRowContext(ctx, query,
+		resolution.Status, moderatorID, resolution.Note, actions, reportID))
+	if errors.Is(err, sql.ErrNoRows) {
+		// Either the report does not exist or it is no longer open
+		if _, err := s.GetReport(ctx, reportID); err != nil {
+			return nil, err
+		}
+		return nil, ErrReportNotOpen
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error resolving report", "report_id", reportID, "error", err)
+		return nil, fmt.Errorf("resolving report failed: %w", err)
+	}
+	return report, nil
+}
+
+// GetSystemStats counts users, activity and moderation work for the admin dashboard.
+// "Recent" means within the 7 days before now.
+func (s *DBService) GetSystemStats(ctx context.Context, now time.Time) (*models.SystemStats, error) {
+	weekAgo := now.UTC().AddDate(0, 0, -7)
+	stats := models.SystemStats{UsersByRole: map[models.UserRole]int{}}
+
+	query := `
+		SELECT
+			(SELECT COUNT(*) FROM users),
+			(SELECT COUNT(*) FROM users WHERE suspended_at IS NOT NULL),
+			(SELECT COUNT(*) FROM users WHERE deletion_scheduled_for IS NOT NULL),
+			(SELECT COUNT(*) FROM users WHERE last_active_at >= ?),
+			(SELECT COUNT(*) FROM users WHERE created_at >= ?),
+			(SELECT COUNT(*) FROM swipes),
+			(SELECT COUNT(*) FROM conversations),
+			(SELECT COUNT(*) FROM messages),
+			(SELECT COUNT(*) FROM user_reports WHERE status = 'open')`
+	err := s.DB.QueryRowContext(ctx, query, weekAgo, weekAgo).Scan(
+		&stats.Users,
+		&stats.SuspendedUsers,
+		&stats.PendingDeletions,
+		&stats.ActiveUsersLast7Days,
+		&stats.NewUsersLast7Days,
+		&stats.Swipes,
+		&stats.Matches,
+		&stats.Messages,
+		&stats.OpenReports,
+	)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying system stats", "error", err)
+		return nil, fmt.Errorf("querying system stats failed: %w", err)
+	}
+
+	rows, err := s.DB.QueryContext(ctx, `SELECT role, COUNT(*) FROM users GROUP BY role`)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error counting users by role", "error", err)
+		return nil, fmt.Errorf("counting users by role failed: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var role models.UserRole
+		var count int
+		if err := rows.Scan(&role, &count); err != nil {
+			return nil, fmt.Errorf("scanning role count failed: %w", err)
+		}
+		stats.UsersByRole[role] = count
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating role counts failed: %w", err)
+	}
+	return &stats, nil
+}
@@ -0,0 +1,169 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"gin/internal/models"
+)
+
+func TestSuspendUser(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "Go developer")
+		bob := createUser(t, s, "bob", "Go developer")
+
+		if err := s.SuspendUser(ctx, bob.ID, "spam"); err != nil {
+			t.Fatalf("SuspendUser: %v", err)
+		}
+		suspended, err := s.GetUserProfileByID(ctx, bob.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if suspended.SuspendedAt == nil || suspended.SuspensionReason == nil || *suspended.SuspensionReason != "spam" {
+			t.Errorf("suspended profile = %+v", suspended)
+		}
+		// A second suspension updates the reason but keeps the time
+		if err := s.SuspendUser(ctx, bob.ID, "harassment"); err != nil {
+			t.Fatalf("second SuspendUser: %v", err)
+		}
+		if again, _ := s.GetUserProfileByID(ctx, bob.ID); !again.SuspendedAt.Equal(*suspended.SuspendedAt) || *again.SuspensionReason != "harassment" {
+			t.Errorf("after second suspension: suspended at %v for %q", again.SuspendedAt, *again.SuspensionReason)
+		}
+
+		// Suspended users are hidden from decks and search
+		if candidates, err := s.GetSwipeCandidates(ctx, alice.ID, models.CardFilters{}, 10); err != nil || len(candidates) != 0 {
+			t.Errorf("GetSwipeCandidates = %d profiles, %v; want none", len(candidates), err)
+		}
+		if results, err := s.SearchUsers(ctx, alice.ID, "bob", 10, 0); err != nil || len(results) != 0 {
+			t.Errorf("SearchUsers = %d results, %v; want none", len(results), err)
+		}
+
+		if err := s.UnsuspendUser(ctx, bob.ID); err != nil {
+			t.Fatalf("UnsuspendUser: %v", err)
+		}
+		if err := s.UnsuspendUser(ctx, bob.ID); !errors.Is(err, ErrNotSuspended) {
+			t.Errorf("second UnsuspendUser error = %v, want ErrNotSuspended", err)
+		}
+		if err := s.SuspendUser(ctx, "00000000-0000-0000-0000-000000000000", "spam"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("SuspendUser(unknown user) error = %v, want sql.ErrNoRows", err)
+		}
+	})
+}
+
+func TestListUsers(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "")
+		bob := createUser(t, s, "bob_the_builder", "")
+		carol := createUser(t, s, "carol", "")
+
+		if err := s.SetUserRole(ctx, alice.ID, models.RoleModerator); err != nil {
+			t.Fatalf("SetUserRole: %v", err)
+		}
+		if err := s.SetUserRole(ctx, alice.ID, "owner"); err == nil {
+			t.Error("SetUserRole accepted an unknown role")
+		}
+		if err := s.SetUserRole(ctx, "00000000-0000-0000-0000-000000000000", models.RoleAdmin); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("SetUserRole(unknown user) error = %v, want sql.ErrNoRows", err)
+		}
+		if err := s.SuspendUser(ctx, carol.ID, "spam"); err != nil {
+			t.Fatal(err)
+		}
+
+		suspended, active := true, false
+		tests := []struct {
+			name   string
+			filter models.AdminUserFilter
+			want   []string
+		}{
+			{"everyone, newest first", models.AdminUserFilter{}, []string{carol.ID, bob.ID, alice.ID}},
+			{"username substring", models.AdminUserFilter{Query: "BUILD"}, []string{bob.ID}},
+			{"LIKE wildcards are literal", models.AdminUserFilter{Query: "_the_"}, []string{bob.ID}},
+			{"exact ID", models.AdminUserFilter{Query: alice.ID}, []string{alice.ID}},
+			{"Clerk ID", models.AdminUserFilter{Query: "clerk_carol"}, []string{carol.ID}},
+			{"role", models.AdminUserFilter{Role: models.RoleModerator}, []string{alice.ID}},
+			{"suspended", models.AdminUserFilter{Suspended: &suspended}, []string{carol.ID}},
+			{"active", models.AdminUserFilter{Suspended: &active}, []string{bob.ID, alice.ID}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				users, err := s.ListUsers(ctx, tt.filter, 10, 0)
+				if err != nil {
+					t.Fatalf("ListUsers: %v", err)
+				}
+				var got []string
+				for _, user := range users {
+					got = append(got, user.ID)
+				}
+				if len(got) != len(tt.want) {
+					t.Fatalf("ListUsers = %v, want %v", got, tt.want)
+				}
+				for i := range got {
+					if got[i] != tt.want[i] {
+						t.Fatalf("ListUsers = %v, want %v", got, tt.want)
+					}
+				}
+			})
+		}
+	})
+}
+
+func TestResolveReport(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "")
+		bob := createUser(t, s, "bob", "")
+		moderator := createUser(t, s, "mod", "")
+
+		var reportID string
+		err := s.DB.Writer().QueryRowContext(ctx,
+			`INSERT INTO user_reports (reporter_user_id, reported_user_id, reason) VALUES (?, ?, ?) RETURNING id`,
+			alice.ID, bob.ID, "spam",
+		).Scan(&reportID)
+		if err != nil {
+			t.Fatalf("inserting report: %v", err)
+		}
+
+		open, err := s.GetReports(ctx, models.ReportOpen, 10, 0)
+		if err != nil || len(open) != 1 || open[0].ID != reportID {
+			t.Fatalf("GetReports(open) = %+v, %v; want the report", open, err)
+		}
+		if stats, err := s.GetSystemStats(ctx, time.Now()); err != nil || stats.OpenReports != 1 || stats.Users != 3 || stats.UsersByRole[models.RoleUser] != 3 {
+			t.Errorf("GetSystemStats = %+v, %v", stats, err)
+		}
+
+		if _, err := s.ResolveReport(ctx, reportID, moderator.ID, models.ReportResolution{Status: models.ReportOpen}); err == nil {
+			t.Error("ResolveReport accepted the open status")
+		}
+		note := "warned the user"
+		report, err := s.ResolveReport(ctx, reportID, moderator.ID, models.ReportResolution{
+			Status:  models.ReportResolved,
+			Actions: []models.ReportAction{models.ReportActionSuspendUser},
+			Note:    &note,
+		})
+		if err != nil {
+			t.Fatalf("ResolveReport: %v", err)
+		}
+		if report.Status != models.ReportResolved || report.ResolvedAt == nil || report.ResolvedByUserID == nil ||
+			*report.ResolvedByUserID != moderator.ID || report.ResolutionNote == nil || *report.ResolutionNote != note ||
+			len(report.ResolutionActions) != 1 || report.ResolutionActions[0] != models.ReportActionSuspendUser {
+			t.Errorf("resolved report = %+v", report)
+		}
+		if _, err := s.ResolveReport(ctx, reportID, moderator.ID, models.ReportResolution{Status: models.ReportDismissed}); !errors.Is(err, ErrReportNotOpen) {
+			t.Errorf("resolving a closed report error = %v, want ErrReportNotOpen", err)
+		}
+		if _, err := s.ResolveReport(ctx, "00000000-0000-0000-0000-000000000000", moderator.ID, models.ReportResolution{Status: models.ReportDismissed}); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("resolving an unknown report error = %v, want sql.ErrNoRows", err)
+		}
+		if open, _ := s.GetReports(ctx, models.ReportOpen, 10, 0); len(open) != 0 {
+			t.Errorf("%d open reports after resolving, want none", len(open))
+		}
+		if all, _ := s.GetReports(ctx, "", 10, 0); len(all) != 1 {
+			t.Errorf("%d reports of any status, want 1", len(all))
+		}
+	})
+}
@@ -0,0 +1,275 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/mattn/go-sqlite3"
+)
+
+// Snapshots are named backupFilePrefix + UTC timestamp + ".db" so that they sort by age.
+const (
+	backupFilePrefix     = "devmatch-"
+	backupTimestampStyle = "20060102T150405Z"
+)
+
+// ErrBackupUnsupported is returned by the backup functions on databases other than SQLite.
+var ErrBackupUnsupported = errors.New("backups are only supported for SQLite; use pg_dump for PostgreSQL")
+
+// Backup is a snapshot file in a backup directory.
+type Backup struct {
+	Path      string
+	CreatedAt time.Time // Parsed from the file name
+	Size      int64
+}
+
+// BackupTo copies the live database to a new SQLite file at destPath using SQLite's
+// online backup API. It reads through the read pool, so in WAL mode writers are not
+// blocked while the copy runs.
+func (db *DB) BackupTo(ctx context.Context, destPath string) error {
+	if db.Dialect != DialectSQLite {
+		return ErrBackupUnsupported
+	}
+	if _, err := os.Stat(destPath); err == nil {
+		return fmt.Errorf("backup destination %s already exists", destPath)
+	}
+
+	dest, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=rwc", destPath))
+	if err != nil {
+		return fmt.Errorf("failed to open backup destination: %w", err)
+	}
+	defer dest.Close()
+
+	if err := copySQLite(ctx, dest, db.DB); err != nil {
+		os.Remove(destPath)
+		return err
+	}
+	// The copy inherits WAL mode from the live database; switch it back to a rollback
+	// journal so the snapshot is one self-contained file that can be opened read-only.
+	if _, err := dest.ExecContext(ctx, `PRAGMA journal_mode=DELETE`); err != nil {
+		os.Remove(destPath)
+		return fmt.Errorf("failed to finalize backup: %w", err)
+	}
+	return nil
+}
+
+// RestoreFrom replaces the whole content of the database with the snapshot at
+// snapshotPath, after checking the snapshot's integrity. The copy goes through the write
+// connection so the WAL stays consistent; nothing else should be using the database.
+func (db *DB) RestoreFrom(ctx context.Context, snapshotPath string) error {
+	if db.Dialect != DialectSQLite {
+		return ErrBackupUnsupported
+	}
+	if err := CheckIntegrity(ctx, snapshotPath); err != nil {
+		return fmt.Errorf("refusing to restore from %s: %w", snapshotPath, err)
+	}
+
+	src, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", snapshotPath))
+	if err != nil {
+		return fmt.Errorf("failed to open snapshot: %w", err)
+	}
+	defer src.Close()
+
+	return copySQLite(ctx, db.writePool(), src)
+}
+
+// copySQLite copies the main database of src into dest with the online backup API.
+func copySQLite(ctx context.Context, dest, src *sql.DB) error {
+	destConn, err := dest.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get destination connection: %w", err)
+	}
+	defer destConn.Close()
+	srcConn, err := src.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get source connection: %w", err)
+	}
+	defer srcConn.Close()
+
+	return destConn.Raw(func(destDriverConn any) error {
+		return srcConn.Raw(func(srcDriverConn any) error {
+			destSQLite, ok := destDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected destination driver connection %T", destDriverConn)
+			}
+			srcSQLite, ok := srcDriverConn.(*sqlite3.SQLiteConn)
+			if !ok {
+				return fmt.Errorf("unexpected source driver connection %T", srcDriverConn)
+			}
+
+			backup, err := destSQLite.Backup("main", srcSQLite, "main")
+			if err != nil {
+				return fmt.Errorf("failed to start backup: %w", err)
+			}
+			// Copy everything in one step: a single read transaction gives a consistent
+			// snapshot, and under WAL it does not hold up writers.
+			done, err := backup.Step(-1)
+			if err != nil {
+				backup.Finish()
+				return fmt.Errorf("backup step failed: %w", err)
+			}
+			if !done {
+				backup.Finish()
+				return errors.New("backup did not complete")
+			}
+			if err := backup.Finish(); err != nil {
+				return fmt.Errorf("failed to finish backup: %w", err)
+			}
+			return nil
+		})
+	})
+}
+
+// CheckIntegrity runs PRAGMA integrity_check on the SQLite file at path, opened read-only,
+// and returns an error listing the problems it reports.
+func CheckIntegrity(ctx context.Context, path string) error {
+	if _, err := os.Stat(path); err != nil {
+		return fmt.Errorf("cannot check integrity: %w", err)
+	}
+	db, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=ro", path))
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %w", path, err)
+	}
+	defer db.Close()
+
+	rows, err := db.QueryContext(ctx, `PRAGMA integrity_check`)
+	if err != nil {
+		return fmt.Errorf("integrity check of %s failed: %w", path, err)
+	}
+	defer rows.Close()
+
+	var problems []string
+	for rows.Next() {
+		var line string
+		if err := rows.Scan(&line); err != nil {
+			return fmt.Errorf("reading integrity check result failed: %w", err)
+		}
+		if line != "ok" {
+			problems = append(problems, line)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("integrity check of %s failed: %w", path, err)
+	}
+	if len(problems) > 0 {
+		return fmt.Errorf("integrity check of %s found %d problem(s): %s", path, len(problems), strings.Join(problems, "; "))
+	}
+	return nil
+}
+
+// CreateBackup snapshots the database into a new timestamped file in dir (created if
+// needed) and verifies the snapshot. A snapshot that fails verification is deleted.
+func (db *DB) CreateBackup(ctx context.Context, dir string) (*Backup, error) {
+	if db.Dialect != DialectSQLite {
+		return nil, ErrBackupUnsupported
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create backup directory: %w", err)
+	}
+
+	createdAt := time.Now().UTC().Truncate(time.Second)
+	path := filepath.Join(dir, backupFilePrefix+createdAt.Format(backupTimestampStyle)+".db")
+	if err := db.BackupTo(ctx, path); err != nil {
+		return nil, err
+	}
+	if err := CheckIntegrity(ctx, path); err != nil {
+		os.Remove(path)
+		return nil, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat backup: %w", err)
+	}
+	return &Backup{Path: path, CreatedAt: createdAt, Size: info.Size()}, nil
+}
+
+// ListBackups returns the snapshots in dir, newest first. Other files are ignored.
+// A missing directory has no backups.
+func ListBackups(dir string) ([]Backup, error) {
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return []Backup{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read backup directory: %w", err)
+	}
+
+	backups := []Backup{}
+	for _, entry := range entries {
+		name := entry.Name()
+		if entry.IsDir() || !strings.HasPrefix(name, backupFilePrefix) || !strings.HasSuffix(name, ".db") {
+			continue
+		}
+		createdAt, err := time.Parse(backupTimestampStyle, strings.TrimSuffix(strings.TrimPrefix(name, backupFilePrefix), ".db"))
+		if err != nil {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil, fmt.Errorf("failed to stat %s: %w", name, err)
+		}
+		backups = append(backups, Backup{Path: filepath.Join(dir, name), CreatedAt: createdAt, Size: info.Size()})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].CreatedAt.After(backups[j].CreatedAt) })
+	return backups, nil
+}
+
+// PruneBackups deletes all but the newest keep snapshots in dir and returns the deleted ones.
+func PruneBackups(dir string, keep int) ([]Backup, error) {
+	if keep < 1 {
+		return nil, errors.New("refusing to prune every backup; keep must be at least 1")
+	}
+	backups, err := ListBackups(dir)
+	if err != nil {
+		return nil, err
+	}
+	if len(backups) <= keep {
+		return []Backup{}, nil
+	}
+
+	pruned := []Backup{}
+	for _, backup := range backups[keep:] {
+		if err := os.Remove(backup.Path); err != nil {
+			return pruned, fmt.Errorf("failed to delete %s: %w", backup.Path, err)
+		}
+		pruned = append(pruned, backup)
+	}
+	return pruned, nil
+}
+
+// RunPeriodicBackups snapshots the database into dir every interval, keeping the newest
+// keep snapshots, until ctx is cancelled. Failures are logged and retried next time.
+func (db *DB) RunPeriodicBackups(ctx context.Context, dir string, interval time.Duration, keep int) {
+	if db.Dialect != DialectSQLite {
+		logger.InfoContext(ctx, "Periodic backups disabled", "reason", ErrBackupUnsupported)
+		return
+	}
+	logger.InfoContext(ctx, "Backing up the database periodically", "dir", dir, "interval", interval.String(), "keep", keep)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			backup, err := db.CreateBackup(ctx, dir)
+			if err != nil {
+				logger.ErrorContext(ctx, "Error creating periodic database backup", "error", err)
+				continue
+			}
+			logger.InfoContext(ctx, "Database backed up", "path", backup.Path, "bytes", backup.Size)
+			if _, err := PruneBackups(dir, keep); err != nil {
+				logger.ErrorContext(ctx, "Error pruning database backups", "error", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package database
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestBackupAndRestore(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+	createUser(t, s, "alice", "Go developer")
+
+	backup, err := s.DB.CreateBackup(ctx, filepath.Join(t.TempDir(), "backups"))
+	if err != nil {
+		t.Fatalf("CreateBackup: %v", err)
+	}
+	if backup.Size == 0 {
+		t.Errorf("backup %s is empty", backup.Path)
+	}
+	if err := CheckIntegrity(ctx, backup.Path); err != nil {
+		t.Errorf("CheckIntegrity: %v", err)
+	}
+	if err := s.DB.BackupTo(ctx, backup.Path); err == nil {
+		t.Error("BackupTo over an existing file succeeded")
+	}
+
+	// A profile created after the snapshot is gone once the snapshot is restored
+	createUser(t, s, "bob", "Rust developer")
+	if err := s.DB.RestoreFrom(ctx, backup.Path); err != nil {
+		t.Fatalf("RestoreFrom: %v", err)
+	}
+	var usernames []string
+	rows, err := s.DB.QueryContext(ctx, `SELECT username FROM users ORDER BY username`)
+	if err != nil {
+		t.Fatalf("listing users: %v", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var username string
+		if err := rows.Scan(&username); err != nil {
+			t.Fatalf("scanning username: %v", err)
+		}
+		usernames = append(usernames, username)
+	}
+	if len(usernames) != 1 || usernames[0] != "alice" {
+		t.Errorf("users after restore = %v, want [alice]", usernames)
+	}
+}
+
+func TestRestoreRejectsCorruptSnapshot(t *testing.T) {
+	ctx := context.Background()
+	s := newTestService(t)
+
+	corrupt := filepath.Join(t.TempDir(), "corrupt.db")
+	if err := os.WriteFile(corrupt, []byte("not a database"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckIntegrity(ctx, corrupt); err == nil {
+		t.Error("CheckIntegrity accepted a corrupt file")
+	}
+	if err := s.DB.RestoreFrom(ctx, corrupt); err == nil {
+		t.Error("RestoreFrom accepted a corrupt snapshot")
+	}
+	if err := CheckIntegrity(ctx, filepath.Join(t.TempDir(), "missing.db")); err == nil {
+		t.Error("CheckIntegrity accepted a missing file")
+	}
+}
+
+func TestListAndPruneBackups(t *testing.T) {
+	dir := t.TempDir()
+	base := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 4; i++ {
+		name := backupFilePrefix + base.Add(time.Duration(i)*time.Hour).Format(backupTimestampStyle) + ".db"
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("snapshot"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// Files that are not snapshots are left alone
+	for _, name := range []string{"notes.txt", backupFilePrefix + "latest.db"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, err := ListBackups(dir)
+	if err != nil {
+		t.Fatalf("ListBackups: %v", err)
+	}
+	if len(backups) != 4 {
+		t.Fatalf("ListBackups returned %d backups, want 4", len(backups))
+	}
+	if want := base.Add(3 * time.Hour); !backups[0].CreatedAt.Equal(want) {
+		t.Errorf("newest backup created at %s, want %s", backups[0].CreatedAt, want)
+	}
+
+	if _, err := PruneBackups(dir, 0); err == nil {
+		t.Error("PruneBackups with keep 0 succeeded")
+	}
+	pruned, err := PruneBackups(dir, 2)
+	if err != nil {
+		t.Fatalf("PruneBackups: %v", err)
+	}
+	if len(pruned) != 2 || !pruned[0].CreatedAt.Equal(base.Add(time.Hour)) || !pruned[1].CreatedAt.Equal(base) {
+		t.Errorf("pruned = %+v, want the two oldest backups", pruned)
+	}
+	if remaining, _ := ListBackups(dir); len(remaining) != 2 {
+		t.Errorf("%d backups left after pruning, want 2", len(remaining))
+	}
+	if _, err := os.Stat(filepath.Join(dir, "notes.txt")); err != nil {
+		t.Errorf("pruning removed an unrelated file: %v", err)
+	}
+
+	if missing, err := ListBackups(filepath.Join(dir, "missing")); err != nil || len(missing) != 0 {
+		t.Errorf("ListBackups(missing dir) = %v, %v; want no backups", missing, err)
+	}
+}
@@ -0,0 +1,140 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gin/internal/models"
+)
+
+// ErrBlocked is returned when a user acts on someone they have blocked or been blocked
+// by, such as swiping on them or messaging them.
+var ErrBlocked = errors.New("one of the users has blocked the other")
+
+// --- Block Operations ---
+
+// blockedEither returns an EXISTS condition that is true when the user in column and the
+// user bound to both of its placeholders have blocked each other in either direction.
+func blockedEither(column string) string {
+	return `EXISTS (
+		SELECT 1 FROM user_blocks b
+		WHERE (b.blocker_user_id = ? AND b.blocked_user_id = ` + column + `)
+		   OR (b.blocker_user_id = ` + column + ` AND b.blocked_user_id = ?)
+	)`
+}
+
+// BlockUser makes blockerID block blockedID. Blocking someone twice is not an error.
+// Returns sql.ErrNoRows if either user does not exist.
+func (s *DBService) BlockUser(ctx context.Context, blockerID, blockedID string) error {
+	query := `
+		INSERT INTO user_blocks (blocker_user_id, blocked_user_id)
+		SELECT ?, ? WHERE EXISTS (SELECT 1 FROM users WHERE id = ?) AND EXISTS (SELECT 1 FROM users WHERE id = ?)
+		ON CONFLICT (blocker_user_id, blocked_user_id) DO NOTHING`
+	res, err := s.DB.ExecContext(ctx, query, blockerID, blockedID, blockerID, blockedID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error blocking user", "blocker_id", blockerID, "blocked_id", blockedID, "error", err)
+		return fmt.Errorf("blocking user failed: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		// Nothing inserted: either already blocked or a user is missing
+		blocked, err := s.hasBlocked(ctx, blockerID, blockedID)
+		if err != nil {
+			return err
+		}
+		if !blocked {
+			return sql.ErrNoRows
+		}
+	}
+	return nil
+}
+
+// hasBlocked reports whether blockerID has blocked blockedID, in that direction only.
+func (s *DBService) hasBlocked(ctx context.Context, blockerID, blockedID string) (bool, error) {
+	var exists int
+	err := s.DB.QueryRowContext(ctx,
+		`SELECT 1 FROM user_blocks WHERE blocker_user_id = ? AND blocked_user_id = ?`, blockerID, blockedID,
+	).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("checking block failed: %w", err)
+	}
+	return true, nil
+}
+
+// UnblockUser lifts the block blockerID placed on blockedID.
+// Returns sql.ErrNoRows if there was none.
+func (s *DBService) UnblockUser(ctx context.Context, blockerID, blockedID string) error {
+	res, err := s.DB.ExecContext(ctx,
+		`DELETE FROM user_blocks WHERE blocker_user_id = ? AND blocked_user_id = ?`, blockerID, blockedID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error unblocking user", "blocker_id", blockerID, "blocked_id", blockedID, "error", err)
+		return fmt.Errorf("unblocking user failed: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetBlockedUsers returns the users blockerID has blocked, most recently blocked first.
+func (s *DBService) GetBlockedUsers(ctx context.Context, blockerID string) ([]models.BlockedUser, error) {
+	query := `
+		SELECT ` + prefixedUserColumns("u") + `, b.created_at
+		FROM user_blocks b
+		JOIN users u ON u.id = b.blocked_user_id
+		WHERE b.blocker_user_id = ?
+		ORDER BY b.created_at DESC` + s.DB.Dialect.insertionTiebreak("b")
+
+	rows, err := s.DB.QueryContext(ctx, query, blockerID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying blocked users", "blocker_id", blockerID, "error", err)
+		return nil, fmt.Errorf("querying blocked users failed: %w", err)
+	}
+	defer rows.Close()
+
+	blocked := []models.BlockedUser{}
+	for rows.Next() {
+		var entry models.BlockedUser
+		if err := rows.Scan(append(userScanDest(&entry.User), &entry.BlockedAt)...); err != nil {
+			return nil, fmt.Errorf("scanning blocked user row failed: %w", err)
+		}
+		blocked = append(blocked, entry)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating blocked user rows failed: %w", err)
+	}
+	return blocked, nil
+}
+
+// IsBlockedBetween reports whether userA and userB have blocked each other in either direction.
+func (s *DBService) IsBlockedBetween(ctx context.Context, userA, userB string) (bool, error) {
+	var blocked bool
+	err := s.DB.QueryRowContext(ctx, `SELECT `+blockedEither("?"), userA, userB, userB, userA).Scan(&blocked)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error checking blocks between users", "user_a", userA, "user_b", userB, "error", err)
+		return false, fmt.Errorf("checking blocks failed: %w", err)
+	}
+	return blocked, nil
+}
+
+// IsBlockedInConversation reports whether userID and another participant of
+// conversationID have blocked each other in either direction.
+func (s *DBService) IsBlockedInConversation(ctx context.Context, conversationID, userID string) (bool, error) {
+	query := `
+		SELECT EXISTS (
+			SELECT 1 FROM conversation_participants other
+			WHERE other.conversation_id = ? AND other.user_id != ?
+			  AND ` + blockedEither("other.user_id") + `
+		)`
+	var blocked bool
+	err := s.DB.QueryRowContext(ctx, query, conversationID, userID, userID, userID).Scan(&blocked)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error checking blocks in conversation", "conversation_id", conversationID, "user_id", userID, "error", err)
+		return false, fmt.Errorf("checking blocks failed: %w", err)
+	}
+	return blocked, nil
+}
@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"gin/internal/models"
+)
+
+// matchUsers has a and b like each other and returns their conversation.
+func matchUsers(t *testing.T, s *DBService, a, b *models.User) string {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: a.ID, SwipedID: b.ID, Direction: models.SwipeLike}, 0); err != nil {
+		t.Fatalf("swipe by %s: %v", *a.Username, err)
+	}
+	result, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: b.ID, SwipedID: a.ID, Direction: models.SwipeLike}, 0)
+	if err != nil || !result.Matched || result.ConversationID == nil {
+		t.Fatalf("swipe by %s = %+v (err %v), want a match", *b.Username, result, err)
+	}
+	return *result.ConversationID
+}
+
+func TestBlockUser(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "Writes Rust")
+		bob := createUser(t, s, "bob", "Writes Rust")
+		carol := createUser(t, s, "carol", "Writes Rust")
+		conversationID := matchUsers(t, s, alice, bob)
+
+		if err := s.BlockUser(ctx, alice.ID, bob.ID); err != nil {
+			t.Fatalf("BlockUser: %v", err)
+		}
+		if err := s.BlockUser(ctx, alice.ID, bob.ID); err != nil {
+			t.Errorf("blocking twice: %v", err)
+		}
+		if err := s.BlockUser(ctx, alice.ID, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("blocking an unknown user error = %v, want sql.ErrNoRows", err)
+		}
+
+		blocked, err := s.GetBlockedUsers(ctx, alice.ID)
+		if err != nil || len(blocked) != 1 || blocked[0].User.ID != bob.ID {
+			t.Fatalf("GetBlockedUsers = %+v, %v; want bob", blocked, err)
+		}
+		if others, _ := s.GetBlockedUsers(ctx, bob.ID); len(others) != 0 {
+			t.Errorf("bob blocked %d users, want none", len(others))
+		}
+
+		// The block applies in both directions
+		for _, pair := range [][2]*models.User{{alice, bob}, {bob, alice}} {
+			if blocked, err := s.IsBlockedBetween(ctx, pair[0].ID, pair[1].ID); err != nil || !blocked {
+				t.Errorf("IsBlockedBetween(%s, %s) = %v, %v; want true", *pair[0].Username, *pair[1].Username, blocked, err)
+			}
+			if blocked, err := s.IsBlockedInConversation(ctx, conversationID, pair[0].ID); err != nil || !blocked {
+				t.Errorf("IsBlockedInConversation for %s = %v, %v; want true", *pair[0].Username, blocked, err)
+			}
+			conversations, err := s.GetConversations(ctx, pair[0].ID)
+			if err != nil || len(conversations) != 0 {
+				t.Errorf("conversations of %s = %+v, %v; want none", *pair[0].Username, conversations, err)
+			}
+			if _, err := s.CreateMessage(ctx, conversationID, pair[0].ID, "hello?"); !errors.Is(err, ErrBlocked) {
+				t.Errorf("message from %s error = %v, want ErrBlocked", *pair[0].Username, err)
+			}
+			candidates, err := s.GetSwipeCandidates(ctx, pair[0].ID, models.CardFilters{}, 10)
+			if err != nil {
+				t.Fatalf("GetSwipeCandidates: %v", err)
+			}
+			for _, c := range candidates {
+				if c.ID == pair[1].ID {
+					t.Errorf("%s is a candidate for %s", *pair[1].Username, *pair[0].Username)
+				}
+			}
+			results, err := s.SearchUsers(ctx, pair[0].ID, "rust", 10, 0)
+			if err != nil {
+				t.Fatalf("SearchUsers: %v", err)
+			}
+			if len(results) != 1 || results[0].User.ID != carol.ID {
+				t.Errorf("search by %s returned %d results, want only carol", *pair[0].Username, len(results))
+			}
+		}
+		if blocked, _ := s.IsBlockedBetween(ctx, alice.ID, carol.ID); blocked {
+			t.Error("alice and carol are blocked")
+		}
+		if _, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: bob.ID, SwipedID: alice.ID, Direction: models.SwipeDislike}, 0); !errors.Is(err, ErrBlocked) {
+			t.Errorf("swipe on a blocker error = %v, want ErrBlocked", err)
+		}
+
+		if err := s.UnblockUser(ctx, alice.ID, bob.ID); err != nil {
+			t.Fatalf("UnblockUser: %v", err)
+		}
+		if err := s.UnblockUser(ctx, alice.ID, bob.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("unblocking twice error = %v, want sql.ErrNoRows", err)
+		}
+		if conversations, _ := s.GetConversations(ctx, bob.ID); len(conversations) != 1 {
+			t.Errorf("bob has %d conversations after the unblock, want 1", len(conversations))
+		}
+		if _, err := s.CreateMessage(ctx, conversationID, bob.ID, "hello again"); err != nil {
+			t.Errorf("message after the unblock: %v", err)
+		}
+	})
+}
+
+func TestCreateMessageNotParticipant(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "")
+		bob := createUser(t, s, "bob", "")
+		mallory := createUser(t, s, "mallory", "")
+		conversationID := matchUsers(t, s, alice, bob)
+
+		if _, err := s.CreateMessage(ctx, conversationID, mallory.ID, "hi"); !errors.Is(err, ErrNotConversationParticipant) {
+			t.Errorf("message from an outsider error = %v, want ErrNotConversationParticipant", err)
+		}
+		if ok, err := s.IsConversationParticipant(ctx, conversationID, mallory.ID); err != nil || ok {
+			t.Errorf("IsConversationParticipant(mallory) = %v, %v; want false", ok, err)
+		}
+		msg, err := s.CreateMessage(ctx, conversationID, alice.ID, "hi")
+		if err != nil || msg.SenderID == nil || *msg.SenderID != alice.ID || msg.Content != "hi" {
+			t.Fatalf("CreateMessage = %+v, %v", msg, err)
+		}
+	})
+}
@@ -0,0 +1,228 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gin/internal/models"
+)
+
+// ErrNotConversationParticipant is returned when a user acts on a conversation they are not part of.
+var ErrNotConversationParticipant = errors.New("user is not a participant in this conversation")
+
+// --- Conversation Operations ---
+
+// createConversation opens a new conversation between the given users within tx
+// and returns its ID.
+func createConversation(ctx context.Context, tx *Tx, userIDs ...string) (string, error) {
+	var conversationID string
+	err := tx.QueryRowContext(ctx, `INSERT INTO conversations DEFAULT VALUES RETURNING id`).Scan(&conversationID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error creating conversation", "user_ids", userIDs, "error", err)
+		return "", fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	for _, userID := range userIDs {
+		_, err = tx.ExecContext(ctx,
+			`INSERT INTO conversation_participants (conversation_id, user_id) VALUES (?, ?)`,
+			conversationID, userID,
+		)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error adding user to conversation", "user_id", userID, "conversation_id", conversationID, "error", err)
+			return "", fmt.Errorf("failed to add conversation participant: %w", err)
+		}
+	}
+	return conversationID, nil
+}
+
+// findConversationBetween returns the ID of the conversation shared by userA and userB,
+// or "" if there is none.
+func findConversationBetween(ctx context.Context, tx *Tx, userA, userB string) (string, error) {
+	query := `
+		SELECT cp1.conversation_id
+		FROM conversation_participants cp1
+		JOIN conversation_participants cp2 ON cp2.conversation_id = cp1.conversation_id
+		WHERE cp1.user_id = ? AND cp2.user_id = ?
+		LIMIT 1`
+
+	var conversationID string
+	err := tx.QueryRowContext(ctx, query, userA, userB).Scan(&conversationID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to look up conversation: %w", err)
+	}
+	return conversationID, nil
+}
+
+// deleteConversationIfEmpty deletes conversationID within tx if it has no messages.
+// Participants are removed by the ON DELETE CASCADE foreign key. Reports whether it was deleted.
+func deleteConversationIfEmpty(ctx context.Context, tx *Tx, conversationID string) (bool, error) {
+	query := `
+		DELETE FROM conversations
+		WHERE id = ? AND NOT EXISTS (SELECT 1 FROM messages WHERE conversation_id = ?)`
+
+	res, err := tx.ExecContext(ctx, query, conversationID, conversationID)
+	if err != nil {
+		return false, fmt.Errorf("failed to delete conversation: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check deleted conversation: %w", err)
+	}
+	return n > 0, nil
+}
+
+// GetConversations returns the conversations userID takes part in, most recently active
+// first. Conversations with someone userID has blocked or been blocked by are left out.
+func (s *DBService) GetConversations(ctx context.Context, userID string) ([]models.Conversation, error) {
+	query := `
+		SELECT c.id, c.created_at, c.updated_at
+		FROM conversations c
+		JOIN conversation_participants cp ON cp.conversation_id = c.id
+		WHERE cp.user_id = ?
+		  AND NOT EXISTS (
+			SELECT 1 FROM conversation_participants other
+			WHERE other.conversation_id = c.id AND other.user_id != ?
+			  AND ` + blockedEither("other.user_id") + `
+		  )
+		ORDER BY c.updated_at DESC`
+
+	rows, err := s.DB.QueryContext(ctx, query, userID, userID, userID, userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying conversations", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("querying conversations failed: %w", err)
+	}
+	defer rows.Close()
+
+	conversations := []models.Conversation{}
+	index := make(map[string]int)
+	for rows.Next() {
+		var conv models.Conversation
+		if err := rows.Scan(&conv.ID, &conv.CreatedAt, &conv.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("scanning conversation row failed: %w", err)
+		}
+		index[conv.ID] = len(conversations)
+		conversations = append(conversations, conv)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating conversation rows failed: %w", err)
+	}
+	rows.Close()
+
+	participantsQuery := `
+		SELECT conversation_id, user_id
+		FROM conversation_participants
+		WHERE conversation_id IN (SELECT conversation_id FROM conversation_participants WHERE user_id = ?)
+		ORDER BY user_id`
+
+	rows, err = s.DB.QueryContext(ctx, participantsQuery, userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying conversation participants", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("querying conversation participants failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var conversationID, participantID string
+		if err := rows.Scan(&conversationID, &participantID); err != nil {
+			return nil, fmt.Errorf("scanning participant row failed: %w", err)
+		}
+		if i, ok := index[conversationID]; ok {
+			conversations[i].UserIDs = append(conversations[i].UserIDs, participantID)
+		}
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating participant rows failed: %w", err)
+	}
+	return conversations, nil
+}
+
+// IsConversationParticipant reports whether userID takes part in conversationID.
+func (s *DBService) IsConversationParticipant(ctx context.Context, conversationID, userID string) (bool, error) {
+	var exists int
+	err := s.DB.QueryRowContext(ctx,
+		`SELECT 1 FROM conversation_participants WHERE conversation_id = ? AND user_id = ?`,
+		conversationID, userID,
+	).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error checking conversation participant", "user_id", userID, "conversation_id", conversationID, "error", err)
+		return false, fmt.Errorf("checking conversation participant failed: %w", err)
+	}
+	return true, nil
+}
+
+// GetMessages returns messages of conversationID, newest first.
+func (s *DBService) GetMessages(ctx context.Context, conversationID string, limit, offset int) ([]models.Message, error) {
+	query := `
+		SELECT id, conversation_id, sender_user_id, content, sent_at
+		FROM messages
+		WHERE conversation_id = ?
+		ORDER BY sent_at DESC` + s.DB.Dialect.insertionTiebreak("") + `
+		LIMIT ? OFFSET ?`
+
+	rows, err := s.DB.QueryContext(ctx, query, conversationID, limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying messages", "conversation_id", conversationID, "error", err)
+		return nil, fmt.Errorf("querying messages failed: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []models.Message{}
+	for rows.Next() {
+		var msg models.Message
+		if err := rows.Scan(&msg.ID, &msg.ConversationID, &msg.SenderID, &msg.Content, &msg.SentAt); err != nil {
+			return nil, fmt.Errorf("scanning message row failed: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating message rows failed: %w", err)
+	}
+	return messages, nil
+}
+
+// CreateMessage stores a message from senderID in conversationID.
+// Returns ErrNotConversationParticipant if the sender is not part of the conversation
+// and ErrBlocked if the sender and another participant have blocked each other.
+func (s *DBService) CreateMessage(ctx context.Context, conversationID, senderID, content string) (*models.Message, error) {
+	// The checks are part of the insert so they cannot race with the conversation being
+	// dissolved or a block being placed
+	query := `
+		INSERT INTO messages (conversation_id, sender_user_id, content)
+		SELECT ?, ?, ?
+		WHERE EXISTS (SELECT 1 FROM conversation_participants WHERE conversation_id = ? AND user_id = ?)
+		  AND NOT EXISTS (
+			SELECT 1 FROM conversation_participants other
+			WHERE other.conversation_id = ? AND other.user_id != ?
+			  AND ` + blockedEither("other.user_id") + `
+		  )
+		RETURNING id, conversation_id, sender_user_id, content, sent_at`
+
+	var msg models.Message
+	err := s.DB.Writer().QueryRowContext(ctx, query,
+		conversationID, senderID, content, conversationID, senderID, conversationID, senderID, senderID, senderID,
+	).Scan(&msg.ID, &msg.ConversationID, &msg.SenderID, &msg.Content, &msg.SentAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		// Nothing inserted; tell the two reasons apart
+		isParticipant, err := s.IsConversationParticipant(ctx, conversationID, senderID)
+		if err != nil {
+			return nil, err
+		}
+		if !isParticipant {
+			return nil, ErrNotConversationParticipant
+		}
+		return nil, ErrBlocked
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error creating message", "conversation_id", conversationID, "sender_id", senderID, "error", err)
+		return nil, fmt.Errorf("creating message failed: %w", err)
+	}
+	return &msg, nil
+}
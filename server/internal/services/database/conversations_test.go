@@ -0,0 +1,42 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestGetMessagesNewestFirst(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "")
+		bob := createUser(t, s, "bob", "")
+		conversationID := matchUsers(t, s, alice, bob)
+		for _, content := range []string{"first", "second", "third"} {
+			if _, err := s.CreateMessage(ctx, conversationID, alice.ID, content); err != nil {
+				t.Fatalf("CreateMessage(%q): %v", content, err)
+			}
+		}
+
+		for _, tt := range []struct {
+			limit, offset int
+			want          string
+		}{
+			{10, 0, "third,second,first"},
+			{2, 0, "third,second"},
+			{2, 2, "first"},
+		} {
+			messages, err := s.GetMessages(ctx, conversationID, tt.limit, tt.offset)
+			if err != nil {
+				t.Fatalf("GetMessages: %v", err)
+			}
+			var got []string
+			for _, msg := range messages {
+				got = append(got, msg.Content)
+			}
+			if strings.Join(got, ",") != tt.want {
+				t.Errorf("GetMessages(limit %d, offset %d) = %v, want %s", tt.limit, tt.offset, got, tt.want)
+			}
+		}
+	})
+}
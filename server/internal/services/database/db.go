@@ -5,189 +5,366 @@ import (
 	"database/sql"
 	"errors"
 	"fmt"
-	"log"
+	"strings"
 	"time"
 
+	"gin/internal/logging"
 	"gin/internal/models" // Ensure this matches your module path
 
+	_ "github.com/jackc/pgx/v5/stdlib" // Registers the "pgx" driver
 	_ "github.com/mattn/go-sqlite3"
 )
 
+var logger = logging.For("database")
+
 // DBService encapsulates database operations.
 type DBService struct {
-	DB *sql.DB
+	DB *DB
 }
 
 // NewDBService creates a new DBService.
-func NewDBService(db *sql.DB) *DBService {
+func NewDBService(db *DB) *DBService {
 	return &DBService{DB: db}
 }
 
-// ConnectDB establishes a connection to the SQLite database and ensures the schema exists.
-func ConnectDB(databasePath string) (*sql.DB, error) {
-	// Note: While databasePath comes from config (trusted), directly concatenating
-	// into DSN isn't ideal. Consider validating the path format rigorously.
-	dsn := fmt.Sprintf("file:%s?_foreign_keys=on&cache=shared&mode=rwc", databasePath)
-	db, err := sql.Open("sqlite3", dsn)
+// SQLite tuning. WAL lets readers proceed while a write is in progress, and the busy
+// timeout makes a connection wait for a lock instead of failing immediately. Writes are
+// serialized through one connection (see DB), so the timeout mostly covers other
+// processes such as cmd/seed or cmd/migrate running against the same file.
+const (
+	sqliteBusyTimeout     = 5 * time.Second
+	sqliteReadPoolSize    = 10
+	sqliteReadIdleConns   = 5
+	postgresPoolSize      = 10
+	postgresIdleConns     = 5
+	connectionMaxLifetime = time.Hour
+)
+
+// OpenDB opens and pings the database named by databaseURL without touching its schema.
+// postgres:// and postgresql:// URLs select PostgreSQL; anything else is the path of a
+// SQLite file, optionally prefixed with sqlite://.
+// Most callers want ConnectDB; cmd/migrate uses this to manage the schema itself.
+func OpenDB(databaseURL string) (*DB, error) {
+	var (
+		db  *DB
+		err error
+	)
+	if strings.HasPrefix(databaseURL, "postgres://") || strings.HasPrefix(databaseURL, "postgresql://") {
+		db, err = openPostgres(databaseURL)
+	} else {
+		db, err = openSQLite(strings.TrimPrefix(databaseURL, "sqlite://"))
+	}
 	if err != nil {
-		log.Printf("Error opening database '%s': %v", databasePath, err) // Removed newline
-		return nil, fmt.Errorf("failed to open database: %w", err)
+		return nil, err
 	}
 
-	// Configure connection pool
-	db.SetMaxOpenConns(10)
-	db.SetMaxIdleConns(5)
-	db.SetConnMaxLifetime(time.Hour)
-
 	// Ping the database to verify connection
 	pingCtx, cancelPing := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancelPing()
-	err = db.PingContext(pingCtx)
+	err = db.writePool().PingContext(pingCtx)
+	if err == nil {
+		err = db.DB.PingContext(pingCtx)
+	}
 	if err != nil {
-		db.Close()                                                       // Close the connection if ping fails
-		log.Printf("Error pinging database '%s': %v", databasePath, err) // Removed newline
+		db.Close() // Close the connections if ping fails
+		logger.Error("Error pinging database", "dialect", db.Dialect, "error", err)
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
-	log.Printf("Database connection established successfully to %s.", databasePath) // Removed newline
+	// Only the SQLite path is logged; Postgres URLs may embed credentials
+	if db.Dialect == DialectSQLite {
+		logger.Info("Database connection established successfully.", "dialect", db.Dialect, "path", databaseURL)
+	} else {
+		logger.Info("Database connection established successfully.", "dialect", db.Dialect)
+	}
 
-	// Ensure the necessary tables exist
-	schemaCtx, cancelSchema := context.WithTimeout(context.Background(), 15*time.Second) // Longer timeout for schema ops
-	defer cancelSchema()
-	err = ensureSchema(schemaCtx, db)
+	return db, nil
+}
+
+// openPostgres opens a pgx connection pool shared by reads and writes.
+func openPostgres(databaseURL string) (*DB, error) {
+	pool, err := sql.Open("pgx", databaseURL)
 	if err != nil {
-		db.Close()                                            // Close the connection if schema setup fails
-		log.Printf("Error ensuring database schema: %v", err) // Removed newline
-		return nil, fmt.Errorf("failed to ensure database schema: %w", err)
+		logger.Error("Error opening postgres database", "error", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
 	}
+	pool.SetMaxOpenConns(postgresPoolSize)
+	pool.SetMaxIdleConns(postgresIdleConns)
+	pool.SetConnMaxLifetime(connectionMaxLifetime)
+	return &DB{DB: pool, Dialect: DialectPostgres}, nil
+}
 
-	return db, nil
+// openSQLite opens the SQLite file at databasePath as one write connection and a pool of
+// read-only connections, all in WAL mode.
+func openSQLite(databasePath string) (*DB, error) {
+	// Note: While the path comes from config (trusted), directly concatenating
+	// into DSN isn't ideal. Consider validating the path format rigorously.
+	// The shared cache is deliberately not used: its table-level locks fail with
+	// SQLITE_LOCKED right away and ignore the busy timeout.
+	params := fmt.Sprintf("_foreign_keys=on&_journal_mode=WAL&_synchronous=NORMAL&_busy_timeout=%d",
+		sqliteBusyTimeout.Milliseconds())
+
+	// The writer starts transactions with BEGIN IMMEDIATE so a transaction that reads
+	// before writing cannot fail to upgrade its lock halfway through.
+	writer, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=rwc&_txlock=immediate&%s", databasePath, params))
+	if err != nil {
+		logger.Error("Error opening sqlite database", "error", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	writer.SetMaxOpenConns(1)
+	writer.SetMaxIdleConns(1)
+	writer.SetConnMaxLifetime(0) // Keep the one connection (and its page cache) for good
+
+	// Readers refuse to write, so a write accidentally sent to the read pool fails loudly
+	// instead of competing with the writer for the lock.
+	readers, err := sql.Open("sqlite3", fmt.Sprintf("file:%s?mode=rwc&_query_only=on&%s", databasePath, params))
+	if err != nil {
+		writer.Close()
+		logger.Error("Error opening sqlite database", "error", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	readers.SetMaxOpenConns(sqliteReadPoolSize)
+	readers.SetMaxIdleConns(sqliteReadIdleConns)
+	readers.SetConnMaxLifetime(connectionMaxLifetime)
+
+	return &DB{DB: readers, Dialect: DialectSQLite, writer: writer}, nil
 }
 
-// ensureSchema creates the database tables if they don't exist.
-// Note: This executes multiple DDL statements. If one fails mid-way,
-// previous ones are not automatically rolled back by this function.
-// For robust migrations, consider a dedicated library (e.g., migrate, sql-migrate).
-func ensureSchema(ctx context.Context, db *sql.DB) error {
-	schemaSQL := `
-	PRAGMA foreign_keys = ON; -- Ensure foreign keys are enforced
-
-	-- Users Table --
-	CREATE TABLE IF NOT EXISTS users (
-		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
-		clerk_user_id TEXT UNIQUE NOT NULL,
-		username TEXT,
-		picture_url TEXT,
-		bio TEXT,
-		github_url TEXT,
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
-	);
-	CREATE INDEX IF NOT EXISTS idx_users_clerk_user_id ON users(clerk_user_id);
-
-	CREATE TRIGGER IF NOT EXISTS trigger_users_update_updated_at
-	AFTER UPDATE ON users FOR EACH ROW
-	WHEN OLD.updated_at = NEW.updated_at -- Avoid infinite loop if updated_at is explicitly set
-	BEGIN
-		UPDATE users SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
-	END;
-
-	-- Swipes Table --
-	CREATE TABLE IF NOT EXISTS swipes (
-		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
-		swiper_user_id TEXT NOT NULL,
-		swiped_user_id TEXT NOT NULL,
-		direction TEXT NOT NULL CHECK(direction IN ('like', 'dislike')),
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
-		FOREIGN KEY (swiper_user_id) REFERENCES users(id) ON DELETE CASCADE,
-		FOREIGN KEY (swiped_user_id) REFERENCES users(id) ON DELETE CASCADE,
-		UNIQUE (swiper_user_id, swiped_user_id)
-	);
-	CREATE INDEX IF NOT EXISTS idx_swipes_swiper_id ON swipes(swiper_user_id);
-	CREATE INDEX IF NOT EXISTS idx_swipes_swiped_id ON swipes(swiped_user_id);
-
-	-- Conversations Table --
-	CREATE TABLE IF NOT EXISTS conversations (
-		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
-		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
-		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL -- Tracks last activity/message
-	);
-
-	-- Conversation Participants Join Table --
-	CREATE TABLE IF NOT EXISTS conversation_participants (
-		conversation_id TEXT NOT NULL,
-		user_id TEXT NOT NULL,
-		PRIMARY KEY (conversation_id, user_id),
-		FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
-		FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
-	);
-	CREATE INDEX IF NOT EXISTS idx_conv_participants_user_id ON conversation_participants(user_id);
-
-	-- Messages Table --
-	CREATE TABLE IF NOT EXISTS messages (
-		id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
-		conversation_id TEXT NOT NULL,
-		sender_user_id TEXT NOT NULL,
-		content TEXT NOT NULL,
-		sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
-		FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
-		FOREIGN KEY (sender_user_id) REFERENCES users(id) ON DELETE CASCADE -- Assuming sender must exist
-	);
-	CREATE INDEX IF NOT EXISTS idx_messages_conversation_id ON messages(conversation_id);
-	CREATE INDEX IF NOT EXISTS idx_messages_sent_at ON messages(sent_at);
-
-	-- Trigger to update conversation updated_at on new message --
-	CREATE TRIGGER IF NOT EXISTS trigger_update_conversation_on_message
-	AFTER INSERT ON messages FOR EACH ROW
-	BEGIN
-		UPDATE conversations SET updated_at = NEW.sent_at WHERE id = NEW.conversation_id;
-	END;
-	`
-
-	log.Println("Ensuring database schema...")
-	_, err := db.ExecContext(ctx, schemaSQL)
+// ConnectDB establishes a connection to the database named by databaseURL (see OpenDB)
+// and applies any pending migrations.
+func ConnectDB(databaseURL string) (*DB, error) {
+	db, err := OpenDB(databaseURL)
 	if err != nil {
-		log.Printf("Error executing schema SQL: %v", err) // Removed newline
-		return fmt.Errorf("failed to execute schema SQL: %w", err)
+		return nil, err
 	}
-	log.Println("Database schema check complete.")
-	return nil
+
+	// Bring the schema up to date
+	schemaCtx, cancelSchema := context.WithTimeout(context.Background(), 15*time.Second) // Longer timeout for schema ops
+	defer cancelSchema()
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		db.Close()
+		return nil, err
+	}
+	applied, err := migrator.Up(schemaCtx)
+	if err != nil {
+		db.Close() // Close the connection if schema setup fails
+		logger.Error("Error migrating database schema", "error", err)
+		return nil, fmt.Errorf("failed to migrate database schema: %w", err)
+	}
+	logger.Info("Database schema up to date.", "applied", applied)
+
+	if db.Dialect == DialectSQLite {
+		indexed, err := tableExists(schemaCtx, db, db.Dialect, "users_fts")
+		if err != nil {
+			db.Close()
+			return nil, err
+		}
+		if !indexed {
+			logger.Warn("No full-text search index, user search falls back to LIKE matching. Build with -tags sqlite_fts5 for full-text search on new databases.")
+			db.likeSearch = true
+		}
+	}
+
+	return db, nil
 }
 
 // --- User Profile Operations ---
 
-// GetUserProfileByClerkID retrieves a user profile using their Clerk ID.
-// Returns sql.ErrNoRows if the user is not found.
-func (s *DBService) GetUserProfileByClerkID(ctx context.Context, clerkUserID string) (*models.User, error) {
-	query := `
-		SELECT id, clerk_user_id, username, picture_url, bio, github_url, created_at, updated_at
-		FROM users
-		WHERE clerk_user_id = ?`
+// userColumnNames lists the users columns in the order expected by scanUser.
+var userColumnNames = []string{
+	"id", "clerk_user_id", "username", "picture_url", "bio", "github_url", "primary_language",
+	"timezone_offset_minutes", "github_followers", "github_public_repos", "last_active_at",
+	"deletion_scheduled_for", "role", "suspended_at", "suspension_reason", "created_at", "updated_at",
+}
 
-	var user models.User
-	err := s.DB.QueryRowContext(ctx, query, clerkUserID).Scan(
+// userColumns is userColumnNames as a SELECT list.
+var userColumns = strings.Join(userColumnNames, ", ")
+
+// prefixedUserColumns is userColumns qualified with a table alias, for queries with joins.
+func prefixedUserColumns(alias string) string {
+	cols := make([]string, len(userColumnNames))
+	for i, name := range userColumnNames {
+		cols[i] = alias + "." + name
+	}
+	return strings.Join(cols, ", ")
+}
+
+// rowScanner is satisfied by both *sql.Row and *sql.Rows.
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+// userScanDest returns scan destinations for userColumns, so callers selecting extra
+// columns can append their own.
+func userScanDest(user *models.User) []any {
+	return []any{
 		&user.ID,
 		&user.ClerkUserID,
 		&user.Username,
 		&user.PictureURL,
 		&user.Bio,
 		&user.GitHubURL,
+		&user.PrimaryLanguage,
+		&user.TimezoneOffsetMinutes,
+		&user.GitHubFollowers,
+		&user.GitHubPublicRepos,
+		&user.LastActiveAt,
+		&user.DeletionScheduledFor,
+		&user.Role,
+		&user.SuspendedAt,
+		&user.SuspensionReason,
 		&user.CreatedAt,
 		&user.UpdatedAt,
-	)
+	}
+}
+
+// scanUser scans a row selected with userColumns into a models.User.
+func scanUser(row rowScanner) (*models.User, error) {
+	var user models.User
+	if err := row.Scan(userScanDest(&user)...); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// queryer is satisfied by both *DB and *Tx.
+type queryer interface {
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// attachInterests loads the interest tags of the given users and sets them in place.
+func attachInterests(ctx context.Context, q queryer, users []models.User) error {
+	if len(users) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(users))
+	placeholders := make([]string, 0, len(users))
+	args := make([]any, 0, len(users))
+	for i, user := range users {
+		index[user.ID] = i
+		placeholders = append(placeholders, "?")
+		args = append(args, user.ID)
+	}
+
+	query := `SELECT user_id, tag FROM user_interests WHERE user_id IN (` + strings.Join(placeholders, ", ") + `) ORDER BY tag`
+	rows, err := q.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying interests", "users", len(users), "error", err)
+		return fmt.Errorf("querying user interests failed: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID, tag string
+		if err := rows.Scan(&userID, &tag); err != nil {
+			return fmt.Errorf("scanning interest row failed: %w", err)
+		}
+		i := index[userID]
+		users[i].Interests = append(users[i].Interests, tag)
+	}
+	return rows.Err()
+}
+
+// attachUserInterests is attachInterests for a single user.
+func attachUserInterests(ctx context.Context, q queryer, user *models.User) error {
+	users := []models.User{*user}
+	if err := attachInterests(ctx, q, users); err != nil {
+		return err
+	}
+	user.Interests = users[0].Interests
+	return nil
+}
+
+// replaceInterests overwrites the interest tags of userID within tx.
+func replaceInterests(ctx context.Context, tx *Tx, userID string, tags []string) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM user_interests WHERE user_id = ?`, userID); err != nil {
+		return fmt.Errorf("failed to clear interests: %w", err)
+	}
+	for _, tag := range models.NormalizeInterestTags(tags) {
+		if _, err := tx.ExecContext(ctx, `INSERT INTO user_interests (user_id, tag) VALUES (?, ?)`, userID, tag); err != nil {
+			return fmt.Errorf("failed to insert interest %q: %w", tag, err)
+		}
+	}
+	return nil
+}
+
+// GetUserProfileByClerkID retrieves a user profile using their Clerk ID.
+// Returns sql.ErrNoRows if the user is not found.
+func (s *DBService) GetUserProfileByClerkID(ctx context.Context, clerkUserID string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE clerk_user_id = ?`
+
+	user, err := scanUser(s.DB.QueryRowContext(ctx, query, clerkUserID))
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			// Log the specific case but return the original error for clarity upstream
-			log.Printf("No user found with Clerk ID: %q", clerkUserID) // Removed newline
-			return nil, err                                            // Return sql.ErrNoRows
+			logger.DebugContext(ctx, "No user found with Clerk ID", "clerk_user_id", clerkUserID)
+			return nil, err // Return sql.ErrNoRows
 		}
-		log.Printf("Error querying user by Clerk ID %q: %v", clerkUserID, err) // Removed newline
+		logger.ErrorContext(ctx, "Error querying user by Clerk ID", "clerk_user_id", clerkUserID, "error", err)
 		return nil, fmt.Errorf("querying user by Clerk ID %q failed: %w", clerkUserID, err)
 	}
-	return &user, nil
+	if err := attachUserInterests(ctx, s.DB, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetUserProfileByID retrieves a user profile using its internal database ID.
+// Returns sql.ErrNoRows if the user is not found.
+func (s *DBService) GetUserProfileByID(ctx context.Context, userID string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = ?`
+
+	user, err := scanUser(s.DB.QueryRowContext(ctx, query, userID))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, err
+		}
+		logger.ErrorContext(ctx, "Error querying user by ID", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("querying user by ID %q failed: %w", userID, err)
+	}
+	if err := attachUserInterests(ctx, s.DB, user); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetAllUserProfiles returns every user profile. It is intended for batch jobs
+// such as the recommender, not for request handlers.
+func (s *DBService) GetAllUserProfiles(ctx context.Context) ([]models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users ORDER BY created_at`
+
+	rows, err := s.DB.QueryContext(ctx, query)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying all users", "error", err)
+		return nil, fmt.Errorf("querying all users failed: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning user row failed: %w", err)
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating user rows failed: %w", err)
+	}
+	rows.Close()
+
+	if err := attachInterests(ctx, s.DB, users); err != nil {
+		return nil, err
+	}
+	return users, nil
 }
 
 // CreateOrUpdateUserProfile creates a new user or updates an existing one based on Clerk User ID.
-// Uses a transaction and returns the created or updated user profile.
+// Interests are replaced only when user.Interests is non-nil, and the role only when
+// user.Role is set (new users default to models.RoleUser). Saving a profile also counts
+// as activity for last_active_at. Uses a transaction and returns the created or updated user profile.
 func (s *DBService) CreateOrUpdateUserProfile(ctx context.Context, user models.User) (*models.User, error) {
 	if user.ClerkUserID == "" {
 		return nil, errors.New("ClerkUserID is required to create or update profile")
@@ -196,7 +373,7 @@ func (s *DBService) CreateOrUpdateUserProfile(ctx context.Context, user models.U
 	// Start a transaction
 	tx, err := s.DB.BeginTx(ctx, nil) // Use default transaction options
 	if err != nil {
-		log.Printf("Error starting transaction for ClerkID %q: %v", user.ClerkUserID, err) // Removed newline
+		logger.ErrorContext(ctx, "Error starting profile upsert transaction", "clerk_user_id", user.ClerkUserID, "error", err)
 		return nil, fmt.Errorf("failed to begin transaction: %w", err)
 	}
 	// Defer rollback in case of errors - it's a no-op if Commit() succeeds
@@ -204,61 +381,127 @@ func (s *DBService) CreateOrUpdateUserProfile(ctx context.Context, user models.U
 
 	// Use COALESCE to handle nil pointers gracefully in the update part
 	upsertQuery := `
-		INSERT INTO users (clerk_user_id, username, picture_url, bio, github_url)
-		VALUES (?, ?, ?, ?, ?)
+		INSERT INTO users (clerk_user_id, username, picture_url, bio, github_url, timezone_offset_minutes, role, last_active_at)
+		VALUES (?, ?, ?, ?, ?, ?, COALESCE(?, 'user'), CURRENT_TIMESTAMP)
 		ON CONFLICT (clerk_user_id) DO UPDATE SET
 			username = COALESCE(excluded.username, users.username),
 			picture_url = COALESCE(excluded.picture_url, users.picture_url),
 			bio = COALESCE(excluded.bio, users.bio),
 			github_url = COALESCE(excluded.github_url, users.github_url),
+			timezone_offset_minutes = COALESCE(excluded.timezone_offset_minutes, users.timezone_offset_minutes),
+			role = COALESCE(?, users.role), -- excluded.role is already defaulted, so the value is passed again
+			last_active_at = CURRENT_TIMESTAMP,
 			updated_at = CURRENT_TIMESTAMP` // Let trigger handle updated_at if possible, but set here for INSERT case
 
+	var role *string // NULL keeps the stored role
+	if user.Role != "" {
+		if !user.Role.Valid() {
+			return nil, fmt.Errorf("unknown role %q", user.Role)
+		}
+		r := string(user.Role)
+		role = &r
+	}
+
 	_, err = tx.ExecContext(ctx, upsertQuery,
 		user.ClerkUserID,
 		user.Username,
 		user.PictureURL,
 		user.Bio,
 		user.GitHubURL,
+		user.TimezoneOffsetMinutes,
+		role,
+		role,
 	)
 	if err != nil {
-		log.Printf("Error executing upsert for ClerkID %q: %v", user.ClerkUserID, err) // Removed newline
+		logger.ErrorContext(ctx, "Error executing profile upsert", "clerk_user_id", user.ClerkUserID, "error", err)
 		// Rollback is deferred
 		return nil, fmt.Errorf("upsert failed for ClerkID %q: %w", user.ClerkUserID, err)
 	}
 
 	// Select the user data after upsert
-	selectQuery := `
-		SELECT id, clerk_user_id, username, picture_url, bio, github_url, created_at, updated_at
-		FROM users
-		WHERE clerk_user_id = ?`
-
-	var createdOrUpdatedUser models.User
-	err = tx.QueryRowContext(ctx, selectQuery, user.ClerkUserID).Scan(
-		&createdOrUpdatedUser.ID,
-		&createdOrUpdatedUser.ClerkUserID,
-		&createdOrUpdatedUser.Username,
-		&createdOrUpdatedUser.PictureURL,
-		&createdOrUpdatedUser.Bio,
-		&createdOrUpdatedUser.GitHubURL,
-		&createdOrUpdatedUser.CreatedAt,
-		&createdOrUpdatedUser.UpdatedAt,
-	)
+	selectQuery := `SELECT ` + userColumns + ` FROM users WHERE clerk_user_id = ?`
+
+	createdOrUpdatedUser, err := scanUser(tx.QueryRowContext(ctx, selectQuery, user.ClerkUserID))
 	if err != nil {
 		// This shouldn't happen if the upsert succeeded, but handle it defensively
-		log.Printf("Error selecting user after upsert for ClerkID %q: %v", user.ClerkUserID, err) // Removed newline
+		logger.ErrorContext(ctx, "Error selecting user after upsert", "clerk_user_id", user.ClerkUserID, "error", err)
 		// Rollback is deferred
 		return nil, fmt.Errorf("failed to select user after upsert for ClerkID %q: %w", user.ClerkUserID, err)
 	}
 
+	if user.Interests != nil {
+		if err := replaceInterests(ctx, tx, createdOrUpdatedUser.ID, user.Interests); err != nil {
+			logger.ErrorContext(ctx, "Error saving interests", "clerk_user_id", user.ClerkUserID, "error", err)
+			return nil, err
+		}
+	}
+	if err := attachUserInterests(ctx, tx, createdOrUpdatedUser); err != nil {
+		return nil, err
+	}
+
 	// Commit the transaction
 	if err = tx.Commit(); err != nil {
-		log.Printf("Error committing transaction for ClerkID %q: %v", user.ClerkUserID, err) // Removed newline
+		logger.ErrorContext(ctx, "Error committing profile upsert", "clerk_user_id", user.ClerkUserID, "error", err)
 		// Rollback is deferred, but commit failed
 		return nil, fmt.Errorf("failed to commit transaction for ClerkID %q: %w", user.ClerkUserID, err)
 	}
 
-	return &createdOrUpdatedUser, nil
+	return createdOrUpdatedUser, nil
+}
+
+// UpdateGitHubStats stores the GitHub activity summary used by deck filters and search
+// for userID, and records it as synced now.
+func (s *DBService) UpdateGitHubStats(ctx context.Context, userID string, stats models.GitHubStats) error {
+	query := `
+		UPDATE users
+		SET primary_language = ?, github_followers = ?, github_public_repos = ?, repo_names = ?, github_synced_at = ?
+		WHERE id = ?`
+
+	res, err := s.DB.ExecContext(ctx, query,
+		stats.PrimaryLanguage, stats.Followers, stats.PublicRepos, strings.Join(stats.RepoNames, " "), time.Now().UTC(), userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error updating GitHub stats", "user_id", userID, "error", err)
+		return fmt.Errorf("updating GitHub stats for user %q failed: %w", userID, err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// GetUsersDueForGitHubSync returns up to limit users with a GitHub URL whose stats were
+// never fetched or last fetched before syncedBefore, never fetched first and then the
+// stalest. Users pending deletion are left out.
+func (s *DBService) GetUsersDueForGitHubSync(ctx context.Context, syncedBefore time.Time, limit int) ([]models.User, error) {
+	query := `
+		SELECT ` + userColumns + `
+		FROM users
+		WHERE github_url IS NOT NULL AND github_url <> ''
+			AND deletion_scheduled_for IS NULL
+			AND (github_synced_at IS NULL OR github_synced_at < ?)
+		ORDER BY github_synced_at IS NOT NULL, github_synced_at, created_at
+		LIMIT ?`
+
+	rows, err := s.DB.QueryContext(ctx, query, syncedBefore.UTC(), limit)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying users due for GitHub sync", "error", err)
+		return nil, fmt.Errorf("querying users due for GitHub sync failed: %w", err)
+	}
+	defer rows.Close()
+
+	users := []models.User{}
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning user row failed: %w", err)
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating user rows failed: %w", err)
+	}
+	return users, nil
 }
 
-// TODO: Implement other DB operations (Get Swipes, Create Swipe, Get Conversations, Create Message, etc.)
+// TODO: Implement other DB operations (Get Conversations, Create Message, etc.)
 // Remember to use context and handle potential sql.ErrNoRows appropriately.
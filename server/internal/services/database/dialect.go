@@ -0,0 +1,170 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+)
+
+// Dialect identifies the SQL database behind a DB.
+type Dialect string
+
+const (
+	DialectSQLite   Dialect = "sqlite"
+	DialectPostgres Dialect = "postgres"
+)
+
+// Rebind rewrites the ? placeholders used throughout this package into the dialect's
+// own style ($1, $2, ... for Postgres). Question marks inside quoted strings are kept.
+func (d Dialect) Rebind(query string) string {
+	if d != DialectPostgres || !strings.Contains(query, "?") {
+		return query
+	}
+
+	var b strings.Builder
+	b.Grow(len(query) + 8)
+	n := 0
+	var quote rune // The quote character we are inside of, or 0
+	for _, r := range query {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '?':
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// insertionTiebreak returns an ORDER BY suffix that orders rows of alias created within the
+// same timestamp by insertion order, newest first. SQLite's CURRENT_TIMESTAMP only has
+// second resolution so rowid is used; Postgres timestamps are precise enough on their own.
+func (d Dialect) insertionTiebreak(alias string) string {
+	if d != DialectSQLite {
+		return ""
+	}
+	if alias == "" {
+		return ", rowid DESC"
+	}
+	return ", " + alias + ".rowid DESC"
+}
+
+// isUniqueViolation reports whether err is a unique constraint violation in either dialect.
+func isUniqueViolation(err error) bool {
+	var sqliteErr sqlite3.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.ExtendedCode == sqlite3.ErrConstraintUnique
+	}
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505" // unique_violation
+	}
+	return false
+}
+
+// DB wraps *sql.DB so that queries written with ? placeholders run unchanged on every
+// dialect. Only the context-aware methods rebind; use those.
+//
+// On SQLite, writes go through a separate single-connection pool so that writers queue
+// in Go instead of failing with "database is locked"; the embedded pool only reads.
+// ExecContext and BeginTx use the writer, QueryContext and QueryRowContext the readers.
+// Statements that write and return rows (INSERT ... RETURNING) must go through Writer().
+//
+// DB and Tx time every query for metrics.DBQueryDuration (see observeQuery).
+type DB struct {
+	*sql.DB
+	Dialect    Dialect
+	writer     *sql.DB // Nil when reads and writes share the embedded pool (Postgres)
+	likeSearch bool    // No full-text index (SQLite without FTS5), so search uses LIKE
+}
+
+// Writer returns a DB whose every method uses the write connection.
+func (db *DB) Writer() *DB {
+	if db.writer == nil {
+		return db
+	}
+	return &DB{DB: db.writer, Dialect: db.Dialect, likeSearch: db.likeSearch}
+}
+
+// writePool returns the pool that executes writes.
+func (db *DB) writePool() *sql.DB {
+	if db.writer == nil {
+		return db.DB
+	}
+	return db.writer
+}
+
+// ExecContext rebinds query for the dialect and executes it on the write connection.
+func (db *DB) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer observeQuery(time.Now())
+	return db.writePool().ExecContext(ctx, db.Dialect.Rebind(query), args...)
+}
+
+// QueryContext rebinds query for the dialect and runs it on the read pool.
+func (db *DB) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer observeQuery(time.Now())
+	return db.DB.QueryContext(ctx, db.Dialect.Rebind(query), args...)
+}
+
+// QueryRowContext rebinds query for the dialect and runs it on the read pool.
+func (db *DB) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	defer observeQuery(time.Now())
+	return db.DB.QueryRowContext(ctx, db.Dialect.Rebind(query), args...)
+}
+
+// BeginTx starts a transaction on the write connection whose queries are rebound like the DB's.
+func (db *DB) BeginTx(ctx context.Context, opts *sql.TxOptions) (*Tx, error) {
+	tx, err := db.writePool().BeginTx(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+	return &Tx{Tx: tx, dialect: db.Dialect}, nil
+}
+
+// Close closes the read pool and, if separate, the write connection.
+func (db *DB) Close() error {
+	err := db.DB.Close()
+	if db.writer != nil {
+		if werr := db.writer.Close(); err == nil {
+			err = werr
+		}
+	}
+	return err
+}
+
+// Tx wraps *sql.Tx with the same placeholder rebinding as DB.
+type Tx struct {
+	*sql.Tx
+	dialect Dialect
+}
+
+// ExecContext rebinds query for the dialect and executes it.
+func (tx *Tx) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	defer observeQuery(time.Now())
+	return tx.Tx.ExecContext(ctx, tx.dialect.Rebind(query), args...)
+}
+
+// QueryContext rebinds query for the dialect and runs it.
+func (tx *Tx) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	defer observeQuery(time.Now())
+	return tx.Tx.QueryContext(ctx, tx.dialect.Rebind(query), args...)
+}
+
+// QueryRowContext rebinds query for the dialect and runs it.
+func (tx *Tx) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	defer observeQuery(time.Now())
+	return tx.Tx.QueryRowContext(ctx, tx.dialect.Rebind(query), args...)
+}
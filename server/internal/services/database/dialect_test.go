@@ -0,0 +1,159 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"gin/internal/models"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/mattn/go-sqlite3"
+)
+
+func TestRebind(t *testing.T) {
+	tests := []struct {
+		name    string
+		dialect Dialect
+		query   string
+		want    string
+	}{
+		{"sqlite unchanged", DialectSQLite, `SELECT * FROM users WHERE id = ? AND role = ?`, `SELECT * FROM users WHERE id = ? AND role = ?`},
+		{"postgres numbered", DialectPostgres, `SELECT * FROM users WHERE id = ? AND bio = ?`, `SELECT * FROM users WHERE id = $1 AND bio = $2`},
+		{"no placeholders", DialectPostgres, `SELECT 1`, `SELECT 1`},
+		{"single quotes kept", DialectPostgres, `SELECT '?' || ? FROM t WHERE a = ?`, `SELECT '?' || $1 FROM t WHERE a = $2`},
+		{"double quotes kept", DialectPostgres, `SELECT "odd?name" FROM t WHERE a = ?`, `SELECT "odd?name" FROM t WHERE a = $1`},
+		{"more than nine", DialectPostgres, strings.Repeat("?,", 10) + "?", "$1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.dialect.Rebind(tt.query); got != tt.want {
+				t.Errorf("Rebind(%q) = %q, want %q", tt.query, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInsertionTiebreak(t *testing.T) {
+	tests := []struct {
+		dialect Dialect
+		alias   string
+		want    string
+	}{
+		{DialectSQLite, "", ", rowid DESC"},
+		{DialectSQLite, "s", ", s.rowid DESC"},
+		{DialectPostgres, "s", ""},
+	}
+	for _, tt := range tests {
+		if got := tt.dialect.insertionTiebreak(tt.alias); got != tt.want {
+			t.Errorf("%s.insertionTiebreak(%q) = %q, want %q", tt.dialect, tt.alias, got, tt.want)
+		}
+	}
+}
+
+func TestIsUniqueViolation(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"sqlite unique", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintUnique}, true},
+		{"sqlite foreign key", sqlite3.Error{Code: sqlite3.ErrConstraint, ExtendedCode: sqlite3.ErrConstraintForeignKey}, false},
+		{"postgres unique wrapped", fmt.Errorf("insert: %w", &pgconn.PgError{Code: "23505"}), true},
+		{"postgres foreign key", &pgconn.PgError{Code: "23503"}, false},
+		{"other", errors.New("boom"), false},
+	}
+	for _, tt := range tests {
+		if got := isUniqueViolation(tt.err); got != tt.want {
+			t.Errorf("%s: isUniqueViolation = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestOpenDBSQLiteURL(t *testing.T) {
+	db, err := OpenDB("sqlite://" + filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer db.Close()
+	if db.Dialect != DialectSQLite {
+		t.Errorf("dialect = %s, want sqlite", db.Dialect)
+	}
+}
+
+func TestSQLitePools(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+
+	for name, pool := range map[string]*DB{"readers": db, "writer": db.Writer()} {
+		var mode string
+		if err := pool.QueryRowContext(ctx, `PRAGMA journal_mode`).Scan(&mode); err != nil || mode != "wal" {
+			t.Errorf("%s journal mode = %q (err %v), want wal", name, mode, err)
+		}
+	}
+
+	// ExecContext goes to the writer; the embedded read pool refuses to write
+	if _, err := db.ExecContext(ctx, `CREATE TABLE t (v TEXT)`); err != nil {
+		t.Fatalf("ExecContext: %v", err)
+	}
+	if _, err := db.DB.ExecContext(ctx, `INSERT INTO t (v) VALUES ('x')`); err == nil {
+		t.Error("write on the read pool succeeded, want it refused")
+	}
+	var v string
+	if err := db.Writer().QueryRowContext(ctx, `INSERT INTO t (v) VALUES ('y') RETURNING v`).Scan(&v); err != nil || v != "y" {
+		t.Errorf("INSERT ... RETURNING on the writer = %q (err %v)", v, err)
+	}
+}
+
+func TestConcurrentWrites(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	const users = 12
+	var ids []string
+	for i := 0; i < users; i++ {
+		ids = append(ids, createUser(t, s, fmt.Sprintf("user%d", i), "").ID)
+	}
+
+	// Every user likes every other user at once, with deck reads in between; transactions
+	// that read before writing used to fail with "database is locked"
+	var wg sync.WaitGroup
+	errs := make(chan error, users*users)
+	for _, swiper := range ids {
+		wg.Add(1)
+		go func(swiper string) {
+			defer wg.Done()
+			for _, swiped := range ids {
+				if swiped == swiper {
+					continue
+				}
+				if _, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: swiper, SwipedID: swiped, Direction: models.SwipeLike}, 0); err != nil {
+					errs <- err
+				}
+				if _, err := s.GetSwipeCandidates(ctx, swiper, models.CardFilters{}, 5); err != nil {
+					errs <- err
+				}
+			}
+		}(swiper)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Errorf("concurrent operation failed: %v", err)
+	}
+
+	var conversations int
+	if err := s.DB.QueryRowContext(ctx, `SELECT COUNT(*) FROM conversations`).Scan(&conversations); err != nil {
+		t.Fatal(err)
+	}
+	if want := users * (users - 1) / 2; conversations != want {
+		t.Errorf("%d conversations, want one per pair (%d)", conversations, want)
+	}
+}
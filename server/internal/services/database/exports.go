@@ -0,0 +1,259 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"gin/internal/models"
+)
+
+// --- Personal Data Export Operations ---
+
+// dataExportColumns lists the data_exports columns in the order expected by scanDataExport.
+const dataExportColumns = `id, user_id, status, download_token, file_path, size_bytes, error, created_at, completed_at, expires_at`
+
+func scanDataExport(row rowScanner) (*models.DataExport, error) {
+	var e models.DataExport
+	err := row.Scan(&e.ID, &e.UserID, &e.Status, &e.DownloadToken, &e.FilePath, &e.SizeBytes, &e.Error,
+		&e.CreatedAt, &e.CompletedAt, &e.ExpiresAt)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// GetUserDataExport gathers everything stored about userID for a personal data export.
+// Returns sql.ErrNoRows if the user does not exist.
+func (s *DBService) GetUserDataExport(ctx context.Context, userID string) (*models.UserDataExport, error) {
+	profile, err := s.GetUserProfileByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	export := &models.UserDataExport{
+		Profile: *models.NewUserAccount(profile),
+		GitHub: models.ExportedGitHubData{
+			GitHubURL:       profile.GitHubURL,
+			PrimaryLanguage: profile.PrimaryLanguage,
+			Followers:       profile.GitHubFollowers,
+			PublicRepos:     profile.GitHubPublicRepos,
+			RepoNames:       []string{},
+		},
+	}
+
+	var repoNames sql.NullString
+	if err := s.DB.QueryRowContext(ctx, `SELECT repo_names FROM users WHERE id = ?`, userID).Scan(&repoNames); err != nil {
+		logger.ErrorContext(ctx, "Error fetching repo names for export", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("fetching repo names failed: %w", err)
+	}
+	if repoNames.Valid {
+		export.GitHub.RepoNames = strings.Fields(repoNames.String)
+	}
+
+	if export.Swipes, err = s.getSwipesBy(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Conversations, err = s.GetConversations(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.MessagesSent, err = s.getMessagesSentBy(ctx, userID); err != nil {
+		return nil, err
+	}
+	if export.Notifications, err = s.GetNotifications(ctx, userID, false, math.MaxInt32, 0); err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// getSwipesBy returns every swipe made by userID, oldest first.
+func (s *DBService) getSwipesBy(ctx context.Context, userID string) ([]models.Swipe, error) {
+	query := `SELECT ` + swipeColumns + ` FROM swipes WHERE swiper_user_id = ? ORDER BY created_at`
+	rows, err := s.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying swipes for export", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("querying swipes failed: %w", err)
+	}
+	defer rows.Close()
+
+	swipes := []models.Swipe{}
+	for rows.Next() {
+		var swipe models.Swipe
+		if err := rows.Scan(swipeScanDest(&swipe)...); err != nil {
+			return nil, fmt.Errorf("scanning swipe row failed: %w", err)
+		}
+		swipes = append(swipes, swipe)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating swipe rows failed: %w", err)
+	}
+	return swipes, nil
+}
+
+// getMessagesSentBy returns every message sent by userID, oldest first.
+func (s *DBService) getMessagesSentBy(ctx context.Context, userID string) ([]models.Message, error) {
+	query := `
+		SELECT id, conversation_id, sender_user_id, content, sent_at
+		FROM messages
+		WHERE sender_user_id = ?
+		ORDER BY sent_at`
+	rows, err := s.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying sent messages for export", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("querying messages failed: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []models.Message{}
+	for rows.Next() {
+		var msg models.Message
+		if err := rows.Scan(&msg.ID, &msg.ConversationID, &msg.SenderID, &msg.Content, &msg.SentAt); err != nil {
+			return nil, fmt.Errorf("scanning message row failed: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating message rows failed: %w", err)
+	}
+	return messages, nil
+}
+
+// CountUserHistory returns how many swipes and messages userID has made, as a measure of
+// how large their data export will be.
+func (s *DBService) CountUserHistory(ctx context.Context, userID string) (int, error) {
+	query := `
+		SELECT (SELECT COUNT(*) FROM swipes WHERE swiper_user_id = ?)
+			+ (SELECT COUNT(*) FROM messages WHERE sender_user_id = ?)`
+	var count int
+	if err := s.DB.QueryRowContext(ctx, query, userID, userID).Scan(&count); err != nil {
+		logger.ErrorContext(ctx, "Error counting history", "user_id", userID, "error", err)
+		return 0, fmt.Errorf("counting user history failed: %w", err)
+	}
+	return count, nil
+}
+
+// CreateDataExport records a pending export job for userID that expires at expiresAt
+// unless it is completed first.
+func (s *DBService) CreateDataExport(ctx context.Context, userID string, expiresAt time.Time) (*models.DataExport, error) {
+	query := `
+		INSERT INTO data_exports (user_id, expires_at) VALUES (?, ?)
+		RETURNING ` + dataExportColumns
+
+	export, err := scanDataExport(s.DB.Writer().QueryRowContext(ctx, query, userID, expiresAt.UTC()))
+	if err != nil {
+		logger.ErrorContext(ctx, "Error creating data export", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("creating data export failed: %w", err)
+	}
+	return export, nil
+}
+
+// GetDataExports returns the export jobs of userID, newest first.
+func (s *DBService) GetDataExports(ctx context.Context, userID string) ([]models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE user_id = ?
+		ORDER BY created_at DESC` + s.DB.Dialect.insertionTiebreak("")
+	rows, err := s.DB.QueryContext(ctx, query, userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying data exports", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("querying data exports failed: %w", err)
+	}
+	defer rows.Close()
+
+	exports := []models.DataExport{}
+	for rows.Next() {
+		export, err := scanDataExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning data export row failed: %w", err)
+		}
+		exports = append(exports, *export)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating data export rows failed: %w", err)
+	}
+	return exports, nil
+}
+
+// GetDataExport returns export job exportID if it belongs to userID, or sql.ErrNoRows.
+func (s *DBService) GetDataExport(ctx context.Context, userID, exportID string) (*models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE id = ? AND user_id = ?`
+	export, err := scanDataExport(s.DB.QueryRowContext(ctx, query, exportID, userID))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.ErrorContext(ctx, "Error fetching data export", "export_id", exportID, "error", err)
+		return nil, fmt.Errorf("fetching data export failed: %w", err)
+	}
+	return export, err
+}
+
+// GetDataExportByToken returns the ready export whose download token is token, or sql.ErrNoRows.
+func (s *DBService) GetDataExportByToken(ctx context.Context, token string) (*models.DataExport, error) {
+	query := `SELECT ` + dataExportColumns + ` FROM data_exports WHERE download_token = ? AND status = ?`
+	export, err := scanDataExport(s.DB.QueryRowContext(ctx, query, token, models.DataExportReady))
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		logger.ErrorContext(ctx, "Error fetching data export by token", "error", err)
+		return nil, fmt.Errorf("fetching data export failed: %w", err)
+	}
+	return export, err
+}
+
+// CompleteDataExport marks exportID ready for download from filePath with downloadToken
+// until expiresAt.
+func (s *DBService) CompleteDataExport(ctx context.Context, exportID, filePath, downloadToken string, sizeBytes int64, expiresAt time.Time) error {
+	query := `
+		UPDATE data_exports
+		SET status = ?, file_path = ?, download_token = ?, size_bytes = ?, completed_at = ?, expires_at = ?
+		WHERE id = ?`
+	res, err := s.DB.ExecContext(ctx, query, models.DataExportReady, filePath, downloadToken, sizeBytes,
+		time.Now().UTC(), expiresAt.UTC(), exportID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error completing data export", "export_id", exportID, "error", err)
+		return fmt.Errorf("completing data export failed: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// FailDataExport marks exportID failed with reason; the job is kept until expiresAt so
+// the user can see what happened.
+func (s *DBService) FailDataExport(ctx context.Context, exportID, reason string, expiresAt time.Time) error {
+	query := `
+		UPDATE data_exports SET status = ?, error = ?, completed_at = ?, expires_at = ?
+		WHERE id = ?`
+	res, err := s.DB.ExecContext(ctx, query, models.DataExportFailed, reason, time.Now().UTC(), expiresAt.UTC(), exportID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error marking data export failed", "export_id", exportID, "error", err)
+		return fmt.Errorf("failing data export failed: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
+
+// DeleteExpiredDataExports deletes the export jobs that expired before now and returns
+// them, so the caller can remove their archives.
+func (s *DBService) DeleteExpiredDataExports(ctx context.Context, now time.Time) ([]models.DataExport, error) {
+	query := `DELETE FROM data_exports WHERE expires_at < ? RETURNING ` + dataExportColumns
+	rows, err := s.DB.Writer().QueryContext(ctx, query, now.UTC())
+	if err != nil {
+		logger.ErrorContext(ctx, "Error deleting expired data exports", "error", err)
+		return nil, fmt.Errorf("deleting expired data exports failed: %w", err)
+	}
+	defer rows.Close()
+
+	deleted := []models.DataExport{}
+	for rows.Next() {
+		export, err := scanDataExport(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning data export row failed: %w", err)
+		}
+		deleted = append(deleted, *export)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating data export rows failed: %w", err)
+	}
+	return deleted, nil
+}
@@ -0,0 +1,130 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"gin/internal/models"
+)
+
+func TestGetUserDataExport(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "Go developer")
+		bob := createUser(t, s, "bob", "Rust developer")
+		carol := createUser(t, s, "carol", "")
+
+		for _, swipe := range []models.Swipe{
+			{SwiperID: alice.ID, SwipedID: bob.ID, Direction: models.SwipeLike},
+			{SwiperID: alice.ID, SwipedID: carol.ID, Direction: models.SwipeDislike},
+			{SwiperID: bob.ID, SwipedID: alice.ID, Direction: models.SwipeSuperLike},
+		} {
+			if _, err := s.CreateSwipe(ctx, swipe, 5); err != nil {
+				t.Fatalf("CreateSwipe: %v", err)
+			}
+		}
+		conversations, err := s.GetConversations(ctx, alice.ID)
+		if err != nil || len(conversations) != 1 {
+			t.Fatalf("GetConversations = %v, %v; want the match's conversation", conversations, err)
+		}
+		_, err = s.DB.ExecContext(ctx, `INSERT INTO messages (conversation_id, sender_user_id, content) VALUES (?, ?, ?), (?, ?, ?)`,
+			conversations[0].ID, alice.ID, "Hi Bob", conversations[0].ID, bob.ID, "Hi Alice")
+		if err != nil {
+			t.Fatalf("inserting messages: %v", err)
+		}
+
+		export, err := s.GetUserDataExport(ctx, alice.ID)
+		if err != nil {
+			t.Fatalf("GetUserDataExport: %v", err)
+		}
+		if export.Profile.ID != alice.ID {
+			t.Errorf("profile ID = %s, want %s", export.Profile.ID, alice.ID)
+		}
+		if len(export.Swipes) != 2 {
+			t.Errorf("exported %d swipes, want alice's 2", len(export.Swipes))
+		}
+		if len(export.Conversations) != 1 || len(export.Conversations[0].UserIDs) != 2 {
+			t.Errorf("conversations = %+v, want one with both participants", export.Conversations)
+		}
+		if len(export.MessagesSent) != 1 || export.MessagesSent[0].Content != "Hi Bob" {
+			t.Errorf("messages sent = %+v, want only alice's message", export.MessagesSent)
+		}
+		if len(export.Notifications) != 1 || export.Notifications[0].Type != models.NotificationSuperLike {
+			t.Errorf("notifications = %+v, want bob's super like", export.Notifications)
+		}
+
+		count, err := s.CountUserHistory(ctx, alice.ID)
+		if err != nil || count != 3 {
+			t.Errorf("CountUserHistory = %d, %v; want 2 swipes + 1 message", count, err)
+		}
+
+		if _, err := s.GetUserDataExport(ctx, "00000000-0000-0000-0000-000000000000"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetUserDataExport(unknown user) error = %v, want sql.ErrNoRows", err)
+		}
+	})
+}
+
+func TestDataExportLifecycle(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "")
+		bob := createUser(t, s, "bob", "")
+		now := time.Now()
+
+		export, err := s.CreateDataExport(ctx, alice.ID, now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("CreateDataExport: %v", err)
+		}
+		if export.Status != models.DataExportPending {
+			t.Errorf("new export status = %s, want pending", export.Status)
+		}
+		if _, err := s.GetDataExport(ctx, bob.ID, export.ID); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetDataExport by another user error = %v, want sql.ErrNoRows", err)
+		}
+
+		if err := s.CompleteDataExport(ctx, export.ID, "/tmp/export.zip", "secret-token", 1234, now.Add(24*time.Hour)); err != nil {
+			t.Fatalf("CompleteDataExport: %v", err)
+		}
+		ready, err := s.GetDataExportByToken(ctx, "secret-token")
+		if err != nil {
+			t.Fatalf("GetDataExportByToken: %v", err)
+		}
+		if ready.ID != export.ID || ready.Status != models.DataExportReady || ready.SizeBytes == nil || *ready.SizeBytes != 1234 {
+			t.Errorf("ready export = %+v", ready)
+		}
+		if _, err := s.GetDataExportByToken(ctx, "wrong-token"); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("GetDataExportByToken(wrong token) error = %v, want sql.ErrNoRows", err)
+		}
+
+		failed, err := s.CreateDataExport(ctx, alice.ID, now.Add(time.Hour))
+		if err != nil {
+			t.Fatalf("CreateDataExport: %v", err)
+		}
+		if err := s.FailDataExport(ctx, failed.ID, "disk full", now.Add(-time.Minute)); err != nil {
+			t.Fatalf("FailDataExport: %v", err)
+		}
+		if err := s.FailDataExport(ctx, "00000000-0000-0000-0000-000000000000", "disk full", now); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("FailDataExport(unknown export) error = %v, want sql.ErrNoRows", err)
+		}
+
+		exports, err := s.GetDataExports(ctx, alice.ID)
+		if err != nil || len(exports) != 2 || exports[0].ID != failed.ID {
+			t.Fatalf("GetDataExports = %+v, %v; want both exports, newest first", exports, err)
+		}
+
+		// Only the failed export has expired
+		deleted, err := s.DeleteExpiredDataExports(ctx, now)
+		if err != nil {
+			t.Fatalf("DeleteExpiredDataExports: %v", err)
+		}
+		if len(deleted) != 1 || deleted[0].ID != failed.ID || deleted[0].Error == nil || *deleted[0].Error != "disk full" {
+			t.Errorf("deleted = %+v, want the failed export", deleted)
+		}
+		if exports, _ := s.GetDataExports(ctx, alice.ID); len(exports) != 1 || exports[0].ID != export.ID {
+			t.Errorf("exports left = %+v, want the ready export", exports)
+		}
+	})
+}
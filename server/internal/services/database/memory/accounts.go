@@ -0,0 +1,176 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"gin/internal/models"
+	"gin/internal/services/database"
+)
+
+// ScheduleAccountDeletion schedules userID to be purged at purgeAt, keeping an earlier schedule.
+func (s *Store) ScheduleAccountDeletion(ctx context.Context, userID string, purgeAt time.Time) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.users[userID]
+	if !ok {
+		return time.Time{}, sql.ErrNoRows
+	}
+	if rec.user.DeletionScheduledFor == nil {
+		at := purgeAt.UTC()
+		rec.user.DeletionScheduledFor = &at
+	}
+	return *rec.user.DeletionScheduledFor, nil
+}
+
+// CancelAccountDeletion cancels the pending deletion of userID, or returns
+// database.ErrNoDeletionScheduled.
+func (s *Store) CancelAccountDeletion(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.users[userID]
+	if !ok || rec.user.DeletionScheduledFor == nil {
+		return database.ErrNoDeletionScheduled
+	}
+	rec.user.DeletionScheduledFor = nil
+	return nil
+}
+
+// GetAccountsDueForDeletion returns up to limit users whose grace period ended by now.
+func (s *Store) GetAccountsDueForDeletion(ctx context.Context, now time.Time, limit int) ([]models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	due := []models.User{}
+	for _, user := range s.sortedUsers() {
+		if user.DeletionScheduledFor != nil && !user.DeletionScheduledFor.After(now) {
+			due = append(due, user)
+		}
+	}
+	return paginate(due, limit, 0), nil
+}
+
+// PurgeUser deletes userID and everything that cascades from it, keeping their messages
+// in conversations others are still in without a sender.
+func (s *Store) PurgeUser(ctx context.Context, userID string) (*models.AccountPurgeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[userID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	var result models.AccountPurgeResult
+	for id, conv := range s.conversations {
+		if !contains(conv.conversation.UserIDs, userID) {
+			continue
+		}
+		others := []string{}
+		for _, participant := range conv.conversation.UserIDs {
+			if participant != userID {
+				others = append(others, participant)
+			}
+		}
+		if len(others) == 0 {
+			delete(s.conversations, id)
+			result.ConversationsDeleted++
+		} else {
+			conv.conversation.UserIDs = others
+		}
+	}
+
+	messages := s.messages[:0]
+	for _, msg := range s.messages {
+		if _, ok := s.conversations[msg.ConversationID]; !ok {
+			continue // Its conversation was deleted
+		}
+		if sentBy(msg, userID) {
+			msg.SenderID = nil
+			result.MessagesAnonymized++
+		}
+		messages = append(messages, msg)
+	}
+	s.messages = messages
+
+	removedSwipes := make(map[string]struct{})
+	swipes := s.swipes[:0]
+	for _, rec := range s.swipes {
+		if rec.swipe.SwiperID == userID || rec.swipe.SwipedID == userID {
+			removedSwipes[rec.swipe.ID] = struct{}{}
+			continue
+		}
+		swipes = append(swipes, rec)
+	}
+	s.swipes = swipes
+
+	notifications := s.notifications[:0]
+	for _, rec := range s.notifications {
+		n := rec.notification
+		if n.UserID == userID || (n.ActorUserID != nil && *n.ActorUserID == userID) {
+			continue
+		}
+		if n.SwipeID != nil {
+			if _, removed := removedSwipes[*n.SwipeID]; removed {
+				continue
+			}
+		}
+		notifications = append(notifications, rec)
+	}
+	s.notifications = notifications
+
+	for key := range s.quotas {
+		if strings.HasPrefix(key, userID+"/") {
+			delete(s.quotas, key)
+		}
+	}
+	exports := s.dataExports[:0]
+	for _, export := range s.dataExports {
+		if export.UserID != userID {
+			exports = append(exports, export)
+		}
+	}
+	s.dataExports = exports
+
+	for key := range s.blocks {
+		blocker, blocked, _ := strings.Cut(key, "/")
+		if blocker == userID || blocked == userID {
+			delete(s.blocks, key)
+		}
+	}
+
+	reports := s.reports[:0]
+	for _, report := range s.reports {
+		if report.ReportedUserID == userID {
+			delete(s.reportEvidence, report.ID)
+			continue
+		}
+		if report.ReporterUserID != nil && *report.ReporterUserID == userID {
+			report.ReporterUserID = nil
+		}
+		if report.ResolvedByUserID != nil && *report.ResolvedByUserID == userID {
+			report.ResolvedByUserID = nil
+		}
+		reports = append(reports, report)
+	}
+	s.reports = reports
+	for _, evidence := range s.reportEvidence {
+		for i := range evidence {
+			if evidence[i].SenderUserID != nil && *evidence[i].SenderUserID == userID {
+				evidence[i].SenderUserID = nil
+			}
+		}
+	}
+	s.forgetRemovedEvidence()
+
+	moderationLog := s.moderationLog[:0]
+	for _, event := range s.moderationLog {
+		if event.UserID != userID {
+			moderationLog = append(moderationLog, event)
+		}
+	}
+	s.moderationLog = moderationLog
+	s.forgetRemovedModerationRefs()
+
+	delete(s.users, userID)
+	return &result, nil
+}
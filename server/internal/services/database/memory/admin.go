@@ -0,0 +1,177 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin/internal/models"
+	"gin/internal/services/database"
+)
+
+// SetUserRole changes the role of userID, or returns sql.ErrNoRows.
+func (s *Store) SetUserRole(ctx context.Context, userID string, role models.UserRole) error {
+	if !role.Valid() {
+		return fmt.Errorf("unknown role %q", role)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.users[userID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	rec.user.Role = role
+	rec.user.UpdatedAt = s.now()
+	return nil
+}
+
+// ListUsers returns users matching filter, newest first.
+func (s *Store) ListUsers(ctx context.Context, filter models.AdminUserFilter, limit, offset int) ([]models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	query := strings.TrimSpace(filter.Query)
+	lowerQuery := strings.ToLower(query)
+	users := s.sortedUsers()
+	matched := []models.User{}
+	for i := len(users) - 1; i >= 0; i-- {
+		user := users[i]
+		if query != "" &&
+			!strings.Contains(strings.ToLower(deref(user.Username)), lowerQuery) &&
+			!strings.Contains(strings.ToLower(deref(user.GitHubURL)), lowerQuery) &&
+			user.ID != query && user.ClerkUserID != query {
+			continue
+		}
+		if filter.Role != "" && user.Role != filter.Role {
+			continue
+		}
+		if filter.Suspended != nil && (user.SuspendedAt != nil) != *filter.Suspended {
+			continue
+		}
+		matched = append(matched, user)
+	}
+	return paginate(matched, limit, offset), nil
+}
+
+// SuspendUser suspends userID for reason, keeping an earlier suspension time.
+func (s *Store) SuspendUser(ctx context.Context, userID, reason string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.users[userID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	if rec.user.SuspendedAt == nil {
+		now := s.now()
+		rec.user.SuspendedAt = &now
+	}
+	rec.user.SuspensionReason = &reason
+	return nil
+}
+
+// UnsuspendUser lifts the suspension of userID, or returns database.ErrNotSuspended.
+func (s *Store) UnsuspendUser(ctx context.Context, userID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.users[userID]
+	if !ok || rec.user.SuspendedAt == nil {
+		return database.ErrNotSuspended
+	}
+	rec.user.SuspendedAt = nil
+	rec.user.SuspensionReason = nil
+	return nil
+}
+
+// GetReports returns reports with status (any if empty), oldest first.
+func (s *Store) GetReports(ctx context.Context, status models.ReportStatus, limit, offset int) ([]models.Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	reports := []models.Report{}
+	for _, report := range s.reports {
+		if status == "" || report.Status == status {
+			reports = append(reports, report)
+		}
+	}
+	return paginate(reports, limit, offset), nil
+}
+
+// GetReport returns the report with reportID, or sql.ErrNoRows.
+func (s *Store) GetReport(ctx context.Context, reportID string) (*models.Report, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, report := range s.reports {
+		if report.ID == reportID {
+			return &report, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// ResolveReport closes the open report reportID as resolution describes, or returns
+// sql.ErrNoRows or database.ErrReportNotOpen.
+func (s *Store) ResolveReport(ctx context.Context, reportID, moderatorID string, resolution models.ReportResolution) (*models.Report, error) {
+	if resolution.Status != models.ReportResolved && resolution.Status != models.ReportDismissed {
+		return nil, fmt.Errorf("cannot resolve a report as %q", resolution.Status)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.reports {
+		report := &s.reports[i]
+		if report.ID != reportID {
+			continue
+		}
+		if report.Status != models.ReportOpen {
+			return nil, database.ErrReportNotOpen
+		}
+		now := s.now()
+		report.Status = resolution.Status
+		report.ResolvedAt = &now
+		report.ResolvedByUserID = &moderatorID
+		report.ResolutionNote = resolution.Note
+		if len(resolution.Actions) > 0 {
+			report.ResolutionActions = append([]models.ReportAction(nil), resolution.Actions...)
+		}
+		resolved := *report
+		return &resolved, nil
+	}
+	return nil, sql.ErrNoRows
+}
+
+// GetSystemStats counts users, activity and moderation work; recent means the 7 days before now.
+func (s *Store) GetSystemStats(ctx context.Context, now time.Time) (*models.SystemStats, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	weekAgo := now.UTC().AddDate(0, 0, -7)
+
+	stats := models.SystemStats{
+		UsersByRole: map[models.UserRole]int{},
+		Users:       len(s.users),
+		Swipes:      len(s.swipes),
+		Matches:     len(s.conversations),
+		Messages:    len(s.messages),
+	}
+	for _, rec := range s.users {
+		user := rec.user
+		stats.UsersByRole[user.Role]++
+		if user.SuspendedAt != nil {
+			stats.SuspendedUsers++
+		}
+		if user.DeletionScheduledFor != nil {
+			stats.PendingDeletions++
+		}
+		if user.LastActiveAt != nil && !user.LastActiveAt.Before(weekAgo) {
+			stats.ActiveUsersLast7Days++
+		}
+		if !user.CreatedAt.Before(weekAgo) {
+			stats.NewUsersLast7Days++
+		}
+	}
+	for _, report := range s.reports {
+		if report.Status == models.ReportOpen {
+			stats.OpenReports++
+		}
+	}
+	return &stats, nil
+}
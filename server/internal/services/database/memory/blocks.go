@@ -0,0 +1,103 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"sort"
+
+	"gin/internal/models"
+)
+
+// blockKey is the key of the block blockerID placed on blockedID.
+func blockKey(blockerID, blockedID string) string {
+	return blockerID + "/" + blockedID
+}
+
+// blockedBetween reports whether userA and userB have blocked each other in either
+// direction. Callers must hold s.mu.
+func (s *Store) blockedBetween(userA, userB string) bool {
+	_, ab := s.blocks[blockKey(userA, userB)]
+	_, ba := s.blocks[blockKey(userB, userA)]
+	return ab || ba
+}
+
+// blockedInConversation reports whether userID has a block with another participant of
+// conv. Callers must hold s.mu.
+func (s *Store) blockedInConversation(conv *conversationRecord, userID string) bool {
+	for _, participant := range conv.conversation.UserIDs {
+		if participant != userID && s.blockedBetween(userID, participant) {
+			return true
+		}
+	}
+	return false
+}
+
+// BlockUser makes blockerID block blockedID, or returns sql.ErrNoRows if either is missing.
+func (s *Store) BlockUser(ctx context.Context, blockerID, blockedID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[blockerID]; !ok {
+		return sql.ErrNoRows
+	}
+	if _, ok := s.users[blockedID]; !ok {
+		return sql.ErrNoRows
+	}
+	key := blockKey(blockerID, blockedID)
+	if _, exists := s.blocks[key]; !exists {
+		s.blocks[key] = blockRecord{blockedAt: s.now(), seq: s.nextSeq()}
+	}
+	return nil
+}
+
+// UnblockUser lifts the block blockerID placed on blockedID, or returns sql.ErrNoRows.
+func (s *Store) UnblockUser(ctx context.Context, blockerID, blockedID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := blockKey(blockerID, blockedID)
+	if _, exists := s.blocks[key]; !exists {
+		return sql.ErrNoRows
+	}
+	delete(s.blocks, key)
+	return nil
+}
+
+// GetBlockedUsers returns the users blockerID has blocked, most recently blocked first.
+func (s *Store) GetBlockedUsers(ctx context.Context, blockerID string) ([]models.BlockedUser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type entry struct {
+		blocked models.BlockedUser
+		seq     int64
+	}
+	entries := []entry{}
+	for _, rec := range s.users {
+		block, ok := s.blocks[blockKey(blockerID, rec.user.ID)]
+		if !ok {
+			continue
+		}
+		entries = append(entries, entry{models.BlockedUser{User: copyUser(rec.user), BlockedAt: block.blockedAt}, block.seq})
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].seq > entries[j].seq })
+
+	blocked := make([]models.BlockedUser, len(entries))
+	for i, e := range entries {
+		blocked[i] = e.blocked
+	}
+	return blocked, nil
+}
+
+// IsBlockedBetween reports whether userA and userB have blocked each other in either direction.
+func (s *Store) IsBlockedBetween(ctx context.Context, userA, userB string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.blockedBetween(userA, userB), nil
+}
+
+// IsBlockedInConversation reports whether userID has a block with another participant of conversationID.
+func (s *Store) IsBlockedInConversation(ctx context.Context, conversationID, userID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conv, ok := s.conversations[conversationID]
+	return ok && s.blockedInConversation(conv, userID), nil
+}
@@ -0,0 +1,182 @@
+package memory
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"gin/internal/models"
+)
+
+// GetUserDataExport gathers everything stored about userID, or returns sql.ErrNoRows.
+func (s *Store) GetUserDataExport(ctx context.Context, userID string) (*models.UserDataExport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.users[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+
+	profile := copyUser(rec.user)
+	export := &models.UserDataExport{
+		Profile: *models.NewUserAccount(&profile),
+		GitHub: models.ExportedGitHubData{
+			GitHubURL:       profile.GitHubURL,
+			PrimaryLanguage: profile.PrimaryLanguage,
+			Followers:       profile.GitHubFollowers,
+			PublicRepos:     profile.GitHubPublicRepos,
+			RepoNames:       append([]string{}, rec.repoNames...),
+		},
+		Swipes:        []models.Swipe{},
+		Conversations: []models.Conversation{},
+		MessagesSent:  []models.Message{},
+		Notifications: []models.Notification{},
+	}
+	for _, swipe := range s.swipes {
+		if swipe.swipe.SwiperID == userID {
+			export.Swipes = append(export.Swipes, swipe.swipe)
+		}
+	}
+	for _, conv := range s.conversations {
+		if contains(conv.conversation.UserIDs, userID) {
+			c := conv.conversation
+			c.UserIDs = append([]string(nil), c.UserIDs...)
+			export.Conversations = append(export.Conversations, c)
+		}
+	}
+	for _, msg := range s.messages {
+		if sentBy(msg, userID) {
+			export.MessagesSent = append(export.MessagesSent, msg)
+		}
+	}
+	for i := len(s.notifications) - 1; i >= 0; i-- {
+		if n := s.notifications[i].notification; n.UserID == userID {
+			export.Notifications = append(export.Notifications, n)
+		}
+	}
+	return export, nil
+}
+
+// CountUserHistory returns how many swipes and messages userID has made.
+func (s *Store) CountUserHistory(ctx context.Context, userID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	count := 0
+	for _, swipe := range s.swipes {
+		if swipe.swipe.SwiperID == userID {
+			count++
+		}
+	}
+	for _, msg := range s.messages {
+		if sentBy(msg, userID) {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// CreateDataExport records a pending export job for userID.
+func (s *Store) CreateDataExport(ctx context.Context, userID string, expiresAt time.Time) (*models.DataExport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.users[userID]; !ok {
+		return nil, sql.ErrNoRows
+	}
+	export := models.DataExport{
+		ID:        newID(),
+		UserID:    userID,
+		Status:    models.DataExportPending,
+		CreatedAt: s.now(),
+		ExpiresAt: expiresAt.UTC(),
+	}
+	s.dataExports = append(s.dataExports, export)
+	return &export, nil
+}
+
+// GetDataExports returns the export jobs of userID, newest first.
+func (s *Store) GetDataExports(ctx context.Context, userID string) ([]models.DataExport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	exports := []models.DataExport{}
+	for i := len(s.dataExports) - 1; i >= 0; i-- {
+		if s.dataExports[i].UserID == userID {
+			exports = append(exports, s.dataExports[i])
+		}
+	}
+	return exports, nil
+}
+
+// GetDataExport returns export job exportID if it belongs to userID, or sql.ErrNoRows.
+func (s *Store) GetDataExport(ctx context.Context, userID, exportID string) (*models.DataExport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, export := range s.dataExports {
+		if export.ID == exportID && export.UserID == userID {
+			return &export, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// GetDataExportByToken returns the ready export with download token token, or sql.ErrNoRows.
+func (s *Store) GetDataExportByToken(ctx context.Context, token string) (*models.DataExport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, export := range s.dataExports {
+		if export.Status == models.DataExportReady && export.DownloadToken != nil && *export.DownloadToken == token {
+			return &export, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// CompleteDataExport marks exportID ready for download.
+func (s *Store) CompleteDataExport(ctx context.Context, exportID, filePath, downloadToken string, sizeBytes int64, expiresAt time.Time) error {
+	return s.finishDataExport(exportID, func(export *models.DataExport) {
+		export.Status = models.DataExportReady
+		export.FilePath = &filePath
+		export.DownloadToken = &downloadToken
+		export.SizeBytes = &sizeBytes
+		export.ExpiresAt = expiresAt.UTC()
+	})
+}
+
+// FailDataExport marks exportID failed with reason.
+func (s *Store) FailDataExport(ctx context.Context, exportID, reason string, expiresAt time.Time) error {
+	return s.finishDataExport(exportID, func(export *models.DataExport) {
+		export.Status = models.DataExportFailed
+		export.Error = &reason
+		export.ExpiresAt = expiresAt.UTC()
+	})
+}
+
+func (s *Store) finishDataExport(exportID string, update func(*models.DataExport)) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.dataExports {
+		if s.dataExports[i].ID == exportID {
+			now := s.now()
+			s.dataExports[i].CompletedAt = &now
+			update(&s.dataExports[i])
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// DeleteExpiredDataExports deletes and returns the export jobs that expired before now.
+func (s *Store) DeleteExpiredDataExports(ctx context.Context, now time.Time) ([]models.DataExport, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	deleted := []models.DataExport{}
+	kept := s.dataExports[:0]
+	for _, export := range s.dataExports {
+		if export.ExpiresAt.Before(now) {
+			deleted = append(deleted, export)
+		} else {
+			kept = append(kept, export)
+		}
+	}
+	s.dataExports = kept
+	return deleted, nil
+}
@@ -0,0 +1,808 @@
+// Package memory provides in-memory implementations of the database repositories so
+// handlers can be exercised without a database file. It mirrors the behaviour of the
+// SQL implementation, including its sentinel errors, but keeps everything in maps.
+package memory
+
+import (
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"gin/internal/models"
+	"gin/internal/services/database"
+)
+
+// Store is an in-memory implementation of every repository in package database.
+// It is safe for concurrent use. The zero value is not usable; call NewStore.
+type Store struct {
+	mu  sync.Mutex
+	now func() time.Time // Clock, replaceable to test time-based behaviour
+	seq int64            // Insertion counter used to order rows created at the same instant
+
+	users          map[string]*userRecord // Keyed by user ID
+	swipes         []swipeRecord
+	quotas         map[string]int // Super likes used, keyed by user ID + "/" + UTC day
+	conversations  map[string]*conversationRecord
+	messages       []models.Message
+	notifications  []notificationRecord
+	dataExports    []models.DataExport                 // In creation order
+	webhookEvents  map[string]struct{}                 // Processed webhook deliveries, keyed by source + "/" + event ID
+	reports        []models.Report                     // In creation order
+	blocks         map[string]blockRecord              // Keyed by blocker ID + "/" + blocked ID
+	reportEvidence map[string][]models.ReportedMessage // Messages attached to reports, keyed by report ID
+	moderationLog  []models.ModerationEvent            // In creation order
+}
+
+type userRecord struct {
+	user           models.User
+	repoNames      []string
+	githubSyncedAt *time.Time
+}
+
+type blockRecord struct {
+	blockedAt time.Time
+	seq       int64
+}
+
+type swipeRecord struct {
+	swipe models.Swipe
+	seq   int64
+}
+
+type conversationRecord struct {
+	conversation models.Conversation
+}
+
+type notificationRecord struct {
+	notification models.Notification
+	seq          int64
+}
+
+// The Store implements every repository.
+var (
+	_ database.UserRepository         = (*Store)(nil)
+	_ database.SwipeRepository        = (*Store)(nil)
+	_ database.ConversationRepository = (*Store)(nil)
+	_ database.NotificationRepository = (*Store)(nil)
+	_ database.DataExportRepository   = (*Store)(nil)
+	_ database.AccountRepository      = (*Store)(nil)
+	_ database.WebhookEventRepository = (*Store)(nil)
+	_ database.BlockRepository        = (*Store)(nil)
+	_ database.ReportRepository       = (*Store)(nil)
+	_ database.ModerationRepository   = (*Store)(nil)
+	_ database.AdminRepository        = (*Store)(nil)
+)
+
+// NewStore creates an empty Store that uses the system clock.
+func NewStore() *Store {
+	return &Store{
+		now:            func() time.Time { return time.Now().UTC() },
+		users:          make(map[string]*userRecord),
+		quotas:         make(map[string]int),
+		conversations:  make(map[string]*conversationRecord),
+		webhookEvents:  make(map[string]struct{}),
+		blocks:         make(map[string]blockRecord),
+		reportEvidence: make(map[string][]models.ReportedMessage),
+	}
+}
+
+// SetClock replaces the clock used for timestamps, e.g. to test undo windows.
+func (s *Store) SetClock(now func() time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.now = now
+}
+
+// newID returns a random ID in the same format as the SQLite schema's default.
+func newID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic("memory: failed to generate ID: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
+
+// nextSeq returns the next insertion sequence number. Callers must hold s.mu.
+func (s *Store) nextSeq() int64 {
+	s.seq++
+	return s.seq
+}
+
+// copyUser returns a copy of u that shares no slices with the store.
+func copyUser(u models.User) models.User {
+	if u.Interests != nil {
+		u.Interests = append([]string(nil), u.Interests...)
+	}
+	return u
+}
+
+// --- Users ---
+
+// GetUserProfileByClerkID returns the user with clerkUserID, or sql.ErrNoRows.
+func (s *Store) GetUserProfileByClerkID(ctx context.Context, clerkUserID string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, rec := range s.users {
+		if rec.user.ClerkUserID == clerkUserID {
+			user := copyUser(rec.user)
+			return &user, nil
+		}
+	}
+	return nil, sql.ErrNoRows
+}
+
+// GetUserProfileByID returns the user with userID, or sql.ErrNoRows.
+func (s *Store) GetUserProfileByID(ctx context.Context, userID string) (*models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.users[userID]
+	if !ok {
+		return nil, sql.ErrNoRows
+	}
+	user := copyUser(rec.user)
+	return &user, nil
+}
+
+// GetAllUserProfiles returns every user, oldest first.
+func (s *Store) GetAllUserProfiles(ctx context.Context) ([]models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.sortedUsers(), nil
+}
+
+// sortedUsers returns copies of all users ordered by creation. Callers must hold s.mu.
+func (s *Store) sortedUsers() []models.User {
+	users := make([]models.User, 0, len(s.users))
+	for _, rec := range s.users {
+		users = append(users, copyUser(rec.user))
+	}
+	sort.Slice(users, func(i, j int) bool {
+		if !users[i].CreatedAt.Equal(users[j].CreatedAt) {
+			return users[i].CreatedAt.Before(users[j].CreatedAt)
+		}
+		return users[i].ID < users[j].ID
+	})
+	return users
+}
+
+// CreateOrUpdateUserProfile upserts by ClerkUserID with the same rules as the SQL version:
+// nil fields keep their stored value, Interests are replaced only when non-nil and the
+// role only when set.
+func (s *Store) CreateOrUpdateUserProfile(ctx context.Context, user models.User) (*models.User, error) {
+	if user.ClerkUserID == "" {
+		return nil, errors.New("ClerkUserID is required to create or update profile")
+	}
+	if user.Role != "" && !user.Role.Valid() {
+		return nil, fmt.Errorf("unknown role %q", user.Role)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+
+	var rec *userRecord
+	for _, existing := range s.users {
+		if existing.user.ClerkUserID == user.ClerkUserID {
+			rec = existing
+			break
+		}
+	}
+	if rec == nil {
+		rec = &userRecord{user: models.User{
+			ID:          newID(),
+			ClerkUserID: user.ClerkUserID,
+			Interests:   []string{},
+			Role:        models.RoleUser,
+			CreatedAt:   now,
+		}}
+		s.users[rec.user.ID] = rec
+	}
+
+	stored := &rec.user
+	if user.Username != nil {
+		stored.Username = user.Username
+	}
+	if user.PictureURL != nil {
+		stored.PictureURL = user.PictureURL
+	}
+	if user.Bio != nil {
+		stored.Bio = user.Bio
+	}
+	if user.GitHubURL != nil {
+		stored.GitHubURL = user.GitHubURL
+	}
+	if user.TimezoneOffsetMinutes != nil {
+		stored.TimezoneOffsetMinutes = user.TimezoneOffsetMinutes
+	}
+	if user.Role != "" {
+		stored.Role = user.Role
+	}
+	if user.Interests != nil {
+		stored.Interests = models.NormalizeInterestTags(user.Interests)
+		sort.Strings(stored.Interests)
+	}
+	stored.LastActiveAt = &now
+	stored.UpdatedAt = now
+
+	result := copyUser(*stored)
+	return &result, nil
+}
+
+// UpdateGitHubStats stores the GitHub activity summary of userID, or returns sql.ErrNoRows.
+func (s *Store) UpdateGitHubStats(ctx context.Context, userID string, stats models.GitHubStats) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	rec, ok := s.users[userID]
+	if !ok {
+		return sql.ErrNoRows
+	}
+	rec.user.PrimaryLanguage = stats.PrimaryLanguage
+	rec.user.GitHubFollowers = stats.Followers
+	rec.user.GitHubPublicRepos = stats.PublicRepos
+	rec.repoNames = append([]string(nil), stats.RepoNames...)
+	now := s.now()
+	rec.githubSyncedAt = &now
+	rec.user.UpdatedAt = now
+	return nil
+}
+
+// GetUsersDueForGitHubSync returns up to limit users with a GitHub URL whose stats were
+// never fetched or last fetched before syncedBefore, never fetched first and then the
+// stalest. Users pending deletion are left out.
+func (s *Store) GetUsersDueForGitHubSync(ctx context.Context, syncedBefore time.Time, limit int) ([]models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	type due struct {
+		user     models.User
+		syncedAt *time.Time
+	}
+	var candidates []due
+	for _, user := range s.sortedUsers() {
+		rec := s.users[user.ID]
+		if deref(user.GitHubURL) == "" || user.DeletionScheduledFor != nil {
+			continue
+		}
+		if rec.githubSyncedAt != nil && !rec.githubSyncedAt.Before(syncedBefore) {
+			continue
+		}
+		candidates = append(candidates, due{user: user, syncedAt: rec.githubSyncedAt})
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		a, b := candidates[i].syncedAt, candidates[j].syncedAt
+		if a == nil || b == nil {
+			return a == nil && b != nil
+		}
+		return a.Before(*b)
+	})
+
+	users := []models.User{}
+	for _, c := range candidates {
+		if len(users) == limit {
+			break
+		}
+		users = append(users, c.user)
+	}
+	return users, nil
+}
+
+// searchField is one searchable part of a profile and its weight, matching the SQL ranking.
+type searchField struct {
+	text   string
+	weight float64
+}
+
+// searchTokens splits text into lowercase words the way the SQL indexes do.
+func searchTokens(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// SearchUsers matches every query word as a prefix of some word in the username, bio,
+// interests or repository names, ranking by the weights of the fields that matched.
+// The snippet is the highest-weighted matching field with matches marked.
+func (s *Store) SearchUsers(ctx context.Context, callerID, query string, limit, offset int) ([]models.UserSearchResult, error) {
+	terms := searchTokens(query)
+	if len(terms) == 0 {
+		return []models.UserSearchResult{}, nil
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	results := []models.UserSearchResult{}
+	for _, user := range s.sortedUsers() {
+		if user.ID == callerID || user.DeletionScheduledFor != nil || user.SuspendedAt != nil || s.blockedBetween(callerID, user.ID) {
+			continue
+		}
+		rec := s.users[user.ID]
+		fields := []searchField{
+			{deref(user.Username), 10},
+			{deref(user.Bio), 2},
+			{strings.Join(user.Interests, " "), 5},
+			{strings.Join(rec.repoNames, " "), 3},
+		}
+
+		var rank float64
+		var best *searchField
+		matchedAll := true
+		for _, term := range terms {
+			matched := false
+			for i := range fields {
+				if fieldHasPrefix(fields[i].text, term) {
+					matched = true
+					rank += fields[i].weight
+					if best == nil || fields[i].weight > best.weight {
+						best = &fields[i]
+					}
+				}
+			}
+			if !matched {
+				matchedAll = false
+				break
+			}
+		}
+		if !matchedAll {
+			continue
+		}
+		results = append(results, models.UserSearchResult{
+			User:    user,
+			Snippet: markMatches(best.text, terms),
+			Rank:    rank,
+		})
+	}
+
+	sort.SliceStable(results, func(i, j int) bool { return results[i].Rank > results[j].Rank })
+	return paginate(results, limit, offset), nil
+}
+
+// fieldHasPrefix reports whether any word of text starts with term.
+func fieldHasPrefix(text, term string) bool {
+	for _, word := range searchTokens(text) {
+		if strings.HasPrefix(word, term) {
+			return true
+		}
+	}
+	return false
+}
+
+// markMatches HTML-escapes text and wraps words starting with any of terms in <mark> tags.
+func markMatches(text string, terms []string) string {
+	var b strings.Builder
+	for i, word := range strings.Fields(text) {
+		if i > 0 {
+			b.WriteByte(' ')
+		}
+		marked := false
+		for _, token := range searchTokens(word) {
+			for _, term := range terms {
+				if strings.HasPrefix(token, term) {
+					marked = true
+				}
+			}
+		}
+		if marked {
+			b.WriteString("<mark>" + html.EscapeString(word) + "</mark>")
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+	}
+	return b.String()
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+// paginate returns the page of items described by limit and offset.
+func paginate[T any](items []T, limit, offset int) []T {
+	if offset >= len(items) {
+		return items[:0]
+	}
+	items = items[offset:]
+	if limit < len(items) {
+		items = items[:limit]
+	}
+	return items
+}
+
+// --- Swipes ---
+
+// CreateSwipe records swipe, enforcing the super like quota and opening a conversation
+// on a mutual like, like DBService.CreateSwipe.
+func (s *Store) CreateSwipe(ctx context.Context, swipe models.Swipe, superLikeDailyLimit int) (*models.SwipeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := s.now()
+
+	if _, ok := s.users[swipe.SwiperID]; !ok {
+		return nil, errors.New("swiper does not exist")
+	}
+	if _, ok := s.users[swipe.SwipedID]; !ok {
+		return nil, errors.New("swiped user does not exist")
+	}
+	if s.blockedBetween(swipe.SwiperID, swipe.SwipedID) {
+		return nil, database.ErrBlocked
+	}
+	for _, rec := range s.swipes {
+		if rec.swipe.SwiperID == swipe.SwiperID && rec.swipe.SwipedID == swipe.SwipedID {
+			return nil, database.ErrAlreadySwiped
+		}
+	}
+
+	var result models.SwipeResult
+	if swipe.Direction == models.SwipeSuperLike {
+		key := swipe.SwiperID + "/" + now.Format("2006-01-02")
+		if superLikeDailyLimit <= 0 || s.quotas[key] >= superLikeDailyLimit {
+			return nil, database.ErrSuperLikeQuotaExceeded
+		}
+		s.quotas[key]++
+		remaining := superLikeDailyLimit - s.quotas[key]
+		result.SuperLikesRemaining = &remaining
+	}
+
+	swipe.ID = newID()
+	swipe.CreatedAt = now
+	s.swipes = append(s.swipes, swipeRecord{swipe: swipe, seq: s.nextSeq()})
+	result.Swipe = swipe
+
+	if swipe.Direction == models.SwipeSuperLike {
+		swiperID, swipeID := swipe.SwiperID, swipe.ID
+		s.notifications = append(s.notifications, notificationRecord{
+			notification: models.Notification{
+				ID:          newID(),
+				UserID:      swipe.SwipedID,
+				Type:        models.NotificationSuperLike,
+				ActorUserID: &swiperID,
+				SwipeID:     &swipeID,
+				Message:     swipe.IntroNote,
+				CreatedAt:   now,
+			},
+			seq: s.nextSeq(),
+		})
+	}
+
+	if rec, ok := s.users[swipe.SwiperID]; ok {
+		rec.user.LastActiveAt = &now
+	}
+
+	if swipe.Direction.IsLike() {
+		for _, rec := range s.swipes {
+			if rec.swipe.SwiperID == swipe.SwipedID && rec.swipe.SwipedID == swipe.SwiperID && rec.swipe.Direction.IsLike() {
+				// A conversation kept by an undo (because it had messages) is picked up again
+				conv := s.conversationBetween(swipe.SwiperID, swipe.SwipedID)
+				if conv == nil {
+					conv = &conversationRecord{conversation: models.Conversation{
+						ID:        newID(),
+						UserIDs:   []string{swipe.SwiperID, swipe.SwipedID},
+						CreatedAt: now,
+						UpdatedAt: now,
+					}}
+					s.conversations[conv.conversation.ID] = conv
+				}
+				conversationID := conv.conversation.ID
+				result.Matched = true
+				result.ConversationID = &conversationID
+				break
+			}
+		}
+	}
+	return &result, nil
+}
+
+// GetSwipeCandidates returns users userID has not swiped on that match filters.
+func (s *Store) GetSwipeCandidates(ctx context.Context, userID string, filters models.CardFilters, limit int) ([]models.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	swiped := make(map[string]struct{})
+	for _, rec := range s.swipes {
+		if rec.swipe.SwiperID == userID {
+			swiped[rec.swipe.SwipedID] = struct{}{}
+		}
+	}
+	wantTags := models.NormalizeInterestTags(filters.Interests)
+	var activeSince time.Time
+	if filters.ActiveWithinDays > 0 {
+		activeSince = s.now().AddDate(0, 0, -filters.ActiveWithinDays)
+	}
+
+	users := s.sortedUsers()
+	// Newest profiles first, as in the SQL version
+	for i, j := 0, len(users)-1; i < j; i, j = i+1, j-1 {
+		users[i], users[j] = users[j], users[i]
+	}
+
+	candidates := []models.User{}
+	for _, user := range users {
+		if user.ID == userID {
+			continue
+		}
+		if _, done := swiped[user.ID]; done {
+			continue
+		}
+		if user.DeletionScheduledFor != nil || user.SuspendedAt != nil || s.blockedBetween(userID, user.ID) {
+			continue
+		}
+		if filters.PrimaryLanguage != "" && (user.PrimaryLanguage == nil || !strings.EqualFold(*user.PrimaryLanguage, filters.PrimaryLanguage)) {
+			continue
+		}
+		if len(wantTags) > 0 && !hasAnyTag(user.Interests, wantTags) {
+			continue
+		}
+		if filters.TimezoneMinMinutes != nil && (user.TimezoneOffsetMinutes == nil || *user.TimezoneOffsetMinutes < *filters.TimezoneMinMinutes) {
+			continue
+		}
+		if filters.TimezoneMaxMinutes != nil && (user.TimezoneOffsetMinutes == nil || *user.TimezoneOffsetMinutes > *filters.TimezoneMaxMinutes) {
+			continue
+		}
+		if user.GitHubFollowers < filters.MinFollowers || user.GitHubPublicRepos < filters.MinPublicRepos {
+			continue
+		}
+		if filters.ActiveWithinDays > 0 && (user.LastActiveAt == nil || user.LastActiveAt.Before(activeSince)) {
+			continue
+		}
+		candidates = append(candidates, user)
+		if len(candidates) == limit {
+			break
+		}
+	}
+	return candidates, nil
+}
+
+func hasAnyTag(tags, want []string) bool {
+	for _, tag := range tags {
+		for _, w := range want {
+			if tag == w {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// GetLikes returns every like and super like, oldest first.
+func (s *Store) GetLikes(ctx context.Context) ([]models.Swipe, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var likes []models.Swipe
+	for _, rec := range s.swipes {
+		if rec.swipe.Direction.IsLike() {
+			likes = append(likes, rec.swipe)
+		}
+	}
+	return likes, nil
+}
+
+// UndoLastSwipe removes the latest swipe of userID if it is younger than window,
+// dissolving its match if the conversation has no messages.
+func (s *Store) UndoLastSwipe(ctx context.Context, userID string, window time.Duration) (*models.UndoSwipeResult, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	last := -1
+	for i, rec := range s.swipes {
+		if rec.swipe.SwiperID == userID && (last < 0 || rec.seq > s.swipes[last].seq) {
+			last = i
+		}
+	}
+	if last < 0 {
+		return nil, database.ErrNoSwipeToUndo
+	}
+	swipe := s.swipes[last].swipe
+	if s.now().Sub(swipe.CreatedAt) > window {
+		return nil, database.ErrUndoWindowExpired
+	}
+
+	s.swipes = append(s.swipes[:last], s.swipes[last+1:]...)
+	// Notifications about the swipe go with it, like the ON DELETE CASCADE in SQL
+	kept := s.notifications[:0]
+	for _, rec := range s.notifications {
+		if rec.notification.SwipeID == nil || *rec.notification.SwipeID != swipe.ID {
+			kept = append(kept, rec)
+		}
+	}
+	s.notifications = kept
+
+	result := &models.UndoSwipeResult{Swipe: swipe}
+	if swipe.Direction.IsLike() {
+		if conv := s.conversationBetween(swipe.SwiperID, swipe.SwipedID); conv != nil {
+			conversationID := conv.conversation.ID
+			result.ConversationID = &conversationID
+			if !s.hasMessages(conversationID) {
+				delete(s.conversations, conversationID)
+				s.forgetRemovedModerationRefs()
+				result.MatchDissolved = true
+			}
+		}
+	}
+	return result, nil
+}
+
+// conversationBetween returns the conversation shared by two users, if any. Callers must hold s.mu.
+func (s *Store) conversationBetween(userA, userB string) *conversationRecord {
+	for _, conv := range s.conversations {
+		if contains(conv.conversation.UserIDs, userA) && contains(conv.conversation.UserIDs, userB) {
+			return conv
+		}
+	}
+	return nil
+}
+
+// hasMessages reports whether conversationID has any messages. Callers must hold s.mu.
+func (s *Store) hasMessages(conversationID string) bool {
+	for _, msg := range s.messages {
+		if msg.ConversationID == conversationID {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(items []string, item string) bool {
+	for _, it := range items {
+		if it == item {
+			return true
+		}
+	}
+	return false
+}
+
+// sentBy reports whether msg was sent by userID (and not anonymized since).
+func sentBy(msg models.Message, userID string) bool {
+	return msg.SenderID != nil && *msg.SenderID == userID
+}
+
+// GetSwipeHistory returns the swipes of userID, newest first, with the swiped profiles.
+func (s *Store) GetSwipeHistory(ctx context.Context, userID string, direction models.SwipeDirection, limit, offset int) ([]models.SwipeHistoryEntry, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entries := []models.SwipeHistoryEntry{}
+	for i := len(s.swipes) - 1; i >= 0; i-- {
+		swipe := s.swipes[i].swipe
+		if swipe.SwiperID != userID || (direction != "" && swipe.Direction != direction) {
+			continue
+		}
+		rec, ok := s.users[swipe.SwipedID]
+		if !ok {
+			continue
+		}
+		entries = append(entries, models.SwipeHistoryEntry{Swipe: swipe, SwipedUser: copyUser(rec.user)})
+	}
+	return paginate(entries, limit, offset), nil
+}
+
+// --- Conversations ---
+
+// GetConversations returns the conversations of userID, most recently active first,
+// leaving out those with someone userID has a block with.
+func (s *Store) GetConversations(ctx context.Context, userID string) ([]models.Conversation, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	conversations := []models.Conversation{}
+	for _, conv := range s.conversations {
+		if contains(conv.conversation.UserIDs, userID) && !s.blockedInConversation(conv, userID) {
+			c := conv.conversation
+			c.UserIDs = append([]string(nil), c.UserIDs...)
+			sort.Strings(c.UserIDs)
+			conversations = append(conversations, c)
+		}
+	}
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].UpdatedAt.After(conversations[j].UpdatedAt)
+	})
+	return conversations, nil
+}
+
+// IsConversationParticipant reports whether userID takes part in conversationID.
+func (s *Store) IsConversationParticipant(ctx context.Context, conversationID, userID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conv, ok := s.conversations[conversationID]
+	return ok && contains(conv.conversation.UserIDs, userID), nil
+}
+
+// GetMessages returns the messages of conversationID, newest first.
+func (s *Store) GetMessages(ctx context.Context, conversationID string, limit, offset int) ([]models.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages := []models.Message{}
+	for i := len(s.messages) - 1; i >= 0; i-- {
+		if s.messages[i].ConversationID == conversationID {
+			messages = append(messages, s.messages[i])
+		}
+	}
+	return paginate(messages, limit, offset), nil
+}
+
+// CreateMessage stores a message, or returns database.ErrNotConversationParticipant or
+// database.ErrBlocked.
+func (s *Store) CreateMessage(ctx context.Context, conversationID, senderID, content string) (*models.Message, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	conv, ok := s.conversations[conversationID]
+	if !ok || !contains(conv.conversation.UserIDs, senderID) {
+		return nil, database.ErrNotConversationParticipant
+	}
+	if s.blockedInConversation(conv, senderID) {
+		return nil, database.ErrBlocked
+	}
+
+	msg := models.Message{
+		ID:             newID(),
+		ConversationID: conversationID,
+		SenderID:       &senderID,
+		Content:        content,
+		SentAt:         s.now(),
+	}
+	s.messages = append(s.messages, msg)
+	conv.conversation.UpdatedAt = msg.SentAt
+	return &msg, nil
+}
+
+// --- Notifications ---
+
+// GetNotifications returns the notifications of userID, newest first.
+func (s *Store) GetNotifications(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]models.Notification, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	notifications := []models.Notification{}
+	for i := len(s.notifications) - 1; i >= 0; i-- {
+		n := s.notifications[i].notification
+		if n.UserID != userID || (unreadOnly && n.ReadAt != nil) {
+			continue
+		}
+		notifications = append(notifications, n)
+	}
+	return paginate(notifications, limit, offset), nil
+}
+
+// MarkNotificationRead marks a notification of userID as read, or returns sql.ErrNoRows.
+func (s *Store) MarkNotificationRead(ctx context.Context, userID, notificationID string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.notifications {
+		n := &s.notifications[i].notification
+		if n.ID == notificationID && n.UserID == userID {
+			if n.ReadAt == nil {
+				now := s.now()
+				n.ReadAt = &now
+			}
+			return nil
+		}
+	}
+	return sql.ErrNoRows
+}
+
+// --- Webhook events ---
+
+// IsWebhookEventProcessed reports whether eventID from source has been recorded.
+func (s *Store) IsWebhookEventProcessed(ctx context.Context, source, eventID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.webhookEvents[source+"/"+eventID]
+	return ok, nil
+}
+
+// RecordWebhookEvent marks eventID from source as processed.
+func (s *Store) RecordWebhookEvent(ctx context.Context, source, eventID, eventType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.webhookEvents[source+"/"+eventID] = struct{}{}
+	return nil
+}
@@ -0,0 +1,57 @@
+package memory
+
+import (
+	"context"
+	"fmt"
+
+	"gin/internal/models"
+)
+
+// RecordModerationEvent adds event to the moderation log and returns it. Only flagged and
+// rejected content is logged.
+func (s *Store) RecordModerationEvent(ctx context.Context, event models.ModerationEvent) (*models.ModerationEvent, error) {
+	if event.Decision != models.ModerationFlag && event.Decision != models.ModerationReject {
+		return nil, fmt.Errorf("cannot log a moderation decision of %q", event.Decision)
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	event.ID = newID()
+	event.CreatedAt = s.now()
+	s.moderationLog = append(s.moderationLog, event)
+	return &event, nil
+}
+
+// GetModerationEvents returns moderation log entries matching filter, newest first.
+func (s *Store) GetModerationEvents(ctx context.Context, filter models.ModerationEventFilter, limit, offset int) ([]models.ModerationEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	events := []models.ModerationEvent{}
+	for i := len(s.moderationLog) - 1; i >= 0; i-- {
+		event := s.moderationLog[i]
+		if (filter.Decision == "" || event.Decision == filter.Decision) &&
+			(filter.ContentType == "" || event.ContentType == filter.ContentType) &&
+			(filter.UserID == "" || event.UserID == filter.UserID) {
+			events = append(events, event)
+		}
+	}
+	return paginate(events, limit, offset), nil
+}
+
+// forgetRemovedModerationRefs clears the message and conversation IDs of moderation log
+// entries whose message or conversation no longer exists, as the SQL foreign keys do.
+// Callers must hold s.mu.
+func (s *Store) forgetRemovedModerationRefs() {
+	for i := range s.moderationLog {
+		event := &s.moderationLog[i]
+		if event.MessageID != nil {
+			if _, ok := s.findMessage(*event.MessageID); !ok {
+				event.MessageID = nil
+			}
+		}
+		if event.ConversationID != nil {
+			if _, ok := s.conversations[*event.ConversationID]; !ok {
+				event.ConversationID = nil
+			}
+		}
+	}
+}
@@ -0,0 +1,119 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"gin/internal/models"
+	"gin/internal/services/database"
+)
+
+// CreateReport files report with copies of the messages in messageIDs, with the same
+// rules and errors as DBService.CreateReport.
+func (s *Store) CreateReport(ctx context.Context, report models.Report, messageIDs []string) (*models.Report, error) {
+	if report.ReporterUserID == nil {
+		return nil, errors.New("a report needs a reporter")
+	}
+	reporterID := *report.ReporterUserID
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	evidence := []models.ReportedMessage{}
+	seen := make(map[string]struct{}, len(messageIDs))
+	for _, messageID := range messageIDs {
+		if _, dup := seen[messageID]; dup {
+			continue
+		}
+		seen[messageID] = struct{}{}
+
+		msg, ok := s.findMessage(messageID)
+		if !ok || !sentBy(msg, report.ReportedUserID) {
+			return nil, fmt.Errorf("%w: %s", database.ErrInvalidReportedMessage, messageID)
+		}
+		conv, ok := s.conversations[msg.ConversationID]
+		if !ok || !contains(conv.conversation.UserIDs, reporterID) {
+			return nil, fmt.Errorf("%w: %s", database.ErrInvalidReportedMessage, messageID)
+		}
+		id := msg.ID
+		evidence = append(evidence, models.ReportedMessage{MessageID: &id, SenderUserID: msg.SenderID, Content: msg.Content, SentAt: msg.SentAt})
+	}
+
+	for _, existing := range s.reports {
+		if existing.Status == models.ReportOpen && existing.ReportedUserID == report.ReportedUserID &&
+			existing.ReporterUserID != nil && *existing.ReporterUserID == reporterID {
+			return nil, database.ErrDuplicateReport
+		}
+	}
+
+	created := models.Report{
+		ID:             newID(),
+		ReporterUserID: &reporterID,
+		ReportedUserID: report.ReportedUserID,
+		Reason:         report.Reason,
+		Details:        report.Details,
+		Status:         models.ReportOpen,
+		CreatedAt:      s.now(),
+	}
+	s.reports = append(s.reports, created)
+	s.reportEvidence[created.ID] = evidence
+	return &created, nil
+}
+
+// findMessage returns the message with messageID. Callers must hold s.mu.
+func (s *Store) findMessage(messageID string) (models.Message, bool) {
+	for _, msg := range s.messages {
+		if msg.ID == messageID {
+			return msg, true
+		}
+	}
+	return models.Message{}, false
+}
+
+// forgetRemovedEvidence clears the message IDs of report evidence whose message no
+// longer exists, as the SQL foreign key does. Callers must hold s.mu.
+func (s *Store) forgetRemovedEvidence() {
+	for _, evidence := range s.reportEvidence {
+		for i := range evidence {
+			if evidence[i].MessageID == nil {
+				continue
+			}
+			if _, ok := s.findMessage(*evidence[i].MessageID); !ok {
+				evidence[i].MessageID = nil
+			}
+		}
+	}
+}
+
+// GetReportMessages returns the messages attached to reportID, oldest first.
+func (s *Store) GetReportMessages(ctx context.Context, reportID string) ([]models.ReportedMessage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]models.ReportedMessage{}, s.reportEvidence[reportID]...), nil
+}
+
+// RemoveReportedMessages deletes the messages attached to reportID and returns how many were deleted.
+func (s *Store) RemoveReportedMessages(ctx context.Context, reportID string) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	remove := make(map[string]struct{})
+	for _, evidence := range s.reportEvidence[reportID] {
+		if evidence.MessageID != nil {
+			remove[*evidence.MessageID] = struct{}{}
+		}
+	}
+	removed := 0
+	messages := s.messages[:0]
+	for _, msg := range s.messages {
+		if _, ok := remove[msg.ID]; ok {
+			removed++
+			continue
+		}
+		messages = append(messages, msg)
+	}
+	s.messages = messages
+	s.forgetRemovedEvidence()
+	s.forgetRemovedModerationRefs()
+	return removed, nil
+}
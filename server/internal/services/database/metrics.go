@@ -0,0 +1,36 @@
+package database
+
+import (
+	"runtime"
+	"strings"
+	"time"
+
+	"gin/internal/metrics"
+)
+
+// dbServiceMethodPrefix is how DBService methods appear in stack frames.
+const dbServiceMethodPrefix = "(*DBService)."
+
+// observeQuery records the time since start in metrics.DBQueryDuration, labelled with
+// the DBService method that ran the query. Queries run by anything else (migrations,
+// backups) are labelled "other".
+func observeQuery(start time.Time) {
+	metrics.DBQueryDuration.WithLabelValues(queryOperation()).Observe(time.Since(start).Seconds())
+}
+
+// queryOperation returns the name of the innermost DBService method on the stack, so
+// queries made through helpers are attributed to the method using them.
+func queryOperation() string {
+	var pcs [16]uintptr
+	n := runtime.Callers(3, pcs[:]) // Skip runtime.Callers, queryOperation and observeQuery
+	frames := runtime.CallersFrames(pcs[:n])
+	for {
+		frame, more := frames.Next()
+		if _, method, ok := strings.Cut(frame.Function, dbServiceMethodPrefix); ok {
+			return method
+		}
+		if !more {
+			return "other"
+		}
+	}
+}
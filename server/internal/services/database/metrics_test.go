@@ -0,0 +1,53 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"gin/internal/metrics"
+)
+
+// queryCount returns how many queries metrics.DBQueryDuration recorded for operation.
+func queryCount(t *testing.T, operation string) uint64 {
+	t.Helper()
+	families, err := metrics.Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, family := range families {
+		if family.GetName() != "devmatch_db_query_duration_seconds" {
+			continue
+		}
+		for _, m := range family.GetMetric() {
+			for _, label := range m.GetLabel() {
+				if label.GetName() == "operation" && label.GetValue() == operation {
+					return m.GetHistogram().GetSampleCount()
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func TestQueryMetrics(t *testing.T) {
+	s := newTestService(t)
+	ctx := context.Background()
+	user := createUser(t, s, "alice", "")
+
+	before := queryCount(t, "GetUserProfileByID")
+	if _, err := s.GetUserProfileByID(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	if after := queryCount(t, "GetUserProfileByID"); after <= before {
+		t.Errorf("GetUserProfileByID queries recorded = %d, want more than %d", after, before)
+	}
+
+	// Queries outside DBService methods are labelled "other"
+	before = queryCount(t, "other")
+	if _, err := s.DB.ExecContext(ctx, `SELECT 1`); err != nil {
+		t.Fatal(err)
+	}
+	if after := queryCount(t, "other"); after != before+1 {
+		t.Errorf("other queries recorded = %d, want %d", after, before+1)
+	}
+}
@@ -0,0 +1,468 @@
+package database
+
+import (
+	"context"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles holds the numbered schema migrations, one directory per dialect.
+// Each version has a NNNN_name.up.sql file and a matching NNNN_name.down.sql file,
+// and both dialects share version numbers so their schemas stay in step. SQLite
+// migrations creating FTS5 tables also have .nofts5 variants (see Migration).
+//
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+// ErrChecksumMismatch is returned when an applied migration no longer matches the
+// embedded file, i.e. someone edited a migration after it was released.
+var ErrChecksumMismatch = errors.New("migration checksum mismatch")
+
+// ErrNotInitialised is returned by Status when the database has no schema_migrations
+// table, i.e. Up has never run against it.
+var ErrNotInitialised = errors.New("database not initialised")
+
+// Migration is a single schema change with the SQL to apply and revert it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+	// UpNoFTS5 and DownNoFTS5, from NNNN_name.up.nofts5.sql and NNNN_name.down.nofts5.sql,
+	// replace Up and Down on SQLite builds without FTS5. They leave out the full-text
+	// index, and search falls back to LIKE matching. Optional.
+	UpNoFTS5   string
+	DownNoFTS5 string
+}
+
+// Checksum identifies the contents of the up migration.
+func (m Migration) Checksum() string {
+	sum := sha256.Sum256([]byte(m.Up))
+	return hex.EncodeToString(sum[:])
+}
+
+// MigrationState describes a known migration and whether it has been applied.
+type MigrationState struct {
+	Migration
+	Applied          bool
+	AppliedAt        *time.Time
+	ChecksumMismatch bool // Applied, but with different SQL than the embedded file
+}
+
+// legacyMarkers names a table created by each SQLite migration that ensureSchema also
+// used to create. Databases from before migrations existed have no schema_migrations table;
+// the versions whose marker table already exists are recorded as applied instead of run.
+var legacyMarkers = map[int]string{
+	1: "users",
+	2: "user_interests",
+	3: "users_fts",
+	4: "notifications",
+}
+
+// fullTextIndexes names the FTS5 table created by each SQLite migration with a .nofts5
+// variant. Down reverts with the variant when the table is missing.
+var fullTextIndexes = map[int]string{
+	3: "users_fts",
+}
+
+// Migrator applies and reverts the embedded migrations, recording progress in the
+// schema_migrations table. Every migration runs in its own transaction.
+type Migrator struct {
+	db         *DB
+	migrations []Migration
+}
+
+// NewMigrator creates a Migrator for db using the embedded migration files of its dialect.
+func NewMigrator(db *DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, path.Join("migrations", string(db.Dialect)))
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// loadMigrations reads and pairs the up/down files in dir, sorted by version.
+func loadMigrations(fsys fs.FS, dir string) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		fileName := entry.Name()
+		var direction, variant string
+		switch {
+		case strings.HasSuffix(fileName, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(fileName, ".down.sql"):
+			direction = "down"
+		case strings.HasSuffix(fileName, ".up.nofts5.sql"):
+			direction, variant = "up", ".nofts5"
+		case strings.HasSuffix(fileName, ".down.nofts5.sql"):
+			direction, variant = "down", ".nofts5"
+		default:
+			return nil, fmt.Errorf("unexpected migration file %q", fileName)
+		}
+
+		base := strings.TrimSuffix(fileName, "."+direction+variant+".sql")
+		versionStr, name, ok := strings.Cut(base, "_")
+		if !ok {
+			return nil, fmt.Errorf("migration file %q must be named NNNN_name.%s.sql", fileName, direction)
+		}
+		version, err := strconv.Atoi(versionStr)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("migration file %q has an invalid version", fileName)
+		}
+
+		contents, err := fs.ReadFile(fsys, path.Join(dir, fileName))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %q: %w", fileName, err)
+		}
+
+		m, exists := byVersion[version]
+		if !exists {
+			m = &Migration{Version: version, Name: name}
+			byVersion[version] = m
+		} else if m.Name != name {
+			return nil, fmt.Errorf("migration version %d has conflicting names %q and %q", version, m.Name, name)
+		}
+		switch {
+		case direction == "up" && variant == "":
+			m.Up = string(contents)
+		case direction == "up":
+			m.UpNoFTS5 = string(contents)
+		case variant == "":
+			m.Down = string(contents)
+		default:
+			m.DownNoFTS5 = string(contents)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down file", m.Version, m.Name)
+		}
+		if (m.UpNoFTS5 == "") != (m.DownNoFTS5 == "") {
+			return nil, fmt.Errorf("migration %04d_%s needs both an up and a down nofts5 file", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must be consecutive from 1; found %04d at position %d", m.Version, i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// appliedMigration is a row of schema_migrations.
+type appliedMigration struct {
+	checksum  string
+	appliedAt time.Time
+}
+
+// ensureMigrationsTable creates schema_migrations, adopting a pre-migration database
+// if this is the first time migrations run against it.
+func (m *Migrator) ensureMigrationsTable(ctx context.Context) error {
+	exists, err := tableExists(ctx, m.db, m.db.Dialect, "schema_migrations")
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	createSQL := `
+	CREATE TABLE schema_migrations (
+		version INTEGER PRIMARY KEY,
+		name TEXT NOT NULL,
+		checksum TEXT NOT NULL,
+		applied_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+	);`
+	if _, err := tx.ExecContext(ctx, createSQL); err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	// Record the migrations that ensureSchema had already applied, stopping at the first gap.
+	// Postgres support arrived after ensureSchema was gone, so there is nothing to adopt there.
+	for _, migration := range m.migrations {
+		if m.db.Dialect != DialectSQLite {
+			break
+		}
+		marker, ok := legacyMarkers[migration.Version]
+		if !ok {
+			break
+		}
+		present, err := tableExists(ctx, tx, m.db.Dialect, marker)
+		if err != nil {
+			return err
+		}
+		if !present {
+			break
+		}
+		logger.InfoContext(ctx, "Adopting existing schema as migration", "version", migration.Version, "name", migration.Name)
+		if err := recordMigration(ctx, tx, migration); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit schema_migrations: %w", err)
+	}
+	return nil
+}
+
+// tableExists reports whether a table (including virtual tables) named name exists.
+func tableExists(ctx context.Context, q queryer, dialect Dialect, name string) (bool, error) {
+	query := `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = ?`
+	if dialect == DialectPostgres {
+		query = `SELECT COUNT(*) FROM information_schema.tables WHERE table_schema = current_schema() AND table_name = ?`
+	}
+
+	var n int
+	err := q.QueryRowContext(ctx, query, name).Scan(&n)
+	if err != nil {
+		return false, fmt.Errorf("failed to check for table %s: %w", name, err)
+	}
+	return n > 0, nil
+}
+
+// checkFTS5 reports whether the database supports FTS5, which only SQLite builds with the
+// sqlite_fts5 tag do. It fails if the database already has a full-text index but the
+// build lacks FTS5, since every write to an indexed table would fail.
+func (m *Migrator) checkFTS5(ctx context.Context) (bool, error) {
+	if m.db.Dialect != DialectSQLite {
+		return false, nil
+	}
+	fts5, err := hasFTS5(ctx, m.db)
+	if err != nil || fts5 {
+		return fts5, err
+	}
+	for _, index := range fullTextIndexes {
+		indexed, err := tableExists(ctx, m.db, m.db.Dialect, index)
+		if err != nil {
+			return false, err
+		}
+		if indexed {
+			return false, fmt.Errorf("database has the full-text index %s but SQLite was built without FTS5; build with -tags sqlite_fts5", index)
+		}
+	}
+	return false, nil
+}
+
+// hasFTS5 reports whether the SQLite library was compiled with FTS5.
+func hasFTS5(ctx context.Context, q queryer) (bool, error) {
+	var used int
+	if err := q.QueryRowContext(ctx, `SELECT sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&used); err != nil {
+		return false, fmt.Errorf("failed to check for FTS5: %w", err)
+	}
+	return used == 1, nil
+}
+
+// recordMigration marks migration as applied within tx.
+func recordMigration(ctx context.Context, tx *Tx, migration Migration) error {
+	_, err := tx.ExecContext(ctx,
+		`INSERT INTO schema_migrations (version, name, checksum) VALUES (?, ?, ?)`,
+		migration.Version, migration.Name, migration.Checksum(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to record migration %04d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+// applied returns the rows of schema_migrations keyed by version.
+func (m *Migrator) applied(ctx context.Context) (map[int]appliedMigration, error) {
+	rows, err := m.db.QueryContext(ctx, `SELECT version, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]appliedMigration)
+	for rows.Next() {
+		var (
+			version int
+			row     appliedMigration
+		)
+		if err := rows.Scan(&version, &row.checksum, &row.appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations row: %w", err)
+		}
+		applied[version] = row
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate schema_migrations: %w", err)
+	}
+	return applied, nil
+}
+
+// Status lists every known migration and whether it has been applied. It only reads,
+// so it returns ErrNotInitialised rather than creating schema_migrations.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationState, error) {
+	exists, err := tableExists(ctx, m.db, m.db.Dialect, "schema_migrations")
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrNotInitialised
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		state := MigrationState{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.appliedAt
+			state.Applied = true
+			state.AppliedAt = &appliedAt
+			state.ChecksumMismatch = row.checksum != migration.Checksum()
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// verify checks that the applied migrations match the embedded files and that the
+// database is not ahead of this binary.
+func (m *Migrator) verify(applied map[int]appliedMigration) error {
+	for version := range applied {
+		if version > len(m.migrations) {
+			return fmt.Errorf("database is at migration %04d but this build only knows up to %04d", version, len(m.migrations))
+		}
+	}
+	for _, migration := range m.migrations {
+		row, ok := applied[migration.Version]
+		if ok && row.checksum != migration.Checksum() {
+			return fmt.Errorf("%w: %04d_%s was changed after it was applied", ErrChecksumMismatch, migration.Version, migration.Name)
+		}
+	}
+	return nil
+}
+
+// Up applies every pending migration in order and returns how many were applied.
+func (m *Migrator) Up(ctx context.Context) (int, error) {
+	if err := m.ensureMigrationsTable(ctx); err != nil {
+		return 0, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.verify(applied); err != nil {
+		return 0, err
+	}
+	fts5, err := m.checkFTS5(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+		logger.InfoContext(ctx, "Applying migration", "version", migration.Version, "name", migration.Name)
+		script := migration.Up
+		if !fts5 && migration.UpNoFTS5 != "" {
+			logger.WarnContext(ctx, "SQLite built without FTS5, applying migration without its full-text index; build with -tags sqlite_fts5 for full-text search",
+				"version", migration.Version, "name", migration.Name)
+			script = migration.UpNoFTS5
+		}
+		if err := m.run(ctx, migration, script, true); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// Down reverts the most recently applied migrations, at most steps of them, and
+// returns how many were reverted.
+func (m *Migrator) Down(ctx context.Context, steps int) (int, error) {
+	if err := m.ensureMigrationsTable(ctx); err != nil {
+		return 0, err
+	}
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return 0, err
+	}
+	if err := m.verify(applied); err != nil {
+		return 0, err
+	}
+
+	count := 0
+	for i := len(m.migrations) - 1; i >= 0 && count < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		logger.InfoContext(ctx, "Reverting migration", "version", migration.Version, "name", migration.Name)
+		script := migration.Down
+		if index, ok := fullTextIndexes[migration.Version]; ok && migration.DownNoFTS5 != "" {
+			indexed, err := tableExists(ctx, m.db, m.db.Dialect, index)
+			if err != nil {
+				return count, err
+			}
+			if !indexed {
+				script = migration.DownNoFTS5
+			}
+		}
+		if err := m.run(ctx, migration, script, false); err != nil {
+			return count, err
+		}
+		count++
+	}
+	return count, nil
+}
+
+// run executes script for migration in a transaction and records (up) or removes (down)
+// its schema_migrations row in the same transaction.
+func (m *Migrator) run(ctx context.Context, migration Migration, script string, up bool) error {
+	tx, err := m.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %04d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, script); err != nil {
+		if strings.Contains(err.Error(), "no such module: fts5") {
+			return fmt.Errorf("migration %04d_%s failed (SQLite FTS5 missing; build with -tags sqlite_fts5): %w", migration.Version, migration.Name, err)
+		}
+		return fmt.Errorf("migration %04d_%s failed: %w", migration.Version, migration.Name, err)
+	}
+
+	if up {
+		err = recordMigration(ctx, tx, migration)
+	} else {
+		_, err = tx.ExecContext(ctx, `DELETE FROM schema_migrations WHERE version = ?`, migration.Version)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %04d: %w", migration.Version, err)
+	}
+	return nil
+}
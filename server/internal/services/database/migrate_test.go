@@ -0,0 +1,198 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+func TestLoadMigrations(t *testing.T) {
+	file := func(sql string) *fstest.MapFile { return &fstest.MapFile{Data: []byte(sql)} }
+
+	tests := []struct {
+		name    string
+		files   fstest.MapFS
+		want    []string // version_name of each migration, in order
+		wantErr string
+	}{
+		{"sorted by version", fstest.MapFS{
+			"m/0002_second.up.sql": file("B"), "m/0002_second.down.sql": file("b"),
+			"m/0001_first.up.sql": file("A"), "m/0001_first.down.sql": file("a"),
+		}, []string{"1_first", "2_second"}, ""},
+		{"missing down", fstest.MapFS{"m/0001_first.up.sql": file("A")}, nil, "needs both an up and a down file"},
+		{"gap in versions", fstest.MapFS{
+			"m/0001_first.up.sql": file("A"), "m/0001_first.down.sql": file("a"),
+			"m/0003_third.up.sql": file("C"), "m/0003_third.down.sql": file("c"),
+		}, nil, "must be consecutive"},
+		{"conflicting names", fstest.MapFS{"m/0001_first.up.sql": file("A"), "m/0001_other.down.sql": file("a")}, nil, "conflicting names"},
+		{"no version", fstest.MapFS{"m/first.up.sql": file("A")}, nil, "must be named"},
+		{"stray file", fstest.MapFS{"m/README.md": file("")}, nil, "unexpected migration file"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			migrations, err := loadMigrations(tt.files, "m")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("error = %v, want it to mention %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("loadMigrations: %v", err)
+			}
+			var got []string
+			for _, m := range migrations {
+				got = append(got, fmt.Sprintf("%d_%s", m.Version, m.Name))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("migrations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMigrationsRoundTrip(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		migrator, err := NewMigrator(s.DB)
+		if err != nil {
+			t.Fatalf("NewMigrator: %v", err)
+		}
+		total := len(migrator.migrations)
+
+		reverted, err := migrator.Down(ctx, total)
+		if err != nil {
+			t.Fatalf("Down: %v", err)
+		}
+		if reverted != total {
+			t.Errorf("Down reverted %d migrations, want %d", reverted, total)
+		}
+		if exists, err := tableExists(ctx, s.DB, s.DB.Dialect, "users"); err != nil || exists {
+			t.Errorf("users table exists = %v (err %v) after reverting everything", exists, err)
+		}
+		states, err := migrator.Status(ctx)
+		if err != nil {
+			t.Fatalf("Status: %v", err)
+		}
+		for _, state := range states {
+			if state.Applied {
+				t.Errorf("migration %04d_%s still applied after Down", state.Version, state.Name)
+			}
+		}
+
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			t.Fatalf("Up after Down: %v", err)
+		}
+		if applied != total {
+			t.Errorf("Up applied %d migrations, want %d", applied, total)
+		}
+		if applied, err := migrator.Up(ctx); err != nil || applied != 0 {
+			t.Errorf("second Up applied %d migrations (err %v), want none", applied, err)
+		}
+
+		states, err = migrator.Status(ctx)
+		if err != nil {
+			t.Fatalf("Status: %v", err)
+		}
+		for _, state := range states {
+			if !state.Applied || state.AppliedAt == nil || state.ChecksumMismatch {
+				t.Errorf("migration %04d_%s: applied=%v checksum mismatch=%v", state.Version, state.Name, state.Applied, state.ChecksumMismatch)
+			}
+		}
+	})
+}
+
+func TestMigrationChecksumMismatch(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		if _, err := s.DB.ExecContext(ctx, `UPDATE schema_migrations SET checksum = 'edited' WHERE version = 1`); err != nil {
+			t.Fatal(err)
+		}
+		migrator, err := NewMigrator(s.DB)
+		if err != nil {
+			t.Fatalf("NewMigrator: %v", err)
+		}
+
+		if _, err := migrator.Up(ctx); !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("Up error = %v, want ErrChecksumMismatch", err)
+		}
+		if _, err := migrator.Down(ctx, 1); !errors.Is(err, ErrChecksumMismatch) {
+			t.Errorf("Down error = %v, want ErrChecksumMismatch", err)
+		}
+		states, err := migrator.Status(ctx)
+		if err != nil {
+			t.Fatalf("Status: %v", err)
+		}
+		if !states[0].ChecksumMismatch || states[1].ChecksumMismatch {
+			t.Errorf("checksum mismatches = %v, %v; want only the first migration flagged", states[0].ChecksumMismatch, states[1].ChecksumMismatch)
+		}
+	})
+}
+
+func TestMigrationStatusNotInitialised(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "empty.db"))
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+
+	if _, err := migrator.Status(ctx); !errors.Is(err, ErrNotInitialised) {
+		t.Errorf("Status error = %v, want ErrNotInitialised", err)
+	}
+	if exists, err := tableExists(ctx, db, db.Dialect, "schema_migrations"); err != nil || exists {
+		t.Errorf("schema_migrations exists = %v (err %v) after Status, want it left alone", exists, err)
+	}
+}
+
+func TestMigratorAdoptsLegacySchema(t *testing.T) {
+	db, err := OpenDB(filepath.Join(t.TempDir(), "legacy.db"))
+	if err != nil {
+		t.Fatalf("OpenDB: %v", err)
+	}
+	defer db.Close()
+	ctx := context.Background()
+	migrator, err := NewMigrator(db)
+	if err != nil {
+		t.Fatalf("NewMigrator: %v", err)
+	}
+
+	// A database set up by ensureSchema has the tables but no schema_migrations
+	for _, migration := range migrator.migrations {
+		if _, ok := legacyMarkers[migration.Version]; !ok {
+			break
+		}
+		if _, err := db.ExecContext(ctx, migration.Up); err != nil {
+			if strings.Contains(err.Error(), "no such module: fts5") {
+				t.Skip("SQLite FTS5 missing; run with -tags sqlite_fts5")
+			}
+			t.Fatalf("creating legacy schema: %v", err)
+		}
+	}
+
+	applied, err := migrator.Up(ctx)
+	if err != nil {
+		t.Fatalf("Up: %v", err)
+	}
+	if want := len(migrator.migrations) - len(legacyMarkers); applied != want {
+		t.Errorf("Up applied %d migrations, want %d (the legacy ones are adopted)", applied, want)
+	}
+	states, err := migrator.Status(ctx)
+	if err != nil {
+		t.Fatalf("Status: %v", err)
+	}
+	for _, state := range states {
+		if !state.Applied {
+			t.Errorf("migration %04d_%s not applied", state.Version, state.Name)
+		}
+	}
+}
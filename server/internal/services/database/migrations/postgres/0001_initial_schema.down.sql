@@ -0,0 +1,7 @@
+DROP TABLE messages;
+DROP FUNCTION update_conversation_on_message();
+DROP TABLE conversation_participants;
+DROP TABLE conversations;
+DROP TABLE swipes;
+DROP TABLE users;
+DROP FUNCTION set_updated_at();
@@ -0,0 +1,78 @@
+-- Initial schema, matching the SQLite baseline.
+-- IDs are text so they can be shared with SQLite-era data; new ones are random UUIDs.
+
+-- Users Table --
+CREATE TABLE users (
+	id TEXT PRIMARY KEY DEFAULT gen_random_uuid()::text,
+	clerk_user_id TEXT UNIQUE NOT NULL,
+	username TEXT,
+	picture_url TEXT,
+	bio TEXT,
+	github_url TEXT,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+
+CREATE FUNCTION set_updated_at() RETURNS trigger AS $$
+BEGIN
+	-- Leave updated_at alone if the statement set it explicitly
+	IF NEW.updated_at = OLD.updated_at THEN
+		NEW.updated_at := CURRENT_TIMESTAMP;
+	END IF;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_users_update_updated_at
+BEFORE UPDATE ON users FOR EACH ROW
+EXECUTE FUNCTION set_updated_at();
+
+-- Swipes Table --
+CREATE TABLE swipes (
+	id TEXT PRIMARY KEY DEFAULT gen_random_uuid()::text,
+	swiper_user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	swiped_user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	direction TEXT NOT NULL CHECK(direction IN ('like', 'dislike')),
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	UNIQUE (swiper_user_id, swiped_user_id)
+);
+CREATE INDEX idx_swipes_swiper_id ON swipes(swiper_user_id);
+CREATE INDEX idx_swipes_swiped_id ON swipes(swiped_user_id);
+
+-- Conversations Table --
+CREATE TABLE conversations (
+	id TEXT PRIMARY KEY DEFAULT gen_random_uuid()::text,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL -- Tracks last activity/message
+);
+
+-- Conversation Participants Join Table --
+CREATE TABLE conversation_participants (
+	conversation_id TEXT NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	PRIMARY KEY (conversation_id, user_id)
+);
+CREATE INDEX idx_conv_participants_user_id ON conversation_participants(user_id);
+
+-- Messages Table --
+CREATE TABLE messages (
+	id TEXT PRIMARY KEY DEFAULT gen_random_uuid()::text,
+	conversation_id TEXT NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+	sender_user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	content TEXT NOT NULL,
+	sent_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_messages_conversation_id ON messages(conversation_id);
+CREATE INDEX idx_messages_sent_at ON messages(sent_at);
+
+-- Keep conversation updated_at in step with its latest message --
+CREATE FUNCTION update_conversation_on_message() RETURNS trigger AS $$
+BEGIN
+	UPDATE conversations SET updated_at = NEW.sent_at WHERE id = NEW.conversation_id;
+	RETURN NEW;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_update_conversation_on_message
+AFTER INSERT ON messages FOR EACH ROW
+EXECUTE FUNCTION update_conversation_on_message();
@@ -0,0 +1,7 @@
+DROP TABLE user_interests;
+
+ALTER TABLE users DROP COLUMN primary_language;
+ALTER TABLE users DROP COLUMN timezone_offset_minutes;
+ALTER TABLE users DROP COLUMN github_followers;
+ALTER TABLE users DROP COLUMN github_public_repos;
+ALTER TABLE users DROP COLUMN last_active_at;
@@ -0,0 +1,23 @@
+-- Profile attributes used by deck filters and the recommender.
+-- citext gives primary_language the case-insensitive matching of SQLite's COLLATE NOCASE.
+CREATE EXTENSION IF NOT EXISTS citext;
+
+ALTER TABLE users ADD COLUMN primary_language CITEXT;
+ALTER TABLE users ADD COLUMN timezone_offset_minutes INTEGER;
+ALTER TABLE users ADD COLUMN github_followers INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN github_public_repos INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN last_active_at TIMESTAMPTZ;
+
+CREATE INDEX idx_users_primary_language ON users(primary_language);
+CREATE INDEX idx_users_timezone_offset ON users(timezone_offset_minutes);
+CREATE INDEX idx_users_github_followers ON users(github_followers);
+CREATE INDEX idx_users_github_public_repos ON users(github_public_repos);
+CREATE INDEX idx_users_last_active_at ON users(last_active_at);
+
+-- User Interest Tags (one row per tag, lowercase) --
+CREATE TABLE user_interests (
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (user_id, tag)
+);
+CREATE INDEX idx_user_interests_tag ON user_interests(tag);
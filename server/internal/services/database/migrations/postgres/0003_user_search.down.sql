@@ -0,0 +1,9 @@
+DROP TRIGGER trigger_user_interests_search ON user_interests;
+DROP FUNCTION users_search_on_interest();
+DROP TRIGGER trigger_users_search_update ON users;
+DROP TRIGGER trigger_users_search_insert ON users;
+DROP FUNCTION users_search_on_user();
+DROP FUNCTION refresh_user_search(TEXT);
+DROP TABLE users_search;
+
+ALTER TABLE users DROP COLUMN repo_names;
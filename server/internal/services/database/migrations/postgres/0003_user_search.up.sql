@@ -0,0 +1,60 @@
+-- Full-text developer search over a weighted tsvector per user.
+-- Weights follow the SQLite bm25 ranking: username (A), interests (B), repositories (C), bio (D).
+
+-- Space-separated GitHub repository names, indexed for search --
+ALTER TABLE users ADD COLUMN repo_names TEXT;
+
+-- Search documents, one per user --
+CREATE TABLE users_search (
+	user_id TEXT PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+	document TSVECTOR NOT NULL
+);
+CREATE INDEX idx_users_search_document ON users_search USING GIN (document);
+
+-- Rebuilds the search document of one user; does nothing if the user is gone --
+CREATE FUNCTION refresh_user_search(target_user_id TEXT) RETURNS void AS $$
+BEGIN
+	INSERT INTO users_search (user_id, document)
+	SELECT u.id,
+		setweight(to_tsvector('simple', coalesce(u.username, '')), 'A') ||
+		setweight(to_tsvector('simple', coalesce((SELECT string_agg(tag, ' ') FROM user_interests WHERE user_id = u.id), '')), 'B') ||
+		setweight(to_tsvector('simple', coalesce(u.repo_names, '')), 'C') ||
+		setweight(to_tsvector('simple', coalesce(u.bio, '')), 'D')
+	FROM users u
+	WHERE u.id = target_user_id
+	ON CONFLICT (user_id) DO UPDATE SET document = EXCLUDED.document;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE FUNCTION users_search_on_user() RETURNS trigger AS $$
+BEGIN
+	PERFORM refresh_user_search(NEW.id);
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_users_search_insert
+AFTER INSERT ON users FOR EACH ROW
+EXECUTE FUNCTION users_search_on_user();
+
+CREATE TRIGGER trigger_users_search_update
+AFTER UPDATE OF username, bio, repo_names ON users FOR EACH ROW
+EXECUTE FUNCTION users_search_on_user();
+
+CREATE FUNCTION users_search_on_interest() RETURNS trigger AS $$
+BEGIN
+	IF TG_OP = 'DELETE' THEN
+		PERFORM refresh_user_search(OLD.user_id);
+	ELSE
+		PERFORM refresh_user_search(NEW.user_id);
+	END IF;
+	RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER trigger_user_interests_search
+AFTER INSERT OR DELETE ON user_interests FOR EACH ROW
+EXECUTE FUNCTION users_search_on_interest();
+
+-- Index existing profiles --
+SELECT refresh_user_search(id) FROM users;
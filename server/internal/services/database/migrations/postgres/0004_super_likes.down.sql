@@ -0,0 +1,8 @@
+DROP TABLE notifications;
+DROP TABLE swipe_quotas;
+
+-- Super likes become plain likes; intro notes are dropped --
+ALTER TABLE swipes DROP COLUMN intro_note;
+UPDATE swipes SET direction = 'like' WHERE direction = 'superlike';
+ALTER TABLE swipes DROP CONSTRAINT swipes_direction_check;
+ALTER TABLE swipes ADD CONSTRAINT swipes_direction_check CHECK(direction IN ('like', 'dislike'));
@@ -0,0 +1,25 @@
+-- Super likes: a third swipe direction carrying an optional intro note.
+ALTER TABLE swipes DROP CONSTRAINT swipes_direction_check;
+ALTER TABLE swipes ADD CONSTRAINT swipes_direction_check CHECK(direction IN ('like', 'dislike', 'superlike'));
+ALTER TABLE swipes ADD COLUMN intro_note TEXT;
+
+-- Daily super like usage, one row per user per UTC day --
+CREATE TABLE swipe_quotas (
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	day TEXT NOT NULL, -- YYYY-MM-DD in UTC
+	superlikes_used INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (user_id, day)
+);
+
+-- Notifications Table --
+CREATE TABLE notifications (
+	id TEXT PRIMARY KEY DEFAULT gen_random_uuid()::text,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,          -- Recipient
+	type TEXT NOT NULL,
+	actor_user_id TEXT REFERENCES users(id) ON DELETE CASCADE,             -- User who triggered the notification, if any
+	swipe_id TEXT REFERENCES swipes(id) ON DELETE CASCADE,                 -- Swipe the notification is about, if any
+	message TEXT,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	read_at TIMESTAMPTZ
+);
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at);
@@ -0,0 +1,16 @@
+-- Personal data export jobs. The archive is a file on the server; download_token is the
+-- secret part of its download link.
+CREATE TABLE data_exports (
+	id TEXT PRIMARY KEY DEFAULT gen_random_uuid()::text,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	status TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending', 'ready', 'failed')),
+	download_token TEXT UNIQUE,
+	file_path TEXT,
+	size_bytes BIGINT,
+	error TEXT,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	completed_at TIMESTAMPTZ,
+	expires_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_data_exports_user_created ON data_exports(user_id, created_at);
+CREATE INDEX idx_data_exports_expires ON data_exports(expires_at);
@@ -0,0 +1,10 @@
+-- Messages of deleted users have no sender to point back to and are dropped --
+DROP INDEX idx_messages_sender_id;
+DELETE FROM messages WHERE sender_user_id IS NULL;
+ALTER TABLE messages DROP CONSTRAINT messages_sender_user_id_fkey;
+ALTER TABLE messages ADD CONSTRAINT messages_sender_user_id_fkey
+	FOREIGN KEY (sender_user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE messages ALTER COLUMN sender_user_id SET NOT NULL;
+
+DROP INDEX idx_users_deletion_scheduled_for;
+ALTER TABLE users DROP COLUMN deletion_scheduled_for;
@@ -0,0 +1,11 @@
+-- Account deletion: a user asks to be deleted and is purged once the grace period ends.
+ALTER TABLE users ADD COLUMN deletion_scheduled_for TIMESTAMPTZ;
+CREATE INDEX idx_users_deletion_scheduled_for ON users(deletion_scheduled_for);
+
+-- Messages outlive their sender: deleting a user keeps their messages in conversations
+-- that others still have, with sender_user_id set to NULL.
+ALTER TABLE messages ALTER COLUMN sender_user_id DROP NOT NULL;
+ALTER TABLE messages DROP CONSTRAINT messages_sender_user_id_fkey;
+ALTER TABLE messages ADD CONSTRAINT messages_sender_user_id_fkey
+	FOREIGN KEY (sender_user_id) REFERENCES users(id) ON DELETE SET NULL;
+CREATE INDEX idx_messages_sender_id ON messages(sender_user_id);
@@ -0,0 +1,9 @@
+-- Webhook deliveries that have been processed, keyed by the sender's message ID, so
+-- redeliveries of the same event are acknowledged without being applied twice.
+CREATE TABLE webhook_events (
+	source TEXT NOT NULL, -- e.g. 'clerk'
+	id TEXT NOT NULL, -- Message ID from the sender, e.g. the svix-id header
+	type TEXT NOT NULL,
+	processed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	PRIMARY KEY (source, id)
+);
@@ -0,0 +1,8 @@
+DROP TABLE user_reports;
+
+DROP INDEX idx_users_suspended_at;
+ALTER TABLE users DROP COLUMN suspension_reason;
+ALTER TABLE users DROP COLUMN suspended_at;
+
+DROP INDEX idx_users_role;
+ALTER TABLE users DROP COLUMN role;
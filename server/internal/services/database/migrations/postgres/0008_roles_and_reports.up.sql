@@ -0,0 +1,25 @@
+-- Roles and moderation. A user's role is 'user' unless raised by an admin or by the
+-- "role" key of their Clerk public metadata.
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user' CHECK(role IN ('user', 'moderator', 'admin'));
+CREATE INDEX idx_users_role ON users(role);
+
+-- Suspended users cannot use the API and are hidden from decks and search --
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMPTZ;
+ALTER TABLE users ADD COLUMN suspension_reason TEXT;
+CREATE INDEX idx_users_suspended_at ON users(suspended_at);
+
+-- Reports filed against users, reviewed by moderators --
+CREATE TABLE user_reports (
+	id TEXT PRIMARY KEY DEFAULT gen_random_uuid()::text,
+	reporter_user_id TEXT REFERENCES users(id) ON DELETE SET NULL, -- NULL once the reporter has deleted their account
+	reported_user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	reason TEXT NOT NULL,
+	details TEXT,
+	status TEXT NOT NULL DEFAULT 'open' CHECK(status IN ('open', 'resolved', 'dismissed')),
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	resolved_at TIMESTAMPTZ,
+	resolved_by_user_id TEXT REFERENCES users(id) ON DELETE SET NULL,
+	resolution_note TEXT
+);
+CREATE INDEX idx_user_reports_status_created ON user_reports(status, created_at);
+CREATE INDEX idx_user_reports_reported ON user_reports(reported_user_id);
@@ -0,0 +1,27 @@
+-- Blocks: a blocked user disappears from the blocker's deck, search and chats, and
+-- the other way round --
+CREATE TABLE user_blocks (
+	blocker_user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	blocked_user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	PRIMARY KEY (blocker_user_id, blocked_user_id)
+);
+CREATE INDEX idx_user_blocks_blocked ON user_blocks(blocked_user_id);
+
+-- Messages attached to a report as evidence. The content is copied so moderators can
+-- still read it after the message is removed; message_id is cleared then.
+CREATE TABLE report_messages (
+	report_id TEXT NOT NULL REFERENCES user_reports(id) ON DELETE CASCADE,
+	message_id TEXT REFERENCES messages(id) ON DELETE SET NULL,
+	sender_user_id TEXT REFERENCES users(id) ON DELETE SET NULL,
+	content TEXT NOT NULL,
+	sent_at TIMESTAMPTZ NOT NULL
+);
+CREATE INDEX idx_report_messages_report ON report_messages(report_id);
+CREATE INDEX idx_report_messages_message ON report_messages(message_id);
+
+-- What the moderator did when closing a report, e.g. 'suspend_user,remove_messages' --
+ALTER TABLE user_reports ADD COLUMN resolution_actions TEXT;
+
+-- One open report per reporter and reported user; later ones add nothing to the queue --
+CREATE UNIQUE INDEX idx_user_reports_open_pair ON user_reports(reporter_user_id, reported_user_id) WHERE status = 'open';
@@ -0,0 +1,18 @@
+-- Audit log of content the moderation pipeline flagged or rejected. The content is kept
+-- so moderators can review it; rejected content was never saved anywhere else.
+CREATE TABLE moderation_events (
+	id TEXT PRIMARY KEY DEFAULT gen_random_uuid()::text,
+	user_id TEXT NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+	content_type TEXT NOT NULL CHECK(content_type IN ('message', 'bio')),
+	conversation_id TEXT REFERENCES conversations(id) ON DELETE SET NULL, -- Set for messages
+	message_id TEXT REFERENCES messages(id) ON DELETE SET NULL,           -- Set for flagged messages, which were sent
+	decision TEXT NOT NULL CHECK(decision IN ('flag', 'reject')),
+	stage TEXT NOT NULL, -- Which checker decided, e.g. 'rules' or 'llm'
+	rule TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	content TEXT NOT NULL,
+	created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+CREATE INDEX idx_moderation_events_created ON moderation_events(created_at);
+CREATE INDEX idx_moderation_events_user ON moderation_events(user_id);
+CREATE INDEX idx_moderation_events_message ON moderation_events(message_id);
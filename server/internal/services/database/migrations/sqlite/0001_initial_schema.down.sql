@@ -0,0 +1,7 @@
+DROP TRIGGER IF EXISTS trigger_update_conversation_on_message;
+DROP TABLE IF EXISTS messages;
+DROP TABLE IF EXISTS conversation_participants;
+DROP TABLE IF EXISTS conversations;
+DROP TABLE IF EXISTS swipes;
+DROP TRIGGER IF EXISTS trigger_users_update_updated_at;
+DROP TABLE IF EXISTS users;
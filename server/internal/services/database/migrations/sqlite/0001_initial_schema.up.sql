@@ -0,0 +1,73 @@
+-- Initial schema, as created by ensureSchema before migrations existed.
+-- IF NOT EXISTS lets this adopt databases created by that code.
+
+-- Users Table --
+CREATE TABLE IF NOT EXISTS users (
+	id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+	clerk_user_id TEXT UNIQUE NOT NULL,
+	username TEXT,
+	picture_url TEXT,
+	bio TEXT,
+	github_url TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL
+);
+CREATE INDEX IF NOT EXISTS idx_users_clerk_user_id ON users(clerk_user_id);
+
+CREATE TRIGGER IF NOT EXISTS trigger_users_update_updated_at
+AFTER UPDATE ON users FOR EACH ROW
+WHEN OLD.updated_at = NEW.updated_at -- Avoid infinite loop if updated_at is explicitly set
+BEGIN
+	UPDATE users SET updated_at = CURRENT_TIMESTAMP WHERE id = OLD.id;
+END;
+
+-- Swipes Table --
+CREATE TABLE IF NOT EXISTS swipes (
+	id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+	swiper_user_id TEXT NOT NULL,
+	swiped_user_id TEXT NOT NULL,
+	direction TEXT NOT NULL CHECK(direction IN ('like', 'dislike')),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	FOREIGN KEY (swiper_user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (swiped_user_id) REFERENCES users(id) ON DELETE CASCADE,
+	UNIQUE (swiper_user_id, swiped_user_id)
+);
+CREATE INDEX IF NOT EXISTS idx_swipes_swiper_id ON swipes(swiper_user_id);
+CREATE INDEX IF NOT EXISTS idx_swipes_swiped_id ON swipes(swiped_user_id);
+
+-- Conversations Table --
+CREATE TABLE IF NOT EXISTS conversations (
+	id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL -- Tracks last activity/message
+);
+
+-- Conversation Participants Join Table --
+CREATE TABLE IF NOT EXISTS conversation_participants (
+	conversation_id TEXT NOT NULL,
+	user_id TEXT NOT NULL,
+	PRIMARY KEY (conversation_id, user_id),
+	FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX IF NOT EXISTS idx_conv_participants_user_id ON conversation_participants(user_id);
+
+-- Messages Table --
+CREATE TABLE IF NOT EXISTS messages (
+	id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+	conversation_id TEXT NOT NULL,
+	sender_user_id TEXT NOT NULL,
+	content TEXT NOT NULL,
+	sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+	FOREIGN KEY (sender_user_id) REFERENCES users(id) ON DELETE CASCADE -- Assuming sender must exist
+);
+CREATE INDEX IF NOT EXISTS idx_messages_conversation_id ON messages(conversation_id);
+CREATE INDEX IF NOT EXISTS idx_messages_sent_at ON messages(sent_at);
+
+-- Trigger to update conversation updated_at on new message --
+CREATE TRIGGER IF NOT EXISTS trigger_update_conversation_on_message
+AFTER INSERT ON messages FOR EACH ROW
+BEGIN
+	UPDATE conversations SET updated_at = NEW.sent_at WHERE id = NEW.conversation_id;
+END;
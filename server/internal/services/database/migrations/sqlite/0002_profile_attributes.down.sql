@@ -0,0 +1,13 @@
+DROP TABLE user_interests;
+
+DROP INDEX idx_users_primary_language;
+DROP INDEX idx_users_timezone_offset;
+DROP INDEX idx_users_github_followers;
+DROP INDEX idx_users_github_public_repos;
+DROP INDEX idx_users_last_active_at;
+
+ALTER TABLE users DROP COLUMN primary_language;
+ALTER TABLE users DROP COLUMN timezone_offset_minutes;
+ALTER TABLE users DROP COLUMN github_followers;
+ALTER TABLE users DROP COLUMN github_public_repos;
+ALTER TABLE users DROP COLUMN last_active_at;
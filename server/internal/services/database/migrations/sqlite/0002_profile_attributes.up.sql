@@ -0,0 +1,21 @@
+-- Profile attributes used by deck filters and the recommender.
+ALTER TABLE users ADD COLUMN primary_language TEXT COLLATE NOCASE;
+ALTER TABLE users ADD COLUMN timezone_offset_minutes INTEGER;
+ALTER TABLE users ADD COLUMN github_followers INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN github_public_repos INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN last_active_at TIMESTAMP;
+
+CREATE INDEX idx_users_primary_language ON users(primary_language);
+CREATE INDEX idx_users_timezone_offset ON users(timezone_offset_minutes);
+CREATE INDEX idx_users_github_followers ON users(github_followers);
+CREATE INDEX idx_users_github_public_repos ON users(github_public_repos);
+CREATE INDEX idx_users_last_active_at ON users(last_active_at);
+
+-- User Interest Tags (one row per tag, lowercase) --
+CREATE TABLE user_interests (
+	user_id TEXT NOT NULL,
+	tag TEXT NOT NULL,
+	PRIMARY KEY (user_id, tag),
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_user_interests_tag ON user_interests(tag);
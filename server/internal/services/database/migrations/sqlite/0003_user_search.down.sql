@@ -0,0 +1,8 @@
+DROP TRIGGER trigger_user_interests_fts_delete;
+DROP TRIGGER trigger_user_interests_fts_insert;
+DROP TRIGGER trigger_users_fts_delete;
+DROP TRIGGER trigger_users_fts_update;
+DROP TRIGGER trigger_users_fts_insert;
+DROP TABLE users_fts;
+
+ALTER TABLE users DROP COLUMN repo_names;
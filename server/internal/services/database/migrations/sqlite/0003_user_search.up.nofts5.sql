@@ -0,0 +1,5 @@
+-- 0003_user_search for SQLite builds without FTS5: everything but the full-text index.
+-- Search falls back to LIKE matching (see SearchUsers).
+
+-- Space-separated GitHub repository names, matched by search --
+ALTER TABLE users ADD COLUMN repo_names TEXT;
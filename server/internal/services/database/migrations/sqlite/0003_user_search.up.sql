@@ -0,0 +1,58 @@
+-- Full-text developer search. FTS5 requires building with the sqlite_fts5 tag.
+
+-- Space-separated GitHub repository names, indexed for search --
+ALTER TABLE users ADD COLUMN repo_names TEXT;
+
+-- Full-text index over profiles --
+CREATE VIRTUAL TABLE users_fts USING fts5(
+	user_id UNINDEXED,
+	username,
+	bio,
+	interests,
+	repo_names,
+	tokenize = 'unicode61 remove_diacritics 2'
+);
+
+CREATE TRIGGER trigger_users_fts_insert
+AFTER INSERT ON users FOR EACH ROW
+BEGIN
+	INSERT INTO users_fts (user_id, username, bio, interests, repo_names)
+	VALUES (NEW.id, NEW.username, NEW.bio, '', NEW.repo_names);
+END;
+
+CREATE TRIGGER trigger_users_fts_update
+AFTER UPDATE OF username, bio, repo_names ON users FOR EACH ROW
+BEGIN
+	UPDATE users_fts
+	SET username = NEW.username, bio = NEW.bio, repo_names = NEW.repo_names
+	WHERE user_id = NEW.id;
+END;
+
+CREATE TRIGGER trigger_users_fts_delete
+AFTER DELETE ON users FOR EACH ROW
+BEGIN
+	DELETE FROM users_fts WHERE user_id = OLD.id;
+END;
+
+CREATE TRIGGER trigger_user_interests_fts_insert
+AFTER INSERT ON user_interests FOR EACH ROW
+BEGIN
+	UPDATE users_fts
+	SET interests = (SELECT group_concat(tag, ' ') FROM user_interests WHERE user_id = NEW.user_id)
+	WHERE user_id = NEW.user_id;
+END;
+
+CREATE TRIGGER trigger_user_interests_fts_delete
+AFTER DELETE ON user_interests FOR EACH ROW
+BEGIN
+	UPDATE users_fts
+	SET interests = COALESCE((SELECT group_concat(tag, ' ') FROM user_interests WHERE user_id = OLD.user_id), '')
+	WHERE user_id = OLD.user_id;
+END;
+
+-- Index existing profiles --
+INSERT INTO users_fts (user_id, username, bio, interests, repo_names)
+SELECT u.id, u.username, u.bio,
+	COALESCE((SELECT group_concat(tag, ' ') FROM user_interests WHERE user_id = u.id), ''),
+	u.repo_names
+FROM users u;
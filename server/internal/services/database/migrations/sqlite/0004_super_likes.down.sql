@@ -0,0 +1,23 @@
+DROP TABLE notifications;
+DROP TABLE swipe_quotas;
+
+-- Super likes become plain likes; intro notes are dropped --
+CREATE TABLE swipes_old (
+	id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+	swiper_user_id TEXT NOT NULL,
+	swiped_user_id TEXT NOT NULL,
+	direction TEXT NOT NULL CHECK(direction IN ('like', 'dislike')),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	FOREIGN KEY (swiper_user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (swiped_user_id) REFERENCES users(id) ON DELETE CASCADE,
+	UNIQUE (swiper_user_id, swiped_user_id)
+);
+INSERT INTO swipes_old (id, swiper_user_id, swiped_user_id, direction, created_at)
+	SELECT id, swiper_user_id, swiped_user_id,
+		CASE direction WHEN 'superlike' THEN 'like' ELSE direction END,
+		created_at
+	FROM swipes;
+DROP TABLE swipes;
+ALTER TABLE swipes_old RENAME TO swipes;
+CREATE INDEX idx_swipes_swiper_id ON swipes(swiper_user_id);
+CREATE INDEX idx_swipes_swiped_id ON swipes(swiped_user_id);
@@ -0,0 +1,44 @@
+-- Super likes: a third swipe direction carrying an optional intro note.
+-- SQLite cannot alter a CHECK constraint in place, so swipes is rebuilt.
+CREATE TABLE swipes_new (
+	id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+	swiper_user_id TEXT NOT NULL,
+	swiped_user_id TEXT NOT NULL,
+	direction TEXT NOT NULL CHECK(direction IN ('like', 'dislike', 'superlike')),
+	intro_note TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	FOREIGN KEY (swiper_user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (swiped_user_id) REFERENCES users(id) ON DELETE CASCADE,
+	UNIQUE (swiper_user_id, swiped_user_id)
+);
+INSERT INTO swipes_new (id, swiper_user_id, swiped_user_id, direction, created_at)
+	SELECT id, swiper_user_id, swiped_user_id, direction, created_at FROM swipes;
+DROP TABLE swipes;
+ALTER TABLE swipes_new RENAME TO swipes;
+CREATE INDEX idx_swipes_swiper_id ON swipes(swiper_user_id);
+CREATE INDEX idx_swipes_swiped_id ON swipes(swiped_user_id);
+
+-- Daily super like usage, one row per user per UTC day --
+CREATE TABLE swipe_quotas (
+	user_id TEXT NOT NULL,
+	day TEXT NOT NULL, -- YYYY-MM-DD in UTC
+	superlikes_used INTEGER NOT NULL DEFAULT 0,
+	PRIMARY KEY (user_id, day),
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+
+-- Notifications Table --
+CREATE TABLE notifications (
+	id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+	user_id TEXT NOT NULL,       -- Recipient
+	type TEXT NOT NULL,
+	actor_user_id TEXT,          -- User who triggered the notification, if any
+	swipe_id TEXT,               -- Swipe the notification is about, if any
+	message TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	read_at TIMESTAMP,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (actor_user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (swipe_id) REFERENCES swipes(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at);
@@ -0,0 +1,17 @@
+-- Personal data export jobs. The archive is a file on the server; download_token is the
+-- secret part of its download link.
+CREATE TABLE data_exports (
+	id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+	user_id TEXT NOT NULL,
+	status TEXT NOT NULL DEFAULT 'pending' CHECK(status IN ('pending', 'ready', 'failed')),
+	download_token TEXT UNIQUE,
+	file_path TEXT,
+	size_bytes INTEGER,
+	error TEXT,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	completed_at TIMESTAMP,
+	expires_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_data_exports_user_created ON data_exports(user_id, created_at);
+CREATE INDEX idx_data_exports_expires ON data_exports(expires_at);
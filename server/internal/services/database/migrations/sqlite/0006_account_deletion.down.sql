@@ -0,0 +1,26 @@
+-- Messages of deleted users have no sender to point back to and are dropped --
+CREATE TABLE messages_old (
+	id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+	conversation_id TEXT NOT NULL,
+	sender_user_id TEXT NOT NULL,
+	content TEXT NOT NULL,
+	sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+	FOREIGN KEY (sender_user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+INSERT INTO messages_old (id, conversation_id, sender_user_id, content, sent_at)
+	SELECT id, conversation_id, sender_user_id, content, sent_at FROM messages
+	WHERE sender_user_id IS NOT NULL;
+DROP TABLE messages;
+ALTER TABLE messages_old RENAME TO messages;
+CREATE INDEX idx_messages_conversation_id ON messages(conversation_id);
+CREATE INDEX idx_messages_sent_at ON messages(sent_at);
+
+CREATE TRIGGER trigger_update_conversation_on_message
+AFTER INSERT ON messages FOR EACH ROW
+BEGIN
+	UPDATE conversations SET updated_at = NEW.sent_at WHERE id = NEW.conversation_id;
+END;
+
+DROP INDEX idx_users_deletion_scheduled_for;
+ALTER TABLE users DROP COLUMN deletion_scheduled_for;
@@ -0,0 +1,30 @@
+-- Account deletion: a user asks to be deleted and is purged once the grace period ends.
+ALTER TABLE users ADD COLUMN deletion_scheduled_for TIMESTAMP;
+CREATE INDEX idx_users_deletion_scheduled_for ON users(deletion_scheduled_for);
+
+-- Messages outlive their sender: deleting a user keeps their messages in conversations
+-- that others still have, with sender_user_id set to NULL. SQLite cannot change a foreign
+-- key in place, so messages is rebuilt.
+CREATE TABLE messages_new (
+	id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+	conversation_id TEXT NOT NULL,
+	sender_user_id TEXT, -- NULL once the sender has deleted their account
+	content TEXT NOT NULL,
+	sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	FOREIGN KEY (conversation_id) REFERENCES conversations(id) ON DELETE CASCADE,
+	FOREIGN KEY (sender_user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+INSERT INTO messages_new (id, conversation_id, sender_user_id, content, sent_at)
+	SELECT id, conversation_id, sender_user_id, content, sent_at FROM messages;
+DROP TABLE messages;
+ALTER TABLE messages_new RENAME TO messages;
+CREATE INDEX idx_messages_conversation_id ON messages(conversation_id);
+CREATE INDEX idx_messages_sent_at ON messages(sent_at);
+CREATE INDEX idx_messages_sender_id ON messages(sender_user_id);
+
+-- Dropping the table dropped its trigger --
+CREATE TRIGGER trigger_update_conversation_on_message
+AFTER INSERT ON messages FOR EACH ROW
+BEGIN
+	UPDATE conversations SET updated_at = NEW.sent_at WHERE id = NEW.conversation_id;
+END;
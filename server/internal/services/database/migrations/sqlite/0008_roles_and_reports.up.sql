@@ -0,0 +1,28 @@
+-- Roles and moderation. A user's role is 'user' unless raised by an admin or by the
+-- "role" key of their Clerk public metadata.
+ALTER TABLE users ADD COLUMN role TEXT NOT NULL DEFAULT 'user' CHECK(role IN ('user', 'moderator', 'admin'));
+CREATE INDEX idx_users_role ON users(role);
+
+-- Suspended users cannot use the API and are hidden from decks and search --
+ALTER TABLE users ADD COLUMN suspended_at TIMESTAMP;
+ALTER TABLE users ADD COLUMN suspension_reason TEXT;
+CREATE INDEX idx_users_suspended_at ON users(suspended_at);
+
+-- Reports filed against users, reviewed by moderators --
+CREATE TABLE user_reports (
+	id TEXT PRIMARY KEY DEFAULT (lower(hex(randomblob(16)))),
+	reporter_user_id TEXT, -- NULL once the reporter has deleted their account
+	reported_user_id TEXT NOT NULL,
+	reason TEXT NOT NULL,
+	details TEXT,
+	status TEXT NOT NULL DEFAULT 'open' CHECK(status IN ('open', 'resolved', 'dismissed')),
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	resolved_at TIMESTAMP,
+	resolved_by_user_id TEXT,
+	resolution_note TEXT,
+	FOREIGN KEY (reporter_user_id) REFERENCES users(id) ON DELETE SET NULL,
+	FOREIGN KEY (reported_user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (resolved_by_user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX idx_user_reports_status_created ON user_reports(status, created_at);
+CREATE INDEX idx_user_reports_reported ON user_reports(reported_user_id);
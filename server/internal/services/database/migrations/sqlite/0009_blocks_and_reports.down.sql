@@ -0,0 +1,5 @@
+DROP INDEX idx_user_reports_open_pair;
+ALTER TABLE user_reports DROP COLUMN resolution_actions;
+DROP TABLE report_messages;
+
+DROP TABLE user_blocks;
@@ -0,0 +1,32 @@
+-- Blocks: a blocked user disappears from the blocker's deck, search and chats, and
+-- the other way round --
+CREATE TABLE user_blocks (
+	blocker_user_id TEXT NOT NULL,
+	blocked_user_id TEXT NOT NULL,
+	created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP NOT NULL,
+	PRIMARY KEY (blocker_user_id, blocked_user_id),
+	FOREIGN KEY (blocker_user_id) REFERENCES users(id) ON DELETE CASCADE,
+	FOREIGN KEY (blocked_user_id) REFERENCES users(id) ON DELETE CASCADE
+);
+CREATE INDEX idx_user_blocks_blocked ON user_blocks(blocked_user_id);
+
+-- Messages attached to a report as evidence. The content is copied so moderators can
+-- still read it after the message is removed; message_id is cleared then.
+CREATE TABLE report_messages (
+	report_id TEXT NOT NULL,
+	message_id TEXT,
+	sender_user_id TEXT,
+	content TEXT NOT NULL,
+	sent_at TIMESTAMP NOT NULL,
+	FOREIGN KEY (report_id) REFERENCES user_reports(id) ON DELETE CASCADE,
+	FOREIGN KEY (message_id) REFERENCES messages(id) ON DELETE SET NULL,
+	FOREIGN KEY (sender_user_id) REFERENCES users(id) ON DELETE SET NULL
+);
+CREATE INDEX idx_report_messages_report ON report_messages(report_id);
+CREATE INDEX idx_report_messages_message ON report_messages(message_id);
+
+-- What the moderator did when closing a report, e.g. 'suspend_user,remove_messages' --
+ALTER TABLE user_reports ADD COLUMN resolution_actions TEXT;
+
+-- One open report per reporter and reported user; later ones add nothing to the queue --
+CREATE UNIQUE INDEX idx_user_reports_open_pair ON user_reports(reporter_user_id, reported_user_id) WHERE status = 'open';
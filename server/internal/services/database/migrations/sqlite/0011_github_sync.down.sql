@@ -0,0 +1,2 @@
+DROP INDEX idx_users_github_synced_at;
+ALTER TABLE users DROP COLUMN github_synced_at;
@@ -0,0 +1,4 @@
+-- When each profile's GitHub stats were last fetched, so the background refresh can
+-- pick the stalest profiles first. NULL until the first fetch.
+ALTER TABLE users ADD COLUMN github_synced_at TIMESTAMP;
+CREATE INDEX idx_users_github_synced_at ON users(github_synced_at);
@@ -0,0 +1,101 @@
+package database
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"gin/internal/models"
+)
+
+// --- Moderation Log Operations ---
+
+// moderationEventColumns lists the moderation_events columns in the order expected by scanModerationEvent.
+const moderationEventColumns = `id, user_id, content_type, conversation_id, message_id, decision, stage, rule, reason, content, created_at`
+
+// scanModerationEvent scans a row selected with moderationEventColumns into a models.ModerationEvent.
+func scanModerationEvent(row rowScanner) (*models.ModerationEvent, error) {
+	var event models.ModerationEvent
+	err := row.Scan(
+		&event.ID,
+		&event.UserID,
+		&event.ContentType,
+		&event.ConversationID,
+		&event.MessageID,
+		&event.Decision,
+		&event.Stage,
+		&event.Rule,
+		&event.Reason,
+		&event.Content,
+		&event.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+// RecordModerationEvent adds event to the moderation log and returns it. Only flagged and
+// rejected content is logged.
+func (s *DBService) RecordModerationEvent(ctx context.Context, event models.ModerationEvent) (*models.ModerationEvent, error) {
+	if event.Decision != models.ModerationFlag && event.Decision != models.ModerationReject {
+		return nil, fmt.Errorf("cannot log a moderation decision of %q", event.Decision)
+	}
+	query := `
+		INSERT INTO moderation_events (user_id, content_type, conversation_id, message_id, decision, stage, rule, reason, content)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		RETURNING ` + moderationEventColumns
+	recorded, err := scanModerationEvent(s.DB.Writer().QueryRowContext(ctx, query,
+		event.UserID, event.ContentType, event.ConversationID, event.MessageID,
+		event.Decision, event.Stage, event.Rule, event.Reason, event.Content))
+	if err != nil {
+		logger.ErrorContext(ctx, "Error logging moderation decision", "user_id", event.UserID, "error", err)
+		return nil, fmt.Errorf("logging moderation decision failed: %w", err)
+	}
+	return recorded, nil
+}
+
+// GetModerationEvents returns moderation log entries matching filter, newest first.
+func (s *DBService) GetModerationEvents(ctx context.Context, filter models.ModerationEventFilter, limit, offset int) ([]models.ModerationEvent, error) {
+	conditions := []string{}
+	args := []any{}
+	if filter.Decision != "" {
+		conditions = append(conditions, "decision = ?")
+		args = append(args, filter.Decision)
+	}
+	if filter.ContentType != "" {
+		conditions = append(conditions, "content_type = ?")
+		args = append(args, filter.ContentType)
+	}
+	if filter.UserID != "" {
+		conditions = append(conditions, "user_id = ?")
+		args = append(args, filter.UserID)
+	}
+
+	query := `SELECT ` + moderationEventColumns + ` FROM moderation_events`
+	if len(conditions) > 0 {
+		query += ` WHERE ` + strings.Join(conditions, " AND ")
+	}
+	query += ` ORDER BY created_at DESC` + s.DB.Dialect.insertionTiebreak("") + ` LIMIT ? OFFSET ?`
+	args = append(args, limit, offset)
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying moderation log", "error", err)
+		return nil, fmt.Errorf("querying moderation log failed: %w", err)
+	}
+	defer rows.Close()
+
+	events := []models.ModerationEvent{}
+	for rows.Next() {
+		event, err := scanModerationEvent(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning moderation event row failed: %w", err)
+		}
+		events = append(events, *event)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating moderation event rows failed: %w", err)
+	}
+	return events, nil
+}
@@ -0,0 +1,61 @@
+package database
+
+import (
+	"context"
+	"testing"
+
+	"gin/internal/models"
+)
+
+func TestModerationEvents(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "")
+		bob := createUser(t, s, "bob", "")
+
+		if _, err := s.RecordModerationEvent(ctx, models.ModerationEvent{UserID: alice.ID, Decision: models.ModerationAllow}); err == nil {
+			t.Error("RecordModerationEvent logged an allowed message")
+		}
+		events := []models.ModerationEvent{
+			{UserID: alice.ID, ContentType: models.ModerationContentMessage, Decision: models.ModerationFlag, Stage: "rules", Rule: "link", Content: "see my site"},
+			{UserID: alice.ID, ContentType: models.ModerationContentBio, Decision: models.ModerationReject, Stage: "llm", Rule: "harassment", Content: "mean bio"},
+			{UserID: bob.ID, ContentType: models.ModerationContentMessage, Decision: models.ModerationReject, Stage: "rules", Rule: "banned_word", Content: "rude"},
+		}
+		for _, event := range events {
+			recorded, err := s.RecordModerationEvent(ctx, event)
+			if err != nil {
+				t.Fatalf("RecordModerationEvent: %v", err)
+			}
+			if recorded.ID == "" || recorded.Content != event.Content || recorded.CreatedAt.IsZero() {
+				t.Errorf("recorded event = %+v", recorded)
+			}
+		}
+
+		tests := []struct {
+			name   string
+			filter models.ModerationEventFilter
+			want   []string // Contents, newest first
+		}{
+			{"all", models.ModerationEventFilter{}, []string{"rude", "mean bio", "see my site"}},
+			{"rejected", models.ModerationEventFilter{Decision: models.ModerationReject}, []string{"rude", "mean bio"}},
+			{"messages", models.ModerationEventFilter{ContentType: models.ModerationContentMessage}, []string{"rude", "see my site"}},
+			{"by author", models.ModerationEventFilter{UserID: alice.ID, Decision: models.ModerationFlag}, []string{"see my site"}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				got, err := s.GetModerationEvents(ctx, tt.filter, 10, 0)
+				if err != nil {
+					t.Fatalf("GetModerationEvents: %v", err)
+				}
+				if len(got) != len(tt.want) {
+					t.Fatalf("got %d events, want %v", len(got), tt.want)
+				}
+				for i := range got {
+					if got[i].Content != tt.want[i] {
+						t.Errorf("event %d = %q, want %q", i, got[i].Content, tt.want[i])
+					}
+				}
+			})
+		}
+	})
+}
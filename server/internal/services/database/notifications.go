@@ -0,0 +1,76 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gin/internal/models"
+)
+
+// --- Notification Operations ---
+
+// createNotification inserts a notification within tx.
+func createNotification(ctx context.Context, tx *Tx, n models.Notification) error {
+	query := `
+		INSERT INTO notifications (user_id, type, actor_user_id, swipe_id, message)
+		VALUES (?, ?, ?, ?, ?)`
+
+	if _, err := tx.ExecContext(ctx, query, n.UserID, n.Type, n.ActorUserID, n.SwipeID, n.Message); err != nil {
+		logger.ErrorContext(ctx, "Error creating notification", "type", n.Type, "user_id", n.UserID, "error", err)
+		return fmt.Errorf("failed to create notification: %w", err)
+	}
+	return nil
+}
+
+// GetNotifications returns the notifications of userID, newest first.
+func (s *DBService) GetNotifications(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]models.Notification, error) {
+	query := `
+		SELECT id, user_id, type, actor_user_id, swipe_id, message, created_at, read_at
+		FROM notifications
+		WHERE user_id = ?`
+	if unreadOnly {
+		query += ` AND read_at IS NULL`
+	}
+	query += `
+		ORDER BY created_at DESC` + s.DB.Dialect.insertionTiebreak("") + `
+		LIMIT ? OFFSET ?`
+
+	rows, err := s.DB.QueryContext(ctx, query, userID, limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying notifications", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("querying notifications failed: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := []models.Notification{}
+	for rows.Next() {
+		var n models.Notification
+		if err := rows.Scan(&n.ID, &n.UserID, &n.Type, &n.ActorUserID, &n.SwipeID, &n.Message, &n.CreatedAt, &n.ReadAt); err != nil {
+			return nil, fmt.Errorf("scanning notification row failed: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating notification rows failed: %w", err)
+	}
+	return notifications, nil
+}
+
+// MarkNotificationRead marks notificationID as read if it belongs to userID.
+// Returns sql.ErrNoRows if there is no such notification for the user.
+func (s *DBService) MarkNotificationRead(ctx context.Context, userID, notificationID string) error {
+	query := `
+		UPDATE notifications SET read_at = COALESCE(read_at, CURRENT_TIMESTAMP)
+		WHERE id = ? AND user_id = ?`
+
+	res, err := s.DB.ExecContext(ctx, query, notificationID, userID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error marking notification read", "notification_id", notificationID, "error", err)
+		return fmt.Errorf("marking notification read failed: %w", err)
+	}
+	if n, err := res.RowsAffected(); err == nil && n == 0 {
+		return sql.ErrNoRows
+	}
+	return nil
+}
@@ -0,0 +1,137 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"gin/internal/models"
+)
+
+var (
+	// ErrDuplicateReport is returned when a user reports someone they already have an open report about.
+	ErrDuplicateReport = errors.New("an open report about this user already exists")
+	// ErrInvalidReportedMessage is returned when a report references a message that does not
+	// exist, was not sent by the reported user or is in a conversation the reporter is not part of.
+	ErrInvalidReportedMessage = errors.New("reported message not found")
+)
+
+// --- Report Operations ---
+
+// CreateReport files report (ReporterUserID, ReportedUserID, Reason and Details) with
+// copies of the messages in messageIDs attached as evidence, and returns it.
+// Returns ErrInvalidReportedMessage if a message cannot be attached and
+// ErrDuplicateReport if the reporter already has an open report about the user.
+func (s *DBService) CreateReport(ctx context.Context, report models.Report, messageIDs []string) (*models.Report, error) {
+	if report.ReporterUserID == nil {
+		return nil, errors.New("a report needs a reporter")
+	}
+	reporterID := *report.ReporterUserID
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // Rollback is ignored if Commit succeeds
+
+	// Only messages the reported user sent to the reporter can be attached
+	evidenceQuery := `
+		SELECT m.id, m.sender_user_id, m.content, m.sent_at
+		FROM messages m
+		JOIN conversation_participants cp ON cp.conversation_id = m.conversation_id AND cp.user_id = ?
+		WHERE m.id = ? AND m.sender_user_id = ?`
+	evidence := make([]models.ReportedMessage, 0, len(messageIDs))
+	seen := make(map[string]struct{}, len(messageIDs))
+	for _, messageID := range messageIDs {
+		if _, dup := seen[messageID]; dup {
+			continue
+		}
+		seen[messageID] = struct{}{}
+
+		var msg models.ReportedMessage
+		err := tx.QueryRowContext(ctx, evidenceQuery, reporterID, messageID, report.ReportedUserID).
+			Scan(&msg.MessageID, &msg.SenderUserID, &msg.Content, &msg.SentAt)
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidReportedMessage, messageID)
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "Error loading reported message", "message_id", messageID, "reporter_id", reporterID, "error", err)
+			return nil, fmt.Errorf("loading reported message failed: %w", err)
+		}
+		evidence = append(evidence, msg)
+	}
+
+	insertQuery := `
+		INSERT INTO user_reports (reporter_user_id, reported_user_id, reason, details)
+		VALUES (?, ?, ?, ?)
+		RETURNING ` + reportColumns
+	created, err := scanReport(tx.QueryRowContext(ctx, insertQuery, reporterID, report.ReportedUserID, report.Reason, report.Details))
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrDuplicateReport
+		}
+		logger.ErrorContext(ctx, "Error creating report", "reporter_id", reporterID, "user_id", report.ReportedUserID, "error", err)
+		return nil, fmt.Errorf("creating report failed: %w", err)
+	}
+
+	for _, msg := range evidence {
+		_, err := tx.ExecContext(ctx,
+			`INSERT INTO report_messages (report_id, message_id, sender_user_id, content, sent_at) VALUES (?, ?, ?, ?, ?)`,
+			created.ID, msg.MessageID, msg.SenderUserID, msg.Content, msg.SentAt)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error attaching message to report", "message_id", *msg.MessageID, "report_id", created.ID, "error", err)
+			return nil, fmt.Errorf("attaching reported message failed: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return created, nil
+}
+
+// GetReportMessages returns the messages attached to reportID, oldest first.
+func (s *DBService) GetReportMessages(ctx context.Context, reportID string) ([]models.ReportedMessage, error) {
+	query := `
+		SELECT message_id, sender_user_id, content, sent_at
+		FROM report_messages
+		WHERE report_id = ?
+		ORDER BY sent_at`
+
+	rows, err := s.DB.QueryContext(ctx, query, reportID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying messages of report", "report_id", reportID, "error", err)
+		return nil, fmt.Errorf("querying reported messages failed: %w", err)
+	}
+	defer rows.Close()
+
+	messages := []models.ReportedMessage{}
+	for rows.Next() {
+		var msg models.ReportedMessage
+		if err := rows.Scan(&msg.MessageID, &msg.SenderUserID, &msg.Content, &msg.SentAt); err != nil {
+			return nil, fmt.Errorf("scanning reported message row failed: %w", err)
+		}
+		messages = append(messages, msg)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating reported message rows failed: %w", err)
+	}
+	return messages, nil
+}
+
+// RemoveReportedMessages deletes the messages attached to reportID from their
+// conversations and returns how many were deleted. The copies on the report remain.
+func (s *DBService) RemoveReportedMessages(ctx context.Context, reportID string) (int, error) {
+	query := `DELETE FROM messages WHERE id IN (SELECT message_id FROM report_messages WHERE report_id = ?)`
+	res, err := s.DB.ExecContext(ctx, query, reportID)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error removing messages of report", "report_id", reportID, "error", err)
+		return 0, fmt.Errorf("removing reported messages failed: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("counting removed messages failed: %w", err)
+	}
+	return int(n), nil
+}
@@ -0,0 +1,82 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gin/internal/models"
+)
+
+func TestCreateReport(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "")
+		bob := createUser(t, s, "bob", "")
+		carol := createUser(t, s, "carol", "")
+		moderator := createUser(t, s, "mod", "")
+		conversationID := matchUsers(t, s, alice, bob)
+
+		abuse, err := s.CreateMessage(ctx, conversationID, bob.ID, "buy my crypto")
+		if err != nil {
+			t.Fatal(err)
+		}
+		own, err := s.CreateMessage(ctx, conversationID, alice.ID, "no thanks")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		report := models.Report{ReporterUserID: &alice.ID, ReportedUserID: bob.ID, Reason: models.ReportScam}
+		// Only messages the reported user sent to the reporter are evidence
+		for _, messageID := range []string{own.ID, "00000000-0000-0000-0000-000000000000"} {
+			if _, err := s.CreateReport(ctx, report, []string{messageID}); !errors.Is(err, ErrInvalidReportedMessage) {
+				t.Errorf("attaching message %s error = %v, want ErrInvalidReportedMessage", messageID, err)
+			}
+		}
+		outsider := models.Report{ReporterUserID: &carol.ID, ReportedUserID: bob.ID, Reason: models.ReportScam}
+		if _, err := s.CreateReport(ctx, outsider, []string{abuse.ID}); !errors.Is(err, ErrInvalidReportedMessage) {
+			t.Errorf("attaching someone else's conversation error = %v, want ErrInvalidReportedMessage", err)
+		}
+
+		created, err := s.CreateReport(ctx, report, []string{abuse.ID, abuse.ID})
+		if err != nil {
+			t.Fatalf("CreateReport: %v", err)
+		}
+		if created.Status != models.ReportOpen || created.ReportedUserID != bob.ID || created.Reason != models.ReportScam {
+			t.Errorf("created report = %+v", created)
+		}
+		if _, err := s.CreateReport(ctx, report, nil); !errors.Is(err, ErrDuplicateReport) {
+			t.Errorf("second open report error = %v, want ErrDuplicateReport", err)
+		}
+
+		evidence, err := s.GetReportMessages(ctx, created.ID)
+		if err != nil || len(evidence) != 1 || evidence[0].Content != abuse.Content {
+			t.Fatalf("GetReportMessages = %+v, %v; want the reported message once", evidence, err)
+		}
+
+		// Removing the messages keeps the copies on the report
+		if n, err := s.RemoveReportedMessages(ctx, created.ID); err != nil || n != 1 {
+			t.Fatalf("RemoveReportedMessages = %d, %v; want 1", n, err)
+		}
+		messages, err := s.GetMessages(ctx, conversationID, 10, 0)
+		if err != nil || len(messages) != 1 || messages[0].ID != own.ID {
+			t.Errorf("messages after removal = %+v, %v; want only alice's", messages, err)
+		}
+		evidence, err = s.GetReportMessages(ctx, created.ID)
+		if err != nil || len(evidence) != 1 || evidence[0].MessageID != nil || evidence[0].Content != abuse.Content {
+			t.Errorf("evidence after removal = %+v, %v; want the copy without a message ID", evidence, err)
+		}
+
+		resolution := models.ReportResolution{
+			Status:  models.ReportResolved,
+			Actions: []models.ReportAction{models.ReportActionRemoveMessages},
+		}
+		if _, err := s.ResolveReport(ctx, created.ID, moderator.ID, resolution); err != nil {
+			t.Fatalf("ResolveReport: %v", err)
+		}
+		// Once the first report is closed the user can be reported again
+		if _, err := s.CreateReport(ctx, report, nil); err != nil {
+			t.Errorf("reporting again after resolution: %v", err)
+		}
+	})
+}
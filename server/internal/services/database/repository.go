@@ -0,0 +1,125 @@
+package database
+
+import (
+	"context"
+	"time"
+
+	"gin/internal/models"
+)
+
+// The repository interfaces below are what handlers and background jobs depend on.
+// DBService implements all of them against SQL; package memory provides in-memory
+// implementations for running handlers without a database. Implementations must
+// report a missing row as sql.ErrNoRows and use the sentinel errors of this package
+// (ErrAlreadySwiped, ErrNotConversationParticipant, ...) so callers can match on them.
+
+// UserRepository stores user profiles.
+type UserRepository interface {
+	GetUserProfileByClerkID(ctx context.Context, clerkUserID string) (*models.User, error)
+	GetUserProfileByID(ctx context.Context, userID string) (*models.User, error)
+	GetAllUserProfiles(ctx context.Context) ([]models.User, error)
+	CreateOrUpdateUserProfile(ctx context.Context, user models.User) (*models.User, error)
+	UpdateGitHubStats(ctx context.Context, userID string, stats models.GitHubStats) error
+	GetUsersDueForGitHubSync(ctx context.Context, syncedBefore time.Time, limit int) ([]models.User, error)
+	SearchUsers(ctx context.Context, callerID, query string, limit, offset int) ([]models.UserSearchResult, error)
+}
+
+// SwipeRepository stores swipes and the matches they produce.
+type SwipeRepository interface {
+	CreateSwipe(ctx context.Context, swipe models.Swipe, superLikeDailyLimit int) (*models.SwipeResult, error)
+	GetSwipeCandidates(ctx context.Context, userID string, filters models.CardFilters, limit int) ([]models.User, error)
+	GetLikes(ctx context.Context) ([]models.Swipe, error)
+	UndoLastSwipe(ctx context.Context, userID string, window time.Duration) (*models.UndoSwipeResult, error)
+	GetSwipeHistory(ctx context.Context, userID string, direction models.SwipeDirection, limit, offset int) ([]models.SwipeHistoryEntry, error)
+}
+
+// ConversationRepository stores conversations and their messages.
+type ConversationRepository interface {
+	GetConversations(ctx context.Context, userID string) ([]models.Conversation, error)
+	IsConversationParticipant(ctx context.Context, conversationID, userID string) (bool, error)
+	GetMessages(ctx context.Context, conversationID string, limit, offset int) ([]models.Message, error)
+	CreateMessage(ctx context.Context, conversationID, senderID, content string) (*models.Message, error)
+}
+
+// NotificationRepository stores in-app notifications.
+type NotificationRepository interface {
+	GetNotifications(ctx context.Context, userID string, unreadOnly bool, limit, offset int) ([]models.Notification, error)
+	MarkNotificationRead(ctx context.Context, userID, notificationID string) error
+}
+
+// DataExportRepository gathers a user's personal data and tracks export jobs.
+type DataExportRepository interface {
+	GetUserDataExport(ctx context.Context, userID string) (*models.UserDataExport, error)
+	CountUserHistory(ctx context.Context, userID string) (int, error)
+	CreateDataExport(ctx context.Context, userID string, expiresAt time.Time) (*models.DataExport, error)
+	GetDataExports(ctx context.Context, userID string) ([]models.DataExport, error)
+	GetDataExport(ctx context.Context, userID, exportID string) (*models.DataExport, error)
+	GetDataExportByToken(ctx context.Context, token string) (*models.DataExport, error)
+	CompleteDataExport(ctx context.Context, exportID, filePath, downloadToken string, sizeBytes int64, expiresAt time.Time) error
+	FailDataExport(ctx context.Context, exportID, reason string, expiresAt time.Time) error
+	DeleteExpiredDataExports(ctx context.Context, now time.Time) ([]models.DataExport, error)
+}
+
+// AccountRepository manages account deletion requests and purges accounts.
+type AccountRepository interface {
+	ScheduleAccountDeletion(ctx context.Context, userID string, purgeAt time.Time) (time.Time, error)
+	CancelAccountDeletion(ctx context.Context, userID string) error
+	GetAccountsDueForDeletion(ctx context.Context, now time.Time, limit int) ([]models.User, error)
+	PurgeUser(ctx context.Context, userID string) (*models.AccountPurgeResult, error)
+}
+
+// WebhookEventRepository remembers processed webhook deliveries for idempotency.
+type WebhookEventRepository interface {
+	IsWebhookEventProcessed(ctx context.Context, source, eventID string) (bool, error)
+	RecordWebhookEvent(ctx context.Context, source, eventID, eventType string) error
+}
+
+// BlockRepository stores the users each user has blocked. Blocks hide both users from
+// each other, whoever placed them.
+type BlockRepository interface {
+	BlockUser(ctx context.Context, blockerID, blockedID string) error
+	UnblockUser(ctx context.Context, blockerID, blockedID string) error
+	GetBlockedUsers(ctx context.Context, blockerID string) ([]models.BlockedUser, error)
+	IsBlockedBetween(ctx context.Context, userA, userB string) (bool, error)
+	IsBlockedInConversation(ctx context.Context, conversationID, userID string) (bool, error)
+}
+
+// ReportRepository files reports for the moderation queue.
+type ReportRepository interface {
+	CreateReport(ctx context.Context, report models.Report, messageIDs []string) (*models.Report, error)
+}
+
+// ModerationRepository keeps the audit log of flagged and rejected content.
+type ModerationRepository interface {
+	RecordModerationEvent(ctx context.Context, event models.ModerationEvent) (*models.ModerationEvent, error)
+	GetModerationEvents(ctx context.Context, filter models.ModerationEventFilter, limit, offset int) ([]models.ModerationEvent, error)
+}
+
+// AdminRepository backs the admin API: roles, suspensions, the report queue and stats.
+type AdminRepository interface {
+	SetUserRole(ctx context.Context, userID string, role models.UserRole) error
+	ListUsers(ctx context.Context, filter models.AdminUserFilter, limit, offset int) ([]models.User, error)
+	SuspendUser(ctx context.Context, userID, reason string) error
+	UnsuspendUser(ctx context.Context, userID string) error
+	GetReports(ctx context.Context, status models.ReportStatus, limit, offset int) ([]models.Report, error)
+	GetReport(ctx context.Context, reportID string) (*models.Report, error)
+	GetReportMessages(ctx context.Context, reportID string) ([]models.ReportedMessage, error)
+	RemoveReportedMessages(ctx context.Context, reportID string) (int, error)
+	ResolveReport(ctx context.Context, reportID, moderatorID string, resolution models.ReportResolution) (*models.Report, error)
+	GetSystemStats(ctx context.Context, now time.Time) (*models.SystemStats, error)
+}
+
+// DBService is the SQL implementation of every repository.
+var (
+	_ UserRepository         = (*DBService)(nil)
+	_ SwipeRepository        = (*DBService)(nil)
+	_ ConversationRepository = (*DBService)(nil)
+	_ NotificationRepository = (*DBService)(nil)
+	_ DataExportRepository   = (*DBService)(nil)
+	_ AccountRepository      = (*DBService)(nil)
+	_ WebhookEventRepository = (*DBService)(nil)
+	_ BlockRepository        = (*DBService)(nil)
+	_ ReportRepository       = (*DBService)(nil)
+	_ ModerationRepository   = (*DBService)(nil)
+	_ AdminRepository        = (*DBService)(nil)
+)
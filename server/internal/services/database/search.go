@@ -0,0 +1,272 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"html"
+	"strings"
+	"unicode"
+
+	"gin/internal/models"
+)
+
+// Snippet markers are control characters that cannot appear in profile text, so the
+// snippet can be HTML-escaped safely before they are swapped for <mark> tags.
+const (
+	snippetStart = "\x02"
+	snippetEnd   = "\x03"
+	snippetWords = 12 // Snippet length in words, as in the snippet() and ts_headline calls
+)
+
+// buildMatchQuery turns free-form user input into an FTS5 MATCH expression in which every
+// word must match as a prefix. Quoting each word keeps FTS5 operators in the input inert.
+// Returns "" if the input contains no searchable words.
+func buildMatchQuery(input string) string {
+	var terms []string
+	for _, word := range strings.Fields(input) {
+		word = strings.ReplaceAll(word, `"`, "")
+		if word == "" {
+			continue
+		}
+		terms = append(terms, `"`+word+`"*`)
+	}
+	return strings.Join(terms, " ")
+}
+
+// searchTerms splits input into lowercase words of letters and digits, dropping
+// everything else, including any query syntax.
+func searchTerms(input string) []string {
+	return strings.FieldsFunc(strings.ToLower(input), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// buildTSQuery is buildMatchQuery for Postgres: it returns a to_tsquery expression in
+// which every word must match as a prefix. Words are reduced to letters and digits, so
+// tsquery operators in the input are dropped. Returns "" if nothing searchable is left.
+func buildTSQuery(input string) string {
+	var terms []string
+	for _, word := range searchTerms(input) {
+		terms = append(terms, word+":*")
+	}
+	return strings.Join(terms, " & ")
+}
+
+// SearchUsers runs a full-text search over usernames, bios, interests and repository names
+// on behalf of callerID. Results are ordered by relevance (bm25, weighting usernames highest)
+// and exclude the caller and anyone who has blocked or been blocked by them.
+// Returns an empty result if query contains no searchable words.
+func (s *DBService) SearchUsers(ctx context.Context, callerID, query string, limit, offset int) ([]models.UserSearchResult, error) {
+	if s.DB.Dialect == DialectPostgres {
+		return s.searchUsersPostgres(ctx, callerID, query, limit, offset)
+	}
+	if s.DB.likeSearch {
+		return s.searchUsersLike(ctx, callerID, query, limit, offset)
+	}
+
+	match := buildMatchQuery(query)
+	if match == "" {
+		return []models.UserSearchResult{}, nil
+	}
+
+	searchQuery := `
+		SELECT ` + prefixedUserColumns("u") + `,
+			snippet(users_fts, -1, ?, ?, '…', 12),
+			bm25(users_fts, 0.0, 10.0, 2.0, 5.0, 3.0) AS score
+		FROM users_fts
+		JOIN users u ON u.id = users_fts.user_id
+		WHERE users_fts MATCH ?
+		  AND u.id != ?
+		  AND u.deletion_scheduled_for IS NULL
+		  AND u.suspended_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_blocks b
+			WHERE (b.blocker_user_id = ? AND b.blocked_user_id = u.id)
+			   OR (b.blocker_user_id = u.id AND b.blocked_user_id = ?)
+		  )
+		ORDER BY score
+		LIMIT ? OFFSET ?`
+
+	rows, err := s.DB.QueryContext(ctx, searchQuery,
+		snippetStart, snippetEnd, match, callerID, callerID, callerID, limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error searching users", "query", query, "error", err)
+		return nil, fmt.Errorf("searching users failed: %w", err)
+	}
+	// bm25 is lower-is-better and negative; flip it so higher means more relevant
+	return s.scanSearchResults(ctx, rows, -1, nil)
+}
+
+// searchUsersPostgres is SearchUsers on the tsvector index in users_search, ranking with
+// ts_rank over the same field weights as the SQLite index.
+func (s *DBService) searchUsersPostgres(ctx context.Context, callerID, query string, limit, offset int) ([]models.UserSearchResult, error) {
+	tsQuery := buildTSQuery(query)
+	if tsQuery == "" {
+		return []models.UserSearchResult{}, nil
+	}
+
+	searchQuery := `
+		SELECT ` + prefixedUserColumns("u") + `,
+			ts_headline('simple', concat_ws(' ', u.username, u.bio, u.repo_names), q, ?),
+			ts_rank(us.document, q) AS score
+		FROM users_search us
+		JOIN users u ON u.id = us.user_id
+		CROSS JOIN to_tsquery('simple', ?) q
+		WHERE us.document @@ q
+		  AND u.id != ?
+		  AND u.deletion_scheduled_for IS NULL
+		  AND u.suspended_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_blocks b
+			WHERE (b.blocker_user_id = ? AND b.blocked_user_id = u.id)
+			   OR (b.blocker_user_id = u.id AND b.blocked_user_id = ?)
+		  )
+		ORDER BY score DESC
+		LIMIT ? OFFSET ?`
+
+	headlineOptions := fmt.Sprintf("StartSel=%s, StopSel=%s, MaxWords=12, MinWords=4, ShortWord=1", snippetStart, snippetEnd)
+	rows, err := s.DB.QueryContext(ctx, searchQuery,
+		headlineOptions, tsQuery, callerID, callerID, callerID, limit, offset)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error searching users", "query", query, "error", err)
+		return nil, fmt.Errorf("searching users failed: %w", err)
+	}
+	return s.scanSearchResults(ctx, rows, 1, nil)
+}
+
+// searchUsersLike is SearchUsers for SQLite builds without FTS5 (see DB). Every word
+// must occur somewhere in the username, bio, repository names or interests, and
+// matches are scored with the same field weights as the full-text index. It scans
+// every profile, so it is only meant for development.
+func (s *DBService) searchUsersLike(ctx context.Context, callerID, query string, limit, offset int) ([]models.UserSearchResult, error) {
+	terms := searchTerms(query)
+	if len(terms) == 0 {
+		return []models.UserSearchResult{}, nil
+	}
+
+	var (
+		conditions []string
+		scores     []string
+		args       []any
+		scoreArgs  []any
+	)
+	for _, term := range terms {
+		pattern := "%" + term + "%" // Terms are letters and digits only, so nothing to escape
+		conditions = append(conditions, `(u.username LIKE ? OR u.bio LIKE ? OR u.repo_names LIKE ?
+			OR EXISTS (SELECT 1 FROM user_interests i WHERE i.user_id = u.id AND i.tag LIKE ?))`)
+		args = append(args, pattern, pattern, pattern, pattern)
+		scores = append(scores, `(CASE WHEN u.username LIKE ? THEN 10 ELSE 0 END
+			+ CASE WHEN u.bio LIKE ? THEN 2 ELSE 0 END
+			+ CASE WHEN u.repo_names LIKE ? THEN 3 ELSE 0 END
+			+ CASE WHEN EXISTS (SELECT 1 FROM user_interests i WHERE i.user_id = u.id AND i.tag LIKE ?) THEN 5 ELSE 0 END)`)
+		scoreArgs = append(scoreArgs, pattern, pattern, pattern, pattern)
+	}
+
+	searchQuery := `
+		SELECT ` + prefixedUserColumns("u") + `,
+			COALESCE(u.username, '') || ' ' || COALESCE(u.bio, '') || ' ' || COALESCE(u.repo_names, ''),
+			` + strings.Join(scores, " + ") + ` AS score
+		FROM users u
+		WHERE ` + strings.Join(conditions, " AND ") + `
+		  AND u.id != ?
+		  AND u.deletion_scheduled_for IS NULL
+		  AND u.suspended_at IS NULL
+		  AND NOT EXISTS (
+			SELECT 1 FROM user_blocks b
+			WHERE (b.blocker_user_id = ? AND b.blocked_user_id = u.id)
+			   OR (b.blocker_user_id = u.id AND b.blocked_user_id = ?)
+		  )
+		ORDER BY score DESC, u.username
+		LIMIT ? OFFSET ?`
+
+	queryArgs := append(scoreArgs, args...)
+	queryArgs = append(queryArgs, callerID, callerID, callerID, limit, offset)
+	rows, err := s.DB.QueryContext(ctx, searchQuery, queryArgs...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error searching users", "query", query, "error", err)
+		return nil, fmt.Errorf("searching users failed: %w", err)
+	}
+	return s.scanSearchResults(ctx, rows, 1, func(text string) string {
+		return likeSnippet(text, terms)
+	})
+}
+
+// likeSnippet is snippet() for searchUsersLike: up to snippetWords words of text around
+// the first one containing a term, with every such word between snippet markers.
+func likeSnippet(text string, terms []string) string {
+	words := strings.Fields(text)
+	first := -1
+	for i, word := range words {
+		lower := strings.ToLower(word)
+		for _, term := range terms {
+			if strings.Contains(lower, term) {
+				words[i] = snippetStart + word + snippetEnd
+				if first < 0 {
+					first = i
+				}
+				break
+			}
+		}
+	}
+
+	start := max(first-snippetWords/2, 0)
+	end := min(start+snippetWords, len(words))
+	start = max(end-snippetWords, 0)
+	snippet := strings.Join(words[start:end], " ")
+	if start > 0 {
+		snippet = "…" + snippet
+	}
+	if end < len(words) {
+		snippet += "…"
+	}
+	return snippet
+}
+
+// scanSearchResults reads rows of user columns followed by a snippet and a score, and
+// closes them. scoreSign orients the score so that a higher Rank is more relevant.
+// markSnippet, if set, adds the snippet markers the query could not.
+func (s *DBService) scanSearchResults(ctx context.Context, rows *sql.Rows, scoreSign float64, markSnippet func(string) string) ([]models.UserSearchResult, error) {
+	defer rows.Close()
+
+	results := []models.UserSearchResult{}
+	var users []models.User
+	for rows.Next() {
+		var (
+			user    models.User
+			snippet string
+			score   float64
+		)
+		dest := append(userScanDest(&user), &snippet, &score)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scanning search row failed: %w", err)
+		}
+		if markSnippet != nil {
+			snippet = markSnippet(snippet)
+		}
+		users = append(users, user)
+		results = append(results, models.UserSearchResult{
+			Snippet: highlightSnippet(snippet),
+			Rank:    scoreSign * score,
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating search rows failed: %w", err)
+	}
+	rows.Close()
+
+	if err := attachInterests(ctx, s.DB, users); err != nil {
+		return nil, err
+	}
+	for i := range results {
+		results[i].User = users[i]
+	}
+	return results, nil
+}
+
+// highlightSnippet HTML-escapes a search snippet and wraps matches in <mark> tags.
+func highlightSnippet(snippet string) string {
+	escaped := html.EscapeString(snippet)
+	escaped = strings.ReplaceAll(escaped, snippetStart, "<mark>")
+	return strings.ReplaceAll(escaped, snippetEnd, "</mark>")
+}
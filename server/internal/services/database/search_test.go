@@ -0,0 +1,150 @@
+package database
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"gin/internal/models"
+)
+
+func TestBuildMatchQuery(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"rust", `"rust"*`},
+		{"  go   wasm ", `"go"* "wasm"*`},
+		{`rust OR "c++"`, `"rust"* "OR"* "c++"*`},
+		{`" "" `, ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := buildMatchQuery(tt.input); got != tt.want {
+			t.Errorf("buildMatchQuery(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestBuildTSQuery(t *testing.T) {
+	tests := []struct {
+		input, want string
+	}{
+		{"Rust", "rust:*"},
+		{"go  wasm", "go:* & wasm:*"},
+		{"c++ & !rust | x:*", "c:* & rust:* & x:*"},
+		{"'); --", ""},
+	}
+	for _, tt := range tests {
+		if got := buildTSQuery(tt.input); got != tt.want {
+			t.Errorf("buildTSQuery(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestHighlightSnippet(t *testing.T) {
+	got := highlightSnippet("Writes " + snippetStart + "Rust" + snippetEnd + " & <b>C</b>")
+	if want := "Writes <mark>Rust</mark> &amp; &lt;b&gt;C&lt;/b&gt;"; got != want {
+		t.Errorf("highlightSnippet = %q, want %q", got, want)
+	}
+}
+
+func TestSearchUsers(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		caller := createUser(t, s, "caller", "Rust fan")
+		bioMatch := createUser(t, s, "ferris", "Writes Rust compilers", "embedded")
+		nameMatch := createUser(t, s, "rustlang", "Systems programming")
+		interestMatch := createUser(t, s, "kernelhacker", "Low level things", "rust")
+		repoMatch := createUser(t, s, "octocat", "Hello world")
+		if err := s.UpdateGitHubStats(ctx, repoMatch.ID, models.GitHubStats{RepoNames: []string{"rust-playground"}}); err != nil {
+			t.Fatal(err)
+		}
+
+		results, err := s.SearchUsers(ctx, caller.ID, "rust", 20, 0)
+		if err != nil {
+			t.Fatalf("SearchUsers: %v", err)
+		}
+		found := make(map[string]models.UserSearchResult)
+		for _, r := range results {
+			found[r.User.ID] = r
+		}
+		for _, want := range []*models.User{bioMatch, nameMatch, interestMatch, repoMatch} {
+			if _, ok := found[want.ID]; !ok {
+				t.Errorf("%s missing from results", *want.Username)
+			}
+		}
+		if _, ok := found[caller.ID]; ok {
+			t.Error("results include the caller")
+		}
+		if len(results) > 0 && results[0].User.ID != nameMatch.ID {
+			t.Errorf("top result = %s, want the username match first", *results[0].User.Username)
+		}
+		if !strings.Contains(found[bioMatch.ID].Snippet, "<mark>Rust</mark>") {
+			t.Errorf("snippet = %q, want the match highlighted", found[bioMatch.ID].Snippet)
+		}
+		if got := found[interestMatch.ID].User.Interests; len(got) != 1 || got[0] != "rust" {
+			t.Errorf("interests of kernelhacker = %v, want them attached", got)
+		}
+		for i := 1; i < len(results); i++ {
+			if results[i].Rank > results[i-1].Rank {
+				t.Errorf("results not ordered by rank: %v before %v", results[i-1].Rank, results[i].Rank)
+			}
+		}
+
+		// Pagination
+		page, err := s.SearchUsers(ctx, caller.ID, "rust", 2, 2)
+		if err != nil {
+			t.Fatalf("SearchUsers: %v", err)
+		}
+		if len(page) != 2 || page[0].User.ID != results[2].User.ID {
+			t.Errorf("second page has %d results, want results 3 and 4", len(page))
+		}
+
+		// Every word must match, as a prefix
+		results, err = s.SearchUsers(ctx, caller.ID, "writ compil", 20, 0)
+		if err != nil {
+			t.Fatalf("SearchUsers: %v", err)
+		}
+		if len(results) != 1 || results[0].User.ID != bioMatch.ID {
+			t.Errorf("prefix search returned %d results, want only ferris", len(results))
+		}
+
+		// Query syntax is inert
+		for _, query := range []string{`"`, `*`, `rust OR`, `'); DROP TABLE users; --`, `<b>`} {
+			if _, err := s.SearchUsers(ctx, caller.ID, query, 20, 0); err != nil {
+				t.Errorf("SearchUsers(%q): %v", query, err)
+			}
+		}
+	})
+}
+
+func TestSearchUsersLike(t *testing.T) {
+	s := newTestService(t)
+	s.DB.likeSearch = true // As on builds without FTS5
+	ctx := context.Background()
+	caller := createUser(t, s, "caller", "")
+	named := createUser(t, s, "ferris", "Writes Rust compilers")
+	bio := "Rust on microcontrollers"
+	unnamed, err := s.CreateOrUpdateUserProfile(ctx, models.User{ClerkUserID: "clerk_unnamed", Bio: &bio})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	results, err := s.SearchUsers(ctx, caller.ID, "rust", 20, 0)
+	if err != nil {
+		t.Fatalf("SearchUsers: %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("SearchUsers returned %d results, want ferris and the user without a username", len(results))
+	}
+	found := make(map[string]models.UserSearchResult)
+	for _, r := range results {
+		found[r.User.ID] = r
+	}
+	if _, ok := found[named.ID]; !ok {
+		t.Error("ferris missing from results")
+	}
+	if got := found[unnamed.ID].Snippet; !strings.Contains(got, "<mark>Rust</mark>") {
+		t.Errorf("snippet of the user without a username = %q, want the match highlighted", got)
+	}
+}
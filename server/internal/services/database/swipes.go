@@ -0,0 +1,388 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin/internal/models"
+)
+
+var (
+	// ErrAlreadySwiped is returned when a user swipes on someone they have already swiped on.
+	ErrAlreadySwiped = errors.New("swipe already recorded for this user")
+	// ErrNoSwipeToUndo is returned when a user with no swipes asks to undo one.
+	ErrNoSwipeToUndo = errors.New("no swipe to undo")
+	// ErrUndoWindowExpired is returned when the most recent swipe is too old to undo.
+	ErrUndoWindowExpired = errors.New("undo window has expired")
+	// ErrSuperLikeQuotaExceeded is returned when a user has used up today's super likes.
+	ErrSuperLikeQuotaExceeded = errors.New("daily super like quota exceeded")
+)
+
+// swipeColumns lists the swipes columns in the order expected by swipeScanDest.
+const swipeColumns = `id, swiper_user_id, swiped_user_id, direction, intro_note, created_at`
+
+// swipeScanDest returns scan destinations for swipeColumns.
+func swipeScanDest(swipe *models.Swipe) []any {
+	return []any{&swipe.ID, &swipe.SwiperID, &swipe.SwipedID, &swipe.Direction, &swipe.IntroNote, &swipe.CreatedAt}
+}
+
+// --- Swipe Operations ---
+
+// CreateSwipe records swipe, using its SwiperID, SwipedID, Direction and IntroNote.
+// If the swipe is a like or super like and the other user has already liked the swiper
+// back, a conversation is opened for the match inside the same transaction. A super like
+// consumes one of the swiper's superLikeDailyLimit daily super likes and notifies the
+// recipient immediately.
+// Returns ErrAlreadySwiped if the pair was already swiped, ErrBlocked if either user has
+// blocked the other and ErrSuperLikeQuotaExceeded if no super likes are left today.
+func (s *DBService) CreateSwipe(ctx context.Context, swipe models.Swipe, superLikeDailyLimit int) (*models.SwipeResult, error) {
+	swiperUserID, swipedUserID := swipe.SwiperID, swipe.SwipedID
+
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error starting swipe transaction", "swiper_id", swiperUserID, "swiped_id", swipedUserID, "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var blocked bool
+	if err := tx.QueryRowContext(ctx, `SELECT `+blockedEither("?"), swiperUserID, swipedUserID, swipedUserID, swiperUserID).Scan(&blocked); err != nil {
+		logger.ErrorContext(ctx, "Error checking blocks between users", "swiper_id", swiperUserID, "swiped_id", swipedUserID, "error", err)
+		return nil, fmt.Errorf("failed to check blocks: %w", err)
+	}
+	if blocked {
+		return nil, ErrBlocked
+	}
+
+	var result models.SwipeResult
+	if swipe.Direction == models.SwipeSuperLike {
+		remaining, err := consumeSuperLike(ctx, tx, swiperUserID, superLikeDailyLimit)
+		if err != nil {
+			return nil, err
+		}
+		result.SuperLikesRemaining = &remaining
+	}
+
+	insertQuery := `
+		INSERT INTO swipes (swiper_user_id, swiped_user_id, direction, intro_note)
+		VALUES (?, ?, ?, ?)
+		RETURNING ` + swipeColumns
+
+	err = tx.QueryRowContext(ctx, insertQuery, swiperUserID, swipedUserID, swipe.Direction, swipe.IntroNote).Scan(swipeScanDest(&result.Swipe)...)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return nil, ErrAlreadySwiped
+		}
+		logger.ErrorContext(ctx, "Error inserting swipe", "swiper_id", swiperUserID, "swiped_id", swipedUserID, "error", err)
+		return nil, fmt.Errorf("failed to insert swipe: %w", err)
+	}
+
+	if swipe.Direction == models.SwipeSuperLike {
+		err = createNotification(ctx, tx, models.Notification{
+			UserID:      swipedUserID,
+			Type:        models.NotificationSuperLike,
+			ActorUserID: &swiperUserID,
+			SwipeID:     &result.Swipe.ID,
+			Message:     swipe.IntroNote,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if _, err = tx.ExecContext(ctx, `UPDATE users SET last_active_at = CURRENT_TIMESTAMP WHERE id = ?`, swiperUserID); err != nil {
+		logger.ErrorContext(ctx, "Error updating last activity", "user_id", swiperUserID, "error", err)
+		return nil, fmt.Errorf("failed to update last activity: %w", err)
+	}
+
+	if swipe.Direction.IsLike() {
+		var exists int
+		reciprocalQuery := `
+			SELECT 1 FROM swipes
+			WHERE swiper_user_id = ? AND swiped_user_id = ? AND direction IN (?, ?)`
+		err = tx.QueryRowContext(ctx, reciprocalQuery, swipedUserID, swiperUserID, models.SwipeLike, models.SwipeSuperLike).Scan(&exists)
+		switch {
+		case err == nil:
+			// A conversation kept by an undo (because it had messages) is picked up again
+			conversationID, err := findConversationBetween(ctx, tx, swiperUserID, swipedUserID)
+			if err != nil {
+				logger.ErrorContext(ctx, "Error finding match conversation", "swiper_id", swiperUserID, "swiped_id", swipedUserID, "error", err)
+				return nil, err
+			}
+			if conversationID == "" {
+				if conversationID, err = createConversation(ctx, tx, swiperUserID, swipedUserID); err != nil {
+					return nil, err
+				}
+			}
+			result.Matched = true
+			result.ConversationID = &conversationID
+		case errors.Is(err, sql.ErrNoRows):
+			// No match yet
+		default:
+			logger.ErrorContext(ctx, "Error checking reciprocal swipe", "swiper_id", swiperUserID, "swiped_id", swipedUserID, "error", err)
+			return nil, fmt.Errorf("failed to check for match: %w", err)
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.ErrorContext(ctx, "Error committing swipe transaction", "swiper_id", swiperUserID, "swiped_id", swipedUserID, "error", err)
+		return nil, fmt.Errorf("failed to commit swipe: %w", err)
+	}
+	return &result, nil
+}
+
+// GetSwipeCandidates returns up to limit users that userID has not swiped on yet and that
+// match filters, excluding userID itself. The order is not meaningful; callers rank the result.
+func (s *DBService) GetSwipeCandidates(ctx context.Context, userID string, filters models.CardFilters, limit int) ([]models.User, error) {
+	conditions := []string{
+		"u.id != ?",
+		"NOT EXISTS (SELECT 1 FROM swipes s WHERE s.swiper_user_id = ? AND s.swiped_user_id = u.id)",
+		"u.deletion_scheduled_for IS NULL", // Accounts on their way out are not shown to anyone new
+		"u.suspended_at IS NULL",
+		"NOT " + blockedEither("u.id"), // Blocks hide both users from each other
+	}
+	args := []any{userID, userID, userID, userID}
+
+	if filters.PrimaryLanguage != "" {
+		// primary_language is declared COLLATE NOCASE, so this stays index-friendly
+		conditions = append(conditions, "u.primary_language = ?")
+		args = append(args, filters.PrimaryLanguage)
+	}
+	if tags := models.NormalizeInterestTags(filters.Interests); len(tags) > 0 {
+		placeholders := make([]string, len(tags))
+		for i, tag := range tags {
+			placeholders[i] = "?"
+			args = append(args, tag)
+		}
+		conditions = append(conditions,
+			"EXISTS (SELECT 1 FROM user_interests ui WHERE ui.user_id = u.id AND ui.tag IN ("+strings.Join(placeholders, ", ")+"))")
+	}
+	if filters.TimezoneMinMinutes != nil {
+		conditions = append(conditions, "u.timezone_offset_minutes >= ?")
+		args = append(args, *filters.TimezoneMinMinutes)
+	}
+	if filters.TimezoneMaxMinutes != nil {
+		conditions = append(conditions, "u.timezone_offset_minutes <= ?")
+		args = append(args, *filters.TimezoneMaxMinutes)
+	}
+	if filters.MinFollowers > 0 {
+		conditions = append(conditions, "u.github_followers >= ?")
+		args = append(args, filters.MinFollowers)
+	}
+	if filters.MinPublicRepos > 0 {
+		conditions = append(conditions, "u.github_public_repos >= ?")
+		args = append(args, filters.MinPublicRepos)
+	}
+	if filters.ActiveWithinDays > 0 {
+		conditions = append(conditions, "u.last_active_at >= ?")
+		args = append(args, time.Now().UTC().AddDate(0, 0, -filters.ActiveWithinDays))
+	}
+	args = append(args, limit)
+
+	query := `
+		SELECT ` + userColumns + `
+		FROM users u
+		WHERE ` + strings.Join(conditions, "\n\t\t  AND ") + `
+		ORDER BY u.created_at DESC
+		LIMIT ?`
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying swipe candidates", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("querying swipe candidates failed: %w", err)
+	}
+	defer rows.Close()
+
+	var users []models.User
+	for rows.Next() {
+		user, err := scanUser(rows)
+		if err != nil {
+			return nil, fmt.Errorf("scanning candidate row failed: %w", err)
+		}
+		users = append(users, *user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating candidate rows failed: %w", err)
+	}
+	rows.Close()
+
+	if err := attachInterests(ctx, s.DB, users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// GetLikes returns every like and super like recorded in the swipes table, oldest first.
+// It is used as the implicit-feedback signal for recommendations.
+func (s *DBService) GetLikes(ctx context.Context) ([]models.Swipe, error) {
+	query := `
+		SELECT ` + swipeColumns + `
+		FROM swipes
+		WHERE direction IN (?, ?)
+		ORDER BY created_at`
+
+	rows, err := s.DB.QueryContext(ctx, query, models.SwipeLike, models.SwipeSuperLike)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying likes", "error", err)
+		return nil, fmt.Errorf("querying likes failed: %w", err)
+	}
+	defer rows.Close()
+
+	var likes []models.Swipe
+	for rows.Next() {
+		var swipe models.Swipe
+		if err := rows.Scan(swipeScanDest(&swipe)...); err != nil {
+			return nil, fmt.Errorf("scanning like row failed: %w", err)
+		}
+		likes = append(likes, swipe)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating like rows failed: %w", err)
+	}
+	return likes, nil
+}
+
+// UndoLastSwipe deletes the most recent swipe made by userID if it is younger than window.
+// If that swipe completed a match, the match's conversation is deleted as well unless
+// messages have already been exchanged in it. Undoing a super like removes its notification
+// but does not refund the daily quota, so super likes cannot be replayed.
+// Returns ErrNoSwipeToUndo or ErrUndoWindowExpired when nothing can be undone.
+func (s *DBService) UndoLastSwipe(ctx context.Context, userID string, window time.Duration) (*models.UndoSwipeResult, error) {
+	tx, err := s.DB.BeginTx(ctx, nil)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error starting undo transaction", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	lastQuery := `
+		SELECT ` + swipeColumns + `
+		FROM swipes
+		WHERE swiper_user_id = ?
+		ORDER BY created_at DESC` + s.DB.Dialect.insertionTiebreak("") + `
+		LIMIT 1`
+
+	var result models.UndoSwipeResult
+	swipe := &result.Swipe
+	err = tx.QueryRowContext(ctx, lastQuery, userID).Scan(swipeScanDest(swipe)...)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNoSwipeToUndo
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error loading last swipe", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to load last swipe: %w", err)
+	}
+	if time.Since(swipe.CreatedAt) > window {
+		return nil, ErrUndoWindowExpired
+	}
+
+	if _, err = tx.ExecContext(ctx, `DELETE FROM swipes WHERE id = ?`, swipe.ID); err != nil {
+		logger.ErrorContext(ctx, "Error deleting swipe", "swipe_id", swipe.ID, "error", err)
+		return nil, fmt.Errorf("failed to delete swipe: %w", err)
+	}
+
+	if swipe.Direction.IsLike() {
+		conversationID, err := findConversationBetween(ctx, tx, swipe.SwiperID, swipe.SwipedID)
+		if err != nil {
+			logger.ErrorContext(ctx, "Error finding match conversation", "swipe_id", swipe.ID, "error", err)
+			return nil, err
+		}
+		if conversationID != "" {
+			result.ConversationID = &conversationID
+			result.MatchDissolved, err = deleteConversationIfEmpty(ctx, tx, conversationID)
+			if err != nil {
+				logger.ErrorContext(ctx, "Error dissolving match conversation", "conversation_id", conversationID, "error", err)
+				return nil, err
+			}
+		}
+	}
+
+	if err = tx.Commit(); err != nil {
+		logger.ErrorContext(ctx, "Error committing undo", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("failed to commit undo: %w", err)
+	}
+	return &result, nil
+}
+
+// GetSwipeHistory returns the swipes made by userID, newest first, together with the
+// profiles they swiped on. An empty direction returns swipes in every direction.
+func (s *DBService) GetSwipeHistory(ctx context.Context, userID string, direction models.SwipeDirection, limit, offset int) ([]models.SwipeHistoryEntry, error) {
+	conditions := "s.swiper_user_id = ?"
+	args := []any{userID}
+	if direction != "" {
+		conditions += " AND s.direction = ?"
+		args = append(args, direction)
+	}
+	args = append(args, limit, offset)
+
+	query := `
+		SELECT s.id, s.swiper_user_id, s.swiped_user_id, s.direction, s.intro_note, s.created_at, ` + prefixedUserColumns("u") + `
+		FROM swipes s
+		JOIN users u ON u.id = s.swiped_user_id
+		WHERE ` + conditions + `
+		ORDER BY s.created_at DESC` + s.DB.Dialect.insertionTiebreak("s") + `
+		LIMIT ? OFFSET ?`
+
+	rows, err := s.DB.QueryContext(ctx, query, args...)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error querying swipe history", "user_id", userID, "error", err)
+		return nil, fmt.Errorf("querying swipe history failed: %w", err)
+	}
+	defer rows.Close()
+
+	entries := []models.SwipeHistoryEntry{}
+	var users []models.User
+	for rows.Next() {
+		var entry models.SwipeHistoryEntry
+		var user models.User
+		dest := append(swipeScanDest(&entry.Swipe), userScanDest(&user)...)
+		if err := rows.Scan(dest...); err != nil {
+			return nil, fmt.Errorf("scanning swipe history row failed: %w", err)
+		}
+		entries = append(entries, entry)
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("iterating swipe history rows failed: %w", err)
+	}
+	rows.Close()
+
+	if err := attachInterests(ctx, s.DB, users); err != nil {
+		return nil, err
+	}
+	for i := range entries {
+		entries[i].SwipedUser = users[i]
+	}
+	return entries, nil
+}
+
+// consumeSuperLike uses one of userID's super likes for the current UTC day within tx and
+// returns how many are left. Returns ErrSuperLikeQuotaExceeded if none are left.
+func consumeSuperLike(ctx context.Context, tx *Tx, userID string, dailyLimit int) (int, error) {
+	if dailyLimit <= 0 {
+		return 0, ErrSuperLikeQuotaExceeded
+	}
+	day := time.Now().UTC().Format("2006-01-02")
+
+	query := `
+		INSERT INTO swipe_quotas (user_id, day, superlikes_used) VALUES (?, ?, 1)
+		ON CONFLICT (user_id, day) DO UPDATE SET superlikes_used = swipe_quotas.superlikes_used + 1
+		WHERE swipe_quotas.superlikes_used < ?
+		RETURNING superlikes_used`
+
+	var used int
+	err := tx.QueryRowContext(ctx, query, userID, day, dailyLimit).Scan(&used)
+	if errors.Is(err, sql.ErrNoRows) {
+		// The conflict update was skipped by its WHERE clause: the quota is used up
+		return 0, ErrSuperLikeQuotaExceeded
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error consuming super like", "user_id", userID, "error", err)
+		return 0, fmt.Errorf("failed to update super like quota: %w", err)
+	}
+	return dailyLimit - used, nil
+}
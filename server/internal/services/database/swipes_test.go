@@ -0,0 +1,306 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+	"time"
+
+	"gin/internal/models"
+)
+
+func TestSwipeCandidateFilters(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		viewer := createUser(t, s, "viewer", "")
+		gopher := createUser(t, s, "gopher", "", "Backend")
+		rustacean := createUser(t, s, "rustacean", "", "systems")
+		swiped := createUser(t, s, "swiped", "")
+
+		goLang, rust := "Go", "Rust"
+		if err := s.UpdateGitHubStats(ctx, gopher.ID, models.GitHubStats{Followers: 500, PublicRepos: 40, PrimaryLanguage: &goLang}); err != nil {
+			t.Fatal(err)
+		}
+		if err := s.UpdateGitHubStats(ctx, rustacean.ID, models.GitHubStats{Followers: 5, PublicRepos: 2, PrimaryLanguage: &rust}); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: viewer.ID, SwipedID: swiped.ID, Direction: models.SwipeDislike}, 0); err != nil {
+			t.Fatal(err)
+		}
+		// gopher lives in UTC-5 and has not been seen for a month
+		if _, err := s.DB.ExecContext(ctx, `UPDATE users SET timezone_offset_minutes = -300, last_active_at = ? WHERE id = ?`,
+			time.Now().UTC().AddDate(0, 0, -30), gopher.ID); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := s.DB.ExecContext(ctx, `UPDATE users SET timezone_offset_minutes = 60 WHERE id = ?`, rustacean.ID); err != nil {
+			t.Fatal(err)
+		}
+
+		minusSix, zero, two := -360, 0, 120
+		tests := []struct {
+			name    string
+			filters models.CardFilters
+			want    []string
+		}{
+			{"no filters skips swiped users", models.CardFilters{}, []string{gopher.ID, rustacean.ID}},
+			{"language ignores case", models.CardFilters{PrimaryLanguage: "go"}, []string{gopher.ID}},
+			{"min followers", models.CardFilters{MinFollowers: 100}, []string{gopher.ID}},
+			{"min repos", models.CardFilters{MinPublicRepos: 50}, nil},
+			{"any interest matches", models.CardFilters{Interests: []string{"Systems", "frontend"}}, []string{rustacean.ID}},
+			{"timezone range", models.CardFilters{TimezoneMinMinutes: &zero, TimezoneMaxMinutes: &two}, []string{rustacean.ID}},
+			{"timezone lower bound", models.CardFilters{TimezoneMinMinutes: &minusSix}, []string{gopher.ID, rustacean.ID}},
+			{"recently active", models.CardFilters{ActiveWithinDays: 7}, []string{rustacean.ID}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				candidates, err := s.GetSwipeCandidates(ctx, viewer.ID, tt.filters, 10)
+				if err != nil {
+					t.Fatalf("GetSwipeCandidates: %v", err)
+				}
+				got := make(map[string]bool)
+				for _, c := range candidates {
+					got[c.ID] = true
+				}
+				if len(got) != len(tt.want) {
+					t.Fatalf("got %d candidates, want %d", len(got), len(tt.want))
+				}
+				for _, id := range tt.want {
+					if !got[id] {
+						t.Errorf("candidate %s missing", id)
+					}
+				}
+			})
+		}
+	})
+}
+
+// countConversations returns how many conversations userID takes part in.
+func countConversations(t *testing.T, s *DBService, userID string) int {
+	t.Helper()
+	var n int
+	err := s.DB.QueryRowContext(context.Background(), `SELECT COUNT(*) FROM conversation_participants WHERE user_id = ?`, userID).Scan(&n)
+	if err != nil {
+		t.Fatalf("counting conversations: %v", err)
+	}
+	return n
+}
+
+func TestSwipeMatchAndUndo(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		alice := createUser(t, s, "alice", "")
+		bob := createUser(t, s, "bob", "")
+
+		first, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: alice.ID, SwipedID: bob.ID, Direction: models.SwipeLike}, 0)
+		if err != nil {
+			t.Fatalf("first swipe: %v", err)
+		}
+		if first.Matched || first.Swipe.ID == "" {
+			t.Fatalf("first swipe = %+v, want a stored swipe without a match", first)
+		}
+		if _, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: alice.ID, SwipedID: bob.ID, Direction: models.SwipeLike}, 0); !errors.Is(err, ErrAlreadySwiped) {
+			t.Errorf("repeated swipe error = %v, want ErrAlreadySwiped", err)
+		}
+
+		match, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: bob.ID, SwipedID: alice.ID, Direction: models.SwipeLike}, 0)
+		if err != nil {
+			t.Fatalf("reciprocal swipe: %v", err)
+		}
+		if !match.Matched || match.ConversationID == nil {
+			t.Fatalf("reciprocal swipe = %+v, want a match with a conversation", match)
+		}
+
+		// Undoing a match nobody has written in yet dissolves it
+		undone, err := s.UndoLastSwipe(ctx, bob.ID, time.Minute)
+		if err != nil {
+			t.Fatalf("UndoLastSwipe: %v", err)
+		}
+		if !undone.MatchDissolved || undone.Swipe.ID != match.Swipe.ID {
+			t.Errorf("undo = %+v, want the reciprocal swipe removed and the match dissolved", undone)
+		}
+		if n := countConversations(t, s, alice.ID); n != 0 {
+			t.Errorf("alice has %d conversations after undo, want none", n)
+		}
+
+		// Once a message is sent the conversation survives the undo
+		match, err = s.CreateSwipe(ctx, models.Swipe{SwiperID: bob.ID, SwipedID: alice.ID, Direction: models.SwipeLike}, 0)
+		if err != nil || !match.Matched {
+			t.Fatalf("swiping again = %+v (err %v), want a match", match, err)
+		}
+		_, err = s.DB.ExecContext(ctx, `INSERT INTO messages (conversation_id, sender_user_id, content) VALUES (?, ?, 'hi')`, *match.ConversationID, alice.ID)
+		if err != nil {
+			t.Fatalf("inserting message: %v", err)
+		}
+		undone, err = s.UndoLastSwipe(ctx, bob.ID, time.Minute)
+		if err != nil {
+			t.Fatalf("UndoLastSwipe: %v", err)
+		}
+		if undone.MatchDissolved || undone.ConversationID == nil || *undone.ConversationID != *match.ConversationID {
+			t.Errorf("undo = %+v, want the conversation with messages kept", undone)
+		}
+		if n := countConversations(t, s, alice.ID); n != 1 {
+			t.Errorf("alice has %d conversations after undo, want 1", n)
+		}
+
+		// Liking again picks the kept conversation back up instead of opening a second one
+		rematch, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: bob.ID, SwipedID: alice.ID, Direction: models.SwipeLike}, 0)
+		if err != nil || !rematch.Matched || rematch.ConversationID == nil {
+			t.Fatalf("liking again = %+v (err %v), want a match", rematch, err)
+		}
+		if *rematch.ConversationID != *match.ConversationID {
+			t.Errorf("rematch conversation = %s, want the kept %s", *rematch.ConversationID, *match.ConversationID)
+		}
+		if n := countConversations(t, s, alice.ID); n != 1 {
+			t.Errorf("alice has %d conversations after liking again, want 1", n)
+		}
+		if _, err := s.UndoLastSwipe(ctx, bob.ID, time.Minute); err != nil {
+			t.Fatalf("UndoLastSwipe: %v", err)
+		}
+
+		if _, err := s.UndoLastSwipe(ctx, bob.ID, time.Minute); !errors.Is(err, ErrNoSwipeToUndo) {
+			t.Errorf("undo without swipes error = %v, want ErrNoSwipeToUndo", err)
+		}
+		if _, err := s.UndoLastSwipe(ctx, alice.ID, -time.Second); !errors.Is(err, ErrUndoWindowExpired) {
+			t.Errorf("undo after the window error = %v, want ErrUndoWindowExpired", err)
+		}
+	})
+}
+
+func TestGetSwipeHistory(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		viewer := createUser(t, s, "viewer", "")
+		var swiped []*models.User
+		for i, name := range []string{"first", "second", "third"} {
+			user := createUser(t, s, name, "", "tag"+name)
+			direction := models.SwipeLike
+			if i == 1 {
+				direction = models.SwipeDislike
+			}
+			if _, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: viewer.ID, SwipedID: user.ID, Direction: direction}, 0); err != nil {
+				t.Fatal(err)
+			}
+			swiped = append(swiped, user)
+		}
+
+		tests := []struct {
+			name          string
+			direction     models.SwipeDirection
+			limit, offset int
+			want          []*models.User
+		}{
+			{"newest first", "", 10, 0, []*models.User{swiped[2], swiped[1], swiped[0]}},
+			{"likes only", models.SwipeLike, 10, 0, []*models.User{swiped[2], swiped[0]}},
+			{"dislikes only", models.SwipeDislike, 10, 0, []*models.User{swiped[1]}},
+			{"paginated", "", 1, 1, []*models.User{swiped[1]}},
+		}
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				entries, err := s.GetSwipeHistory(ctx, viewer.ID, tt.direction, tt.limit, tt.offset)
+				if err != nil {
+					t.Fatalf("GetSwipeHistory: %v", err)
+				}
+				if len(entries) != len(tt.want) {
+					t.Fatalf("got %d entries, want %d", len(entries), len(tt.want))
+				}
+				for i, entry := range entries {
+					if entry.SwipedUser.ID != tt.want[i].ID || entry.Swipe.SwipedID != tt.want[i].ID {
+						t.Errorf("entry %d is %s, want %s", i, *entry.SwipedUser.Username, *tt.want[i].Username)
+					}
+					if len(entry.SwipedUser.Interests) != 1 {
+						t.Errorf("entry %d has interests %v, want them attached", i, entry.SwipedUser.Interests)
+					}
+				}
+			})
+		}
+	})
+}
+
+func TestSuperLikeQuota(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		sender := createUser(t, s, "sender", "")
+		note := "Loved your compiler talk"
+		var first *models.User
+		for i, name := range []string{"first", "second"} {
+			target := createUser(t, s, name, "")
+			if first == nil {
+				first = target
+			}
+			result, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: sender.ID, SwipedID: target.ID, Direction: models.SwipeSuperLike, IntroNote: &note}, 2)
+			if err != nil {
+				t.Fatalf("super like %d: %v", i+1, err)
+			}
+			if result.SuperLikesRemaining == nil || *result.SuperLikesRemaining != 1-i {
+				t.Errorf("super like %d left %v remaining, want %d", i+1, result.SuperLikesRemaining, 1-i)
+			}
+			if result.Swipe.IntroNote == nil || *result.Swipe.IntroNote != note {
+				t.Errorf("super like %d has note %v, want %q", i+1, result.Swipe.IntroNote, note)
+			}
+
+			notifications, err := s.GetNotifications(ctx, target.ID, true, 10, 0)
+			if err != nil {
+				t.Fatalf("GetNotifications: %v", err)
+			}
+			if len(notifications) != 1 || notifications[0].Type != models.NotificationSuperLike ||
+				notifications[0].Message == nil || *notifications[0].Message != note ||
+				notifications[0].ActorUserID == nil || *notifications[0].ActorUserID != sender.ID {
+				t.Errorf("notifications of %s = %+v, want the intro note from sender", name, notifications)
+			}
+		}
+
+		third := createUser(t, s, "third", "")
+		_, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: sender.ID, SwipedID: third.ID, Direction: models.SwipeSuperLike}, 2)
+		if !errors.Is(err, ErrSuperLikeQuotaExceeded) {
+			t.Errorf("super like over quota error = %v, want ErrSuperLikeQuotaExceeded", err)
+		}
+		// A refused super like leaves no swipe behind
+		if _, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: sender.ID, SwipedID: third.ID, Direction: models.SwipeLike}, 2); err != nil {
+			t.Errorf("like after refused super like: %v", err)
+		}
+
+		// A super like is a like: liking back makes a match, and both count for recommendations
+		match, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: first.ID, SwipedID: sender.ID, Direction: models.SwipeLike}, 2)
+		if err != nil || !match.Matched {
+			t.Errorf("liking back a super like = %+v (err %v), want a match", match, err)
+		}
+		likes, err := s.GetLikes(ctx)
+		if err != nil {
+			t.Fatalf("GetLikes: %v", err)
+		}
+		if len(likes) != 4 {
+			t.Errorf("GetLikes returned %d swipes, want 4", len(likes))
+		}
+	})
+}
+
+func TestMarkNotificationRead(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+		sender := createUser(t, s, "sender", "")
+		recipient := createUser(t, s, "recipient", "")
+		if _, err := s.CreateSwipe(ctx, models.Swipe{SwiperID: sender.ID, SwipedID: recipient.ID, Direction: models.SwipeSuperLike}, 1); err != nil {
+			t.Fatal(err)
+		}
+		notifications, err := s.GetNotifications(ctx, recipient.ID, false, 10, 0)
+		if err != nil || len(notifications) != 1 {
+			t.Fatalf("GetNotifications = %v (err %v), want one", notifications, err)
+		}
+		id := notifications[0].ID
+
+		if err := s.MarkNotificationRead(ctx, sender.ID, id); !errors.Is(err, sql.ErrNoRows) {
+			t.Errorf("marking someone else's notification error = %v, want sql.ErrNoRows", err)
+		}
+		if err := s.MarkNotificationRead(ctx, recipient.ID, id); err != nil {
+			t.Fatalf("MarkNotificationRead: %v", err)
+		}
+		if unread, err := s.GetNotifications(ctx, recipient.ID, true, 10, 0); err != nil || len(unread) != 0 {
+			t.Errorf("unread notifications = %v (err %v), want none", unread, err)
+		}
+		all, err := s.GetNotifications(ctx, recipient.ID, false, 10, 0)
+		if err != nil || len(all) != 1 || all[0].ReadAt == nil {
+			t.Errorf("all notifications = %+v (err %v), want one marked read", all, err)
+		}
+	})
+}
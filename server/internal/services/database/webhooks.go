@@ -0,0 +1,40 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+)
+
+// --- Webhook Event Operations ---
+
+// IsWebhookEventProcessed reports whether the webhook message eventID from source has
+// already been processed.
+func (s *DBService) IsWebhookEventProcessed(ctx context.Context, source, eventID string) (bool, error) {
+	var exists int
+	err := s.DB.QueryRowContext(ctx,
+		`SELECT 1 FROM webhook_events WHERE source = ? AND id = ?`, source, eventID,
+	).Scan(&exists)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error checking webhook event", "source", source, "event_id", eventID, "error", err)
+		return false, fmt.Errorf("checking webhook event failed: %w", err)
+	}
+	return true, nil
+}
+
+// RecordWebhookEvent marks the webhook message eventID from source as processed.
+// Recording an event twice is not an error.
+func (s *DBService) RecordWebhookEvent(ctx context.Context, source, eventID, eventType string) error {
+	query := `
+		INSERT INTO webhook_events (id, source, type) VALUES (?, ?, ?)
+		ON CONFLICT (source, id) DO NOTHING`
+	if _, err := s.DB.ExecContext(ctx, query, eventID, source, eventType); err != nil {
+		logger.ErrorContext(ctx, "Error recording webhook event", "source", source, "event_id", eventID, "error", err)
+		return fmt.Errorf("recording webhook event failed: %w", err)
+	}
+	return nil
+}
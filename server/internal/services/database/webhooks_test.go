@@ -0,0 +1,29 @@
+package database
+
+import (
+	"context"
+	"testing"
+)
+
+func TestWebhookEvents(t *testing.T) {
+	forEachDialect(t, func(t *testing.T, s *DBService) {
+		ctx := context.Background()
+
+		if processed, err := s.IsWebhookEventProcessed(ctx, "clerk", "msg_1"); err != nil || processed {
+			t.Fatalf("IsWebhookEventProcessed before recording = %v, %v; want false", processed, err)
+		}
+		// Recording a redelivery again is not an error
+		for i := 0; i < 2; i++ {
+			if err := s.RecordWebhookEvent(ctx, "clerk", "msg_1", "user.created"); err != nil {
+				t.Fatalf("RecordWebhookEvent: %v", err)
+			}
+		}
+		if processed, err := s.IsWebhookEventProcessed(ctx, "clerk", "msg_1"); err != nil || !processed {
+			t.Errorf("IsWebhookEventProcessed after recording = %v, %v; want true", processed, err)
+		}
+		// Event IDs are scoped by source
+		if processed, err := s.IsWebhookEventProcessed(ctx, "github", "msg_1"); err != nil || processed {
+			t.Errorf("IsWebhookEventProcessed for another source = %v, %v; want false", processed, err)
+		}
+	})
+}
@@ -0,0 +1,255 @@
+// Package export builds personal data export archives: a ZIP of JSON files holding
+// everything DevMatch stores about a user. Small exports are streamed straight to the
+// client; large ones are generated in the background and downloaded later through an
+// expiring link.
+package export
+
+import (
+	"archive/zip"
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gin/internal/logging"
+	"gin/internal/models"
+	"gin/internal/services/database"
+)
+
+var logger = logging.For("export")
+
+// jobTimeout bounds how long a background export may take.
+const jobTimeout = 10 * time.Minute
+
+// archiveNotes explains the archive to the person reading it, and records what is
+// deliberately not in it.
+var archiveNotes = []string{
+	"Each JSON file holds one kind of data DevMatch stores about you; timestamps are UTC.",
+	"messages_sent.json only contains messages you wrote. Messages written by others belong to them.",
+	"swipes.json contains the swipes you made, not the swipes others made on you.",
+	"Favorites are not stored by DevMatch yet, so there are none to export.",
+}
+
+// manifest is written to manifest.json at the root of every archive.
+type manifest struct {
+	UserID      string    `json:"userId"`
+	GeneratedAt time.Time `json:"generatedAt"`
+	Files       []string  `json:"files"`
+	Notes       []string  `json:"notes"`
+}
+
+// Exporter writes export archives and runs background export jobs. It is safe for
+// concurrent use.
+type Exporter struct {
+	exports        database.DataExportRepository
+	dir            string        // Where background archives are stored
+	ttl            time.Duration // How long a finished export stays available
+	asyncThreshold int           // Histories with more swipes+messages than this are exported in the background
+}
+
+// NewExporter creates an Exporter storing background archives in dir for ttl.
+// Users with more than asyncThreshold swipes and messages are exported in the background.
+func NewExporter(exports database.DataExportRepository, dir string, ttl time.Duration, asyncThreshold int) *Exporter {
+	return &Exporter{
+		exports:        exports,
+		dir:            dir,
+		ttl:            ttl,
+		asyncThreshold: asyncThreshold,
+	}
+}
+
+// ArchiveName is the file name offered to the client for userID's archive.
+func ArchiveName(userID string, generatedAt time.Time) string {
+	return fmt.Sprintf("devmatch-export-%s-%s.zip", userID, generatedAt.UTC().Format("20060102T150405Z"))
+}
+
+// ShouldRunAsync reports whether userID's history is large enough to export in the background.
+func (e *Exporter) ShouldRunAsync(ctx context.Context, userID string) (bool, error) {
+	count, err := e.exports.CountUserHistory(ctx, userID)
+	if err != nil {
+		return false, err
+	}
+	return count > e.asyncThreshold, nil
+}
+
+// WriteArchive gathers userID's data and writes it to w as a ZIP archive.
+func (e *Exporter) WriteArchive(ctx context.Context, w io.Writer, userID string) error {
+	data, err := e.exports.GetUserDataExport(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("gathering user data: %w", err)
+	}
+
+	files := []struct {
+		name    string
+		content any
+	}{
+		{"profile.json", data.Profile},
+		{"github.json", data.GitHub},
+		{"swipes.json", data.Swipes},
+		{"conversations.json", data.Conversations},
+		{"messages_sent.json", data.MessagesSent},
+		{"notifications.json", data.Notifications},
+	}
+
+	m := manifest{UserID: userID, GeneratedAt: time.Now().UTC(), Notes: archiveNotes}
+	for _, f := range files {
+		m.Files = append(m.Files, f.name)
+	}
+
+	zw := zip.NewWriter(w)
+	if err := writeJSONFile(zw, "manifest.json", m); err != nil {
+		return err
+	}
+	for _, f := range files {
+		if err := writeJSONFile(zw, f.name, f.content); err != nil {
+			return err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return fmt.Errorf("finishing archive: %w", err)
+	}
+	return nil
+}
+
+// writeJSONFile adds name to the archive with content encoded as indented JSON.
+func writeJSONFile(zw *zip.Writer, name string, content any) error {
+	fw, err := zw.CreateHeader(&zip.FileHeader{Name: name, Method: zip.Deflate, Modified: time.Now()})
+	if err != nil {
+		return fmt.Errorf("adding %s to archive: %w", name, err)
+	}
+	enc := json.NewEncoder(fw)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(content); err != nil {
+		return fmt.Errorf("writing %s: %w", name, err)
+	}
+	return nil
+}
+
+// StartExport returns userID's export that is still being generated, if there is one, or
+// records a new export job and generates its archive in the background.
+func (e *Exporter) StartExport(ctx context.Context, userID string) (*models.DataExport, error) {
+	existing, err := e.exports.GetDataExports(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, export := range existing {
+		if export.Status == models.DataExportPending {
+			return &export, nil
+		}
+	}
+
+	// A job that never finishes (e.g. the server restarted) is cleaned up at this expiry
+	export, err := e.exports.CreateDataExport(ctx, userID, time.Now().Add(jobTimeout+e.ttl))
+	if err != nil {
+		return nil, err
+	}
+	// The job outlives the request, but keeps its ID in the logs
+	go e.run(logging.RequestID(ctx), *export)
+	return export, nil
+}
+
+// run generates the archive of export and records the outcome.
+func (e *Exporter) run(requestID string, export models.DataExport) {
+	ctx, cancel := context.WithTimeout(logging.WithRequestID(context.Background(), requestID), jobTimeout)
+	defer cancel()
+
+	path, size, err := e.writeArchiveFile(ctx, export)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error generating data export", "export_id", export.ID, "user_id", export.UserID, "error", err)
+		if err := e.exports.FailDataExport(ctx, export.ID, "Generating the archive failed. Please try again.", time.Now().Add(e.ttl)); err != nil {
+			logger.ErrorContext(ctx, "Error recording failure of data export", "export_id", export.ID, "error", err)
+		}
+		return
+	}
+
+	token, err := newDownloadToken()
+	if err == nil {
+		err = e.exports.CompleteDataExport(ctx, export.ID, path, token, size, time.Now().Add(e.ttl))
+	}
+	if err != nil {
+		logger.ErrorContext(ctx, "Error completing data export", "export_id", export.ID, "error", err)
+		os.Remove(path)
+		return
+	}
+	logger.InfoContext(ctx, "Data export is ready", "export_id", export.ID, "user_id", export.UserID, "bytes", size)
+}
+
+// writeArchiveFile writes the archive of export to a file in e.dir and returns its path and size.
+func (e *Exporter) writeArchiveFile(ctx context.Context, export models.DataExport) (string, int64, error) {
+	if err := os.MkdirAll(e.dir, 0o700); err != nil {
+		return "", 0, fmt.Errorf("creating export directory: %w", err)
+	}
+	path := filepath.Join(e.dir, export.ID+".zip")
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return "", 0, fmt.Errorf("creating archive file: %w", err)
+	}
+
+	err = e.WriteArchive(ctx, f, export.UserID)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", 0, err
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		os.Remove(path)
+		return "", 0, fmt.Errorf("checking archive file: %w", err)
+	}
+	return path, info.Size(), nil
+}
+
+// newDownloadToken returns a random, URL-safe token for a download link.
+func newDownloadToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generating download token: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// Start deletes expired exports and their archives now and then every interval until
+// ctx is done. It blocks, so run it in its own goroutine.
+func (e *Exporter) Start(ctx context.Context, interval time.Duration) {
+	e.DeleteExpired(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Data export cleanup loop stopped.")
+			return
+		case <-ticker.C:
+			e.DeleteExpired(ctx)
+		}
+	}
+}
+
+// DeleteExpired removes expired export jobs and their archive files.
+func (e *Exporter) DeleteExpired(ctx context.Context) {
+	expired, err := e.exports.DeleteExpiredDataExports(ctx, time.Now())
+	if err != nil {
+		logger.WarnContext(ctx, "Deleting expired data exports failed", "error", err)
+		return
+	}
+	for _, export := range expired {
+		if export.FilePath == nil {
+			continue
+		}
+		if err := os.Remove(*export.FilePath); err != nil && !os.IsNotExist(err) {
+			logger.WarnContext(ctx, "Deleting archive of expired data export failed", "export_id", export.ID, "error", err)
+		}
+	}
+	if len(expired) > 0 {
+		logger.InfoContext(ctx, "Deleted expired data exports", "count", len(expired))
+	}
+}
@@ -0,0 +1,147 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"gin/internal/models"
+	"gin/internal/services/database/memory"
+)
+
+// newStoreWithUser returns a store holding one profile with a swipe, and its ID.
+func newStoreWithUser(t *testing.T) (*memory.Store, string) {
+	t.Helper()
+	ctx := context.Background()
+	store := memory.NewStore()
+	alice, err := store.CreateOrUpdateUserProfile(ctx, models.User{ClerkUserID: "clerk_alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	bob, err := store.CreateOrUpdateUserProfile(ctx, models.User{ClerkUserID: "clerk_bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.CreateSwipe(ctx, models.Swipe{SwiperID: alice.ID, SwipedID: bob.ID, Direction: models.SwipeLike}, 0); err != nil {
+		t.Fatal(err)
+	}
+	return store, alice.ID
+}
+
+func TestWriteArchive(t *testing.T) {
+	store, userID := newStoreWithUser(t)
+	exporter := NewExporter(store, t.TempDir(), time.Hour, 100)
+
+	var buf bytes.Buffer
+	if err := exporter.WriteArchive(context.Background(), &buf, userID); err != nil {
+		t.Fatalf("WriteArchive: %v", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatalf("reading archive: %v", err)
+	}
+
+	var names []string
+	files := make(map[string]*zip.File)
+	for _, f := range zr.File {
+		names = append(names, f.Name)
+		files[f.Name] = f
+	}
+	want := []string{"manifest.json", "profile.json", "github.json", "swipes.json", "conversations.json", "messages_sent.json", "notifications.json"}
+	if !reflect.DeepEqual(names, want) {
+		t.Fatalf("archive files = %v, want %v", names, want)
+	}
+
+	var m manifest
+	readJSON(t, files["manifest.json"], &m)
+	if m.UserID != userID || !reflect.DeepEqual(m.Files, want[1:]) {
+		t.Errorf("manifest = %+v", m)
+	}
+	var swipes []models.Swipe
+	readJSON(t, files["swipes.json"], &swipes)
+	if len(swipes) != 1 || swipes[0].SwiperID != userID {
+		t.Errorf("swipes.json = %+v, want the user's swipe", swipes)
+	}
+}
+
+func readJSON(t *testing.T, f *zip.File, v any) {
+	t.Helper()
+	r, err := f.Open()
+	if err != nil {
+		t.Fatalf("opening %s: %v", f.Name, err)
+	}
+	defer r.Close()
+	if err := json.NewDecoder(r).Decode(v); err != nil {
+		t.Fatalf("decoding %s: %v", f.Name, err)
+	}
+}
+
+func TestShouldRunAsync(t *testing.T) {
+	store, userID := newStoreWithUser(t)
+	tests := []struct {
+		threshold int
+		want      bool
+	}{
+		{0, true},
+		{1, false}, // One swipe is not more than the threshold
+	}
+	for _, tt := range tests {
+		got, err := NewExporter(store, t.TempDir(), time.Hour, tt.threshold).ShouldRunAsync(context.Background(), userID)
+		if err != nil || got != tt.want {
+			t.Errorf("ShouldRunAsync with threshold %d = %v, %v; want %v", tt.threshold, got, err, tt.want)
+		}
+	}
+}
+
+func TestStartExportAndDeleteExpired(t *testing.T) {
+	ctx := context.Background()
+	store, userID := newStoreWithUser(t)
+	dir := t.TempDir()
+	exporter := NewExporter(store, dir, time.Hour, 0)
+
+	started, err := exporter.StartExport(ctx, userID)
+	if err != nil {
+		t.Fatalf("StartExport: %v", err)
+	}
+
+	var export *models.DataExport
+	deadline := time.Now().Add(5 * time.Second)
+	for {
+		if export, err = store.GetDataExport(ctx, userID, started.ID); err != nil {
+			t.Fatalf("GetDataExport: %v", err)
+		}
+		if export.Status != models.DataExportPending || time.Now().After(deadline) {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if export.Status != models.DataExportReady || export.FilePath == nil || export.DownloadToken == nil {
+		t.Fatalf("export = %+v, want a ready export with an archive", export)
+	}
+	if filepath.Dir(*export.FilePath) != dir {
+		t.Errorf("archive written to %s, want a file in %s", *export.FilePath, dir)
+	}
+
+	// Nothing has expired yet
+	exporter.DeleteExpired(ctx)
+	if _, err := os.Stat(*export.FilePath); err != nil {
+		t.Fatalf("archive missing before expiry: %v", err)
+	}
+
+	if err := store.CompleteDataExport(ctx, export.ID, *export.FilePath, *export.DownloadToken, *export.SizeBytes, time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	exporter.DeleteExpired(ctx)
+	if _, err := os.Stat(*export.FilePath); !os.IsNotExist(err) {
+		t.Errorf("archive of expired export still exists: %v", err)
+	}
+	if exports, _ := store.GetDataExports(ctx, userID); len(exports) != 0 {
+		t.Errorf("exports after expiry = %+v, want none", exports)
+	}
+}
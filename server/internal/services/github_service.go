@@ -2,10 +2,10 @@ package services
 
 import (
 	"context"
-	"log"
-	"net/http"
 
 	"gin/internal/config"
+	"gin/internal/metrics"
+	"gin/internal/models"
 
 	"github.com/google/go-github/v59/github"
 )
@@ -17,25 +17,16 @@ type GitHubService struct {
 }
 
 // NewGitHubService creates a new instance of GitHubService.
-// It initializes the GitHub client, potentially using an OAuth token if available in config.
+// It authenticates with cfg.GitHubToken when one is set, which raises GitHub's rate
+// limit from 60 to 5,000 requests an hour.
 func NewGitHubService(cfg *config.Config) *GitHubService {
-	var tc *http.Client
-	// Example: Using a personal access token from config
-	// Uncomment and adjust if you have a token in your config
-	/*
-		if cfg.GitHubToken != "" {
-			ts := oauth2.StaticTokenSource(
-				&oauth2.Token{AccessToken: cfg.GitHubToken},
-			)
-			tc = oauth2.NewClient(context.Background(), ts)
-			log.Println("GitHub client initialized with authentication.")
-		} else {
-			log.Println("GitHub client initialized without authentication (rate limits apply).")
-		}
-	*/
-	// For now, initialize without authentication
-	log.Println("GitHub client initialized without authentication (rate limits apply).")
-	client := github.NewClient(tc)
+	client := github.NewClient(nil)
+	if cfg.GitHubToken != "" {
+		client = client.WithAuthToken(cfg.GitHubToken)
+		logger.Info("GitHub client initialized with authentication.")
+	} else {
+		logger.Info("GitHub client initialized without authentication (rate limits apply).")
+	}
 
 	return &GitHubService{
 		client: client,
@@ -45,13 +36,79 @@ func NewGitHubService(cfg *config.Config) *GitHubService {
 
 // GetUserData fetches basic user data from GitHub for the given username.
 func (s *GitHubService) GetUserData(ctx context.Context, username string) (*github.User, error) {
-	user, _, err := s.client.Users.Get(ctx, username)
+	user, resp, err := s.client.Users.Get(ctx, username)
+	recordGitHubCall("users.get", resp, err)
 	if err != nil {
-		log.Printf("Error fetching GitHub user data for %s: %v", username, err)
+		logger.ErrorContext(ctx, "Error fetching GitHub user data", "username", username, "error", err)
 		return nil, err
 	}
-	log.Printf("Successfully fetched GitHub user data for %s", username)
+	logger.DebugContext(ctx, "Fetched GitHub user data", "username", username)
 	return user, nil
 }
 
-// Add more methods here as needed, e.g., GetUserRepos, GetRepoDetails, etc.
\ No newline at end of file
+// GetUserRepos fetches the public repositories owned by the given user, most recently pushed first.
+// Only the first page (up to 100 repositories) is requested to keep API usage bounded.
+func (s *GitHubService) GetUserRepos(ctx context.Context, username string) ([]*github.Repository, error) {
+	opts := &github.RepositoryListByUserOptions{
+		Type:        "owner",
+		Sort:        "pushed",
+		ListOptions: github.ListOptions{PerPage: 100},
+	}
+	repos, resp, err := s.client.Repositories.ListByUser(ctx, username, opts)
+	recordGitHubCall("repositories.list_by_user", resp, err)
+	if err != nil {
+		logger.ErrorContext(ctx, "Error fetching GitHub repos", "username", username, "error", err)
+		return nil, err
+	}
+	return repos, nil
+}
+
+// GetUserStats summarizes a GitHub user's followers, public repository count,
+// repository names and primary language. The primary language is the most common language across the
+// user's non-fork repositories.
+func (s *GitHubService) GetUserStats(ctx context.Context, user *github.User) (*models.GitHubStats, error) {
+	repos, err := s.GetUserRepos(ctx, user.GetLogin())
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.GitHubStats{
+		Followers:   user.GetFollowers(),
+		PublicRepos: user.GetPublicRepos(),
+	}
+
+	counts := make(map[string]int)
+	best := ""
+	for _, repo := range repos {
+		stats.RepoNames = append(stats.RepoNames, repo.GetName())
+		language := repo.GetLanguage()
+		if repo.GetFork() || language == "" {
+			continue
+		}
+		counts[language]++
+		if counts[language] > counts[best] || (counts[language] == counts[best] && language < best) {
+			best = language
+		}
+	}
+	if best != "" {
+		stats.PrimaryLanguage = &best
+	}
+	return stats, nil
+}
+
+// recordGitHubCall counts a call to endpoint and updates the rate limit gauges from its
+// response, which GitHub also sends with most errors (including rate limit errors).
+func recordGitHubCall(endpoint string, resp *github.Response, err error) {
+	outcome := metrics.OutcomeSuccess
+	if err != nil {
+		outcome = metrics.OutcomeError
+	}
+	metrics.GitHubAPICalls.WithLabelValues(endpoint, outcome).Inc()
+	if resp != nil && resp.Rate.Limit > 0 {
+		metrics.GitHubRateLimit.Set(float64(resp.Rate.Limit))
+		metrics.GitHubRateLimitRemaining.Set(float64(resp.Rate.Remaining))
+		metrics.GitHubRateLimitReset.Set(float64(resp.Rate.Reset.Unix()))
+	}
+}
+
+// Add more methods here as needed, e.g., GetRepoDetails, etc.
\ No newline at end of file
@@ -0,0 +1,181 @@
+// Package githubsync keeps the GitHub stats stored on profiles (followers, public
+// repositories, repository names and primary language) current. Deck filters and search
+// rely on them, so they are fetched as soon as a profile gets a GitHub account and
+// refreshed in the background after that.
+package githubsync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"gin/internal/logging"
+	"gin/internal/models"
+	"gin/internal/services/database"
+
+	"github.com/google/go-github/v59/github"
+)
+
+var logger = logging.For("githubsync")
+
+const (
+	// syncBatchSize bounds how many profiles one background pass refreshes. Each costs
+	// two GitHub API calls.
+	syncBatchSize = 25
+	// syncTimeout bounds fetching and storing the stats of one profile.
+	syncTimeout = 30 * time.Second
+)
+
+// githubLogin matches valid GitHub usernames.
+var githubLogin = regexp.MustCompile(`^[A-Za-z0-9](?:[A-Za-z0-9-]{0,38})$`)
+
+// GitHubClient reads users from the GitHub API. *services.GitHubService implements it.
+type GitHubClient interface {
+	GetUserData(ctx context.Context, username string) (*github.User, error)
+	GetUserStats(ctx context.Context, user *github.User) (*models.GitHubStats, error)
+}
+
+// Syncer fetches profiles' GitHub stats and stores them. It is safe for concurrent use.
+type Syncer struct {
+	users        database.UserRepository
+	github       GitHubClient
+	refreshAfter time.Duration // How old stats may get before the background job refetches them
+}
+
+// NewSyncer creates a Syncer whose background job refetches stats older than refreshAfter.
+func NewSyncer(users database.UserRepository, github GitHubClient, refreshAfter time.Duration) *Syncer {
+	return &Syncer{users: users, github: github, refreshAfter: refreshAfter}
+}
+
+// LoginFromURL returns the GitHub username in a profile's GitHub URL, such as
+// "https://github.com/torvalds", or "" if the URL does not point at a GitHub user.
+func LoginFromURL(githubURL string) string {
+	u, err := url.Parse(strings.TrimSpace(githubURL))
+	if err != nil || !strings.EqualFold(strings.TrimPrefix(u.Hostname(), "www."), "github.com") {
+		return ""
+	}
+	login := strings.Trim(u.Path, "/")
+	if !githubLogin.MatchString(login) {
+		return ""
+	}
+	return login
+}
+
+// NeedsSync reports whether after's GitHub stats must be fetched: before is the profile
+// as it was (nil if it was just created) and after as it is now.
+func NeedsSync(before, after *models.User) bool {
+	if after == nil || LoginFromURL(deref(after.GitHubURL)) == "" {
+		return false
+	}
+	return before == nil || !strings.EqualFold(deref(before.GitHubURL), deref(after.GitHubURL))
+}
+
+// SyncUser fetches the GitHub stats of user and stores them. Users without a GitHub URL
+// are skipped. A GitHub account that no longer exists is stored as having no activity,
+// so the profile stops matching GitHub-based filters.
+func (s *Syncer) SyncUser(ctx context.Context, user models.User) error {
+	login := LoginFromURL(deref(user.GitHubURL))
+	if login == "" {
+		return nil
+	}
+
+	var stats *models.GitHubStats
+	githubUser, err := s.github.GetUserData(ctx, login)
+	if err == nil {
+		stats, err = s.github.GetUserStats(ctx, githubUser)
+	}
+	if isNotFound(err) {
+		logger.InfoContext(ctx, "GitHub account of profile not found, clearing its stats", "user_id", user.ID, "github_login", login)
+		stats, err = &models.GitHubStats{}, nil
+	}
+	if err != nil {
+		return err
+	}
+	return s.users.UpdateGitHubStats(ctx, user.ID, *stats)
+}
+
+// SyncUserAsync runs SyncUser in the background, so requests creating or updating a
+// profile do not wait for GitHub. Failures are logged; the background job retries them.
+func (s *Syncer) SyncUserAsync(ctx context.Context, user models.User) {
+	// The sync outlives the request, but keeps its ID in the logs
+	go func(requestID string) {
+		ctx, cancel := context.WithTimeout(logging.WithRequestID(context.Background(), requestID), syncTimeout)
+		defer cancel()
+		if err := s.SyncUser(ctx, user); err != nil {
+			logger.WarnContext(ctx, "Fetching GitHub stats of profile failed", "user_id", user.ID, "error", err)
+		}
+	}(logging.RequestID(ctx))
+}
+
+// Start refreshes stale stats now and then on every interval until ctx is done.
+// It blocks, so run it in its own goroutine.
+func (s *Syncer) Start(ctx context.Context, interval time.Duration) {
+	s.SyncDue(ctx)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("GitHub stats refresh loop stopped.")
+			return
+		case <-ticker.C:
+			s.SyncDue(ctx)
+		}
+	}
+}
+
+// SyncDue refreshes the profiles whose stats were never fetched or are older than the
+// refresh interval, and returns how many were refreshed. The pass stops early when
+// GitHub's rate limit is hit; the rest are picked up by the next one.
+func (s *Syncer) SyncDue(ctx context.Context) int {
+	due, err := s.users.GetUsersDueForGitHubSync(ctx, time.Now().Add(-s.refreshAfter), syncBatchSize)
+	if err != nil {
+		logger.WarnContext(ctx, "Loading profiles due for a GitHub stats refresh failed", "error", err)
+		return 0
+	}
+
+	synced := 0
+	for _, user := range due {
+		userCtx, cancel := context.WithTimeout(ctx, syncTimeout)
+		err := s.SyncUser(userCtx, user)
+		cancel()
+		if isRateLimited(err) {
+			logger.WarnContext(ctx, "GitHub rate limit reached, postponing the rest of the stats refresh", "refreshed", synced, "remaining", len(due)-synced)
+			break
+		}
+		if err != nil {
+			logger.WarnContext(ctx, "Refreshing GitHub stats of profile failed", "user_id", user.ID, "error", err)
+			continue
+		}
+		synced++
+	}
+	if synced > 0 {
+		logger.InfoContext(ctx, "Refreshed GitHub stats", "count", synced)
+	}
+	return synced
+}
+
+// isNotFound reports whether err is GitHub answering 404.
+func isNotFound(err error) bool {
+	var errResp *github.ErrorResponse
+	return errors.As(err, &errResp) && errResp.Response != nil && errResp.Response.StatusCode == http.StatusNotFound
+}
+
+// isRateLimited reports whether err is GitHub refusing a call for rate limiting.
+func isRateLimited(err error) bool {
+	var rateErr *github.RateLimitError
+	var abuseErr *github.AbuseRateLimitError
+	return errors.As(err, &rateErr) || errors.As(err, &abuseErr)
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
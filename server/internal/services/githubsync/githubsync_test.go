@@ -0,0 +1,157 @@
+package githubsync
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"gin/internal/models"
+	"gin/internal/services/database/memory"
+
+	"github.com/google/go-github/v59/github"
+)
+
+// fakeGitHub serves stats by login; logins mapped to an error fail with it.
+type fakeGitHub struct {
+	stats map[string]models.GitHubStats
+	errs  map[string]error
+	calls []string
+}
+
+func (f *fakeGitHub) GetUserData(ctx context.Context, username string) (*github.User, error) {
+	f.calls = append(f.calls, username)
+	if err := f.errs[username]; err != nil {
+		return nil, err
+	}
+	return &github.User{Login: github.String(username)}, nil
+}
+
+func (f *fakeGitHub) GetUserStats(ctx context.Context, user *github.User) (*models.GitHubStats, error) {
+	stats := f.stats[user.GetLogin()]
+	return &stats, nil
+}
+
+func notFound() error {
+	return &github.ErrorResponse{Response: &http.Response{StatusCode: http.StatusNotFound}}
+}
+
+func withGitHub(t *testing.T, store *memory.Store, name, githubURL string) *models.User {
+	t.Helper()
+	user, err := store.CreateOrUpdateUserProfile(context.Background(), models.User{ClerkUserID: "user_" + name, Username: &name, GitHubURL: &githubURL})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestLoginFromURL(t *testing.T) {
+	tests := []struct {
+		url, want string
+	}{
+		{"https://github.com/torvalds", "torvalds"},
+		{" https://www.GitHub.com/ada-l/ ", "ada-l"},
+		{"https://github.com/torvalds/linux", ""},
+		{"https://gitlab.com/torvalds", ""},
+		{"https://github.com/-bad", ""},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := LoginFromURL(tt.url); got != tt.want {
+			t.Errorf("LoginFromURL(%q) = %q, want %q", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestNeedsSync(t *testing.T) {
+	profile := func(githubURL string) *models.User { return &models.User{GitHubURL: &githubURL} }
+	tests := []struct {
+		name          string
+		before, after *models.User
+		want          bool
+	}{
+		{"new profile", nil, profile("https://github.com/ada"), true},
+		{"new profile without GitHub", nil, profile(""), false},
+		{"unchanged", profile("https://github.com/ada"), profile("https://github.com/Ada"), false},
+		{"changed", profile("https://github.com/ada"), profile("https://github.com/grace"), true},
+		{"removed", profile("https://github.com/ada"), profile(""), false},
+	}
+	for _, tt := range tests {
+		if got := NeedsSync(tt.before, tt.after); got != tt.want {
+			t.Errorf("%s: NeedsSync = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSyncUser(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	gh := &fakeGitHub{
+		stats: map[string]models.GitHubStats{"ada": {PrimaryLanguage: github.String("Go"), Followers: 10, PublicRepos: 3}},
+		errs:  map[string]error{"gone": notFound(), "flaky": errors.New("connection reset")},
+	}
+	syncer := NewSyncer(store, gh, time.Hour)
+
+	ada := withGitHub(t, store, "ada", "https://github.com/ada")
+	if err := syncer.SyncUser(ctx, *ada); err != nil {
+		t.Fatalf("SyncUser: %v", err)
+	}
+	if got, _ := store.GetUserProfileByID(ctx, ada.ID); got.GitHubFollowers != 10 || got.PrimaryLanguage == nil || *got.PrimaryLanguage != "Go" {
+		t.Errorf("stored profile = %+v, want ada's stats", got)
+	}
+
+	// A deleted GitHub account clears the stats
+	gone := withGitHub(t, store, "gone", "https://github.com/gone")
+	if err := store.UpdateGitHubStats(ctx, gone.ID, models.GitHubStats{Followers: 5}); err != nil {
+		t.Fatal(err)
+	}
+	if err := syncer.SyncUser(ctx, *gone); err != nil {
+		t.Fatalf("SyncUser for a missing account: %v", err)
+	}
+	if got, _ := store.GetUserProfileByID(ctx, gone.ID); got.GitHubFollowers != 0 {
+		t.Errorf("followers of a missing account = %d, want 0", got.GitHubFollowers)
+	}
+
+	flaky := withGitHub(t, store, "flaky", "https://github.com/flaky")
+	if err := syncer.SyncUser(ctx, *flaky); err == nil {
+		t.Error("SyncUser hid a GitHub failure")
+	}
+
+	calls := len(gh.calls)
+	if err := syncer.SyncUser(ctx, models.User{ID: "none"}); err != nil || len(gh.calls) != calls {
+		t.Errorf("SyncUser without a GitHub URL = %v after %d calls, want no calls", err, len(gh.calls)-calls)
+	}
+}
+
+func TestSyncDue(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	gh := &fakeGitHub{errs: map[string]error{
+		"flaky":   errors.New("connection reset"),
+		"limited": &github.RateLimitError{Response: &http.Response{StatusCode: http.StatusForbidden}},
+	}}
+	syncer := NewSyncer(store, gh, time.Hour)
+
+	withGitHub(t, store, "ada", "https://github.com/ada")
+	withGitHub(t, store, "flaky", "https://github.com/flaky")
+	withGitHub(t, store, "limited", "https://github.com/limited")
+	withGitHub(t, store, "later", "https://github.com/later")
+
+	// The failure is skipped and the rate limit stops the pass
+	if n := syncer.SyncDue(ctx); n != 1 {
+		t.Errorf("first pass refreshed %d profiles, want 1", n)
+	}
+	if last := gh.calls[len(gh.calls)-1]; last != "limited" {
+		t.Errorf("last GitHub call was for %s, want the pass to stop at limited", last)
+	}
+
+	delete(gh.errs, "limited")
+	delete(gh.errs, "flaky")
+	if n := syncer.SyncDue(ctx); n != 3 {
+		t.Errorf("second pass refreshed %d profiles, want the 3 left", n)
+	}
+	if n := syncer.SyncDue(ctx); n != 0 {
+		t.Errorf("third pass refreshed %d profiles, want none", n)
+	}
+}
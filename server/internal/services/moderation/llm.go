@@ -0,0 +1,60 @@
+package moderation
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"gin/internal/models"
+	"gin/internal/services"
+)
+
+// ContentClassifier classifies content with a language model. *services.GeminiService
+// implements it.
+type ContentClassifier interface {
+	ClassifyContent(ctx context.Context, contentType, content string) (*services.ContentClassification, error)
+}
+
+// LLMChecker asks a language model to classify content. It catches what word lists
+// cannot, such as harassment without any banned words, at the cost of a remote call on
+// every message.
+type LLMChecker struct {
+	classifier ContentClassifier
+	timeout    time.Duration // Upper bound on a classification, so a slow model cannot stall chat
+}
+
+// NewLLMChecker creates an LLMChecker that gives classifier at most timeout per call.
+func NewLLMChecker(classifier ContentClassifier, timeout time.Duration) *LLMChecker {
+	return &LLMChecker{classifier: classifier, timeout: timeout}
+}
+
+// Name implements Checker.
+func (l *LLMChecker) Name() string { return "llm" }
+
+// Check implements Checker.
+func (l *LLMChecker) Check(ctx context.Context, content Content) (Verdict, error) {
+	ctx, cancel := context.WithTimeout(ctx, l.timeout)
+	defer cancel()
+
+	classification, err := l.classifier.ClassifyContent(ctx, string(content.Type), content.Text)
+	if err != nil {
+		return Verdict{}, err
+	}
+	decision := models.ModerationDecision(classification.Decision)
+	if !decision.Valid() {
+		return Verdict{}, fmt.Errorf("classifier returned unknown decision %q", classification.Decision)
+	}
+	if decision == models.ModerationAllow {
+		return Allowed, nil
+	}
+
+	category := classification.Category
+	if category == "" || category == "none" {
+		category = "other"
+	}
+	reason := classification.Reason
+	if reason == "" {
+		reason = "Classified as " + category
+	}
+	return Verdict{Decision: decision, Stage: l.Name(), Rule: category, Reason: reason}, nil
+}
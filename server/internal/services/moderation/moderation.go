@@ -0,0 +1,108 @@
+// Package moderation screens user-written content (chat messages and profile bios)
+// before it is saved. A Moderator runs a chain of Checkers, such as the local RuleEngine
+// and the optional Gemini classifier, and combines their verdicts into one allow, flag
+// or reject decision. Flagged and rejected content is written to the moderation log for
+// moderators to review.
+package moderation
+
+import (
+	"context"
+
+	"gin/internal/logging"
+	"gin/internal/models"
+	"gin/internal/services/database"
+)
+
+var logger = logging.For("moderation")
+
+// Content is a piece of user-written content to moderate.
+type Content struct {
+	Type           models.ModerationContentType
+	AuthorID       string
+	ConversationID string // Set for messages
+	Text           string
+}
+
+// Verdict is a checker's decision about a piece of content.
+type Verdict struct {
+	Decision models.ModerationDecision
+	Stage    string // The checker that decided, e.g. "rules" or "llm"
+	Rule     string // What triggered, e.g. "banned_word"; empty when allowed
+	Reason   string // Shown to the author when the content is rejected
+}
+
+// Allowed is the verdict for content nothing was found wrong with.
+var Allowed = Verdict{Decision: models.ModerationAllow}
+
+// Checker is one stage of the moderation pipeline.
+type Checker interface {
+	// Name identifies the checker in logs and in the moderation log.
+	Name() string
+	// Check returns the checker's verdict about content. An error means the checker
+	// could not decide; the pipeline carries on without it.
+	Check(ctx context.Context, content Content) (Verdict, error)
+}
+
+// Moderator runs content through a chain of checkers and logs what they flag or reject.
+type Moderator struct {
+	checkers []Checker
+	log      database.ModerationRepository
+}
+
+// NewModerator creates a Moderator that runs checkers in order and records flagged and
+// rejected content in moderationLog.
+func NewModerator(moderationLog database.ModerationRepository, checkers ...Checker) *Moderator {
+	return &Moderator{checkers: checkers, log: moderationLog}
+}
+
+// Check runs content through every checker and returns the strictest verdict; on a tie
+// the earlier checker's verdict is kept. Once a checker rejects the content the
+// remaining checkers are skipped. A checker that fails is skipped too, so an unavailable
+// classifier never blocks users from chatting.
+func (m *Moderator) Check(ctx context.Context, content Content) Verdict {
+	verdict := Allowed
+	for _, checker := range m.checkers {
+		v, err := checker.Check(ctx, content)
+		if err != nil {
+			logger.WarnContext(ctx, "Moderation checker failed, skipping it", "checker", checker.Name(), "content_type", content.Type, "user_id", content.AuthorID, "error", err)
+			continue
+		}
+		if v.Decision.Stricter(verdict.Decision) {
+			verdict = v
+		}
+		if verdict.Decision == models.ModerationReject {
+			break
+		}
+	}
+	return verdict
+}
+
+// Record writes flagged and rejected content to the moderation log; allowed content is
+// not logged. messageID is the ID of a flagged message that was sent, or nil. Failures
+// are logged rather than returned: the decision has been made and must not be undone
+// because the audit log is unavailable.
+func (m *Moderator) Record(ctx context.Context, content Content, verdict Verdict, messageID *string) {
+	if verdict.Decision == models.ModerationAllow {
+		return
+	}
+	// The content itself only goes to the moderation log, never to the server log
+	logger.InfoContext(ctx, "Moderation decision", "decision", verdict.Decision, "content_type", content.Type, "user_id", content.AuthorID, "stage", verdict.Stage, "rule", verdict.Rule)
+
+	event := models.ModerationEvent{
+		UserID:      content.AuthorID,
+		ContentType: content.Type,
+		MessageID:   messageID,
+		Decision:    verdict.Decision,
+		Stage:       verdict.Stage,
+		Rule:        verdict.Rule,
+		Reason:      verdict.Reason,
+		Content:     content.Text,
+	}
+	if content.ConversationID != "" {
+		conversationID := content.ConversationID
+		event.ConversationID = &conversationID
+	}
+	if _, err := m.log.RecordModerationEvent(ctx, event); err != nil {
+		logger.ErrorContext(ctx, "Error recording moderation decision", "user_id", content.AuthorID, "error", err)
+	}
+}
@@ -0,0 +1,131 @@
+package moderation
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gin/internal/models"
+	"gin/internal/services"
+	"gin/internal/services/database/memory"
+)
+
+// fixedChecker returns the same verdict or error every time and counts its calls.
+type fixedChecker struct {
+	name    string
+	verdict Verdict
+	err     error
+	calls   int
+}
+
+func (f *fixedChecker) Name() string { return f.name }
+
+func (f *fixedChecker) Check(ctx context.Context, content Content) (Verdict, error) {
+	f.calls++
+	return f.verdict, f.err
+}
+
+// fakeClassifier returns a fixed classification.
+type fakeClassifier struct {
+	classification services.ContentClassification
+	err            error
+}
+
+func (f fakeClassifier) ClassifyContent(ctx context.Context, contentType, content string) (*services.ContentClassification, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &f.classification, nil
+}
+
+func TestModeratorCheck(t *testing.T) {
+	flag := Verdict{Decision: models.ModerationFlag, Stage: "first", Rule: "a"}
+	otherFlag := Verdict{Decision: models.ModerationFlag, Stage: "second", Rule: "b"}
+	reject := Verdict{Decision: models.ModerationReject, Stage: "second", Rule: "c"}
+
+	tests := []struct {
+		name     string
+		verdicts []Verdict
+		errs     []error
+		want     Verdict
+		calls    []int
+	}{
+		{"no checkers", nil, nil, Allowed, nil},
+		{"strictest wins", []Verdict{flag, reject}, []error{nil, nil}, reject, []int{1, 1}},
+		{"earlier wins a tie", []Verdict{flag, otherFlag}, []error{nil, nil}, flag, []int{1, 1}},
+		{"reject stops the chain", []Verdict{reject, flag}, []error{nil, nil}, reject, []int{1, 0}},
+		{"failed checker skipped", []Verdict{reject, flag}, []error{errors.New("down"), nil}, flag, []int{1, 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var checkers []Checker
+			var fakes []*fixedChecker
+			for i, v := range tt.verdicts {
+				f := &fixedChecker{name: v.Stage, verdict: v, err: tt.errs[i]}
+				fakes = append(fakes, f)
+				checkers = append(checkers, f)
+			}
+			m := NewModerator(memory.NewStore(), checkers...)
+			if got := m.Check(context.Background(), Content{Type: models.ModerationContentMessage, Text: "hi"}); got != tt.want {
+				t.Errorf("Check = %+v, want %+v", got, tt.want)
+			}
+			for i, f := range fakes {
+				if f.calls != tt.calls[i] {
+					t.Errorf("checker %d called %d times, want %d", i, f.calls, tt.calls[i])
+				}
+			}
+		})
+	}
+}
+
+func TestModeratorRecord(t *testing.T) {
+	ctx := context.Background()
+	store := memory.NewStore()
+	m := NewModerator(store)
+	content := Content{Type: models.ModerationContentMessage, AuthorID: "u1", ConversationID: "c1", Text: "buy now"}
+	messageID := "m1"
+
+	m.Record(ctx, content, Allowed, nil)
+	m.Record(ctx, content, Verdict{Decision: models.ModerationFlag, Stage: "rules", Rule: "spam"}, &messageID)
+
+	events, err := store.GetModerationEvents(ctx, models.ModerationEventFilter{}, 10, 0)
+	if err != nil || len(events) != 1 {
+		t.Fatalf("moderation log = %+v, %v; want only the flagged message", events, err)
+	}
+	event := events[0]
+	if event.UserID != "u1" || event.Decision != models.ModerationFlag || event.Rule != "spam" || event.Content != "buy now" ||
+		event.ConversationID == nil || *event.ConversationID != "c1" || event.MessageID == nil || *event.MessageID != messageID {
+		t.Errorf("logged event = %+v", event)
+	}
+}
+
+func TestLLMChecker(t *testing.T) {
+	tests := []struct {
+		name           string
+		classification services.ContentClassification
+		err            error
+		want           Verdict
+		wantErr        bool
+	}{
+		{"allow", services.ContentClassification{Decision: "allow", Category: "none"}, nil, Allowed, false},
+		{"flag", services.ContentClassification{Decision: "flag", Category: "spam", Reason: "Looks like spam"}, nil,
+			Verdict{Decision: models.ModerationFlag, Stage: "llm", Rule: "spam", Reason: "Looks like spam"}, false},
+		{"defaults", services.ContentClassification{Decision: "reject", Category: "none"}, nil,
+			Verdict{Decision: models.ModerationReject, Stage: "llm", Rule: "other", Reason: "Classified as other"}, false},
+		{"unknown decision", services.ContentClassification{Decision: "maybe"}, nil, Verdict{}, true},
+		{"classifier error", services.ContentClassification{}, errors.New("quota"), Verdict{}, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			checker := NewLLMChecker(fakeClassifier{classification: tt.classification, err: tt.err}, time.Second)
+			got, err := checker.Check(context.Background(), Content{Type: models.ModerationContentBio, Text: "text"})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Check = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
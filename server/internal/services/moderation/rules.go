@@ -0,0 +1,260 @@
+package moderation
+
+import (
+	"context"
+	"net/url"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"gin/internal/models"
+)
+
+// DefaultFlaggedPhrases are phrases common in romance and investment scams. Content
+// containing one is flagged for review; RuleOptions.FlaggedPhrases adds to them.
+var DefaultFlaggedPhrases = []string{
+	"gift card",
+	"wire transfer",
+	"western union",
+	"crypto investment",
+	"investment opportunity",
+	"guaranteed returns",
+	"send me money",
+	"cash app",
+	"add me on telegram",
+	"add me on whatsapp",
+	"text me on whatsapp",
+}
+
+// linkShorteners hide where a link goes, which is how phishing links are usually sent.
+var linkShorteners = map[string]struct{}{
+	"bit.ly": {}, "tinyurl.com": {}, "t.co": {}, "goo.gl": {}, "ow.ly": {},
+	"is.gd": {}, "buff.ly": {}, "cutt.ly": {}, "rebrand.ly": {}, "shorturl.at": {},
+}
+
+// linkPattern matches URLs with a scheme, "www." hosts and bare domains on common TLDs.
+var linkPattern = regexp.MustCompile(`(?i)\b(?:https?://[^\s<>"]+|www\.[^\s<>"]+|[a-z0-9](?:[a-z0-9-]*[a-z0-9])?(?:\.[a-z0-9](?:[a-z0-9-]*[a-z0-9])?)*\.(?:com|net|org|io|ly|me|gg|co|xyz|info|biz|app|dev|link|click|top|at|to)\b(?:/[^\s<>"]*)?)`)
+
+// leetReplacer undoes common character substitutions used to dodge word lists.
+var leetReplacer = strings.NewReplacer("0", "o", "1", "i", "3", "e", "4", "a", "5", "s", "7", "t", "@", "a", "$", "s")
+
+// Thresholds of the spam heuristics.
+const (
+	maxRepeatedCharacters = 10  // A run of more identical characters than this is flagged
+	shoutingMinLetters    = 20  // Shorter content is never treated as shouting
+	shoutingUpperRatio    = 0.8 // Share of uppercase letters that counts as shouting
+	repeatMinLength       = 20  // Shorter messages ("hi", "thanks!") are not checked for repeats
+	repeatHistoryLimit    = 50  // Recent messages remembered per author
+)
+
+// RuleOptions configures a RuleEngine. Zero values disable the corresponding rule.
+type RuleOptions struct {
+	BannedWords    []string      // Words and phrases that get content rejected
+	FlaggedPhrases []string      // Words and phrases that get content flagged, in addition to DefaultFlaggedPhrases
+	MaxLinks       int           // Content with more links than this is flagged
+	RepeatWindow   time.Duration // How far back identical messages count as repeats
+	RepeatLimit    int           // Messages repeated this many times within RepeatWindow are rejected
+}
+
+// RuleEngine is the local, rule-based checker: banned and flagged word lists, link and
+// spam heuristics, and detection of the same message being sent over and over. It
+// remembers recent messages in memory, so repeats are only counted per server process.
+type RuleEngine struct {
+	banned   []string // Normalized, see normalizeWords
+	flagged  []string // Normalized, see normalizeWords
+	maxLinks int
+
+	repeatWindow time.Duration
+	repeatLimit  int
+	now          func() time.Time // Clock, replaceable to test time-based behaviour
+
+	mu        sync.Mutex
+	history   map[string][]sentMessage // Recent messages by author ID, oldest first
+	lastSweep time.Time
+}
+
+// sentMessage is a message remembered for repeat detection.
+type sentMessage struct {
+	text   string // Normalized, see normalizeRepeat
+	sentAt time.Time
+}
+
+// NewRuleEngine creates a RuleEngine configured by opts.
+func NewRuleEngine(opts RuleOptions) *RuleEngine {
+	e := &RuleEngine{
+		maxLinks:     opts.MaxLinks,
+		repeatWindow: opts.RepeatWindow,
+		repeatLimit:  opts.RepeatLimit,
+		now:          time.Now,
+		history:      make(map[string][]sentMessage),
+	}
+	for _, word := range opts.BannedWords {
+		if normalized := normalizeWords(word); normalized != "" {
+			e.banned = append(e.banned, normalized)
+		}
+	}
+	for _, phrase := range append(append([]string{}, DefaultFlaggedPhrases...), opts.FlaggedPhrases...) {
+		if normalized := normalizeWords(phrase); normalized != "" {
+			e.flagged = append(e.flagged, normalized)
+		}
+	}
+	return e
+}
+
+// Name implements Checker.
+func (e *RuleEngine) Name() string { return "rules" }
+
+// Check implements Checker. Rules are applied from the most to the least serious and the
+// first one that matches decides.
+func (e *RuleEngine) Check(ctx context.Context, content Content) (Verdict, error) {
+	words := " " + normalizeWords(content.Text) + " "
+	links := linkPattern.FindAllString(content.Text, -1)
+
+	// Repeats are counted before anything else so every message is remembered
+	repeats := 0
+	if content.Type == models.ModerationContentMessage {
+		repeats = e.rememberMessage(content.AuthorID, content.Text)
+	}
+
+	for _, word := range e.banned {
+		if strings.Contains(words, " "+word+" ") {
+			return e.verdict(models.ModerationReject, "banned_word", "Your text contains language that is not allowed"), nil
+		}
+	}
+	if e.repeatLimit > 0 && repeats >= e.repeatLimit {
+		return e.verdict(models.ModerationReject, "repeated_message", "You have sent this message too many times"), nil
+	}
+	for _, phrase := range e.flagged {
+		if strings.Contains(words, " "+phrase+" ") {
+			return e.verdict(models.ModerationFlag, "flagged_phrase", "Contains a phrase common in scams"), nil
+		}
+	}
+	for _, link := range links {
+		if _, shortened := linkShorteners[linkHost(link)]; shortened {
+			return e.verdict(models.ModerationFlag, "link_shortener", "Contains a shortened link"), nil
+		}
+	}
+	if e.maxLinks > 0 && len(links) > e.maxLinks {
+		return e.verdict(models.ModerationFlag, "too_many_links", "Contains many links"), nil
+	}
+	if repeats > 0 && len(links) > 0 {
+		return e.verdict(models.ModerationFlag, "repeated_link", "The same link was sent repeatedly"), nil
+	}
+	if hasRepeatedCharacters(content.Text, maxRepeatedCharacters) {
+		return e.verdict(models.ModerationFlag, "repeated_characters", "Contains long runs of one character"), nil
+	}
+	if isShouting(content.Text) {
+		return e.verdict(models.ModerationFlag, "shouting", "Written mostly in capitals"), nil
+	}
+	return Allowed, nil
+}
+
+// verdict builds a verdict of this checker.
+func (e *RuleEngine) verdict(decision models.ModerationDecision, rule, reason string) Verdict {
+	return Verdict{Decision: decision, Stage: e.Name(), Rule: rule, Reason: reason}
+}
+
+// rememberMessage records that authorID sent text and returns how many identical
+// messages they sent within the repeat window before it.
+func (e *RuleEngine) rememberMessage(authorID, text string) int {
+	if e.repeatWindow <= 0 || e.repeatLimit <= 0 {
+		return 0
+	}
+	normalized := normalizeRepeat(text)
+	if utf8.RuneCountInString(normalized) < repeatMinLength {
+		return 0
+	}
+
+	now := e.now()
+	cutoff := now.Add(-e.repeatWindow)
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	// Every window, forget authors who have not sent anything recently
+	if now.Sub(e.lastSweep) > e.repeatWindow {
+		for author, sent := range e.history {
+			if len(sent) == 0 || sent[len(sent)-1].sentAt.Before(cutoff) {
+				delete(e.history, author)
+			}
+		}
+		e.lastSweep = now
+	}
+
+	recent := e.history[authorID][:0]
+	repeats := 0
+	for _, sent := range e.history[authorID] {
+		if sent.sentAt.Before(cutoff) {
+			continue
+		}
+		recent = append(recent, sent)
+		if sent.text == normalized {
+			repeats++
+		}
+	}
+	recent = append(recent, sentMessage{text: normalized, sentAt: now})
+	if len(recent) > repeatHistoryLimit {
+		recent = recent[len(recent)-repeatHistoryLimit:]
+	}
+	e.history[authorID] = recent
+	return repeats
+}
+
+// normalizeWords lowercases s, undoes leetspeak and reduces it to single-space separated
+// words, so word lists match regardless of case, punctuation and spacing.
+func normalizeWords(s string) string {
+	s = leetReplacer.Replace(strings.ToLower(s))
+	return strings.Join(strings.FieldsFunc(s, func(r rune) bool { return !unicode.IsLetter(r) }), " ")
+}
+
+// normalizeRepeat lowercases s and collapses whitespace, so trivially varied copies of a
+// message count as repeats.
+func normalizeRepeat(s string) string {
+	return strings.Join(strings.Fields(strings.ToLower(s)), " ")
+}
+
+// linkHost returns the lowercased host of a link matched by linkPattern.
+func linkHost(link string) string {
+	if !strings.Contains(link, "://") {
+		link = "http://" + link
+	}
+	u, err := url.Parse(link)
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// hasRepeatedCharacters reports whether s contains a run of more than limit identical
+// non-space characters.
+func hasRepeatedCharacters(s string, limit int) bool {
+	var last rune
+	run := 0
+	for _, r := range s {
+		if r == last && !unicode.IsSpace(r) {
+			run++
+			if run > limit {
+				return true
+			}
+		} else {
+			last, run = r, 1
+		}
+	}
+	return false
+}
+
+// isShouting reports whether s has enough letters to judge and is mostly in capitals.
+func isShouting(s string) bool {
+	letters, upper := 0, 0
+	for _, r := range s {
+		if unicode.IsLetter(r) {
+			letters++
+			if unicode.IsUpper(r) {
+				upper++
+			}
+		}
+	}
+	return letters >= shoutingMinLetters && float64(upper) >= shoutingUpperRatio*float64(letters)
+}
@@ -0,0 +1,140 @@
+package moderation
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gin/internal/models"
+)
+
+func TestNormalizeWords(t *testing.T) {
+	tests := []struct {
+		in, want string
+	}{
+		{"Hello World", "hello world"},
+		{"h3ll0 w0rld", "hello world"},
+		{"$c4m!!", "scam"},
+		{"fr33 m0n3y", "free money"},
+		{"1d10t", "idiot"},
+		{"s.p.a.m", "s p a m"},
+		{"  multiple\tspaces\n", "multiple spaces"},
+		{"@dm1n", "admin"},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		if got := normalizeWords(tt.in); got != tt.want {
+			t.Errorf("normalizeWords(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRuleEngineWordLists(t *testing.T) {
+	engine := NewRuleEngine(RuleOptions{BannedWords: []string{"idiot", "Scam Artist"}})
+
+	tests := []struct {
+		name string
+		text string
+		want models.ModerationDecision
+		rule string
+	}{
+		{"clean", "Nice to meet you", models.ModerationAllow, ""},
+		{"banned", "you idiot", models.ModerationReject, "banned_word"},
+		{"banned in capitals", "You IDIOT", models.ModerationReject, "banned_word"},
+		{"banned in leetspeak", "you 1d10t", models.ModerationReject, "banned_word"},
+		{"banned phrase across punctuation", "what a $c4m-artist!", models.ModerationReject, "banned_word"},
+		{"banned word inside another", "idiotic", models.ModerationAllow, ""},
+		{"default flagged phrase", "Pay me with a G1FT C4RD", models.ModerationFlag, "flagged_phrase"},
+		{"link shortener", "look at https://bit.ly/abc", models.ModerationFlag, "link_shortener"},
+		{"repeated characters", "nooooooooooooooo", models.ModerationFlag, "repeated_characters"},
+		{"shouting", "WHY ARE YOU NOT ANSWERING ME", models.ModerationFlag, "shouting"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			verdict, err := engine.Check(context.Background(), Content{Type: models.ModerationContentBio, AuthorID: "u1", Text: tt.text})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if verdict.Decision != tt.want || verdict.Rule != tt.rule {
+				t.Errorf("Check(%q) = %s/%q, want %s/%q", tt.text, verdict.Decision, verdict.Rule, tt.want, tt.rule)
+			}
+		})
+	}
+}
+
+func TestRuleEngineRepeats(t *testing.T) {
+	const spam = "Check out my portfolio please"
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	// Each step sends text as author after advancing the clock by after
+	type step struct {
+		author string
+		text   string
+		after  time.Duration
+		want   models.ModerationDecision
+	}
+	tests := []struct {
+		name  string
+		steps []step
+	}{
+		{"rejected at the limit", []step{
+			{"u1", spam, 0, models.ModerationAllow},
+			{"u1", spam, time.Second, models.ModerationAllow},
+			{"u1", spam, time.Second, models.ModerationReject},
+		}},
+		{"trivial variations count", []step{
+			{"u1", spam, 0, models.ModerationAllow},
+			{"u1", "CHECK out  my portfolio please", time.Second, models.ModerationAllow},
+			{"u1", "check out my\nportfolio please", time.Second, models.ModerationReject},
+		}},
+		{"counted per author", []step{
+			{"u1", spam, 0, models.ModerationAllow},
+			{"u1", spam, time.Second, models.ModerationAllow},
+			{"u2", spam, time.Second, models.ModerationAllow},
+			{"u2", spam, time.Second, models.ModerationAllow},
+		}},
+		{"older copies expire", []step{
+			{"u1", spam, 0, models.ModerationAllow},
+			{"u1", spam, time.Second, models.ModerationAllow},
+			{"u1", spam, time.Minute, models.ModerationAllow},
+			{"u1", spam, time.Second, models.ModerationAllow},
+			{"u1", spam, time.Second, models.ModerationReject},
+		}},
+		{"short messages are not checked", []step{
+			{"u1", "hi", 0, models.ModerationAllow},
+			{"u1", "hi", time.Second, models.ModerationAllow},
+			{"u1", "hi", time.Second, models.ModerationAllow},
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			engine := NewRuleEngine(RuleOptions{RepeatWindow: 30 * time.Second, RepeatLimit: 2})
+			now := start
+			engine.now = func() time.Time { return now }
+			for i, s := range tt.steps {
+				now = now.Add(s.after)
+				verdict, err := engine.Check(context.Background(), Content{Type: models.ModerationContentMessage, AuthorID: s.author, Text: s.text})
+				if err != nil {
+					t.Fatal(err)
+				}
+				if verdict.Decision != s.want {
+					t.Errorf("message %d from %s: %s (%s), want %s", i+1, s.author, verdict.Decision, verdict.Rule, s.want)
+				}
+			}
+		})
+	}
+}
+
+func TestRuleEngineRepeatedLink(t *testing.T) {
+	engine := NewRuleEngine(RuleOptions{RepeatWindow: time.Minute, RepeatLimit: 5})
+	check := func() Verdict {
+		verdict, _ := engine.Check(context.Background(), Content{Type: models.ModerationContentMessage, AuthorID: "u1", Text: "My site: https://example.com/portfolio"})
+		return verdict
+	}
+	if verdict := check(); verdict.Decision != models.ModerationAllow {
+		t.Errorf("first link: %+v, want allowed", verdict)
+	}
+	if verdict := check(); verdict.Rule != "repeated_link" {
+		t.Errorf("second link: %+v, want flagged as repeated_link", verdict)
+	}
+}
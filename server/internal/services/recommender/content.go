@@ -0,0 +1,77 @@
+package recommender
+
+import (
+	"math"
+	"strings"
+	"unicode"
+
+	"gin/internal/models"
+)
+
+// termVector maps a normalized term to its frequency.
+type termVector map[string]float64
+
+// stopWords are common words that carry no signal about a developer's interests.
+var stopWords = map[string]struct{}{
+	"a": {}, "an": {}, "and": {}, "are": {}, "at": {}, "be": {}, "by": {}, "for": {},
+	"from": {}, "i": {}, "i'm": {}, "in": {}, "is": {}, "it": {}, "my": {}, "of": {},
+	"on": {}, "or": {}, "the": {}, "to": {}, "we": {}, "with": {}, "you": {}, "your": {},
+	"http": {}, "https": {}, "www": {}, "com": {},
+}
+
+// profileTerms extracts the terms used for content similarity from a user profile.
+// Interest tags and the primary language are explicit signals, so they count double.
+func profileTerms(user models.User) termVector {
+	v := termVector{}
+	if user.Bio != nil {
+		v.addText(*user.Bio)
+	}
+	for _, tag := range user.Interests {
+		v[strings.ToLower(tag)] += 2
+	}
+	if user.PrimaryLanguage != nil {
+		v[strings.ToLower(*user.PrimaryLanguage)] += 2
+	}
+	return v
+}
+
+// addText tokenizes text and adds its terms. Characters such as '+' and '#' are kept
+// so that terms like "c++" and "c#" survive tokenization.
+func (v termVector) addText(text string) {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '+' && r != '#' && r != '\''
+	})
+	for _, term := range fields {
+		term = strings.Trim(term, "'")
+		if len(term) < 2 {
+			continue
+		}
+		if _, stop := stopWords[term]; stop {
+			continue
+		}
+		v[term]++
+	}
+}
+
+// add accumulates the frequencies of other into v.
+func (v termVector) add(other termVector) {
+	for term, freq := range other {
+		v[term] += freq
+	}
+}
+
+// cosine returns the cosine similarity of two term vectors, or 0 if either is empty.
+func (v termVector) cosine(other termVector) float64 {
+	if len(v) == 0 || len(other) == 0 {
+		return 0
+	}
+	var dot, normV, normOther float64
+	for term, freq := range v {
+		normV += freq * freq
+		dot += freq * other[term]
+	}
+	for _, freq := range other {
+		normOther += freq * freq
+	}
+	return dot / (math.Sqrt(normV) * math.Sqrt(normOther))
+}
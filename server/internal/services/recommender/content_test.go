@@ -0,0 +1,20 @@
+package recommender
+
+import (
+	"reflect"
+	"testing"
+
+	"gin/internal/models"
+)
+
+func TestProfileTerms(t *testing.T) {
+	bio := "I'm writing a C++ and C# compiler, in Go!"
+	language := "Go"
+	user := models.User{Bio: &bio, Interests: []string{"compilers", "WASM"}, PrimaryLanguage: &language}
+
+	// Stop words and one-letter terms are dropped; tags and the language count double
+	want := termVector{"writing": 1, "c++": 1, "c#": 1, "compiler": 1, "go": 3, "compilers": 2, "wasm": 2}
+	if got := profileTerms(user); !reflect.DeepEqual(got, want) {
+		t.Errorf("profileTerms = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,113 @@
+package recommender
+
+import (
+	"math/rand"
+	"sort"
+
+	"gin/internal/models"
+)
+
+// EvalOptions configures an offline evaluation run.
+type EvalOptions struct {
+	K               int     // Size of the recommendation list scored per user
+	HoldoutFraction float64 // Share of each user's likes hidden from training
+	MinLikes        int     // Users with fewer likes are skipped
+	CFWeight        float64 // Blend weight passed to Model.Score
+	Seed            int64   // Seed for the random holdout split
+}
+
+// EvalResult summarizes an offline evaluation run.
+type EvalResult struct {
+	UsersEvaluated int
+	HeldOutLikes   int
+	PrecisionAtK   float64 // Mean over evaluated users of hits@K / K
+	RecallAtK      float64 // Mean over evaluated users of hits@K / held-out likes
+}
+
+// Evaluate hides a random fraction of each user's likes, trains a model on the rest
+// and measures how many of the hidden likes appear in the top K recommendations.
+// Candidates for a user are every profile except themselves and the items they
+// liked in the training split, mirroring what the live deck would show.
+func Evaluate(interactions []Interaction, profiles []models.User, opts EvalOptions) EvalResult {
+	rng := rand.New(rand.NewSource(opts.Seed))
+
+	byUser := make(map[string][]string)
+	var userIDs []string
+	for _, in := range interactions {
+		if _, seen := byUser[in.UserID]; !seen {
+			userIDs = append(userIDs, in.UserID)
+		}
+		byUser[in.UserID] = append(byUser[in.UserID], in.ItemID)
+	}
+	// Visit users in a fixed order so a given seed always produces the same split.
+	sort.Strings(userIDs)
+
+	var train []Interaction
+	heldOut := make(map[string]map[string]struct{})
+	for _, userID := range userIDs {
+		items := byUser[userID]
+		if len(items) < opts.MinLikes || len(items) < 2 {
+			for _, item := range items {
+				train = append(train, Interaction{UserID: userID, ItemID: item})
+			}
+			continue
+		}
+
+		shuffled := append([]string(nil), items...)
+		rng.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+
+		n := int(float64(len(shuffled)) * opts.HoldoutFraction)
+		if n < 1 {
+			n = 1
+		}
+		if n >= len(shuffled) {
+			n = len(shuffled) - 1
+		}
+		heldOut[userID] = make(map[string]struct{}, n)
+		for _, item := range shuffled[:n] {
+			heldOut[userID][item] = struct{}{}
+		}
+		for _, item := range shuffled[n:] {
+			train = append(train, Interaction{UserID: userID, ItemID: item})
+		}
+	}
+
+	model := BuildModel(train, profiles, DefaultMaxNeighbors)
+
+	var result EvalResult
+	for userID, hidden := range heldOut {
+		candidates := make([]string, 0, len(profiles))
+		for _, profile := range profiles {
+			if profile.ID == userID {
+				continue
+			}
+			if _, liked := model.likes[userID][profile.ID]; liked {
+				continue
+			}
+			candidates = append(candidates, profile.ID)
+		}
+
+		ranked, _ := model.Rank(userID, candidates, opts.CFWeight)
+		if len(ranked) > opts.K {
+			ranked = ranked[:opts.K]
+		}
+
+		hits := 0
+		for _, id := range ranked {
+			if _, ok := hidden[id]; ok {
+				hits++
+			}
+		}
+
+		result.UsersEvaluated++
+		result.HeldOutLikes += len(hidden)
+		result.PrecisionAtK += float64(hits) / float64(opts.K)
+		result.RecallAtK += float64(hits) / float64(len(hidden))
+	}
+
+	if result.UsersEvaluated > 0 {
+		result.PrecisionAtK /= float64(result.UsersEvaluated)
+		result.RecallAtK /= float64(result.UsersEvaluated)
+	}
+	return result
+}
@@ -0,0 +1,174 @@
+package recommender
+
+import (
+	"math"
+	"sort"
+	"time"
+
+	"gin/internal/models"
+)
+
+// DefaultMaxNeighbors is the number of most similar items kept per item.
+const DefaultMaxNeighbors = 50
+
+// Interaction is a single piece of implicit positive feedback: UserID liked ItemID.
+// Items are themselves users, since the deck shows developer profiles.
+type Interaction struct {
+	UserID string
+	ItemID string
+}
+
+// InteractionsFromSwipes converts likes and super likes into interactions, ignoring other directions.
+func InteractionsFromSwipes(swipes []models.Swipe) []Interaction {
+	interactions := make([]Interaction, 0, len(swipes))
+	for _, swipe := range swipes {
+		if !swipe.Direction.IsLike() {
+			continue
+		}
+		interactions = append(interactions, Interaction{UserID: swipe.SwiperID, ItemID: swipe.SwipedID})
+	}
+	return interactions
+}
+
+// Model is an immutable snapshot of item-item similarities and profile content
+// used to score candidates. Build a new one with BuildModel instead of mutating.
+type Model struct {
+	// similarities[i][j] is the cosine similarity between the sets of users who liked i and j.
+	similarities map[string]map[string]float64
+	// likes[u] is the set of items user u liked.
+	likes map[string]map[string]struct{}
+	// terms[u] holds the term frequencies of user u's own profile text.
+	terms map[string]termVector
+
+	BuiltAt      time.Time
+	Interactions int
+}
+
+// BuildModel computes "users who liked X also liked Y" similarities from the given
+// interactions and indexes the profile text of every user for content scoring.
+// Only the maxNeighbors most similar items are kept per item.
+func BuildModel(interactions []Interaction, profiles []models.User, maxNeighbors int) *Model {
+	m := &Model{
+		similarities: make(map[string]map[string]float64),
+		likes:        make(map[string]map[string]struct{}),
+		terms:        make(map[string]termVector, len(profiles)),
+		BuiltAt:      time.Now(),
+	}
+
+	for _, profile := range profiles {
+		m.terms[profile.ID] = profileTerms(profile)
+	}
+
+	// likers[i] is the set of users who liked item i.
+	likers := make(map[string]map[string]struct{})
+	for _, in := range interactions {
+		if in.UserID == in.ItemID {
+			continue
+		}
+		if _, ok := m.likes[in.UserID]; !ok {
+			m.likes[in.UserID] = make(map[string]struct{})
+		}
+		if _, dup := m.likes[in.UserID][in.ItemID]; dup {
+			continue
+		}
+		m.likes[in.UserID][in.ItemID] = struct{}{}
+		if _, ok := likers[in.ItemID]; !ok {
+			likers[in.ItemID] = make(map[string]struct{})
+		}
+		likers[in.ItemID][in.UserID] = struct{}{}
+		m.Interactions++
+	}
+
+	// Count co-occurrences by walking each user's likes rather than comparing
+	// every pair of items, which keeps the cost proportional to the data.
+	coCounts := make(map[string]map[string]int)
+	for _, items := range m.likes {
+		for i := range items {
+			for j := range items {
+				if i == j {
+					continue
+				}
+				if _, ok := coCounts[i]; !ok {
+					coCounts[i] = make(map[string]int)
+				}
+				coCounts[i][j]++
+			}
+		}
+	}
+
+	type neighbor struct {
+		id  string
+		sim float64
+	}
+	for i, counts := range coCounts {
+		neighbors := make([]neighbor, 0, len(counts))
+		for j, co := range counts {
+			sim := float64(co) / math.Sqrt(float64(len(likers[i]))*float64(len(likers[j])))
+			neighbors = append(neighbors, neighbor{id: j, sim: sim})
+		}
+		sort.Slice(neighbors, func(a, b int) bool {
+			if neighbors[a].sim != neighbors[b].sim {
+				return neighbors[a].sim > neighbors[b].sim
+			}
+			return neighbors[a].id < neighbors[b].id
+		})
+		if maxNeighbors > 0 && len(neighbors) > maxNeighbors {
+			neighbors = neighbors[:maxNeighbors]
+		}
+		m.similarities[i] = make(map[string]float64, len(neighbors))
+		for _, n := range neighbors {
+			m.similarities[i][n.id] = n.sim
+		}
+	}
+
+	return m
+}
+
+// CollaborativeScore is the mean similarity between candidateID and the items userID
+// has liked, in [0, 1]. It is 0 for users without likes.
+func (m *Model) CollaborativeScore(userID, candidateID string) float64 {
+	liked := m.likes[userID]
+	if len(liked) == 0 {
+		return 0
+	}
+	var total float64
+	for item := range liked {
+		total += m.similarities[item][candidateID]
+	}
+	return total / float64(len(liked))
+}
+
+// ContentScore is the cosine similarity between candidateID's profile text and
+// userID's taste, which combines their own profile with the profiles they liked.
+func (m *Model) ContentScore(userID, candidateID string) float64 {
+	taste := termVector{}
+	taste.add(m.terms[userID])
+	for item := range m.likes[userID] {
+		taste.add(m.terms[item])
+	}
+	return taste.cosine(m.terms[candidateID])
+}
+
+// Score blends the collaborative and content scores. cfWeight is the weight given
+// to the collaborative score; users without likes fall back to content only.
+func (m *Model) Score(userID, candidateID string, cfWeight float64) float64 {
+	content := m.ContentScore(userID, candidateID)
+	if len(m.likes[userID]) == 0 {
+		return content
+	}
+	return cfWeight*m.CollaborativeScore(userID, candidateID) + (1-cfWeight)*content
+}
+
+// Rank returns candidateIDs ordered by descending blended score, along with the scores.
+// Ties keep the input order so callers can supply a sensible fallback ordering.
+func (m *Model) Rank(userID string, candidateIDs []string, cfWeight float64) ([]string, map[string]float64) {
+	scores := make(map[string]float64, len(candidateIDs))
+	for _, id := range candidateIDs {
+		scores[id] = m.Score(userID, id, cfWeight)
+	}
+	ranked := append([]string(nil), candidateIDs...)
+	sort.SliceStable(ranked, func(a, b int) bool {
+		return scores[ranked[a]] > scores[ranked[b]]
+	})
+	return ranked, scores
+}
@@ -0,0 +1,198 @@
+package recommender
+
+import (
+	"fmt"
+	"math"
+	"reflect"
+	"testing"
+
+	"gin/internal/models"
+)
+
+// profile returns a user with the given ID and bio, or no bio if bio is empty.
+func profile(id, bio string) models.User {
+	user := models.User{ID: id}
+	if bio != "" {
+		user.Bio = &bio
+	}
+	return user
+}
+
+// likes turns {user, item} pairs into interactions.
+func likes(pairs ...[2]string) []Interaction {
+	interactions := make([]Interaction, len(pairs))
+	for i, pair := range pairs {
+		interactions[i] = Interaction{UserID: pair[0], ItemID: pair[1]}
+	}
+	return interactions
+}
+
+func approxEqual(a, b float64) bool {
+	return math.Abs(a-b) < 1e-9
+}
+
+func TestInteractionsFromSwipes(t *testing.T) {
+	swipes := []models.Swipe{
+		{SwiperID: "u1", SwipedID: "a", Direction: models.SwipeLike},
+		{SwiperID: "u1", SwipedID: "b", Direction: models.SwipeDislike},
+		{SwiperID: "u2", SwipedID: "a", Direction: models.SwipeLike},
+		{SwiperID: "u2", SwipedID: "c", Direction: models.SwipeSuperLike},
+	}
+	want := likes([2]string{"u1", "a"}, [2]string{"u2", "a"}, [2]string{"u2", "c"})
+	if got := InteractionsFromSwipes(swipes); !reflect.DeepEqual(got, want) {
+		t.Errorf("InteractionsFromSwipes = %v, want %v", got, want)
+	}
+}
+
+func TestBuildModelSimilarities(t *testing.T) {
+	// a and b are liked by the same two users; c shares one of a's and b's likers
+	interactions := likes(
+		[2]string{"u1", "a"}, [2]string{"u1", "b"},
+		[2]string{"u2", "a"}, [2]string{"u2", "b"}, [2]string{"u2", "c"},
+		[2]string{"u3", "c"},
+		[2]string{"u3", "c"},  // Duplicate, counted once
+		[2]string{"u3", "u3"}, // Self-like, ignored
+	)
+	model := BuildModel(interactions, nil, DefaultMaxNeighbors)
+	if model.Interactions != 6 {
+		t.Errorf("Interactions = %d, want 6", model.Interactions)
+	}
+
+	tests := []struct {
+		user, candidate string
+		want            float64
+	}{
+		{"u3", "a", 0.5}, // u3 liked c; c and a share one of two likers each
+		{"u3", "b", 0.5},
+		{"u1", "c", 0.5}, // Mean over a and b
+		{"u2", "a", 0.5}, // a itself contributes nothing: (0 + 1 + 0.5) / 3
+		{"u1", "u2", 0},  // Never liked
+		{"nobody", "a", 0},
+	}
+	for _, tt := range tests {
+		if got := model.CollaborativeScore(tt.user, tt.candidate); !approxEqual(got, tt.want) {
+			t.Errorf("CollaborativeScore(%s, %s) = %v, want %v", tt.user, tt.candidate, got, tt.want)
+		}
+	}
+	if got := model.similarities["a"]["b"]; !approxEqual(got, 1) {
+		t.Errorf("similarity(a, b) = %v, want 1", got)
+	}
+}
+
+func TestBuildModelMaxNeighbors(t *testing.T) {
+	interactions := likes(
+		[2]string{"u1", "a"}, [2]string{"u1", "b"},
+		[2]string{"u2", "a"}, [2]string{"u2", "b"}, [2]string{"u2", "c"},
+		[2]string{"u3", "c"},
+	)
+	model := BuildModel(interactions, nil, 1)
+
+	tests := []struct {
+		item string
+		want map[string]float64
+	}{
+		{"a", map[string]float64{"b": 1}},
+		{"c", map[string]float64{"a": 0.5}}, // a and b tie; the lower ID is kept
+	}
+	for _, tt := range tests {
+		if got := model.similarities[tt.item]; !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("neighbors of %s = %v, want %v", tt.item, got, tt.want)
+		}
+	}
+}
+
+func TestScore(t *testing.T) {
+	profiles := []models.User{
+		profile("u1", "Go developer"),
+		profile("a", "Go backend"),
+		profile("b", "Rust"),
+		profile("c", "Go and Kubernetes"),
+		profile("newcomer", "Rust systems programming"),
+		profile("rustacean", "Rust compiler hacker"),
+		profile("frontend", "React and CSS"),
+		profile("blank", ""),
+	}
+	interactions := likes(
+		[2]string{"u1", "a"}, [2]string{"u1", "b"},
+		[2]string{"u2", "a"}, [2]string{"u2", "b"}, [2]string{"u2", "c"},
+		[2]string{"u3", "c"},
+	)
+	model := BuildModel(interactions, profiles, DefaultMaxNeighbors)
+
+	// u1's taste is their bio plus those of a and b: go:2, developer, backend, rust
+	tasteToC := 2 / math.Sqrt(7*2)
+
+	tests := []struct {
+		name            string
+		user, candidate string
+		cfWeight        float64
+		want            float64
+	}{
+		{"blend", "u1", "c", 0.6, 0.6*0.5 + 0.4*tasteToC},
+		{"collaborative only", "u1", "c", 1, 0.5},
+		{"content only", "u1", "c", 0, tasteToC},
+		{"taste from liked profiles only", "u3", "a", 0.6, 0.6*0.5 + 0.4*0.5}, // u3 has no bio but liked c
+		{"cold start uses content only", "newcomer", "rustacean", 0.6, 1.0 / 3},
+		{"cold start without overlap", "newcomer", "frontend", 0.6, 0},
+		{"candidate without bio", "newcomer", "blank", 0.6, 0},
+		{"unknown user", "ghost", "rustacean", 0.6, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := model.Score(tt.user, tt.candidate, tt.cfWeight); !approxEqual(got, tt.want) {
+				t.Errorf("Score(%s, %s, %v) = %v, want %v", tt.user, tt.candidate, tt.cfWeight, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestRankColdStart(t *testing.T) {
+	profiles := []models.User{
+		profile("newcomer", "Rust systems programming"),
+		profile("frontend", "React and CSS"),
+		profile("blank", ""),
+		profile("rustacean", "Rust compiler hacker"),
+	}
+	model := BuildModel(nil, profiles, DefaultMaxNeighbors)
+
+	ranked, scores := model.Rank("newcomer", []string{"frontend", "blank", "rustacean"}, 0.7)
+	// Candidates without a content match tie at 0 and keep their input order
+	if want := []string{"rustacean", "frontend", "blank"}; !reflect.DeepEqual(ranked, want) {
+		t.Errorf("Rank = %v, want %v", ranked, want)
+	}
+	if !approxEqual(scores["rustacean"], 1.0/3) || scores["frontend"] != 0 {
+		t.Errorf("scores = %v", scores)
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	// Eight users like the same five projects; a ninth user has a single like and
+	// is too sparse to evaluate. Noise profiles are never liked.
+	var interactions []Interaction
+	var profiles []models.User
+	for i := 1; i <= 8; i++ {
+		user := fmt.Sprintf("user%d", i)
+		profiles = append(profiles, profile(user, ""))
+		for j := 1; j <= 5; j++ {
+			interactions = append(interactions, Interaction{UserID: user, ItemID: fmt.Sprintf("project%d", j)})
+		}
+	}
+	for j := 1; j <= 5; j++ {
+		profiles = append(profiles, profile(fmt.Sprintf("project%d", j), ""), profile(fmt.Sprintf("noise%d", j), ""))
+	}
+	profiles = append(profiles, profile("sparse", ""))
+	interactions = append(interactions, Interaction{UserID: "sparse", ItemID: "project1"})
+
+	opts := EvalOptions{K: 1, HoldoutFraction: 0.2, MinLikes: 2, CFWeight: 1, Seed: 42}
+	result := Evaluate(interactions, profiles, opts)
+	if result.UsersEvaluated != 8 || result.HeldOutLikes != 8 {
+		t.Errorf("evaluated %d users with %d held-out likes, want 8 and 8", result.UsersEvaluated, result.HeldOutLikes)
+	}
+	// Each user's single held-out project is the only candidate similar to their likes
+	if !approxEqual(result.PrecisionAtK, 1) || !approxEqual(result.RecallAtK, 1) {
+		t.Errorf("precision@1 = %v, recall@1 = %v, want 1 and 1", result.PrecisionAtK, result.RecallAtK)
+	}
+	if again := Evaluate(interactions, profiles, opts); again != result {
+		t.Errorf("second run with the same seed = %+v, want %+v", again, result)
+	}
+}
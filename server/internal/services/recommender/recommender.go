@@ -0,0 +1,113 @@
+// Package recommender orders swipe decks by blending item-item collaborative
+// filtering over swipe history with content-based profile similarity.
+package recommender
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"gin/internal/logging"
+	"gin/internal/models"
+	"gin/internal/services/database"
+)
+
+var logger = logging.For("recommender")
+
+// Recommender periodically rebuilds a Model from the database and uses the latest
+// snapshot to rank swipe candidates. It is safe for concurrent use.
+type Recommender struct {
+	users    database.UserRepository
+	swipes   database.SwipeRepository
+	cfWeight float64
+	interval time.Duration
+
+	mu    sync.RWMutex
+	model *Model
+}
+
+// NewRecommender creates a Recommender. cfWeight is the share of the blended score
+// given to collaborative filtering; interval controls how often the model is rebuilt.
+func NewRecommender(users database.UserRepository, swipes database.SwipeRepository, cfWeight float64, interval time.Duration) *Recommender {
+	return &Recommender{
+		users:    users,
+		swipes:   swipes,
+		cfWeight: cfWeight,
+		interval: interval,
+	}
+}
+
+// Start rebuilds the model immediately and then on every interval until ctx is done.
+// It blocks, so run it in its own goroutine.
+func (r *Recommender) Start(ctx context.Context) {
+	if err := r.Refresh(ctx); err != nil {
+		logger.WarnContext(ctx, "Initial recommender refresh failed", "error", err)
+	}
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			logger.Info("Recommender refresh loop stopped.")
+			return
+		case <-ticker.C:
+			if err := r.Refresh(ctx); err != nil {
+				logger.WarnContext(ctx, "Recommender refresh failed", "error", err)
+			}
+		}
+	}
+}
+
+// Refresh rebuilds the model from the current swipes and profiles and swaps it in.
+func (r *Recommender) Refresh(ctx context.Context) error {
+	started := time.Now()
+
+	likes, err := r.swipes.GetLikes(ctx)
+	if err != nil {
+		return fmt.Errorf("loading likes: %w", err)
+	}
+	profiles, err := r.users.GetAllUserProfiles(ctx)
+	if err != nil {
+		return fmt.Errorf("loading profiles: %w", err)
+	}
+
+	model := BuildModel(InteractionsFromSwipes(likes), profiles, DefaultMaxNeighbors)
+
+	r.mu.Lock()
+	r.model = model
+	r.mu.Unlock()
+
+	logger.InfoContext(ctx, "Recommender model rebuilt", "likes", model.Interactions, "profiles", len(profiles), "duration_ms", time.Since(started).Milliseconds())
+	return nil
+}
+
+// RankCandidates orders candidates for userID by descending recommendation score.
+// Before the first model is built, candidates are returned in their original order with a zero score.
+func (r *Recommender) RankCandidates(userID string, candidates []models.User) []models.SwipeCard {
+	r.mu.RLock()
+	model := r.model
+	r.mu.RUnlock()
+
+	cards := make([]models.SwipeCard, 0, len(candidates))
+	if model == nil {
+		for _, candidate := range candidates {
+			cards = append(cards, models.SwipeCard{User: candidate})
+		}
+		return cards
+	}
+
+	byID := make(map[string]models.User, len(candidates))
+	ids := make([]string, 0, len(candidates))
+	for _, candidate := range candidates {
+		byID[candidate.ID] = candidate
+		ids = append(ids, candidate.ID)
+	}
+
+	ranked, scores := model.Rank(userID, ids, r.cfWeight)
+	for _, id := range ranked {
+		cards = append(cards, models.SwipeCard{User: byID[id], Score: scores[id]})
+	}
+	return cards
+}
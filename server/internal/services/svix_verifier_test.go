@@ -0,0 +1,79 @@
+package services
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// svixSignature signs body the way Svix does, for message msgID sent at timestamp.
+func svixSignature(key []byte, msgID, timestamp, body string) string {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(msgID + "." + timestamp + "." + body))
+	return base64.StdEncoding.EncodeToString(mac.Sum(nil))
+}
+
+func TestNewSvixVerifier(t *testing.T) {
+	for _, secret := range []string{"", "whsec_", "whsec_not base64!"} {
+		if _, err := NewSvixVerifier(secret); err == nil {
+			t.Errorf("NewSvixVerifier(%q) succeeded, want an error", secret)
+		}
+	}
+}
+
+func TestSvixVerify(t *testing.T) {
+	key := []byte("test-signing-key")
+	otherKey := []byte("rotated-out-key")
+	verifier, err := NewSvixVerifier("whsec_" + base64.StdEncoding.EncodeToString(key))
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1700000000, 0)
+	verifier.now = func() time.Time { return now }
+
+	body := `{"type":"user.created"}`
+	fresh := strconv.FormatInt(now.Unix(), 10)
+	stale := strconv.FormatInt(now.Add(-6*time.Minute).Unix(), 10)
+	valid := "v1," + svixSignature(key, "msg_1", fresh, body)
+
+	tests := []struct {
+		name                        string
+		msgID, timestamp, signature string
+		body                        string
+		wantErr                     bool
+	}{
+		{"valid", "msg_1", fresh, valid, body, false},
+		{"one of several signatures", "msg_1", fresh, "v1," + svixSignature(otherKey, "msg_1", fresh, body) + " " + valid, body, false},
+		{"tampered body", "msg_1", fresh, valid, `{"type":"user.deleted"}`, true},
+		{"other message ID", "msg_2", fresh, valid, body, true},
+		{"wrong key", "msg_1", fresh, "v1," + svixSignature(otherKey, "msg_1", fresh, body), body, true},
+		{"unknown version", "msg_1", fresh, "v2," + svixSignature(key, "msg_1", fresh, body), body, true},
+		{"stale timestamp", "msg_1", stale, "v1," + svixSignature(key, "msg_1", stale, body), body, true},
+		{"malformed timestamp", "msg_1", "yesterday", valid, body, true},
+		{"missing signature", "msg_1", fresh, "", body, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			header := http.Header{}
+			header.Set("svix-id", tt.msgID)
+			header.Set("svix-timestamp", tt.timestamp)
+			header.Set("svix-signature", tt.signature)
+
+			msgID, err := verifier.Verify(header, []byte(tt.body))
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidWebhookSignature) {
+					t.Errorf("Verify error = %v, want ErrInvalidWebhookSignature", err)
+				}
+				return
+			}
+			if err != nil || msgID != tt.msgID {
+				t.Errorf("Verify = %q, %v; want %q", msgID, err, tt.msgID)
+			}
+		})
+	}
+}
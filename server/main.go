@@ -2,21 +2,31 @@ package main
 
 import (
 	"context"
-	"log"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
-	"gin/api/routes"                 // Corrected import path
-	"gin/internal/config"            // Corrected import path
-	"gin/internal/services"          // Added services import
+	"gin/api/middleware"
+	"gin/api/routes"      // Corrected import path
+	"gin/internal/config" // Corrected import path
+	"gin/internal/logging"
+	"gin/internal/metrics"
+	"gin/internal/services" // Added services import
+	"gin/internal/services/accounts"
+	"gin/internal/services/auth"
 	"gin/internal/services/database" // Corrected import path
+	"gin/internal/services/export"
+	"gin/internal/services/githubsync"
+	"gin/internal/services/moderation"
+	"gin/internal/services/recommender"
 
 	"github.com/clerkinc/clerk-sdk-go/clerk"
 )
 
+var logger = logging.For("main")
+
 // @title Your Project API
 // @version 1.0
 // @description This is the API server for the Your Project application.
@@ -38,29 +48,39 @@ import (
 // @description Clerk JWT token (include 'Bearer ' prefix)
 
 func main() {
-	log.Println("Starting server...")
+	logger.Info("Starting server...")
 
 	// Load Configuration
 	cfg := config.LoadConfig()
-	log.Printf("Configuration loaded: Port=%s, GinMode=%s\n", cfg.Port, cfg.GinMode)
-
-	// Initialize Clerk Client
-	clerkClient, err := clerk.NewClient(cfg.ClerkSecretKey)
-	if err != nil {
-		log.Fatalf("Failed to create Clerk client: %v", err)
+	logging.Configure(cfg.LogLevel, cfg.LogLevels)
+	logger.Info("Configuration loaded", "port", cfg.Port, "gin_mode", cfg.GinMode, "log_level", cfg.LogLevel)
+
+	// Initialize Clerk Client - optional unless Clerk is the auth provider. Without it,
+	// profiles are provisioned bare and account deletion skips Clerk.
+	var clerkClient clerk.Client
+	var clerkService *services.ClerkService
+	if cfg.ClerkSecretKey != "" {
+		var err error
+		clerkClient, err = clerk.NewClient(cfg.ClerkSecretKey)
+		if err != nil {
+			logging.Fatal(logger, "Failed to create Clerk client", "error", err)
+		}
+		clerkService = services.NewClerkService(clerkClient, cfg)
+		logger.Info("Clerk client initialized successfully.")
+	} else {
+		logger.Warn("CLERK_SECRET_KEY not set, running without Clerk.")
 	}
-	log.Println("Clerk client initialized successfully.")
 
 	// Initialize Database Connection Pool
-	dbPool, err := database.ConnectDB(cfg.SQLitePath)
+	dbPool, err := database.ConnectDB(cfg.DatabaseURL)
 	if err != nil {
-		log.Fatalf("Failed to initialize database connection: %v", err)
+		logging.Fatal(logger, "Failed to initialize database connection", "error", err)
 	}
 	defer func() {
-		log.Println("Closing database connection pool...")
+		logger.Info("Closing database connection pool...")
 		dbPool.Close()
 	}()
-	log.Println("Database connection pool initialized successfully.")
+	logger.Info("Database connection pool initialized successfully.")
 
 	// Initialize Services
 	githubService := services.NewGitHubService(cfg)
@@ -68,38 +88,83 @@ func main() {
 	if err != nil {
 		// Decide how to handle Gemini init failure - fatal or just log?
 		// For now, log and continue, but the service will be nil/unusable.
-		// log.Fatalf("Failed to initialize Gemini service: %v", err) // Option: Fatal
-		log.Printf("Warning: Failed to initialize Gemini service: %v. Gemini features will be disabled.", err)
+		// logging.Fatal(logger, "Failed to initialize Gemini service", "error", err) // Option: Fatal
+		logger.Warn("Failed to initialize Gemini service. Gemini features will be disabled.", "error", err)
 		// Ensure geminiService is nil if error occurred (already is due to return nil)
 	} else {
 		// Ensure Gemini client is closed on shutdown
 		defer func() {
-			log.Println("Closing Gemini client...")
+			logger.Info("Closing Gemini client...")
 			geminiService.Close()
 		}()
 	}
-	clerkService := services.NewClerkService(clerkClient, cfg)
-	log.Println("Application services initialized.")
+
+	// Background jobs are stopped through this context on shutdown
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+
+	dbService := database.NewDBService(dbPool)
+	rec := recommender.NewRecommender(dbService, dbService, cfg.RecommenderCFWeight, cfg.RecommenderRefreshInterval)
+	go rec.Start(bgCtx)
+	if cfg.BackupInterval > 0 {
+		go dbPool.RunPeriodicBackups(bgCtx, cfg.BackupDir, cfg.BackupInterval, cfg.BackupKeep)
+	}
+	exporter := export.NewExporter(dbService, cfg.ExportDir, cfg.ExportTTL, cfg.ExportAsyncThreshold)
+	go exporter.Start(bgCtx, time.Hour)
+	var clerkDeleter accounts.ClerkUserDeleter // Left nil without Clerk, so purges skip it
+	if clerkService != nil {
+		clerkDeleter = clerkService
+	}
+	// Profiles of authenticated users are cached briefly; whatever changes or deletes them
+	// outside a request by that user (webhooks, purges) invalidates them here
+	userCache := middleware.NewUserCache(dbService, cfg.UserCacheTTL)
+	purger := accounts.NewPurger(dbService, dbService, clerkDeleter, userCache, 15*time.Minute)
+	go purger.Start(bgCtx)
+
+	// Clerk webhooks keep profiles in step with Clerk; without a signing secret they are refused
+	var webhookVerifier *services.SvixVerifier
+	if cfg.ClerkWebhookSecret != "" {
+		webhookVerifier, err = services.NewSvixVerifier(cfg.ClerkWebhookSecret)
+		if err != nil {
+			logging.Fatal(logger, "Invalid CLERK_WEBHOOK_SECRET", "error", err)
+		}
+	} else {
+		logger.Warn("CLERK_WEBHOOK_SECRET not set, Clerk webhooks will be refused.")
+	}
+
+	moderator := newModerator(cfg, dbService, geminiService)
+
+	// GitHub stats feed deck filters and search: fetched when a profile gets a GitHub
+	// account, then refreshed in the background once they are older than the interval
+	githubSync := githubsync.NewSyncer(dbService, githubService, cfg.GitHubStatsRefreshInterval)
+	if cfg.GitHubStatsRefreshInterval > 0 {
+		go githubSync.Start(bgCtx, time.Hour)
+	}
+
+	authenticator := newAuthenticator(bgCtx, cfg, clerkClient)
+	logger.Info("Application services initialized.")
 
 	// Setup Gin Router
-	router := routes.SetupRouter(dbPool, clerkClient, githubService, geminiService, clerkService)
-	log.Println("Gin router setup complete.")
+	router := routes.SetupRouter(cfg, dbPool, authenticator, githubService, geminiService, clerkService, rec, exporter, purger, webhookVerifier, moderator, githubSync, userCache)
+	logger.Info("Gin router setup complete.")
 
 	// Setup HTTP Server
 	server := &http.Server{
-		Addr:    ":" + cfg.Port,
-		Handler: router,
-		// Optional: Add timeouts for production readiness
-		// ReadTimeout:  15 * time.Second,
-		// WriteTimeout: 15 * time.Second,
-		// IdleTimeout:  60 * time.Second,
+		Addr:              ":" + cfg.Port,
+		Handler:           router,
+		ReadTimeout:       cfg.ServerReadTimeout,
+		ReadHeaderTimeout: cfg.ServerReadHeaderTimeout,
+		WriteTimeout:      cfg.ServerWriteTimeout,
+		IdleTimeout:       cfg.ServerIdleTimeout,
+		MaxHeaderBytes:    cfg.MaxHeaderBytes,
+		ConnState:         metrics.TrackConnState, // Open connections for /metrics
 	}
 
 	// Run server in a goroutine so it doesn't block
 	go func() {
-		log.Printf("Server listening on port %s\n", cfg.Port)
+		logger.Info("Server listening", "port", cfg.Port)
 		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Fatalf("listen: %s\n", err)
+			logging.Fatal(logger, "Server failed to listen", "error", err)
 		}
 	}()
 
@@ -110,15 +175,74 @@ func main() {
 	// kill -9 is syscall.SIGKILL but can't be caught, so don't need to add it
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit // Block until a signal is received
-	log.Println("Shutting down server...")
+	logger.Info("Shutting down server...")
+	stopBackground()
 
 	// The context is used to inform the server it has 5 seconds to finish
 	// the requests it is currently handling
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 	if err := server.Shutdown(ctx); err != nil {
-		log.Fatal("Server forced to shutdown:", err)
+		logging.Fatal(logger, "Server forced to shutdown", "error", err)
+	}
+
+	logger.Info("Server exiting.")
+}
+
+// newAuthenticator creates the Authenticator selected by AUTH_PROVIDER. For Clerk it
+// also loads the signing keys and starts refreshing them until ctx is done.
+func newAuthenticator(ctx context.Context, cfg *config.Config, clerkClient clerk.Client) auth.Authenticator {
+	switch cfg.AuthProvider {
+	case config.AuthProviderLocal:
+		authenticator, err := auth.NewLocalAuthenticator(cfg.LocalAuthSecret, cfg.ClerkClockSkew)
+		if err != nil {
+			logging.Fatal(logger, "Invalid LOCAL_AUTH_SECRET", "error", err)
+		}
+		logger.Info("Authenticating requests with locally signed tokens.")
+		return authenticator
+
+	case config.AuthProviderDev:
+		logger.Warn("DEV AUTH MODE - requests act as whichever seeded user the header names, without credentials.", "header", auth.DevUserHeader)
+		return auth.NewDevAuthenticator()
 	}
 
-	log.Println("Server exiting.")
+	// Session tokens are verified locally against Clerk's signing keys, refreshed in the
+	// background; CLERK_JWKS_FILE swaps Clerk for a local key set (see cmd/devtoken)
+	var jwksSource auth.JWKSSource = auth.NewClerkJWKSSource(clerkClient)
+	if cfg.ClerkJWKSFile != "" {
+		logger.Warn("Verifying session tokens against a local key set instead of Clerk. Never do this in production.", "file", cfg.ClerkJWKSFile)
+		jwksSource = auth.NewFileJWKSSource(cfg.ClerkJWKSFile)
+	}
+	signingKeys := auth.NewKeySet(jwksSource)
+	if err := signingKeys.Refresh(ctx); err != nil {
+		// Not fatal: the keys are fetched again when the first token arrives
+		logger.Warn("Initial JWKS fetch failed", "error", err)
+	}
+	go signingKeys.Start(ctx, cfg.ClerkJWKSRefreshInterval)
+	return auth.NewClerkAuthenticator(auth.NewVerifier(signingKeys, auth.VerifierOptions{
+		Issuer:            cfg.ClerkIssuer,
+		AuthorizedParties: cfg.ClerkAuthorizedParties,
+		ClockSkew:         cfg.ClerkClockSkew,
+	}))
+}
+
+// newModerator creates the content moderation pipeline: the local rules, then Gemini if
+// MODERATION_LLM is set and Gemini is available.
+func newModerator(cfg *config.Config, dbService *database.DBService, geminiService *services.GeminiService) *moderation.Moderator {
+	checkers := []moderation.Checker{moderation.NewRuleEngine(moderation.RuleOptions{
+		BannedWords:    cfg.ModerationBannedWords,
+		FlaggedPhrases: cfg.ModerationFlaggedPhrases,
+		MaxLinks:       cfg.ModerationMaxLinks,
+		RepeatWindow:   cfg.ModerationRepeatWindow,
+		RepeatLimit:    cfg.ModerationRepeatLimit,
+	})}
+	if cfg.ModerationLLM {
+		if geminiService != nil {
+			checkers = append(checkers, moderation.NewLLMChecker(geminiService, cfg.ModerationLLMTimeout))
+			logger.Info("Moderating content with local rules and Gemini.")
+		} else {
+			logger.Warn("MODERATION_LLM is set but Gemini is unavailable; moderating content with local rules only.")
+		}
+	}
+	return moderation.NewModerator(dbService, checkers...)
 }